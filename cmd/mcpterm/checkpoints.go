@@ -0,0 +1,132 @@
+package mcpterm
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/checkpoint"
+	"github.com/navicore/mcpterm-go/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var checkpointDir string // Overrides the configured checkpoint directory
+
+var checkpointsCmd = &cobra.Command{
+	Use:   "checkpoints",
+	Short: "List, diff and restore file checkpoints",
+	Long: `File checkpoints are snapshots taken before a tool modifies files.
+They are grouped by conversation turn and can be restored even outside
+of a git repository.`,
+}
+
+var checkpointsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded turns and the files they changed",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		turns, err := openCheckpointStore().ListTurns()
+		if err != nil {
+			return err
+		}
+
+		if len(turns) == 0 {
+			fmt.Println("No checkpoints recorded")
+			return nil
+		}
+
+		for _, turn := range turns {
+			if len(turn.Entries)+len(turn.Skipped) == 0 {
+				continue
+			}
+
+			status := ""
+			if turn.RestoredAt != nil {
+				status = fmt.Sprintf(" (restored %s)", turn.RestoredAt.Format("2006-01-02 15:04:05"))
+			}
+
+			fmt.Printf("%s  %s%s\n", turn.ID, turn.CreatedAt.Format("2006-01-02 15:04:05"), status)
+			if turn.Prompt != "" {
+				fmt.Printf("  prompt: %s\n", summarizePrompt(turn.Prompt, 72))
+			}
+			for _, file := range turn.Files() {
+				fmt.Printf("  %s\n", file)
+			}
+			for _, skip := range turn.Skipped {
+				fmt.Printf("  %s (not snapshotted: %s)\n", skip.Path, skip.Reason)
+			}
+		}
+
+		return nil
+	},
+}
+
+var checkpointsDiffCmd = &cobra.Command{
+	Use:   "diff <turn-id>",
+	Short: "Show changes made since a turn's checkpoint",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		diff, err := openCheckpointStore().Diff(args[0])
+		if err != nil {
+			return err
+		}
+
+		if diff == "" {
+			fmt.Println("No differences")
+			return nil
+		}
+
+		fmt.Print(diff)
+		return nil
+	},
+}
+
+var checkpointsRestoreCmd = &cobra.Command{
+	Use:   "restore <turn-id>",
+	Short: "Restore files to their state before a turn",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store := openCheckpointStore()
+		restored, err := store.Restore(args[0])
+		for _, path := range restored {
+			fmt.Printf("restored %s\n", path)
+		}
+		if turn, loadErr := store.LoadTurn(args[0]); loadErr == nil {
+			for _, skip := range turn.Skipped {
+				fmt.Printf("not restored %s: %s\n", skip.Path, skip.Reason)
+			}
+		}
+		return err
+	},
+}
+
+// openCheckpointStore opens the checkpoint store from flags or configuration
+func openCheckpointStore() *checkpoint.Store {
+	dir := checkpointDir
+	if dir == "" {
+		cfg, err := config.LoadConfig(configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not load configuration: %v\n", err)
+		}
+		dir = cfg.Chat.Checkpoints.Dir
+	}
+
+	return checkpoint.NewStore(dir)
+}
+
+// summarizePrompt shortens a prompt to a single line of at most maxLen characters
+func summarizePrompt(prompt string, maxLen int) string {
+	prompt = strings.Join(strings.Fields(prompt), " ")
+	if len(prompt) <= maxLen {
+		return prompt
+	}
+	return prompt[:maxLen-3] + "..."
+}
+
+func init() {
+	checkpointsCmd.PersistentFlags().StringVar(&checkpointDir, "dir", "", "Checkpoint directory (default is $HOME/.mcpterm/checkpoints)")
+
+	checkpointsCmd.AddCommand(checkpointsListCmd)
+	checkpointsCmd.AddCommand(checkpointsDiffCmd)
+	checkpointsCmd.AddCommand(checkpointsRestoreCmd)
+	rootCmd.AddCommand(checkpointsCmd)
+}
@@ -0,0 +1,59 @@
+package mcpterm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/navicore/mcpterm-go/pkg/chat"
+	"github.com/navicore/mcpterm-go/pkg/config"
+	"github.com/navicore/mcpterm-go/pkg/jsonrpc"
+	"github.com/navicore/mcpterm-go/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpServeCmd = &cobra.Command{
+	Use:   "mcp-serve",
+	Short: "Serve the built-in tools as an MCP server over stdio",
+	Long: `Runs mcpterm as a Model Context Protocol server on stdin and stdout,
+so other MCP clients and editors can use its built-in tools.
+
+The tool settings of the configuration file apply: only enabled tool
+categories are offered, and timeouts, input validation and file
+checkpoints work as they do in a chat. Use --enable-tool-categories to
+choose the categories to serve.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadAndMergeConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not load configuration: %v\nUsing defaults\n", err)
+			cfg = config.DefaultConfig()
+		}
+
+		// Stdout carries the protocol, so send anything else printed to stderr
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+
+		opts := cfg.GetStandardChatOptions()
+		toolManager, err := chat.NewConfiguredToolManager(opts)
+		if err != nil {
+			return err
+		}
+		defer toolManager.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err = mcp.NewServer(toolManager).Serve(ctx, jsonrpc.NewLineStream(os.Stdin, stdout))
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mcpServeCmd)
+}
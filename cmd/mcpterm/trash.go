@@ -0,0 +1,70 @@
+package mcpterm
+
+import (
+	"fmt"
+
+	"github.com/navicore/mcpterm-go/pkg/trash"
+	"github.com/spf13/cobra"
+)
+
+var trashRestoreTo string // Restores to this path instead of the original one
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List and restore files moved to the trash",
+	Long: `The file_delete tool moves files to the freedesktop trash on Linux
+(~/.local/share/Trash, or a .Trash directory on other mounts), where
+they can be listed and restored. On macOS use the Finder's Trash.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List items in the trash, most recently deleted first",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		items, err := trash.List()
+		if err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			fmt.Println("The trash is empty")
+			return nil
+		}
+
+		for _, item := range items {
+			path := item.Path
+			if item.IsDir {
+				path += "/"
+			}
+			fmt.Printf("%s  %s  (%s)\n", item.DeletionDate.Format("2006-01-02 15:04:05"), path, item.Name)
+		}
+		return nil
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <path-or-name>",
+	Short: "Restore the most recently deleted item with a path or trash name",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		item, err := trash.Find(args[0])
+		if err != nil {
+			return err
+		}
+
+		dest, err := trash.Restore(item, trashRestoreTo)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("restored %s\n", dest)
+		return nil
+	},
+}
+
+func init() {
+	trashRestoreCmd.Flags().StringVar(&trashRestoreTo, "to", "", "Restore to this path instead of the original one")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	rootCmd.AddCommand(trashCmd)
+}
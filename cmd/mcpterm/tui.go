@@ -1,6 +1,7 @@
 package mcpterm
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"github.com/navicore/mcpterm-go/pkg/backend"
 	"github.com/navicore/mcpterm-go/pkg/chat"
 	"github.com/navicore/mcpterm-go/pkg/config"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
 	"github.com/navicore/mcpterm-go/pkg/ui"
 )
 
@@ -117,13 +119,34 @@ func startTUI() {
 			"- `0`/`$` for far left/right\n\n" +
 			"Navigation keys (j/k/g/G/d/u) will ONLY affect message history when it has focus.\n\n" +
 			"*All messages support markdown formatting!*\n\n" +
-			"Type `help` for available commands.",
+			"Type `help` for available commands, or `/undo` to revert the files changed during the last turn.",
 		IsUser: false,
 	})
 
 	// Run the Bubble Tea program
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
+	// Show progress from long running tools while waiting for a response
+	if reporter, ok := chatService.(chat.ToolProgressReporter); ok {
+		reporter.SetToolProgressHandler(func(progress core.Progress) {
+			p.Send(ui.ToolProgressMsg{Progress: progress})
+		})
+	}
+
+	// Ask the user before tools take actions that need approval
+	if approver, ok := chatService.(chat.ToolApprover); ok {
+		approver.SetToolApprovalHandler(func(ctx context.Context, req core.ApprovalRequest) bool {
+			reply := make(chan bool, 1)
+			p.Send(ui.ToolApprovalMsg{Request: req, Reply: reply})
+			select {
+			case approved := <-reply:
+				return approved
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}
+
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
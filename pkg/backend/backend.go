@@ -28,8 +28,9 @@ type ToolUse struct {
 
 // ToolResult represents the result of a tool execution
 type ToolResult struct {
-	Name   string          // Name of the tool that was used
-	Result json.RawMessage // Raw JSON result from the tool
+	Name    string          // Name of the tool that was used
+	Result  json.RawMessage // Raw JSON result from the tool
+	IsError bool            // Whether the result describes a failed tool call
 }
 
 // ChatResponse contains the response from a chat completion
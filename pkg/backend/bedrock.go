@@ -465,6 +465,13 @@ func (b *BedrockBackend) SendMessage(ctx context.Context, req ChatRequest) (Chat
 					result.Name,
 					string(result.Result),
 				)
+				if result.IsError {
+					toolResultContent = fmt.Sprintf(
+						"Tool '%s' failed with the following error: ```json\n%s\n```\n\nPlease correct the tool input and try again, or explain why the task cannot be completed.",
+						result.Name,
+						string(result.Result),
+					)
+				}
 
 				toolResultMsg := ClaudeMessage{
 					Role:    "user",
@@ -0,0 +1,263 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/backend"
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// DefaultMaxConsecutiveToolFailures is the number of failed tool calls in a row
+// after which the agent loop gives up on the turn
+const DefaultMaxConsecutiveToolFailures = 3
+
+// DefaultContinueSteps is the number of extra steps granted when a paused turn is continued
+const DefaultContinueSteps = 10
+
+// LoopBudget limits how much work the agent loop may do in a turn before it
+// pauses and asks the user whether to continue. Zero values disable a limit.
+type LoopBudget struct {
+	MaxIterations    int           // Backend requests per turn (defaults to the tool manager limit)
+	MaxDuration      time.Duration // Wall time per turn
+	MaxTokens        int           // Tokens used per turn, as reported by the backend
+	MaxRepeatedCalls int           // Identical calls to the same tool per turn
+}
+
+// DefaultLoopBudget returns the default agent loop limits
+func DefaultLoopBudget() LoopBudget {
+	return LoopBudget{
+		MaxIterations:    0, // Use the tool manager limit
+		MaxDuration:      10 * time.Minute,
+		MaxTokens:        0,
+		MaxRepeatedCalls: 3,
+	}
+}
+
+// LoopLimit describes why the agent loop paused before the model finished
+type LoopLimit struct {
+	Reason string // Human readable description of the limit that was reached
+}
+
+// agentHost is implemented by chat services that run the shared agent loop.
+// It supplies the conversation sent to the backend and records the messages
+// the loop produces.
+type agentHost interface {
+	// backendMessages returns the conversation to send with the next request
+	backendMessages() []backend.Message
+
+	// recordMessage adds a message produced by the loop to the conversation.
+	// Important messages should be kept in context as long as possible.
+	recordMessage(msg Message, important bool, tags ...string)
+}
+
+// agentLoop sends a conversation to the backend and runs the tools the model
+// requests until it produces a final response. A loop serves a single turn;
+// when it pauses on a budget limit it can be resumed with more steps.
+type agentLoop struct {
+	backend                backend.Backend
+	toolManager            *tools.ToolManager
+	toolsEnabled           bool
+	maxTokens              int
+	temperature            float64
+	maxConsecutiveFailures int
+	budget                 LoopBudget
+
+	// State carried across continuations of the turn
+	toolResults []backend.ToolResult
+	callCounts  map[string]int
+	paused      bool
+}
+
+// run executes the loop for the current turn and returns the message that ends or pauses it
+func (l *agentLoop) run(ctx context.Context, host agentHost) (Message, error) {
+	steps := l.budget.MaxIterations
+	if steps <= 0 && l.toolManager != nil {
+		steps = l.toolManager.GetMaxToolsPerMsg()
+	}
+
+	return l.runSteps(ctx, host, steps)
+}
+
+// resume continues a paused turn for up to steps more backend requests
+func (l *agentLoop) resume(ctx context.Context, host agentHost, steps int) (Message, error) {
+	if !l.paused {
+		return Message{}, fmt.Errorf("there is no paused turn to continue")
+	}
+	if steps <= 0 {
+		steps = DefaultContinueSteps
+	}
+
+	// The user approved more work, so repeated calls get a fresh allowance
+	l.callCounts = nil
+
+	return l.runSteps(ctx, host, steps)
+}
+
+// runSteps runs the loop for at most steps backend requests
+func (l *agentLoop) runSteps(ctx context.Context, host agentHost, steps int) (Message, error) {
+	l.paused = false
+	if l.callCounts == nil {
+		l.callCounts = make(map[string]int)
+	}
+
+	start := time.Now()
+	tokensUsed := 0
+	consecutiveFailures := 0
+
+	for i := 0; i < steps; i++ {
+		if l.budget.MaxDuration > 0 && time.Since(start) >= l.budget.MaxDuration {
+			return l.pause(host, fmt.Sprintf("the turn ran for more than %s", l.budget.MaxDuration)), nil
+		}
+
+		// Create chat request with tools if enabled
+		req := backend.ChatRequest{
+			Messages:    host.backendMessages(),
+			MaxTokens:   l.maxTokens,
+			Temperature: l.temperature,
+			Options:     make(map[string]any),
+		}
+
+		// Add tools if enabled
+		if l.toolsEnabled && l.toolManager != nil && l.toolManager.IsToolsEnabled() {
+			req.Options["tools"] = l.toolManager.GetTools()
+
+			// Add tool results if we have any
+			if len(l.toolResults) > 0 {
+				req.Options["tool_results"] = l.toolResults
+			}
+		}
+
+		// Send to backend
+		resp, err := l.backend.SendMessage(ctx, req)
+		if err != nil {
+			return Message{}, fmt.Errorf("backend error: %w", err)
+		}
+		tokensUsed += resp.Usage["total_tokens"]
+
+		// No tool use, we have a final response
+		if resp.ToolUse == nil || resp.FinishReason != "tool_use" {
+			respMsg := Message{
+				Sender:  "assistant",
+				Content: resp.Content,
+				IsUser:  false,
+			}
+			host.recordMessage(respMsg, false)
+
+			return respMsg, nil
+		}
+
+		if l.budget.MaxTokens > 0 && tokensUsed >= l.budget.MaxTokens {
+			return l.pause(host, fmt.Sprintf("the turn used %d tokens, exceeding the limit of %d", tokensUsed, l.budget.MaxTokens)), nil
+		}
+
+		// Stop the model from calling the same tool with the same input over and over
+		key := toolCallKey(resp.ToolUse)
+		l.callCounts[key]++
+		if l.budget.MaxRepeatedCalls > 0 && l.callCounts[key] > l.budget.MaxRepeatedCalls {
+			return l.pause(host, fmt.Sprintf("the model called '%s' with identical input %d times",
+				resp.ToolUse.Name, l.callCounts[key])), nil
+		}
+
+		// Add a single combined message about tool usage with more details
+		host.recordMessage(Message{
+			Sender: "assistant",
+			Content: fmt.Sprintf("Using the '%s' tool to help answer your question. Tool request details: %s",
+				resp.ToolUse.Name,
+				string(resp.ToolUse.Input)),
+			IsUser: false,
+		}, false, "tool_use", resp.ToolUse.Name)
+
+		result, err := l.toolManager.HandleToolUse(ctx, (*core.ToolUse)(resp.ToolUse))
+		if err != nil {
+			// Stop if the user gave up on the turn rather than the tool failing
+			if ctx.Err() != nil {
+				return Message{}, ctx.Err()
+			}
+
+			consecutiveFailures++
+
+			// Give the error to the model so it can correct itself
+			result = errorToolResult(resp.ToolUse.Name, err)
+			host.recordMessage(Message{
+				Sender:  "system",
+				Content: fmt.Sprintf("Tool '%s' failed: %v", resp.ToolUse.Name, err),
+				IsUser:  false,
+			}, true, "tool_error", resp.ToolUse.Name)
+
+			if l.maxConsecutiveFailures > 0 && consecutiveFailures >= l.maxConsecutiveFailures {
+				errorMsg := Message{
+					Sender: "system",
+					Content: fmt.Sprintf("Stopped after %d consecutive tool failures. Last error: %v",
+						consecutiveFailures, err),
+					IsUser: false,
+				}
+				host.recordMessage(errorMsg, true)
+
+				return errorMsg, nil
+			}
+		} else {
+			consecutiveFailures = 0
+
+			// Add a debug message showing the tool result with formatting
+			host.recordMessage(Message{
+				Sender:  "system",
+				Content: fmt.Sprintf("Debug - Tool '%s' result: ```json\n%s\n```", result.Name, string(result.Result)),
+				IsUser:  false,
+			}, false, "tool_result", result.Name)
+		}
+
+		// Store tool result for next request
+		l.toolResults = append(l.toolResults, *result)
+	}
+
+	return l.pause(host, fmt.Sprintf("the model made %d requests without finishing", steps)), nil
+}
+
+// pause stops the loop so the user can decide whether to continue the turn
+func (l *agentLoop) pause(host agentHost, reason string) Message {
+	l.paused = true
+
+	msg := Message{
+		Sender: "system",
+		Content: fmt.Sprintf("Paused because %s. Type `/continue` to run %d more steps, or `/continue N` to choose how many.",
+			reason, DefaultContinueSteps),
+		IsUser: false,
+		Limit:  &LoopLimit{Reason: reason},
+	}
+	host.recordMessage(msg, true)
+
+	return msg
+}
+
+// toolCallKey identifies a tool call by name and normalized input
+func toolCallKey(toolUse *backend.ToolUse) string {
+	input := string(toolUse.Input)
+
+	// Re-encode the input so formatting and key order do not matter
+	var decoded interface{}
+	if err := json.Unmarshal(toolUse.Input, &decoded); err == nil {
+		if normalized, err := json.Marshal(decoded); err == nil {
+			input = string(normalized)
+		}
+	}
+
+	return toolUse.Name + "\x00" + input
+}
+
+// errorToolResult builds the tool result that reports a failed tool call to the model
+func errorToolResult(name string, err error) *backend.ToolResult {
+	result, marshalErr := json.Marshal(map[string]string{"error": err.Error()})
+	if marshalErr != nil {
+		result = json.RawMessage(`{"error": "tool failed"}`)
+	}
+
+	return &backend.ToolResult{
+		Name:    name,
+		Result:  result,
+		IsError: true,
+	}
+}
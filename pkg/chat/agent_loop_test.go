@@ -0,0 +1,307 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/backend"
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// scriptedBackend returns a fixed sequence of responses and records the requests it receives
+type scriptedBackend struct {
+	responses []backend.ChatResponse
+	requests  []backend.ChatRequest
+}
+
+func (b *scriptedBackend) Name() string              { return "Scripted Backend" }
+func (b *scriptedBackend) Type() backend.BackendType { return backend.BackendMock }
+func (b *scriptedBackend) ModelID() string           { return "scripted" }
+func (b *scriptedBackend) Close() error              { return nil }
+
+func (b *scriptedBackend) SendMessage(ctx context.Context, req backend.ChatRequest) (backend.ChatResponse, error) {
+	b.requests = append(b.requests, req)
+	if len(b.requests) > len(b.responses) {
+		return backend.ChatResponse{}, fmt.Errorf("unexpected request %d", len(b.requests))
+	}
+	return b.responses[len(b.requests)-1], nil
+}
+
+// cancellingBackend cancels the turn after its first response, as a user
+// pressing Esc while a tool runs would
+type cancellingBackend struct {
+	scriptedBackend
+	cancel context.CancelFunc
+}
+
+func (b *cancellingBackend) SendMessage(ctx context.Context, req backend.ChatRequest) (backend.ChatResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return backend.ChatResponse{}, err
+	}
+	defer b.cancel()
+	return b.scriptedBackend.SendMessage(ctx, req)
+}
+
+// echoTool fails when asked to and echoes its input otherwise
+type echoTool struct {
+	*core.BaseToolImpl
+}
+
+func (t *echoTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	var params struct {
+		Text string `json:"text"`
+		Fail bool   `json:"fail"`
+	}
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, err
+	}
+	if params.Fail {
+		return nil, fmt.Errorf("file %s not found", params.Text)
+	}
+	return params.Text, nil
+}
+
+// recordingHost collects the messages produced by the agent loop
+type recordingHost struct {
+	messages []Message
+	tags     [][]string
+}
+
+func (h *recordingHost) backendMessages() []backend.Message {
+	return []backend.Message{{Role: "user", Content: "hello"}}
+}
+
+func (h *recordingHost) recordMessage(msg Message, important bool, tags ...string) {
+	h.messages = append(h.messages, msg)
+	h.tags = append(h.tags, tags)
+}
+
+func newTestToolManager(t *testing.T) *tools.ToolManager {
+	t.Helper()
+
+	manager := tools.NewToolManager()
+	tool := &echoTool{core.NewBaseTool("echo", "Echo text", "filesystem", map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"text": map[string]interface{}{"type": "string"},
+			"fail": map[string]interface{}{"type": "boolean"},
+		},
+		"required": []string{"text"},
+	})}
+	if err := manager.RegisterTool("filesystem", tool); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	return manager
+}
+
+func toolUseResponse(input string) backend.ChatResponse {
+	return backend.ChatResponse{
+		FinishReason: "tool_use",
+		ToolUse:      &backend.ToolUse{Name: "echo", Input: json.RawMessage(input)},
+	}
+}
+
+func TestAgentLoopReturnsToolErrorsToModel(t *testing.T) {
+	b := &scriptedBackend{responses: []backend.ChatResponse{
+		toolUseResponse(`{"text": "missing.txt", "fail": true}`),
+		toolUseResponse(`{"text": "found.txt"}`),
+		{Content: "All done", FinishReason: "stop"},
+	}}
+	loop := agentLoop{
+		backend:                b,
+		toolManager:            newTestToolManager(t),
+		toolsEnabled:           true,
+		budget:                 LoopBudget{MaxIterations: 10},
+		maxConsecutiveFailures: 3,
+	}
+	host := &recordingHost{}
+
+	msg, err := loop.run(context.Background(), host)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if msg.Content != "All done" {
+		t.Errorf("Expected final response, got %q", msg.Content)
+	}
+
+	// The failure must be sent back to the model as an error result
+	results, ok := b.requests[1].Options["tool_results"].([]backend.ToolResult)
+	if !ok || len(results) != 1 {
+		t.Fatalf("Expected one tool result in second request, got %v", b.requests[1].Options["tool_results"])
+	}
+	if !results[0].IsError || !strings.Contains(string(results[0].Result), "missing.txt not found") {
+		t.Errorf("Expected error tool result, got %+v", results[0])
+	}
+
+	results = b.requests[2].Options["tool_results"].([]backend.ToolResult)
+	if len(results) != 2 || results[1].IsError {
+		t.Errorf("Expected successful second tool result, got %+v", results)
+	}
+
+	foundErrorTag := false
+	for _, tags := range host.tags {
+		if len(tags) > 0 && tags[0] == "tool_error" {
+			foundErrorTag = true
+		}
+	}
+	if !foundErrorTag {
+		t.Error("Expected the failure to be recorded with a tool_error tag")
+	}
+}
+
+func TestAgentLoopStopsAfterConsecutiveFailures(t *testing.T) {
+	b := &scriptedBackend{responses: []backend.ChatResponse{
+		toolUseResponse(`{"text": "a", "fail": true}`),
+		toolUseResponse(`{"text": 42}`), // Rejected by schema validation
+		toolUseResponse(`{"text": "c", "fail": true}`),
+	}}
+	loop := agentLoop{
+		backend:                b,
+		toolManager:            newTestToolManager(t),
+		toolsEnabled:           true,
+		budget:                 LoopBudget{MaxIterations: 10},
+		maxConsecutiveFailures: 3,
+	}
+
+	msg, err := loop.run(context.Background(), &recordingHost{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(msg.Content, "Stopped after 3 consecutive tool failures") {
+		t.Errorf("Expected failure limit message, got %q", msg.Content)
+	}
+	if len(b.requests) != 3 {
+		t.Errorf("Expected 3 backend requests, got %d", len(b.requests))
+	}
+}
+
+func TestAgentLoopPausesOnRepeatedCalls(t *testing.T) {
+	b := &scriptedBackend{responses: []backend.ChatResponse{
+		toolUseResponse(`{"text": "same"}`),
+		toolUseResponse(`{ "text":"same" }`), // Formatting differences do not matter
+		toolUseResponse(`{"text": "same"}`),
+		{Content: "Finished after continuing", FinishReason: "stop"},
+	}}
+	loop := agentLoop{
+		backend:      b,
+		toolManager:  newTestToolManager(t),
+		toolsEnabled: true,
+		budget:       LoopBudget{MaxIterations: 10, MaxRepeatedCalls: 2},
+	}
+	host := &recordingHost{}
+
+	msg, err := loop.run(context.Background(), host)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if msg.Limit == nil || !strings.Contains(msg.Limit.Reason, "identical input 3 times") {
+		t.Fatalf("Expected repeated call limit, got %+v", msg)
+	}
+
+	// The third call must not have been executed
+	results := b.requests[2].Options["tool_results"].([]backend.ToolResult)
+	if len(results) != 2 {
+		t.Errorf("Expected 2 executed tool calls, got %d", len(results))
+	}
+
+	msg, err = loop.resume(context.Background(), host, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error resuming: %v", err)
+	}
+	if msg.Content != "Finished after continuing" || msg.Limit != nil {
+		t.Errorf("Expected final response after continuing, got %+v", msg)
+	}
+
+	if _, err := loop.resume(context.Background(), host, 5); err == nil {
+		t.Error("Expected an error when resuming a finished turn")
+	}
+}
+
+func TestAgentLoopBudgetLimits(t *testing.T) {
+	t.Run("Iterations", func(t *testing.T) {
+		manager := newTestToolManager(t)
+		manager.SetMaxToolsPerMsg(2)
+
+		b := &scriptedBackend{responses: []backend.ChatResponse{
+			toolUseResponse(`{"text": "a"}`),
+			toolUseResponse(`{"text": "b"}`),
+			toolUseResponse(`{"text": "c"}`),
+			{Content: "Done", FinishReason: "stop"},
+		}}
+		// The default budget leaves the request limit to the tool manager
+		loop := agentLoop{backend: b, toolManager: manager, toolsEnabled: true, budget: DefaultLoopBudget()}
+		host := &recordingHost{}
+
+		msg, err := loop.run(context.Background(), host)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if msg.Limit == nil || len(b.requests) != 2 {
+			t.Fatalf("Expected pause after 2 requests, got %+v after %d requests", msg, len(b.requests))
+		}
+
+		msg, err = loop.resume(context.Background(), host, 2)
+		if err != nil {
+			t.Fatalf("Unexpected error resuming: %v", err)
+		}
+		if msg.Content != "Done" {
+			t.Errorf("Expected final response, got %+v", msg)
+		}
+
+		// Tool results from before the pause are still sent to the model
+		results := b.requests[3].Options["tool_results"].([]backend.ToolResult)
+		if len(results) != 3 {
+			t.Errorf("Expected 3 tool results, got %d", len(results))
+		}
+	})
+
+	t.Run("Tokens", func(t *testing.T) {
+		response := toolUseResponse(`{"text": "a"}`)
+		response.Usage = map[string]int{"total_tokens": 600}
+
+		b := &scriptedBackend{responses: []backend.ChatResponse{response, response}}
+		loop := agentLoop{
+			backend:      b,
+			toolManager:  newTestToolManager(t),
+			toolsEnabled: true,
+			budget:       LoopBudget{MaxIterations: 10, MaxTokens: 1000},
+		}
+
+		msg, err := loop.run(context.Background(), &recordingHost{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if msg.Limit == nil || !strings.Contains(msg.Limit.Reason, "1200 tokens") {
+			t.Errorf("Expected token limit, got %+v", msg)
+		}
+	})
+}
+
+func TestAgentLoopStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := &cancellingBackend{cancel: cancel}
+	b.responses = []backend.ChatResponse{
+		toolUseResponse(`{"text": "a"}`),
+		{Content: "All done", FinishReason: "stop"},
+	}
+	loop := agentLoop{
+		backend:      b,
+		toolManager:  newTestToolManager(t),
+		toolsEnabled: true,
+		budget:       LoopBudget{MaxIterations: 10},
+	}
+
+	_, err := loop.run(ctx, &recordingHost{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the turn to be cancelled, got %v", err)
+	}
+	if len(b.requests) != 1 {
+		t.Errorf("Expected no requests after cancelling, got %d", len(b.requests))
+	}
+}
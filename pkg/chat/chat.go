@@ -1,7 +1,13 @@
 package chat
 
 import (
+	"context"
+	"fmt"
 	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/checkpoint"
+	"github.com/navicore/mcpterm-go/pkg/mcp"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
 )
 
 // Message represents a chat message
@@ -9,11 +15,12 @@ type Message struct {
 	Sender  string
 	Content string
 	IsUser  bool
+	Limit   *LoopLimit // Set when the turn paused on a loop limit and can be continued
 }
 
 // ChatServiceInterface defines the interface for chat functionality
 type ChatServiceInterface interface {
-	SendMessage(content string) (Message, error)
+	SendMessage(ctx context.Context, content string) (Message, error)
 	GetHistory() []Message
 	GetBackendInfo() (string, string)
 	Clear() error
@@ -23,6 +30,62 @@ type ChatServiceInterface interface {
 	Close() error
 }
 
+// Checkpointer is implemented by chat services that can revert file changes made by tools
+type Checkpointer interface {
+	// UndoLastTurn restores the files changed during the most recent turn
+	UndoLastTurn() (checkpoint.Turn, []string, error)
+}
+
+// undoNotice tells the model which of its file changes the user reverted
+func undoNotice(turn checkpoint.Turn, restored []string) string {
+	notice := fmt.Sprintf("The user reverted the file changes from turn %s: %s", turn.ID, strings.Join(restored, ", "))
+	for _, skip := range turn.Skipped {
+		notice += fmt.Sprintf("\n%s was not restored, as it was not snapshotted: %s", skip.Path, skip.Reason)
+	}
+	return notice
+}
+
+// Continuer is implemented by chat services whose turns can pause on a loop limit
+type Continuer interface {
+	// ContinueTurn resumes a paused turn for up to steps more model requests
+	ContinueTurn(ctx context.Context, steps int) (Message, error)
+}
+
+// ToolProgressReporter is implemented by chat services that can report progress from running tools
+type ToolProgressReporter interface {
+	// SetToolProgressHandler sets the callback that receives tool progress updates
+	SetToolProgressHandler(fn core.ProgressFunc)
+}
+
+// ToolApprover is implemented by chat services whose tools can ask the user to approve actions
+type ToolApprover interface {
+	// SetToolApprovalHandler sets the callback that asks the user to approve tool actions
+	SetToolApprovalHandler(fn core.ApprovalFunc)
+}
+
+// JobProvider is implemented by chat services whose tools can run background jobs
+type JobProvider interface {
+	// Jobs returns the jobs started in this conversation, oldest first
+	Jobs() []core.Job
+}
+
+// MCPProvider is implemented by chat services that use tools from MCP servers
+type MCPProvider interface {
+	// MCPServers returns the manager of the configured MCP servers, or nil if there are none
+	MCPServers() *mcp.Manager
+}
+
+// MCPHost is implemented by chat services that can use MCP resources and prompt templates
+type MCPHost interface {
+	MCPProvider
+
+	// AttachResource reads a resource and attaches it to the next message
+	AttachResource(uri string) (Attachment, error)
+
+	// SendPrompt renders a server's prompt template and sends it as a user message
+	SendPrompt(ctx context.Context, server, name string, args []string) (Message, error)
+}
+
 // SimpleChatService is a basic implementation of ChatServiceInterface
 type SimpleChatService struct {
 	history []Message
@@ -36,7 +99,7 @@ func NewSimpleChatService() *SimpleChatService {
 }
 
 // SendMessage sends a message and returns a response
-func (s *SimpleChatService) SendMessage(content string) (Message, error) {
+func (s *SimpleChatService) SendMessage(ctx context.Context, content string) (Message, error) {
 	// Add user message to history
 	userMsg := Message{
 		Sender:  "You",
@@ -1,8 +1,15 @@
 package chat
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/backend"
+	"github.com/navicore/mcpterm-go/pkg/checkpoint"
+	"github.com/navicore/mcpterm-go/pkg/tools"
 )
 
 func TestChatService(t *testing.T) {
@@ -48,7 +55,7 @@ func TestChatService(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Send message to chat service
-			response, err := chatService.SendMessage(tc.input)
+			response, err := chatService.SendMessage(context.Background(), tc.input)
 			if err != nil {
 				t.Fatalf("Error sending message: %v", err)
 			}
@@ -76,3 +83,49 @@ func TestChatService(t *testing.T) {
 		})
 	}
 }
+
+func TestUndoLastTurnTellsModel(t *testing.T) {
+	// newToolManager returns a manager whose last turn changed a file
+	newToolManager := func(t *testing.T) *tools.ToolManager {
+		path := filepath.Join(t.TempDir(), "a.txt")
+		if err := os.WriteFile(path, []byte("original"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		store := checkpoint.NewStore(filepath.Join(t.TempDir(), "checkpoints"))
+		store.BeginTurn("edit")
+		if err := store.Snapshot("file_write", []string{path}); err != nil {
+			t.Fatal(err)
+		}
+		manager := tools.NewToolManager()
+		manager.SetCheckpointStore(store)
+		return manager
+	}
+
+	// lastMessage returns the last message that would be sent to the backend
+	lastMessage := func(t *testing.T, messages []backend.Message) string {
+		if len(messages) == 0 {
+			t.Fatal("Expected messages for the backend")
+		}
+		return messages[len(messages)-1].Content
+	}
+
+	t.Run("ChatService", func(t *testing.T) {
+		service := &ChatService{toolManager: newToolManager(t)}
+		if _, _, err := service.UndoLastTurn(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := lastMessage(t, service.prepareBackendMessages()); !strings.Contains(got, "The user reverted") {
+			t.Errorf("Expected the undo notice, got %q", got)
+		}
+	})
+
+	t.Run("ContextChatServiceWithoutContextManagement", func(t *testing.T) {
+		service := &ContextChatService{toolManager: newToolManager(t)}
+		if _, _, err := service.UndoLastTurn(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := lastMessage(t, service.prepareBackendMessages()); !strings.Contains(got, "The user reverted") {
+			t.Errorf("Expected the undo notice, got %q", got)
+		}
+	})
+}
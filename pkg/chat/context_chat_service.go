@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"github.com/navicore/mcpterm-go/pkg/backend"
+	"github.com/navicore/mcpterm-go/pkg/checkpoint"
 	contextManager "github.com/navicore/mcpterm-go/pkg/context"
+	"github.com/navicore/mcpterm-go/pkg/mcp"
 	"github.com/navicore/mcpterm-go/pkg/tools"
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
 )
@@ -75,6 +77,9 @@ type ContextChatService struct {
 	conversationMu    sync.RWMutex
 	toolManager       *tools.ToolManager
 	toolsEnabled      bool
+	loop              *agentLoop   // Agent loop of the current turn
+	mcp               *mcp.Manager // Connected MCP servers, nil if none are configured
+	attachments       []Attachment // MCP resources attached to the next message
 
 	// Context management components
 	contextManager      *contextManager.StandardContextManager
@@ -172,20 +177,16 @@ func NewContextChatService(opts ContextChatOptions) (*ContextChatService, error)
 	)
 
 	// Create tool manager
-	toolManager, err := tools.Initialize()
+	toolManager, err := NewConfiguredToolManager(opts.ChatOptions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize tool manager: %w", err)
+		return nil, err
 	}
 
-	// Set tool availability based on options
-	toolManager.EnableTools(opts.EnableTools)
+	// Start MCP servers and offer their tools
+	mcpManager := mountMCPServers(toolManager, opts.MCPServers)
 
-	// Enable specific categories if provided
-	if len(opts.EnabledToolCategories) > 0 {
-		if err := toolManager.EnableCategoriesByIDs(opts.EnabledToolCategories); err != nil {
-			return nil, fmt.Errorf("failed to enable tool categories: %w", err)
-		}
-	}
+	// Offer the language server tools; the servers start when first used
+	mountLanguageServers(toolManager, opts.LSPServers)
 
 	// Create the service instance
 	service := &ContextChatService{
@@ -202,6 +203,7 @@ func NewContextChatService(opts ContextChatOptions) (*ContextChatService, error)
 		tokenCounter:        tokenCounter,
 		messagePrioritizer:  messagePrioritizer,
 		loadedContextInfo:   make(map[string]string),
+		mcp:                 mcpManager,
 	}
 
 	// Load persisted context if enabled
@@ -242,11 +244,50 @@ func NewContextChatService(opts ContextChatOptions) (*ContextChatService, error)
 	return service, nil
 }
 
-// SendMessage sends a message and manages context
-func (s *ContextChatService) SendMessage(content string) (Message, error) {
+// SendMessage sends a message to the chat service
+func (s *ContextChatService) SendMessage(ctx context.Context, content string) (Message, error) {
+	s.conversationMu.Lock()
+	defer s.conversationMu.Unlock()
+
+	return s.sendMessageLocked(ctx, content)
+}
+
+// AttachResource reads an MCP resource and attaches it to the next message
+func (s *ContextChatService) AttachResource(uri string) (Attachment, error) {
+	attachment, err := readAttachment(s.mcp, uri)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	s.conversationMu.Lock()
+	defer s.conversationMu.Unlock()
+
+	s.attachments = append(s.attachments, attachment)
+	return attachment, nil
+}
+
+// SendPrompt renders an MCP prompt template and sends it as a user message
+func (s *ContextChatService) SendPrompt(ctx context.Context, server, name string, args []string) (Message, error) {
+	content, err := renderPrompt(s.mcp, server, name, args)
+	if err != nil {
+		return Message{}, err
+	}
+
 	s.conversationMu.Lock()
 	defer s.conversationMu.Unlock()
 
+	return s.sendMessageLocked(ctx, content, TagMCPPrompt)
+}
+
+// sendMessageLocked adds a user message with any attached resources and
+// runs the turn; the caller must hold conversationMu
+func (s *ContextChatService) sendMessageLocked(ctx context.Context, content string, tags ...string) (Message, error) {
+	if len(s.attachments) > 0 {
+		content = withAttachments(content, s.attachments)
+		tags = append(tags, TagMCPResource)
+		s.attachments = nil
+	}
+
 	// Add user message to history
 	userMsg := Message{
 		Sender:  "user",
@@ -258,6 +299,7 @@ func (s *ContextChatService) SendMessage(content string) (Message, error) {
 	// If context management is enabled, also add to context manager
 	if s.options.EnableContextManagement {
 		enhancedMsg := s.createEnhancedMessage(userMsg)
+		enhancedMsg.Tags = append(enhancedMsg.Tags, tags...)
 		if err := s.contextManager.AddMessage(enhancedMsg); err != nil {
 			// Log error but continue
 			// Do not log errors to avoid interfering with TUI
@@ -309,172 +351,85 @@ func (s *ContextChatService) SendMessage(content string) (Message, error) {
 		}
 	}
 
+	// Group file checkpoints by turn
+	s.toolManager.BeginTurn(content)
+
 	// Process as a conversation with potential tool usage
-	return s.processChatWithTools()
+	return s.processChatWithTools(ctx)
 }
 
 // processChatWithTools handles the full chat flow with tool usage and context
-func (s *ContextChatService) processChatWithTools() (Message, error) {
-	var toolResults []backend.ToolResult
-	maxToolCalls := 10 // Prevent infinite tool usage loops
-
-	for i := 0; i < maxToolCalls; i++ {
-		// Prepare messages for the backend
-		var backendMessages []backend.Message
-		var err error
-
-		if s.options.EnableContextManagement {
-			// Use the context manager to get the optimal message selection
-			selection, err := s.hierarchicalContext.GetHierarchicalSelection(s.options.ContextManagerConfig.MaxContextTokens)
-			if err != nil {
-				// If context selection fails, fall back to traditional approach
-				backendMessages = s.prepareBackendMessages()
-			} else {
-				backendMessages, err = s.contextManager.PrepareBackendMessages(selection)
-				if err != nil {
-					// Fall back to traditional approach
-					backendMessages = s.prepareBackendMessages()
-				}
-			}
-		} else {
-			// Use traditional approach
-			backendMessages = s.prepareBackendMessages()
-		}
-
-		// Create chat request with tools if enabled
-		req := backend.ChatRequest{
-			Messages:    backendMessages,
-			MaxTokens:   s.options.MaxTokens,
-			Temperature: s.options.Temperature,
-			Options:     make(map[string]any),
-		}
-
-		// Add tools if enabled
-		if s.toolsEnabled && s.toolManager != nil && s.toolManager.IsToolsEnabled() {
-			req.Options["tools"] = s.toolManager.GetTools()
-
-			// Add tool results if we have any
-			if len(toolResults) > 0 {
-				req.Options["tool_results"] = toolResults
-			}
-		}
-
-		// Send to backend
-		ctx := context.Background()
-		resp, err := s.backend.SendMessage(ctx, req)
-		if err != nil {
-			return Message{}, fmt.Errorf("backend error: %w", err)
-		}
-
-		// If the model requested a tool
-		if resp.ToolUse != nil && resp.FinishReason == "tool_use" {
-			// Execute the tool first before adding any messages
-			result, err := s.toolManager.HandleToolUse((*core.ToolUse)(resp.ToolUse))
-			if err != nil {
-				// Add error message to history
-				errorMsg := Message{
-					Sender:  "system",
-					Content: fmt.Sprintf("Error executing tool: %v", err),
-					IsUser:  false,
-				}
-				s.messages = append(s.messages, errorMsg)
-
-				// Add to context manager if enabled
-				if s.options.EnableContextManagement {
-					enhancedMsg := s.createEnhancedMessage(errorMsg)
-					// System error messages get high importance
-					enhancedMsg.Importance = contextManager.ImportanceHigh
-					s.contextManager.AddMessage(enhancedMsg)
-				}
-
-				// Return error to user
-				return errorMsg, nil
-			}
-
-			// Add a single combined message about tool usage with more details
-			toolMsg := Message{
-				Sender: "assistant",
-				Content: fmt.Sprintf("Using the '%s' tool to help answer your question. Tool request details: %s",
-					resp.ToolUse.Name,
-					string(resp.ToolUse.Input)),
-				IsUser: false,
-			}
-			s.messages = append(s.messages, toolMsg)
-
-			// Add to context manager if enabled
-			if s.options.EnableContextManagement {
-				enhancedMsg := s.createEnhancedMessage(toolMsg)
-				enhancedMsg.Tags = append(enhancedMsg.Tags, "tool_use")
-				enhancedMsg.Tags = append(enhancedMsg.Tags, resp.ToolUse.Name)
-				s.contextManager.AddMessage(enhancedMsg)
-			}
-
-			// Store tool result for next request
-			toolResults = append(toolResults, *result)
+func (s *ContextChatService) processChatWithTools(ctx context.Context) (Message, error) {
+	// Each turn gets a fresh loop so limits apply per turn
+	s.loop = &agentLoop{
+		backend:                s.backend,
+		toolManager:            s.toolManager,
+		toolsEnabled:           s.toolsEnabled,
+		maxTokens:              s.options.MaxTokens,
+		temperature:            s.options.Temperature,
+		maxConsecutiveFailures: s.options.MaxConsecutiveToolFailures,
+		budget:                 s.options.LoopBudget,
+	}
+
+	return s.loop.run(ctx, s)
+}
 
-			// Add a debug message showing the tool result with formatting
-			debugMsg := Message{
-				Sender:  "system",
-				Content: fmt.Sprintf("Debug - Tool '%s' result: ```json\n%s\n```", result.Name, string(result.Result)),
-				IsUser:  false,
-			}
-			s.messages = append(s.messages, debugMsg)
-
-			// Add to context manager if enabled
-			if s.options.EnableContextManagement {
-				enhancedMsg := s.createEnhancedMessage(debugMsg)
-				enhancedMsg.Tags = append(enhancedMsg.Tags, "tool_result")
-				enhancedMsg.Tags = append(enhancedMsg.Tags, result.Name)
-				s.contextManager.AddMessage(enhancedMsg)
-			}
+// ContinueTurn resumes a turn that paused on a loop limit
+func (s *ContextChatService) ContinueTurn(ctx context.Context, steps int) (Message, error) {
+	s.conversationMu.Lock()
+	defer s.conversationMu.Unlock()
 
-			// Continue to next iteration to send the tool result
-			continue
-		}
+	if s.loop == nil {
+		return Message{}, fmt.Errorf("there is no paused turn to continue")
+	}
 
-		// No tool use, we have a final response
-		respMsg := Message{
-			Sender:  "assistant",
-			Content: resp.Content,
-			IsUser:  false,
-		}
+	return s.loop.resume(ctx, s, steps)
+}
 
-		// Add to history
-		s.messages = append(s.messages, respMsg)
+// backendMessages returns the conversation to send to the backend,
+// selected by the context manager when it is enabled
+func (s *ContextChatService) backendMessages() []backend.Message {
+	if !s.options.EnableContextManagement {
+		// Use traditional approach
+		return s.prepareBackendMessages()
+	}
 
-		// Add to context manager if enabled
-		if s.options.EnableContextManagement {
-			enhancedMsg := s.createEnhancedMessage(respMsg)
+	// Use the context manager to get the optimal message selection
+	selection, err := s.hierarchicalContext.GetHierarchicalSelection(s.options.ContextManagerConfig.MaxContextTokens)
+	if err != nil {
+		// If context selection fails, fall back to traditional approach
+		return s.prepareBackendMessages()
+	}
 
-			// Let the prioritizer enhance the message with tags, etc.
-			s.messagePrioritizer.EnhanceMessage(&enhancedMsg)
+	backendMessages, err := s.contextManager.PrepareBackendMessages(selection)
+	if err != nil {
+		// Fall back to traditional approach
+		return s.prepareBackendMessages()
+	}
 
-			if err := s.contextManager.AddMessage(enhancedMsg); err != nil {
-				// Log error but continue
-				contextLogger.Printf("Error adding message to context: %v", err)
-			}
-		}
+	return backendMessages
+}
 
-		// Return the final response
-		return respMsg, nil
-	}
+// recordMessage adds a message produced by the agent loop to the history
+// and, when enabled, to the context manager
+func (s *ContextChatService) recordMessage(msg Message, important bool, tags ...string) {
+	s.messages = append(s.messages, msg)
 
-	// If we reached max tool calls, inform the user
-	errorMsg := Message{
-		Sender:  "system",
-		Content: "Exceeded maximum number of tool calls. The operation was halted.",
-		IsUser:  false,
+	if !s.options.EnableContextManagement {
+		return
 	}
-	s.messages = append(s.messages, errorMsg)
 
-	// Add to context manager if enabled
-	if s.options.EnableContextManagement {
-		enhancedMsg := s.createEnhancedMessage(errorMsg)
+	enhancedMsg := s.createEnhancedMessage(msg)
+	enhancedMsg.Tags = append(enhancedMsg.Tags, tags...)
+	if important {
+		// System errors and notices get high importance
 		enhancedMsg.Importance = contextManager.ImportanceHigh
-		s.contextManager.AddMessage(enhancedMsg)
 	}
 
-	return errorMsg, nil
+	if err := s.contextManager.AddMessage(enhancedMsg); err != nil {
+		// Log error but continue
+		contextLogger.Printf("Error adding message to context: %v", err)
+	}
 }
 
 // createEnhancedMessage converts a simple message to an enhanced message for the context manager
@@ -698,6 +653,26 @@ func (s *ContextChatService) loadPersistedContext() error {
 	return nil
 }
 
+// MCPServers returns the manager of the configured MCP servers
+func (s *ContextChatService) MCPServers() *mcp.Manager {
+	return s.mcp
+}
+
+// Jobs returns the background jobs started by tools in this conversation
+func (s *ContextChatService) Jobs() []core.Job {
+	return s.toolManager.Jobs()
+}
+
+// SetToolProgressHandler sets the callback that receives progress updates from running tools
+func (s *ContextChatService) SetToolProgressHandler(fn core.ProgressFunc) {
+	s.toolManager.SetProgressHandler(fn)
+}
+
+// SetToolApprovalHandler sets the callback that asks the user to approve tool actions
+func (s *ContextChatService) SetToolApprovalHandler(fn core.ApprovalFunc) {
+	s.toolManager.SetApprovalHandler(fn)
+}
+
 // GetHistory returns the chat history
 func (s *ContextChatService) GetHistory() []Message {
 	s.conversationMu.RLock()
@@ -716,6 +691,7 @@ func (s *ContextChatService) Clear() error {
 	defer s.conversationMu.Unlock()
 
 	s.messages = []Message{}
+	s.attachments = nil
 
 	// Clear context manager if enabled
 	if s.options.EnableContextManagement {
@@ -859,6 +835,29 @@ func (s *ContextChatService) ForceSummarize(messageCount int) (string, error) {
 	return summary.Content, nil
 }
 
+// UndoLastTurn restores the files changed during the most recent turn
+func (s *ContextChatService) UndoLastTurn() (checkpoint.Turn, []string, error) {
+	s.conversationMu.Lock()
+	defer s.conversationMu.Unlock()
+
+	store := s.toolManager.GetCheckpointStore()
+	if store == nil {
+		return checkpoint.Turn{}, nil, fmt.Errorf("checkpoints are disabled")
+	}
+
+	turn, restored, err := store.Undo()
+	if err == nil {
+		// Let the model know its earlier changes were reverted
+		s.recordMessage(Message{
+			Sender:  "system",
+			Content: undoNotice(turn, restored),
+			IsUser:  false,
+		}, true, "undo")
+	}
+
+	return turn, restored, err
+}
+
 // Close closes the chat service and releases resources
 // truncateString helper function to shorten long strings for logging
 func truncateString(s string, maxLen int) string {
@@ -924,10 +923,14 @@ func (s *ContextChatService) Close() error {
 		s.dualModelManager.Shutdown()
 	}
 
+	// Stop tool resources such as MCP servers, the shell session and background jobs
+	err := s.toolManager.Close()
+
 	// Close backends
-	var err error
 	if s.backend != nil {
-		err = s.backend.Close()
+		if backendErr := s.backend.Close(); backendErr != nil {
+			err = backendErr
+		}
 	}
 
 	if s.summarizerBackend != nil && s.summarizerBackend != s.backend {
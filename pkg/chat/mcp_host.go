@@ -0,0 +1,100 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/mcp"
+)
+
+// mcpRequestTimeout bounds requests made to MCP servers on behalf of the user
+const mcpRequestTimeout = time.Minute
+
+// Context manager tags for content that came from MCP servers
+const (
+	TagMCPResource = "mcp_resource"
+	TagMCPPrompt   = "mcp_prompt"
+)
+
+// Attachment is the content of an MCP resource attached to the next user message
+type Attachment struct {
+	Server   string
+	URI      string
+	MimeType string
+	Text     string
+}
+
+// readAttachment reads a resource from the MCP servers
+func readAttachment(servers *mcp.Manager, uri string) (Attachment, error) {
+	if servers == nil {
+		return Attachment{}, fmt.Errorf("no MCP servers are configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mcpRequestTimeout)
+	defer cancel()
+
+	server, result, err := servers.ReadResource(ctx, uri)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	attachment := Attachment{Server: server, URI: uri}
+	var parts []string
+	for _, contents := range result.Contents {
+		if attachment.MimeType == "" {
+			attachment.MimeType = contents.MimeType
+		}
+		if contents.Text != "" {
+			parts = append(parts, contents.Text)
+		} else if contents.Blob != "" {
+			// Binary content cannot be shown to the model as text
+			parts = append(parts, fmt.Sprintf("[binary content of %s, %d bytes base64 encoded]", contents.URI, len(contents.Blob)))
+		}
+	}
+	attachment.Text = strings.Join(parts, "\n")
+
+	return attachment, nil
+}
+
+// withAttachments prepends the attached resources to a user message
+func withAttachments(content string, attachments []Attachment) string {
+	var sb strings.Builder
+	for _, attachment := range attachments {
+		fmt.Fprintf(&sb, "<resource uri=%q server=%q>\n%s\n</resource>\n\n", attachment.URI, attachment.Server, attachment.Text)
+	}
+	sb.WriteString(content)
+	return sb.String()
+}
+
+// renderPrompt renders a server's prompt template with command line style arguments
+func renderPrompt(servers *mcp.Manager, server, name string, args []string) (string, error) {
+	if servers == nil {
+		return "", fmt.Errorf("no MCP servers are configured")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mcpRequestTimeout)
+	defer cancel()
+
+	client, prompt, err := servers.FindPrompt(ctx, server, name)
+	if err != nil {
+		return "", err
+	}
+
+	values, err := prompt.BindArguments(args)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := client.GetPrompt(ctx, name, values)
+	if err != nil {
+		return "", err
+	}
+
+	text := result.Text()
+	if text == "" {
+		return "", fmt.Errorf("prompt %s of mcp server %s has no text content", name, server)
+	}
+	return text, nil
+}
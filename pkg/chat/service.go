@@ -6,8 +6,14 @@ import (
 	"sync"
 
 	"github.com/navicore/mcpterm-go/pkg/backend"
+	"github.com/navicore/mcpterm-go/pkg/checkpoint"
+	"github.com/navicore/mcpterm-go/pkg/lsp"
+	"github.com/navicore/mcpterm-go/pkg/mcp"
 	"github.com/navicore/mcpterm-go/pkg/tools"
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/external"
+	"github.com/navicore/mcpterm-go/pkg/tools/policy"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
 )
 
 // ServiceMessage is used internally by ChatService - use Message from chat.go for the interface
@@ -22,8 +28,32 @@ type ChatOptions struct {
 	MaxTokens             int
 	Temperature           float64
 	BackendOptions        map[string]any
-	EnableTools           bool     // Whether to enable tool support
-	EnabledToolCategories []string // List of enabled tool categories
+	EnableTools           bool                // Whether to enable tool support
+	EnabledToolCategories []string            // List of enabled tool categories
+	EnableCheckpoints     bool                // Whether to snapshot files before tools modify them
+	CheckpointDir         string              // Directory for checkpoints (default ~/.mcpterm/checkpoints)
+	ToolTimeouts          tools.TimeoutConfig // Execution time limits for tools
+
+	// Failed tool calls in a row before the turn is stopped (0 for no limit)
+	MaxConsecutiveToolFailures int
+
+	// Limits on the work done per turn before asking the user to continue
+	LoopBudget LoopBudget
+
+	// External MCP servers whose tools are offered to the model
+	MCPServers []mcp.ServerConfig
+
+	// Language servers backing the code navigation and diagnostics tools
+	LSPServers []lsp.ServerConfig
+
+	// Tools implemented by executables declared in the configuration
+	ExternalTools []external.Spec
+
+	// Rules deciding which shell commands tools may run
+	ShellPolicy policy.Config
+
+	// Restrictions on the processes tools start, by tool category
+	Sandbox sandbox.Config
 }
 
 // DefaultChatOptions returns the default chat options
@@ -38,6 +68,11 @@ func DefaultChatOptions() ChatOptions {
 		BackendOptions:        make(map[string]any),
 		EnableTools:           true,                   // Tools enabled by default
 		EnabledToolCategories: []string{"filesystem"}, // Only filesystem tools by default
+		EnableCheckpoints:     true,
+		ToolTimeouts:          tools.TimeoutConfig{Default: tools.DefaultToolTimeout},
+
+		MaxConsecutiveToolFailures: DefaultMaxConsecutiveToolFailures,
+		LoopBudget:                 DefaultLoopBudget(),
 	}
 }
 
@@ -50,6 +85,9 @@ type ChatService struct {
 	conversationMu sync.RWMutex
 	toolManager    *tools.ToolManager
 	toolsEnabled   bool
+	loop           *agentLoop   // Agent loop of the current turn
+	mcp            *mcp.Manager // Connected MCP servers, nil if none are configured
+	attachments    []Attachment // MCP resources attached to the next message
 }
 
 // NewChatService creates a new chat service
@@ -69,20 +107,16 @@ func NewChatService(opts ChatOptions) (*ChatService, error) {
 	}
 
 	// Create tool manager
-	toolManager, err := tools.Initialize()
+	toolManager, err := NewConfiguredToolManager(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize tool manager: %w", err)
+		return nil, err
 	}
 
-	// Set tool availability based on options
-	toolManager.EnableTools(opts.EnableTools)
+	// Start MCP servers and offer their tools
+	mcpManager := mountMCPServers(toolManager, opts.MCPServers)
 
-	// Enable specific categories if provided
-	if len(opts.EnabledToolCategories) > 0 {
-		if err := toolManager.EnableCategoriesByIDs(opts.EnabledToolCategories); err != nil {
-			return nil, fmt.Errorf("failed to enable tool categories: %w", err)
-		}
-	}
+	// Offer the language server tools; the servers start when first used
+	mountLanguageServers(toolManager, opts.LSPServers)
 
 	return &ChatService{
 		backend:      b,
@@ -91,14 +125,55 @@ func NewChatService(opts ChatOptions) (*ChatService, error) {
 		systemPrompt: opts.InitialSystemPrompt,
 		toolManager:  toolManager,
 		toolsEnabled: opts.EnableTools,
+		mcp:          mcpManager,
 	}, nil
 }
 
 // SendMessage sends a message to the chat service
-func (s *ChatService) SendMessage(content string) (Message, error) {
+func (s *ChatService) SendMessage(ctx context.Context, content string) (Message, error) {
+	s.conversationMu.Lock()
+	defer s.conversationMu.Unlock()
+
+	return s.sendMessageLocked(ctx, content)
+}
+
+// AttachResource reads an MCP resource and attaches it to the next message
+func (s *ChatService) AttachResource(uri string) (Attachment, error) {
+	attachment, err := readAttachment(s.mcp, uri)
+	if err != nil {
+		return Attachment{}, err
+	}
+
+	s.conversationMu.Lock()
+	defer s.conversationMu.Unlock()
+
+	s.attachments = append(s.attachments, attachment)
+	return attachment, nil
+}
+
+// SendPrompt renders an MCP prompt template and sends it as a user message
+func (s *ChatService) SendPrompt(ctx context.Context, server, name string, args []string) (Message, error) {
+	content, err := renderPrompt(s.mcp, server, name, args)
+	if err != nil {
+		return Message{}, err
+	}
+
 	s.conversationMu.Lock()
 	defer s.conversationMu.Unlock()
 
+	return s.sendMessageLocked(ctx, content, TagMCPPrompt)
+}
+
+// sendMessageLocked adds a user message with any attached resources and
+// runs the turn; the caller must hold conversationMu. Tags are ignored as
+// this service keeps no context manager.
+func (s *ChatService) sendMessageLocked(ctx context.Context, content string, tags ...string) (Message, error) {
+	if len(s.attachments) > 0 {
+		content = withAttachments(content, s.attachments)
+		tags = append(tags, TagMCPResource)
+		s.attachments = nil
+	}
+
 	// Add user message to history
 	userMsg := Message{
 		Sender:  "user",
@@ -107,109 +182,59 @@ func (s *ChatService) SendMessage(content string) (Message, error) {
 	}
 	s.messages = append(s.messages, userMsg)
 
+	// Group file checkpoints by turn
+	s.toolManager.BeginTurn(content)
+
 	// Process as a conversation with potential tool use
-	return s.processChatWithTools()
+	return s.processChatWithTools(ctx)
 }
 
 // processChatWithTools handles the full chat flow with potential tool usage
-func (s *ChatService) processChatWithTools() (Message, error) {
-	var toolResults []backend.ToolResult
-	maxToolCalls := 10 // Prevent infinite tool usage loops
-
-	for i := 0; i < maxToolCalls; i++ {
-		// Prepare messages for the backend
-		backendMessages := s.prepareBackendMessages()
-
-		// Create chat request with tools if enabled
-		req := backend.ChatRequest{
-			Messages:    backendMessages,
-			MaxTokens:   s.options.MaxTokens,
-			Temperature: s.options.Temperature,
-			Options:     make(map[string]any),
-		}
-
-		// Add tools if enabled
-		if s.toolsEnabled && s.toolManager != nil && s.toolManager.IsToolsEnabled() {
-			req.Options["tools"] = s.toolManager.GetTools()
+func (s *ChatService) processChatWithTools(ctx context.Context) (Message, error) {
+	// Each turn gets a fresh loop so limits apply per turn
+	s.loop = &agentLoop{
+		backend:                s.backend,
+		toolManager:            s.toolManager,
+		toolsEnabled:           s.toolsEnabled,
+		maxTokens:              s.options.MaxTokens,
+		temperature:            s.options.Temperature,
+		maxConsecutiveFailures: s.options.MaxConsecutiveToolFailures,
+		budget:                 s.options.LoopBudget,
+	}
 
-			// Add tool results if we have any
-			if len(toolResults) > 0 {
-				req.Options["tool_results"] = toolResults
-			}
-		}
+	return s.loop.run(ctx, s)
+}
 
-		// Send to backend
-		ctx := context.Background()
-		resp, err := s.backend.SendMessage(ctx, req)
-		if err != nil {
-			return Message{}, fmt.Errorf("backend error: %w", err)
-		}
+// ContinueTurn resumes a turn that paused on a loop limit
+func (s *ChatService) ContinueTurn(ctx context.Context, steps int) (Message, error) {
+	s.conversationMu.Lock()
+	defer s.conversationMu.Unlock()
 
-		// If the model requested a tool
-		if resp.ToolUse != nil && resp.FinishReason == "tool_use" {
-			// Execute the tool first before adding any messages
-			result, err := s.toolManager.HandleToolUse((*core.ToolUse)(resp.ToolUse))
-			if err != nil {
-				// Add error message to history
-				errorMsg := Message{
-					Sender:  "system",
-					Content: fmt.Sprintf("Error executing tool: %v", err),
-					IsUser:  false,
-				}
-				s.messages = append(s.messages, errorMsg)
-
-				// Return error to user
-				return errorMsg, nil
-			}
-
-			// Add a single combined message about tool usage with more details
-			toolMsg := Message{
-				Sender: "assistant",
-				Content: fmt.Sprintf("Using the '%s' tool to help answer your question. Tool request details: %s",
-					resp.ToolUse.Name,
-					string(resp.ToolUse.Input)),
-				IsUser: false,
-			}
-			s.messages = append(s.messages, toolMsg)
-
-			// Store tool result for next request
-			toolResults = append(toolResults, *result)
-
-			// Add a debug message showing the tool result with formatting
-			debugMsg := Message{
-				Sender:  "system",
-				Content: fmt.Sprintf("Debug - Tool '%s' result: ```json\n%s\n```", result.Name, string(result.Result)),
-				IsUser:  false,
-			}
-			s.messages = append(s.messages, debugMsg)
-
-			// Continue to next iteration to send the tool result
-			continue
-		}
+	if s.loop == nil {
+		return Message{}, fmt.Errorf("there is no paused turn to continue")
+	}
 
-		// No tool use, we have a final response
-		respMsg := Message{
-			Sender:  "assistant",
-			Content: resp.Content,
-			IsUser:  false,
-		}
+	return s.loop.resume(ctx, s, steps)
+}
 
-		// Add to history
-		s.messages = append(s.messages, respMsg)
+// backendMessages returns the conversation to send to the backend
+func (s *ChatService) backendMessages() []backend.Message {
+	return s.prepareBackendMessages()
+}
 
-		// Return the final response
-		return respMsg, nil
-	}
+// recordMessage adds a message produced by the agent loop to the history
+func (s *ChatService) recordMessage(msg Message, important bool, tags ...string) {
+	s.messages = append(s.messages, msg)
+}
 
-	// If we reached max tool calls, inform the user
-	errorMsg := Message{
-		Sender:  "system",
-		Content: "Exceeded maximum number of tool calls. The operation was halted.",
-		IsUser:  false,
-	}
-	s.messages = append(s.messages, errorMsg)
+// SetToolProgressHandler sets the callback that receives progress updates from running tools
+func (s *ChatService) SetToolProgressHandler(fn core.ProgressFunc) {
+	s.toolManager.SetProgressHandler(fn)
+}
 
-	return errorMsg, nil
+// SetToolApprovalHandler sets the callback that asks the user to approve tool actions
+func (s *ChatService) SetToolApprovalHandler(fn core.ApprovalFunc) {
+	s.toolManager.SetApprovalHandler(fn)
 }
 
 // GetHistory returns the chat history
@@ -230,6 +255,7 @@ func (s *ChatService) Clear() error {
 	defer s.conversationMu.Unlock()
 
 	s.messages = []Message{}
+	s.attachments = nil
 	return nil
 }
 
@@ -304,12 +330,50 @@ func (s *ChatService) IsToolsEnabled() bool {
 	return s.toolsEnabled
 }
 
+// UndoLastTurn restores the files changed during the most recent turn
+func (s *ChatService) UndoLastTurn() (checkpoint.Turn, []string, error) {
+	s.conversationMu.Lock()
+	defer s.conversationMu.Unlock()
+
+	store := s.toolManager.GetCheckpointStore()
+	if store == nil {
+		return checkpoint.Turn{}, nil, fmt.Errorf("checkpoints are disabled")
+	}
+
+	turn, restored, err := store.Undo()
+	if err == nil {
+		// Let the model know its earlier changes were reverted
+		s.messages = append(s.messages, Message{
+			Sender:  "system",
+			Content: undoNotice(turn, restored),
+			IsUser:  false,
+		})
+	}
+
+	return turn, restored, err
+}
+
+// MCPServers returns the manager of the configured MCP servers
+func (s *ChatService) MCPServers() *mcp.Manager {
+	return s.mcp
+}
+
+// Jobs returns the background jobs started by tools in this conversation
+func (s *ChatService) Jobs() []core.Job {
+	return s.toolManager.Jobs()
+}
+
 // Close closes the chat service and releases resources
 func (s *ChatService) Close() error {
+	// Stop tool resources such as MCP servers, the shell session and background jobs
+	err := s.toolManager.Close()
+
 	if s.backend != nil {
-		return s.backend.Close()
+		if backendErr := s.backend.Close(); backendErr != nil {
+			return backendErr
+		}
 	}
-	return nil
+	return err
 }
 
 // Default system prompt for backward compatibility
@@ -0,0 +1,104 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/checkpoint"
+	"github.com/navicore/mcpterm-go/pkg/lsp"
+	"github.com/navicore/mcpterm-go/pkg/mcp"
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/policy"
+)
+
+// mcpStartTimeout bounds how long starting the MCP servers may delay startup
+const mcpStartTimeout = 30 * time.Second
+
+// NewConfiguredToolManager creates a tool manager configured from the chat options.
+// It is shared by all chat services, and by the MCP server mode, so they all
+// apply the same tool settings.
+func NewConfiguredToolManager(opts ChatOptions) (*tools.ToolManager, error) {
+	toolManager, err := tools.Initialize()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tool manager: %w", err)
+	}
+
+	// Set tool availability based on options
+	toolManager.EnableTools(opts.EnableTools)
+
+	// Add tools implemented by executables declared in the configuration
+	externalCategories, err := toolManager.LoadExternalTools(opts.ExternalTools)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load external tools: %w", err)
+	}
+
+	// Enable specific categories if provided. Categories created for
+	// external tools stay enabled, as their tools were declared explicitly.
+	if len(opts.EnabledToolCategories) > 0 {
+		categories := append(append([]string{}, opts.EnabledToolCategories...), externalCategories...)
+		if err := toolManager.EnableCategoriesByIDs(categories); err != nil {
+			return nil, fmt.Errorf("failed to enable tool categories: %w", err)
+		}
+	}
+
+	// Check the commands of shell tools against the configured rules
+	commandPolicy, err := policy.New(opts.ShellPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shell policy: %w", err)
+	}
+	toolManager.SetCommandPolicy(commandPolicy)
+
+	// Restrict the processes tools start according to their category
+	if err := toolManager.SetSandbox(opts.Sandbox); err != nil {
+		return nil, fmt.Errorf("invalid sandbox config: %w", err)
+	}
+
+	// Limit the number of model requests per turn
+	toolManager.SetMaxToolsPerMsg(opts.LoopBudget.MaxIterations)
+
+	// Limit how long each tool may run
+	toolManager.SetTimeouts(opts.ToolTimeouts)
+
+	// Snapshot files before tools modify them so turns can be undone
+	if opts.EnableCheckpoints {
+		toolManager.SetCheckpointStore(checkpoint.NewStore(opts.CheckpointDir))
+	}
+
+	return toolManager, nil
+}
+
+// mountMCPServers starts the configured MCP servers and registers their tools.
+// Servers that fail to start are logged and skipped so they do not prevent
+// the chat from starting. The servers are stopped when the tool manager is closed.
+func mountMCPServers(toolManager *tools.ToolManager, servers []mcp.ServerConfig) *mcp.Manager {
+	if len(servers) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mcpStartTimeout)
+	defer cancel()
+
+	manager := mcp.NewManager()
+	if err := manager.Mount(ctx, toolManager, servers); err != nil {
+		contextLogger.Printf("ERROR: Failed to start MCP servers: %v", err)
+	}
+	toolManager.AddCloser(manager)
+
+	return manager
+}
+
+// mountLanguageServers registers the tools backed by the configured language
+// servers. The servers are started when a tool first needs them and stopped
+// when the tool manager is closed.
+func mountLanguageServers(toolManager *tools.ToolManager, servers []lsp.ServerConfig) {
+	if len(servers) == 0 {
+		return
+	}
+
+	manager := lsp.NewManager()
+	if err := manager.Mount(toolManager, servers); err != nil {
+		contextLogger.Printf("ERROR: Failed to configure language servers: %v", err)
+	}
+	toolManager.AddCloser(manager)
+}
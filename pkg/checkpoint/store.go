@@ -0,0 +1,586 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/diff"
+)
+
+// manifestName is the name of the file describing a turn's snapshots
+const manifestName = "manifest.json"
+
+// Directories larger than these limits are not snapshotted, so that deleting
+// a dependency or build output directory does not copy all of it first
+const (
+	DefaultMaxDirEntries = 10000
+	DefaultMaxDirBytes   = 100 << 20
+)
+
+// Entry records the state of a single path before a tool modified it
+type Entry struct {
+	Path       string      `json:"path"`                  // Absolute path that was snapshotted
+	Tool       string      `json:"tool"`                  // Tool that was about to modify the path
+	Existed    bool        `json:"existed"`               // Whether the path existed before the change
+	IsDir      bool        `json:"is_dir,omitempty"`      // Whether the path was a directory
+	LinkTarget string      `json:"link_target,omitempty"` // Target of the path if it was a symlink
+	Mode       os.FileMode `json:"mode,omitempty"`        // Permissions of the original path
+	Blob       string      `json:"blob,omitempty"`        // Snapshot file, relative to the turn directory
+}
+
+// Skip records a path that was not snapshotted, and so cannot be restored
+type Skip struct {
+	Path   string `json:"path"`   // Absolute path that was not snapshotted
+	Tool   string `json:"tool"`   // Tool that was about to modify the path
+	Reason string `json:"reason"` // Why the path was not snapshotted
+}
+
+// Turn groups the snapshots taken during one conversation turn
+type Turn struct {
+	ID         string     `json:"id"`                    // Unique identifier of the turn
+	SessionID  string     `json:"session_id"`            // Session the turn belongs to
+	Sequence   int        `json:"sequence"`              // Position of the turn within its session
+	Prompt     string     `json:"prompt"`                // User message that started the turn
+	CreatedAt  time.Time  `json:"created_at"`            // When the turn started
+	Entries    []Entry    `json:"entries"`               // Snapshotted paths in the order they were taken
+	Skipped    []Skip     `json:"skipped,omitempty"`     // Paths too large to snapshot
+	RestoredAt *time.Time `json:"restored_at,omitempty"` // When the turn was last restored, if ever
+}
+
+// Files returns the distinct paths touched during the turn
+func (t Turn) Files() []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, e := range t.Entries {
+		if e.IsDir || seen[e.Path] {
+			continue
+		}
+		seen[e.Path] = true
+		files = append(files, e.Path)
+	}
+	return files
+}
+
+// Store snapshots files before tools modify them so a turn can be reverted.
+// It works on plain directories and does not depend on version control.
+type Store struct {
+	mu        sync.Mutex
+	baseDir   string
+	sessionID string
+	sequence  int
+	current   *Turn
+	seen      map[string]bool // Paths already snapshotted in the current turn
+
+	maxDirEntries int   // Most files and directories a snapshotted directory may hold
+	maxDirBytes   int64 // Most bytes of files a snapshotted directory may hold
+}
+
+// DefaultDir returns the default checkpoint directory (~/.mcpterm/checkpoints)
+func DefaultDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".mcpterm", "checkpoints")
+	}
+	return filepath.Join(homeDir, ".mcpterm", "checkpoints")
+}
+
+// NewStore creates a checkpoint store rooted at baseDir.
+// The directory is created lazily when the first snapshot is taken.
+func NewStore(baseDir string) *Store {
+	if baseDir == "" {
+		baseDir = DefaultDir()
+	}
+
+	return &Store{
+		baseDir:       baseDir,
+		sessionID:     newSessionID(),
+		seen:          make(map[string]bool),
+		maxDirEntries: DefaultMaxDirEntries,
+		maxDirBytes:   DefaultMaxDirBytes,
+	}
+}
+
+// SetDirLimits sets the size above which directories are not snapshotted
+func (s *Store) SetDirLimits(maxEntries int, maxBytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxDirEntries = maxEntries
+	s.maxDirBytes = maxBytes
+}
+
+// sessionIDCounter tells apart stores created by one process in the same second
+var sessionIDCounter atomic.Int64
+
+// newSessionID returns an ID for a new session: the start time, and the
+// process ID and a counter so that sessions started in the same second,
+// in one process or several, never share turn directories
+func newSessionID() string {
+	id := fmt.Sprintf("%s-%d", time.Now().Format("20060102-150405"), os.Getpid())
+	if n := sessionIDCounter.Add(1); n > 1 {
+		id += fmt.Sprintf(".%d", n)
+	}
+	return id
+}
+
+// BaseDir returns the directory where checkpoints are stored
+func (s *Store) BaseDir() string {
+	return s.baseDir
+}
+
+// BeginTurn starts a new turn; subsequent snapshots are grouped under it
+func (s *Store) BeginTurn(prompt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.beginTurnLocked(prompt)
+}
+
+// beginTurnLocked starts a new turn; the caller must hold s.mu
+func (s *Store) beginTurnLocked(prompt string) {
+	s.sequence++
+	s.current = &Turn{
+		ID:        fmt.Sprintf("%s-%03d", s.sessionID, s.sequence),
+		SessionID: s.sessionID,
+		Sequence:  s.sequence,
+		Prompt:    prompt,
+		CreatedAt: time.Now(),
+	}
+	s.seen = make(map[string]bool)
+}
+
+// Snapshot records the current state of the given paths before tool changes them.
+// Each path is only snapshotted once per turn so the oldest state is kept.
+func (s *Store) Snapshot(tool string, paths []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == nil {
+		// Snapshots outside of an explicit turn still get grouped
+		s.beginTurnLocked("")
+	}
+
+	turnDir := filepath.Join(s.baseDir, s.current.ID)
+	for _, p := range paths {
+		absPath, err := filepath.Abs(p)
+		if err != nil {
+			return fmt.Errorf("failed to resolve path %s: %w", p, err)
+		}
+
+		// Record directories too large to copy instead of snapshotting them
+		if info, err := os.Lstat(absPath); err == nil && info.IsDir() && !s.seen[absPath] {
+			if reason := s.oversized(absPath); reason != "" {
+				s.seen[absPath] = true
+				s.current.Skipped = append(s.current.Skipped, Skip{Path: absPath, Tool: tool, Reason: reason})
+				continue
+			}
+		}
+
+		if err := s.snapshotPath(turnDir, tool, absPath); err != nil {
+			return err
+		}
+	}
+
+	return s.writeManifest(*s.current)
+}
+
+// errOversized stops the walk of a directory once it exceeds a limit
+var errOversized = errors.New("directory exceeds the snapshot limits")
+
+// oversized returns why a directory is too large to snapshot, or "" if it is not
+func (s *Store) oversized(dir string) string {
+	entries := 0
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable entries are reported when the snapshot is taken
+			return nil
+		}
+		entries++
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		if entries > s.maxDirEntries || size > s.maxDirBytes {
+			return errOversized
+		}
+		return nil
+	})
+	if err == nil {
+		return ""
+	}
+	if entries > s.maxDirEntries {
+		return fmt.Sprintf("the directory holds more than %d files and directories", s.maxDirEntries)
+	}
+	return fmt.Sprintf("the directory holds more than %d MB", s.maxDirBytes>>20)
+}
+
+// snapshotPath records a single path, recursing into directories
+func (s *Store) snapshotPath(turnDir, tool, path string) error {
+	if s.seen[path] {
+		return nil
+	}
+	s.seen[path] = true
+
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		s.current.Entries = append(s.current.Entries, Entry{Path: path, Tool: tool, Existed: false})
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+
+	entry := Entry{Path: path, Tool: tool, Existed: true, Mode: info.Mode().Perm()}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return fmt.Errorf("failed to read link %s: %w", path, err)
+		}
+		entry.LinkTarget = target
+		s.current.Entries = append(s.current.Entries, entry)
+
+		// Tools write through links, so the file a link points to is kept too
+		real, err := filepath.EvalSymlinks(path)
+		if os.IsNotExist(err) {
+			// Writing through a dangling link creates its target
+			real = target
+			if !filepath.IsAbs(real) {
+				real = filepath.Join(filepath.Dir(path), real)
+			}
+			if _, err := os.Lstat(real); !os.IsNotExist(err) {
+				return nil
+			}
+		} else if err != nil {
+			return nil
+		}
+		if info, err := os.Lstat(real); err == nil && !info.Mode().IsRegular() {
+			return nil
+		}
+		return s.snapshotPath(turnDir, tool, real)
+
+	case info.IsDir():
+		entry.IsDir = true
+		s.current.Entries = append(s.current.Entries, entry)
+
+		children, err := os.ReadDir(path)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", path, err)
+		}
+		for _, child := range children {
+			if err := s.snapshotPath(turnDir, tool, filepath.Join(path, child.Name())); err != nil {
+				return err
+			}
+		}
+
+	default:
+		entry.Blob = filepath.Join("blobs", fmt.Sprintf("%d", len(s.current.Entries)))
+		if err := copyFile(path, filepath.Join(turnDir, entry.Blob)); err != nil {
+			return fmt.Errorf("failed to snapshot %s: %w", path, err)
+		}
+		s.current.Entries = append(s.current.Entries, entry)
+	}
+
+	return nil
+}
+
+// writeManifest persists a turn's metadata
+func (s *Store) writeManifest(turn Turn) error {
+	turnDir := filepath.Join(s.baseDir, turn.ID)
+	if err := os.MkdirAll(turnDir, 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(turn, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint manifest: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(turnDir, manifestName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint manifest: %w", err)
+	}
+
+	return nil
+}
+
+// ListTurns returns all recorded turns, newest first
+func (s *Store) ListTurns() ([]Turn, error) {
+	entries, err := os.ReadDir(s.baseDir)
+	if os.IsNotExist(err) {
+		return []Turn{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint directory: %w", err)
+	}
+
+	turns := []Turn{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		turn, err := s.LoadTurn(entry.Name())
+		if err != nil {
+			// Skip incomplete or foreign directories
+			continue
+		}
+		turns = append(turns, turn)
+	}
+
+	sort.Slice(turns, func(i, j int) bool {
+		return turns[i].CreatedAt.After(turns[j].CreatedAt)
+	})
+
+	return turns, nil
+}
+
+// LoadTurn reads the manifest of a single turn
+func (s *Store) LoadTurn(id string) (Turn, error) {
+	var turn Turn
+
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return turn, fmt.Errorf("invalid checkpoint id %q", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.baseDir, id, manifestName))
+	if err != nil {
+		return turn, fmt.Errorf("checkpoint %s not found: %w", id, err)
+	}
+
+	if err := json.Unmarshal(data, &turn); err != nil {
+		return turn, fmt.Errorf("failed to parse checkpoint %s: %w", id, err)
+	}
+
+	return turn, nil
+}
+
+// Restore reverts every path touched in the turn to its snapshotted state.
+// It returns the paths that were restored or removed.
+func (s *Store) Restore(id string) ([]string, error) {
+	turn, err := s.LoadTurn(id)
+	if err != nil {
+		return nil, err
+	}
+
+	turnDir := filepath.Join(s.baseDir, turn.ID)
+	var restored []string
+
+	// Remove paths that did not exist before the turn (newest first),
+	// then recreate the original ones in the order they were recorded
+	for i := len(turn.Entries) - 1; i >= 0; i-- {
+		e := turn.Entries[i]
+		if e.Existed {
+			continue
+		}
+		if _, err := os.Lstat(e.Path); err == nil {
+			if err := os.RemoveAll(e.Path); err != nil {
+				return restored, fmt.Errorf("failed to remove %s: %w", e.Path, err)
+			}
+			restored = append(restored, e.Path)
+		}
+	}
+
+	for _, e := range turn.Entries {
+		if !e.Existed {
+			continue
+		}
+
+		if err := restoreEntry(turnDir, e); err != nil {
+			return restored, err
+		}
+		if !e.IsDir {
+			restored = append(restored, e.Path)
+		}
+	}
+
+	now := time.Now()
+	turn.RestoredAt = &now
+	if err := s.writeManifest(turn); err != nil {
+		return restored, err
+	}
+
+	// Later changes in a restored turn must not extend its snapshot
+	s.mu.Lock()
+	if s.current != nil && s.current.ID == turn.ID {
+		s.current = nil
+	}
+	s.mu.Unlock()
+
+	return restored, nil
+}
+
+// restoreEntry puts a single snapshotted path back in place
+func restoreEntry(turnDir string, e Entry) error {
+	switch {
+	case e.IsDir:
+		if info, err := os.Lstat(e.Path); err == nil && !info.IsDir() {
+			if err := os.Remove(e.Path); err != nil {
+				return fmt.Errorf("failed to replace %s: %w", e.Path, err)
+			}
+		}
+		if err := os.MkdirAll(e.Path, e.Mode|0700); err != nil {
+			return fmt.Errorf("failed to recreate directory %s: %w", e.Path, err)
+		}
+		return os.Chmod(e.Path, e.Mode)
+
+	case e.LinkTarget != "":
+		if err := os.RemoveAll(e.Path); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", e.Path, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(e.Path), 0755); err != nil {
+			return fmt.Errorf("failed to recreate parent of %s: %w", e.Path, err)
+		}
+		return os.Symlink(e.LinkTarget, e.Path)
+
+	default:
+		if info, err := os.Lstat(e.Path); err == nil && info.IsDir() {
+			if err := os.RemoveAll(e.Path); err != nil {
+				return fmt.Errorf("failed to replace %s: %w", e.Path, err)
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(e.Path), 0755); err != nil {
+			return fmt.Errorf("failed to recreate parent of %s: %w", e.Path, err)
+		}
+		// Remove the current file first so read-only files can be replaced
+		if err := os.Remove(e.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to replace %s: %w", e.Path, err)
+		}
+		if err := copyFile(filepath.Join(turnDir, e.Blob), e.Path); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", e.Path, err)
+		}
+		return os.Chmod(e.Path, e.Mode)
+	}
+}
+
+// Undo restores the most recent turn of the current session that changed files
+// and has not been restored yet
+func (s *Store) Undo() (Turn, []string, error) {
+	s.mu.Lock()
+	sessionID := s.sessionID
+	s.mu.Unlock()
+
+	turns, err := s.ListTurns()
+	if err != nil {
+		return Turn{}, nil, err
+	}
+
+	for _, turn := range turns {
+		if turn.SessionID != sessionID || turn.RestoredAt != nil || len(turn.Entries)+len(turn.Skipped) == 0 {
+			continue
+		}
+
+		restored, err := s.Restore(turn.ID)
+		return turn, restored, err
+	}
+
+	return Turn{}, nil, fmt.Errorf("no file changes to undo in this session")
+}
+
+// Diff returns a unified diff between the snapshotted and current state of a turn's files
+func (s *Store) Diff(id string) (string, error) {
+	turn, err := s.LoadTurn(id)
+	if err != nil {
+		return "", err
+	}
+
+	turnDir := filepath.Join(s.baseDir, turn.ID)
+	var sb strings.Builder
+
+	for _, e := range turn.Entries {
+		if e.IsDir || e.LinkTarget != "" {
+			continue
+		}
+
+		original := ""
+		if e.Existed {
+			original = filepath.Join(turnDir, e.Blob)
+		}
+
+		current := e.Path
+		if info, err := os.Stat(current); err != nil || info.IsDir() {
+			current = ""
+		}
+
+		out, err := unifiedDiff(original, current, e.Path)
+		if err != nil {
+			return sb.String(), err
+		}
+		sb.WriteString(out)
+	}
+
+	return sb.String(), nil
+}
+
+// unifiedDiff compares two files as a unified diff, labelling both sides
+// with path. A missing file compares as empty.
+func unifiedDiff(original, current, path string) (string, error) {
+	a, err := readForDiff(original)
+	if err != nil {
+		return "", err
+	}
+	b, err := readForDiff(current)
+	if err != nil {
+		return "", err
+	}
+	if a == b {
+		return "", nil
+	}
+	if isBinary(a) || isBinary(b) {
+		return fmt.Sprintf("Binary files a%s and b%s differ\n", path, path), nil
+	}
+	return diff.Unified("a"+path, "b"+path, a, b, 3), nil
+}
+
+// readForDiff reads a file to compare, treating a missing one or an empty
+// path as empty
+func readForDiff(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(content), nil
+}
+
+// isBinary reports whether content has a NUL byte near the start
+func isBinary(content string) bool {
+	return strings.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
+
+// copyFile copies a file from src to dst, creating dst's parent directory
+func copyFile(src, dst string) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer srcFile.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package checkpoint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	workDir := t.TempDir()
+	store := NewStore(filepath.Join(t.TempDir(), "checkpoints"))
+
+	t.Run("RestoreModifiedAndCreatedFiles", func(t *testing.T) {
+		existing := filepath.Join(workDir, "existing.txt")
+		created := filepath.Join(workDir, "created.txt")
+		require.NoError(t, os.WriteFile(existing, []byte("original\n"), 0644))
+
+		store.BeginTurn("change some files")
+		require.NoError(t, store.Snapshot("file_write", []string{existing, created}))
+
+		// Simulate the tool modifying the files
+		require.NoError(t, os.WriteFile(existing, []byte("modified\n"), 0644))
+		require.NoError(t, os.WriteFile(created, []byte("new\n"), 0644))
+
+		// A second snapshot in the same turn must keep the oldest state
+		require.NoError(t, store.Snapshot("file_write", []string{existing}))
+
+		turns, err := store.ListTurns()
+		require.NoError(t, err)
+		require.Len(t, turns, 1)
+		assert.Equal(t, "change some files", turns[0].Prompt)
+		assert.ElementsMatch(t, []string{existing, created}, turns[0].Files())
+
+		diff, err := store.Diff(turns[0].ID)
+		require.NoError(t, err)
+		assert.Contains(t, diff, "-original")
+		assert.Contains(t, diff, "+modified")
+		assert.Contains(t, diff, "+new")
+		assert.Contains(t, diff, "--- a"+existing+"\n+++ b"+existing+"\n@@ -1 +1 @@\n-original\n+modified\n")
+
+		restored, err := store.Restore(turns[0].ID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{existing, created}, restored)
+
+		content, err := os.ReadFile(existing)
+		require.NoError(t, err)
+		assert.Equal(t, "original\n", string(content))
+
+		_, err = os.Stat(created)
+		assert.True(t, os.IsNotExist(err), "File created during the turn should be removed")
+	})
+
+	t.Run("UndoDeletedDirectoryAndRename", func(t *testing.T) {
+		dir := filepath.Join(workDir, "dir")
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "nested", "a.txt"), []byte("a"), 0600))
+
+		oldPath := filepath.Join(workDir, "old.txt")
+		newPath := filepath.Join(workDir, "new.txt")
+		require.NoError(t, os.WriteFile(oldPath, []byte("moved"), 0644))
+
+		store.BeginTurn("delete and rename")
+		require.NoError(t, store.Snapshot("file_delete", []string{dir}))
+		require.NoError(t, os.RemoveAll(dir))
+		require.NoError(t, store.Snapshot("file_rename", []string{oldPath, newPath}))
+		require.NoError(t, os.Rename(oldPath, newPath))
+
+		turn, _, err := store.Undo()
+		require.NoError(t, err)
+		assert.Equal(t, "delete and rename", turn.Prompt)
+
+		info, err := os.Stat(filepath.Join(dir, "nested", "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		_, err = os.Stat(oldPath)
+		assert.NoError(t, err)
+		_, err = os.Stat(newPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("UndoWriteThroughSymlink", func(t *testing.T) {
+		real := filepath.Join(workDir, "real.txt")
+		link := filepath.Join(workDir, "link.txt")
+		dangling := filepath.Join(workDir, "dangling.txt")
+		require.NoError(t, os.WriteFile(real, []byte("original\n"), 0644))
+		require.NoError(t, os.Symlink("real.txt", link))
+		require.NoError(t, os.Symlink("missing.txt", dangling))
+
+		store.BeginTurn("edit through links")
+		require.NoError(t, store.Snapshot("file_edit", []string{link, dangling}))
+		require.NoError(t, os.WriteFile(link, []byte("edited\n"), 0644))
+		require.NoError(t, os.WriteFile(dangling, []byte("created\n"), 0644))
+
+		_, restored, err := store.Undo()
+		require.NoError(t, err)
+		assert.Contains(t, restored, real)
+
+		content, err := os.ReadFile(real)
+		require.NoError(t, err)
+		assert.Equal(t, "original\n", string(content))
+		target, err := os.Readlink(link)
+		require.NoError(t, err)
+		assert.Equal(t, "real.txt", target)
+		assert.NoFileExists(t, filepath.Join(workDir, "missing.txt"), "the file created through a dangling link is removed")
+	})
+
+	t.Run("UndoSkipsRestoredTurns", func(t *testing.T) {
+		// Every turn with changes has been restored by now
+		_, _, err := store.Undo()
+		assert.Error(t, err)
+	})
+
+	t.Run("InvalidID", func(t *testing.T) {
+		_, err := store.Restore("../escape")
+		assert.Error(t, err)
+	})
+}
+
+func TestStoreSessionsDoNotShareTurns(t *testing.T) {
+	baseDir := filepath.Join(t.TempDir(), "checkpoints")
+	path := filepath.Join(t.TempDir(), "a.txt")
+	require.NoError(t, os.WriteFile(path, []byte("a"), 0644))
+
+	// Two sessions started in the same second
+	first, second := NewStore(baseDir), NewStore(baseDir)
+	first.BeginTurn("first")
+	require.NoError(t, first.Snapshot("file_write", []string{path}))
+	second.BeginTurn("second")
+	require.NoError(t, second.Snapshot("file_write", []string{path}))
+
+	turns, err := first.ListTurns()
+	require.NoError(t, err)
+	require.Len(t, turns, 2)
+	assert.NotEqual(t, turns[0].ID, turns[1].ID)
+	assert.NotEqual(t, turns[0].SessionID, turns[1].SessionID)
+	assert.Contains(t, turns[0].SessionID, fmt.Sprintf("-%d", os.Getpid()))
+}
+
+func TestStoreSkipsLargeDirectories(t *testing.T) {
+	workDir := t.TempDir()
+	store := NewStore(filepath.Join(t.TempDir(), "checkpoints"))
+	store.SetDirLimits(3, 1<<20)
+
+	small := filepath.Join(workDir, "small")
+	large := filepath.Join(workDir, "node_modules")
+	require.NoError(t, os.MkdirAll(small, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(small, "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.MkdirAll(large, 0755))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(large, fmt.Sprintf("%d.js", i)), []byte("x"), 0644))
+	}
+
+	store.BeginTurn("delete directories")
+	require.NoError(t, store.Snapshot("file_delete", []string{small, large}))
+	require.NoError(t, os.RemoveAll(small))
+	require.NoError(t, os.RemoveAll(large))
+
+	turns, err := store.ListTurns()
+	require.NoError(t, err)
+	require.Len(t, turns, 1)
+	assert.Equal(t, []Skip{{Path: large, Tool: "file_delete", Reason: "the directory holds more than 3 files and directories"}}, turns[0].Skipped)
+	_, err = os.Stat(filepath.Join(store.BaseDir(), turns[0].ID, "blobs", "1"))
+	require.NoError(t, err, "the small directory is snapshotted")
+	entries, err := os.ReadDir(filepath.Join(store.BaseDir(), turns[0].ID, "blobs"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "nothing from the large directory is copied")
+
+	turn, restored, err := store.Undo()
+	require.NoError(t, err)
+	assert.Len(t, turn.Skipped, 1)
+	assert.Equal(t, []string{filepath.Join(small, "a.txt")}, restored)
+	assert.NoDirExists(t, large)
+
+	// A turn that only skipped paths can still be undone, to report them
+	store.BeginTurn("delete a large directory")
+	require.NoError(t, os.MkdirAll(large, 0755))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(large, fmt.Sprintf("%d.js", i)), []byte("x"), 0644))
+	}
+	require.NoError(t, store.Snapshot("file_delete", []string{large}))
+	turn, restored, err = store.Undo()
+	require.NoError(t, err)
+	assert.Equal(t, "delete a large directory", turn.Prompt)
+	assert.Empty(t, restored)
+}
@@ -5,9 +5,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/navicore/mcpterm-go/pkg/backend"
 	"github.com/navicore/mcpterm-go/pkg/chat"
+	"github.com/navicore/mcpterm-go/pkg/lsp"
+	"github.com/navicore/mcpterm-go/pkg/mcp"
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/external"
+	"github.com/navicore/mcpterm-go/pkg/tools/policy"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
 )
 
 // Config represents the application configuration
@@ -51,6 +59,33 @@ type ChatConfig struct {
 	// List of enabled tool categories
 	EnabledToolCategories []string `json:"enabled_tool_categories"`
 
+	// File checkpoint options
+	Checkpoints CheckpointConfig `json:"checkpoints"`
+
+	// Tool execution time limits
+	ToolTimeouts ToolTimeoutConfig `json:"tool_timeouts"`
+
+	// Failed tool calls in a row before a turn is stopped (0 for no limit)
+	MaxConsecutiveToolFailures int `json:"max_consecutive_tool_failures"`
+
+	// Limits on the work done per turn before asking to continue
+	Loop LoopConfig `json:"loop"`
+
+	// External MCP servers whose tools are offered to the model
+	MCPServers []MCPServerConfig `json:"mcp_servers"`
+
+	// Language servers backing the code navigation and diagnostics tools
+	LSPServers []LSPServerConfig `json:"lsp_servers"`
+
+	// Tools implemented by executables
+	ExternalTools []ExternalToolConfig `json:"external_tools"`
+
+	// Rules deciding which shell commands tools may run
+	ShellPolicy ShellPolicyConfig `json:"shell_policy"`
+
+	// Restrictions on the processes tools start, by tool category
+	Sandbox SandboxConfig `json:"sandbox"`
+
 	// Context Management options
 	ContextManagement ContextManagementConfig `json:"context_management"`
 
@@ -58,6 +93,307 @@ type ChatConfig struct {
 	AWS AWSConfig `json:"aws"`
 }
 
+// CheckpointConfig contains options for snapshotting files before tools modify them
+type CheckpointConfig struct {
+	// Enable file checkpoints
+	Enabled bool `json:"enabled"`
+
+	// Directory to store checkpoints (default ~/.mcpterm/checkpoints)
+	Dir string `json:"dir"`
+}
+
+// ToolTimeoutConfig contains execution time limits for tools, in seconds.
+// A tool limit overrides its category limit, which overrides the default.
+type ToolTimeoutConfig struct {
+	// Limit applied to tools without a more specific setting
+	DefaultSecs int `json:"default_secs"`
+
+	// Limits by tool category ID
+	Categories map[string]int `json:"categories"`
+
+	// Limits by tool name
+	Tools map[string]int `json:"tools"`
+}
+
+// toTimeoutConfig converts the configured limits to a tool manager timeout config
+func (c ToolTimeoutConfig) toTimeoutConfig() tools.TimeoutConfig {
+	timeouts := tools.TimeoutConfig{
+		Default:    time.Duration(c.DefaultSecs) * time.Second,
+		Categories: make(map[string]time.Duration),
+		Tools:      make(map[string]time.Duration),
+	}
+	for id, secs := range c.Categories {
+		timeouts.Categories[id] = time.Duration(secs) * time.Second
+	}
+	for name, secs := range c.Tools {
+		timeouts.Tools[name] = time.Duration(secs) * time.Second
+	}
+	return timeouts
+}
+
+// LoopConfig limits the agent loop of a single turn. Zero disables a limit,
+// except for MaxIterations which falls back to the default.
+type LoopConfig struct {
+	// Maximum model requests per turn
+	MaxIterations int `json:"max_iterations"`
+
+	// Maximum wall time per turn in seconds
+	MaxDurationSecs int `json:"max_duration_secs"`
+
+	// Maximum tokens used per turn
+	MaxTokens int `json:"max_tokens"`
+
+	// Maximum identical calls to the same tool per turn
+	MaxRepeatedCalls int `json:"max_repeated_calls"`
+}
+
+// toLoopBudget converts the configured limits to an agent loop budget
+func (c LoopConfig) toLoopBudget() chat.LoopBudget {
+	return chat.LoopBudget{
+		MaxIterations:    c.MaxIterations,
+		MaxDuration:      time.Duration(c.MaxDurationSecs) * time.Second,
+		MaxTokens:        c.MaxTokens,
+		MaxRepeatedCalls: c.MaxRepeatedCalls,
+	}
+}
+
+// MCPServerConfig describes an MCP server launched over stdio
+type MCPServerConfig struct {
+	// Unique name, used as the prefix of the server's tool names
+	Name string `json:"name"`
+
+	// Executable and arguments to run
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+
+	// Extra environment variables for the server
+	Env map[string]string `json:"env"`
+
+	// Working directory (default is the current directory)
+	Dir string `json:"dir"`
+
+	// Permission level of the server's tools (default read-write)
+	Permission string `json:"permission"`
+
+	// Restarts allowed after the server exits unexpectedly (default 3)
+	MaxRestarts int `json:"max_restarts"`
+}
+
+// toMCPServers converts the configured servers to MCP client configurations
+func toMCPServers(servers []MCPServerConfig) []mcp.ServerConfig {
+	configs := make([]mcp.ServerConfig, 0, len(servers))
+	for _, server := range servers {
+		configs = append(configs, mcp.ServerConfig{
+			Name:        server.Name,
+			Command:     server.Command,
+			Args:        server.Args,
+			Env:         server.Env,
+			Dir:         server.Dir,
+			Permission:  server.Permission,
+			MaxRestarts: server.MaxRestarts,
+		})
+	}
+	return configs
+}
+
+// LSPServerConfig describes a language server launched over stdio
+type LSPServerConfig struct {
+	// Unique name, shown in tool results
+	Name string `json:"name"`
+
+	// Executable and arguments to run
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+
+	// Extra environment variables for the server
+	Env map[string]string `json:"env"`
+
+	// Workspace root (default is the current directory)
+	RootDir string `json:"root_dir"`
+
+	// File extensions the server handles, such as ".go"
+	Extensions []string `json:"extensions"`
+
+	// languageId of opened documents (default derived from the extension)
+	LanguageID string `json:"language_id"`
+
+	// Server specific options sent with the initialize request
+	InitializationOptions map[string]interface{} `json:"initialization_options"`
+
+	// Restarts allowed after the server exits unexpectedly (default 3)
+	MaxRestarts int `json:"max_restarts"`
+}
+
+// toLSPServers converts the configured servers to LSP client configurations
+func toLSPServers(servers []LSPServerConfig) []lsp.ServerConfig {
+	configs := make([]lsp.ServerConfig, 0, len(servers))
+	for _, server := range servers {
+		config := lsp.ServerConfig{
+			Name:        server.Name,
+			Command:     server.Command,
+			Args:        server.Args,
+			Env:         server.Env,
+			RootDir:     server.RootDir,
+			Extensions:  server.Extensions,
+			LanguageID:  server.LanguageID,
+			MaxRestarts: server.MaxRestarts,
+		}
+		// A nil map would be sent as null rather than omitted
+		if len(server.InitializationOptions) > 0 {
+			config.InitializationOptions = server.InitializationOptions
+		}
+		configs = append(configs, config)
+	}
+	return configs
+}
+
+// ExternalToolConfig declares a tool implemented by an executable, which
+// receives its input as JSON on stdin and writes a JSON result to stdout
+type ExternalToolConfig struct {
+	// Tool name and description as seen by the model
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// JSON schema of the tool's input
+	InputSchema map[string]interface{} `json:"input_schema"`
+
+	// Executable and arguments to run
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+
+	// Extra environment variables for the executable
+	Env map[string]string `json:"env"`
+
+	// Working directory (default is the current directory)
+	Dir string `json:"dir"`
+
+	// Category of the tool (default external)
+	Category string `json:"category"`
+
+	// Permission level of the tool (default execute)
+	Permission string `json:"permission"`
+}
+
+// toExternalSpecs converts the configured tools to external tool specs
+func toExternalSpecs(tools []ExternalToolConfig) []external.Spec {
+	specs := make([]external.Spec, 0, len(tools))
+	for _, tool := range tools {
+		specs = append(specs, external.Spec{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+			Command:     tool.Command,
+			Args:        tool.Args,
+			Env:         tool.Env,
+			Dir:         tool.Dir,
+			Category:    tool.Category,
+			Permission:  core.PermissionLevel(tool.Permission),
+		})
+	}
+	return specs
+}
+
+// ShellPolicyConfig decides which commands the shell, shell_session and
+// job_start tools may run. Rules are tried in order and the first match
+// decides; commands no rule matches get the default action.
+type ShellPolicyConfig struct {
+	// Action for commands no rule matches: allow, ask or deny (default allow)
+	Default string `json:"default"`
+
+	// Rules tried in order
+	Rules []ShellRuleConfig `json:"rules"`
+}
+
+// ShellRuleConfig matches commands; every criterion that is set must match
+type ShellRuleConfig struct {
+	// What to do with a matching command: allow, ask or deny
+	Action string `json:"action"`
+
+	// Program name, e.g. "rm"
+	Command string `json:"command,omitempty"`
+
+	// Leading words of the command, e.g. "git push"
+	Prefix string `json:"prefix,omitempty"`
+
+	// Arguments that must all be present; "|" separates alternatives and
+	// short flags match combined or separately, e.g. ["-r|--recursive", "-f|--force"]
+	Args []string `json:"args,omitempty"`
+
+	// Regular expression matched against the command
+	Pattern string `json:"pattern,omitempty"`
+
+	// Explanation given to the model or the user
+	Reason string `json:"reason,omitempty"`
+}
+
+// toPolicyConfig converts the configured rules to a command policy config
+func (c ShellPolicyConfig) toPolicyConfig() policy.Config {
+	config := policy.Config{Default: policy.Action(c.Default)}
+	for _, rule := range c.Rules {
+		config.Rules = append(config.Rules, policy.Rule{
+			Action:  policy.Action(rule.Action),
+			Command: rule.Command,
+			Prefix:  rule.Prefix,
+			Args:    rule.Args,
+			Pattern: rule.Pattern,
+			Reason:  rule.Reason,
+		})
+	}
+	return config
+}
+
+// SandboxConfig restricts the processes started by tools that run commands,
+// such as shell, shell_session, job_start and external tools. Each category
+// may name a profile; tools in categories without one are not restricted.
+type SandboxConfig struct {
+	// Profiles by name
+	Profiles map[string]SandboxProfileConfig `json:"profiles"`
+
+	// Profile name by tool category ID, e.g. {"development": "restricted"}
+	Categories map[string]string `json:"categories"`
+}
+
+// SandboxProfileConfig describes the restrictions of a sandbox profile.
+// Limits of 0 are not set. Limits and no_network are supported on Linux only.
+type SandboxProfileConfig struct {
+	// CPU time per process, in seconds
+	CPUSecs int `json:"cpu_secs,omitempty"`
+
+	// Virtual memory per process, in MiB
+	MemoryMB int `json:"memory_mb,omitempty"`
+
+	// Open files per process
+	OpenFiles int `json:"open_files,omitempty"`
+
+	// Processes, counted across all processes of the user
+	Processes int `json:"processes,omitempty"`
+
+	// Patterns of environment variable names removed, e.g. ["AWS_*", "*_TOKEN"]
+	ScrubEnv []string `json:"scrub_env,omitempty"`
+
+	// Run without network access, in new user and network namespaces
+	NoNetwork bool `json:"no_network,omitempty"`
+}
+
+// toSandboxConfig converts the configured profiles to a sandbox config
+func (c SandboxConfig) toSandboxConfig() sandbox.Config {
+	config := sandbox.Config{
+		Profiles:   make(map[string]sandbox.Profile, len(c.Profiles)),
+		Categories: c.Categories,
+	}
+	for name, profile := range c.Profiles {
+		config.Profiles[name] = sandbox.Profile{
+			CPUSecs:   profile.CPUSecs,
+			MemoryMB:  profile.MemoryMB,
+			OpenFiles: profile.OpenFiles,
+			Processes: profile.Processes,
+			ScrubEnv:  profile.ScrubEnv,
+			NoNetwork: profile.NoNetwork,
+		}
+	}
+	return config
+}
+
 // ContextManagementConfig contains options for advanced context management
 type ContextManagementConfig struct {
 	// Enable advanced context management
@@ -128,6 +464,46 @@ func DefaultConfig() Config {
 			TopP:                  0.9,
 			EnableTools:           true,                   // Enable system tools by default
 			EnabledToolCategories: []string{"filesystem"}, // Enable only filesystem tools by default
+			Checkpoints: CheckpointConfig{
+				Enabled: true,
+				Dir:     "", // Use ~/.mcpterm/checkpoints
+			},
+			ToolTimeouts: ToolTimeoutConfig{
+				DefaultSecs: int(tools.DefaultToolTimeout / time.Second),
+				Categories:  map[string]int{},
+				Tools:       map[string]int{},
+			},
+			MaxConsecutiveToolFailures: chat.DefaultMaxConsecutiveToolFailures,
+			Loop: LoopConfig{
+				MaxIterations:    0, // Use the tool manager limit
+				MaxDurationSecs:  600,
+				MaxTokens:        0, // No token limit
+				MaxRepeatedCalls: 3,
+			},
+			MCPServers:    []MCPServerConfig{},
+			LSPServers:    []LSPServerConfig{},
+			ExternalTools: []ExternalToolConfig{},
+			ShellPolicy: ShellPolicyConfig{
+				Default: string(policy.Allow),
+				Rules: []ShellRuleConfig{
+					{
+						Action:  string(policy.Ask),
+						Command: "rm",
+						Args:    []string{"-r|-R|--recursive", "-f|--force"},
+						Reason:  "recursive forced deletes cannot be undone",
+					},
+					{
+						Action: string(policy.Ask),
+						Prefix: "git push",
+						Args:   []string{"-f|--force|--force-with-lease"},
+						Reason: "force pushes rewrite shared history",
+					},
+				},
+			},
+			Sandbox: SandboxConfig{
+				Profiles:   map[string]SandboxProfileConfig{},
+				Categories: map[string]string{},
+			},
 			ContextManagement: ContextManagementConfig{
 				Enabled:            false, // Disabled by default
 				PrimaryModelID:     "us.anthropic.claude-3-7-sonnet-20250219-v1:0",
@@ -254,6 +630,17 @@ func (c *Config) GetChatOptions() interface{} {
 		BackendOptions:        backendOptions,
 		EnableTools:           c.Chat.EnableTools,
 		EnabledToolCategories: c.Chat.EnabledToolCategories,
+		EnableCheckpoints:     c.Chat.Checkpoints.Enabled,
+		CheckpointDir:         c.Chat.Checkpoints.Dir,
+		ToolTimeouts:          c.Chat.ToolTimeouts.toTimeoutConfig(),
+
+		MaxConsecutiveToolFailures: c.Chat.MaxConsecutiveToolFailures,
+		LoopBudget:                 c.Chat.Loop.toLoopBudget(),
+		MCPServers:                 toMCPServers(c.Chat.MCPServers),
+		LSPServers:                 toLSPServers(c.Chat.LSPServers),
+		ExternalTools:              toExternalSpecs(c.Chat.ExternalTools),
+		ShellPolicy:                c.Chat.ShellPolicy.toPolicyConfig(),
+		Sandbox:                    c.Chat.Sandbox.toSandboxConfig(),
 	}
 
 	// If context management is enabled, return ContextChatOptions
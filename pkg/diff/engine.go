@@ -0,0 +1,352 @@
+// Package diff compares texts line by line or word by word, with the Myers
+// or patience algorithm, and formats the results as unified, side-by-side
+// or word diffs.
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Options controls how two texts are compared
+type Options struct {
+	Algorithm        string // "myers" (default) or "patience"
+	IgnoreWhitespace string // "" (default), "trailing", "change" or "all"
+	IgnoreBlankLines bool   // Changes that only add or remove blank lines do not count
+	Context          int    // Lines of context around each hunk
+}
+
+// Validate checks the option values
+func (o Options) Validate() error {
+	switch o.Algorithm {
+	case "", "myers", "patience":
+	default:
+		return fmt.Errorf("invalid algorithm %q, must be 'myers' or 'patience'", o.Algorithm)
+	}
+	switch o.IgnoreWhitespace {
+	case "", "none", "trailing", "change", "all":
+	default:
+		return fmt.Errorf("invalid ignore_whitespace %q, must be 'trailing', 'change' or 'all'", o.IgnoreWhitespace)
+	}
+	return nil
+}
+
+// maxDiffCost is the number of edit steps the Myers search takes before it
+// settles for a split that may not give the shortest edit script, which
+// bounds the time spent on very different inputs
+const maxDiffCost = 4096
+
+// Compare returns the edit script turning a into b. Lines are compared
+// after applying the whitespace options, but the ops keep the original text.
+func Compare(a, b []string, opts Options) []Op {
+	keys := map[string]int{}
+	ka := lineKeys(a, opts.IgnoreWhitespace, keys)
+	kb := lineKeys(b, opts.IgnoreWhitespace, keys)
+
+	var kinds []byte
+	if opts.Algorithm == "patience" {
+		kinds = patienceScript(ka, kb)
+	} else {
+		kinds = myersScript(ka, kb)
+	}
+	return buildOps(kinds, a, b)
+}
+
+// lineKeys maps each line to an integer that is equal for lines that compare equal
+func lineKeys(lines []string, ignoreWhitespace string, keys map[string]int) []int {
+	ids := make([]int, len(lines))
+	for i, line := range lines {
+		key := normalizeLine(line, ignoreWhitespace)
+		id, ok := keys[key]
+		if !ok {
+			id = len(keys)
+			keys[key] = id
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// normalizeLine returns the form of a line that is compared under a whitespace option
+func normalizeLine(line, ignoreWhitespace string) string {
+	switch ignoreWhitespace {
+	case "trailing":
+		return strings.TrimRightFunc(line, unicode.IsSpace)
+	case "change":
+		return strings.Join(strings.Fields(line), " ")
+	case "all":
+		return strings.Join(strings.Fields(line), "")
+	}
+	return line
+}
+
+// buildOps turns a script of kinds into ops carrying the lines of a and b.
+// Kept lines take their text from a, and within each run of changes the
+// removed lines come before the added ones.
+func buildOps(kinds []byte, a, b []string) []Op {
+	for start := 0; start < len(kinds); start++ {
+		if kinds[start] == ' ' {
+			continue
+		}
+		end, removed := start, 0
+		for ; end < len(kinds) && kinds[end] != ' '; end++ {
+			if kinds[end] == '-' {
+				removed++
+			}
+		}
+		for i := start; i < end; i++ {
+			kinds[i] = '+'
+			if i-start < removed {
+				kinds[i] = '-'
+			}
+		}
+		start = end
+	}
+
+	ops := make([]Op, len(kinds))
+	i, j := 0, 0
+	for n, kind := range kinds {
+		switch kind {
+		case ' ':
+			ops[n] = Op{' ', a[i]}
+			i++
+			j++
+		case '-':
+			ops[n] = Op{'-', a[i]}
+			i++
+		case '+':
+			ops[n] = Op{'+', b[j]}
+			j++
+		}
+	}
+	return ops
+}
+
+// myersScript returns a shortest edit script between a and b as a sequence
+// of ' ', '-' and '+', using the linear space variant of the Myers algorithm
+func myersScript(a, b []int) []byte {
+	d := &myersDiff{a: a, b: b, script: make([]byte, 0, len(a)+len(b))}
+	d.compare(0, len(a), 0, len(b))
+	return d.script
+}
+
+// myersDiff holds the state of one linear space Myers comparison
+type myersDiff struct {
+	a, b   []int
+	script []byte
+}
+
+// compare appends the script for a[aLo:aHi] against b[bLo:bHi]
+func (d *myersDiff) compare(aLo, aHi, bLo, bHi int) {
+	// Common prefix and suffix are kept as is
+	prefix := 0
+	for aLo+prefix < aHi && bLo+prefix < bHi && d.a[aLo+prefix] == d.b[bLo+prefix] {
+		prefix++
+	}
+	d.keep(prefix)
+	aLo, bLo = aLo+prefix, bLo+prefix
+
+	suffix := 0
+	for aHi-suffix > aLo && bHi-suffix > bLo && d.a[aHi-1-suffix] == d.b[bHi-1-suffix] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aHi:
+		d.repeat('+', bHi-bLo)
+	case bLo == bHi:
+		d.repeat('-', aHi-aLo)
+	default:
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		if (x == aLo && y == bLo && u == aHi && v == bHi) || (u == aLo && v == bLo) || (x == aHi && y == bHi) {
+			// No useful split; give up on finding common lines here
+			d.repeat('-', aHi-aLo)
+			d.repeat('+', bHi-bLo)
+			break
+		}
+		d.compare(aLo, x, bLo, y)
+		d.keep(u - x)
+		d.compare(u, aHi, v, bHi)
+	}
+
+	d.keep(suffix)
+}
+
+// middleSnake finds the middle snake of a shortest edit script between
+// a[aLo:aHi] and b[bLo:bHi], returning its start (x, y) and end (u, v).
+// Past maxDiffCost steps it returns the furthest point the forward search
+// reached as an empty snake instead.
+func (d *myersDiff) middleSnake(aLo, aHi, bLo, bHi int) (int, int, int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+
+	for step := 0; step <= limit; step++ {
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if odd && k-delta >= -(step-1) && k-delta <= step-1 && x+backward[offset+delta-k] >= n {
+				return aLo + startX, bLo + startY, aLo + x, bLo + y
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			if !odd && delta-k >= -step && delta-k <= step && x+forward[offset+delta-k] >= n {
+				return aHi - x, bHi - y, aHi - startX, bHi - startY
+			}
+		}
+
+		if step >= maxDiffCost {
+			// Split at the forward point that got furthest along
+			best, bestX, bestY := -1, 0, 0
+			for k := -step; k <= step; k += 2 {
+				x := forward[offset+k]
+				if y := x - k; x <= n && y >= 0 && y <= m && x+y > best {
+					best, bestX, bestY = x+y, x, y
+				}
+			}
+			return aLo + bestX, bLo + bestY, aLo + bestX, bLo + bestY
+		}
+	}
+	return aLo, bLo, aHi, bHi
+}
+
+// keep appends n kept lines to the script
+func (d *myersDiff) keep(n int) {
+	d.repeat(' ', n)
+}
+
+// repeat appends n ops of a kind to the script
+func (d *myersDiff) repeat(kind byte, n int) {
+	for ; n > 0; n-- {
+		d.script = append(d.script, kind)
+	}
+}
+
+// patienceScript returns an edit script between a and b using patience
+// diff: lines that occur exactly once in both are matched in order first,
+// and the gaps between them are compared recursively, falling back to
+// Myers where no such lines remain. The result keeps unique lines such as
+// function signatures aligned, which often reads better than the shortest
+// script.
+func patienceScript(a, b []int) []byte {
+	script := make([]byte, 0, len(a)+len(b))
+	var compare func(aLo, aHi, bLo, bHi int)
+	compare = func(aLo, aHi, bLo, bHi int) {
+		for aLo < aHi && bLo < bHi && a[aLo] == b[bLo] {
+			script = append(script, ' ')
+			aLo++
+			bLo++
+		}
+		suffix := 0
+		for aHi-suffix > aLo && bHi-suffix > bLo && a[aHi-1-suffix] == b[bHi-1-suffix] {
+			suffix++
+		}
+		aHi, bHi = aHi-suffix, bHi-suffix
+
+		anchors := uniqueCommonLines(a[aLo:aHi], b[bLo:bHi])
+		if len(anchors) == 0 {
+			script = append(script, myersScript(a[aLo:aHi], b[bLo:bHi])...)
+		} else {
+			i, j := aLo, bLo
+			for _, anchor := range anchors {
+				compare(i, aLo+anchor[0], j, bLo+anchor[1])
+				script = append(script, ' ')
+				i, j = aLo+anchor[0]+1, bLo+anchor[1]+1
+			}
+			compare(i, aHi, j, bHi)
+		}
+
+		for ; suffix > 0; suffix-- {
+			script = append(script, ' ')
+		}
+	}
+	compare(0, len(a), 0, len(b))
+	return script
+}
+
+// uniqueCommonLines returns the positions in a and b of the longest
+// increasing sequence of lines that occur exactly once in each
+func uniqueCommonLines(a, b []int) [][2]int {
+	type count struct{ a, b, aIndex, bIndex int }
+	counts := map[int]*count{}
+	for i, key := range a {
+		c, ok := counts[key]
+		if !ok {
+			c = &count{}
+			counts[key] = c
+		}
+		c.a++
+		c.aIndex = i
+	}
+	for j, key := range b {
+		if c, ok := counts[key]; ok {
+			c.b++
+			c.bIndex = j
+		}
+	}
+
+	var pairs [][2]int
+	for _, c := range counts {
+		if c.a == 1 && c.b == 1 {
+			pairs = append(pairs, [2]int{c.aIndex, c.bIndex})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+
+	// Patience sorting finds the longest run of pairs increasing in b
+	var piles []int // Index into pairs of the top card of each pile
+	previous := make([]int, len(pairs))
+	for i, pair := range pairs {
+		pile := sort.Search(len(piles), func(p int) bool { return pairs[piles[p]][1] > pair[1] })
+		previous[i] = -1
+		if pile > 0 {
+			previous[i] = piles[pile-1]
+		}
+		if pile == len(piles) {
+			piles = append(piles, i)
+		} else {
+			piles[pile] = i
+		}
+	}
+	if len(piles) == 0 {
+		return nil
+	}
+
+	sequence := make([][2]int, len(piles))
+	for i, p := len(piles)-1, piles[len(piles)-1]; p >= 0; i, p = i-1, previous[p] {
+		sequence[i] = pairs[p]
+	}
+	return sequence
+}
@@ -0,0 +1,169 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lcsLength returns the length of the longest common subsequence of a and b
+func lcsLength(a, b []string) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+// sides rebuilds the two texts an edit script compares
+func sides(ops []Op) (string, string) {
+	var a, b strings.Builder
+	for _, op := range ops {
+		if op.Kind != '+' {
+			a.WriteString(op.Line)
+		}
+		if op.Kind != '-' {
+			b.WriteString(op.Line)
+		}
+	}
+	return a.String(), b.String()
+}
+
+func TestCompareLinesRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		a := randomText(rng)
+		b := mutateText(rng, a)
+		if rng.Intn(3) == 0 {
+			b = randomText(rng)
+		}
+		la, lb := SplitLines(a), SplitLines(b)
+
+		for _, algorithm := range []string{"myers", "patience"} {
+			ops := Compare(la, lb, Options{Algorithm: algorithm})
+			gotA, gotB := sides(ops)
+			require.Equal(t, a, gotA, algorithm)
+			require.Equal(t, b, gotB, algorithm)
+
+			if algorithm == "myers" {
+				kept := 0
+				for _, op := range ops {
+					if op.Kind == ' ' {
+						kept++
+					}
+				}
+				require.Equal(t, lcsLength(la, lb), kept, "the Myers script is a shortest one:\n%q\n%q", a, b)
+			}
+		}
+	}
+}
+
+func TestCompareLinesLarge(t *testing.T) {
+	// Completely different inputs hit the cost limit and still give a valid script
+	var a, b []string
+	for i := 0; i < 20000; i++ {
+		a = append(a, "a"+strings.Repeat("x", i%7)+"\n")
+		b = append(b, "b"+strings.Repeat("y", i%5)+"\n")
+	}
+	a[10000], b[15000] = "same\n", "same\n"
+
+	ops := Compare(a, b, Options{})
+	gotA, gotB := sides(ops)
+	assert.Equal(t, strings.Join(a, ""), gotA)
+	assert.Equal(t, strings.Join(b, ""), gotB)
+}
+
+func TestPatienceAlignsUniqueLines(t *testing.T) {
+	a := SplitLines("func a() {\n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n")
+	b := SplitLines("func b() {\n\treturn 2\n}\n\nfunc c() {\n\treturn 1\n}\n")
+
+	ops := Compare(a, b, Options{Algorithm: "patience"})
+	var kept []string
+	for _, op := range ops {
+		if op.Kind == ' ' {
+			kept = append(kept, op.Line)
+		}
+	}
+	assert.Equal(t, []string{"func b() {\n", "\treturn 2\n", "}\n"}, kept)
+}
+
+func TestCompareLinesWhitespace(t *testing.T) {
+	a := SplitLines("if x {\n\treturn  1\n}   \n")
+	b := SplitLines("if x {\n    return 1\n}\n")
+
+	tests := []struct {
+		ignore  string
+		changes int
+	}{
+		{ignore: "", changes: 4},
+		{ignore: "trailing", changes: 2},
+		{ignore: "change", changes: 0},
+		{ignore: "all", changes: 0},
+	}
+	for _, tt := range tests {
+		changes := 0
+		for _, op := range Compare(a, b, Options{IgnoreWhitespace: tt.ignore}) {
+			if op.Kind != ' ' {
+				changes++
+			}
+		}
+		assert.Equal(t, tt.changes, changes, tt.ignore)
+	}
+
+	// "change" still sees whitespace inserted inside a word
+	ops := Compare(SplitLines("foobar\n"), SplitLines("foo bar\n"), Options{IgnoreWhitespace: "change"})
+	assert.Len(t, ops, 2)
+	ops = Compare(SplitLines("foobar\n"), SplitLines("foo bar\n"), Options{IgnoreWhitespace: "all"})
+	assert.Len(t, ops, 1)
+}
+
+// randomText returns up to 30 lines drawn from a small vocabulary, so that
+// lines repeat, sometimes without a final newline
+func randomText(rng *rand.Rand) string {
+	var b strings.Builder
+	for i := rng.Intn(30); i > 0; i-- {
+		fmt.Fprintf(&b, "w%d\n", rng.Intn(6))
+	}
+	text := b.String()
+	if rng.Intn(4) == 0 {
+		text = strings.TrimSuffix(text, "\n")
+	}
+	return text
+}
+
+// mutateText inserts, deletes and replaces random lines of text
+func mutateText(rng *rand.Rand, text string) string {
+	lines := SplitLines(text)
+	for i := rng.Intn(5); i > 0; i-- {
+		pos := 0
+		if len(lines) > 0 {
+			pos = rng.Intn(len(lines))
+		}
+		switch rng.Intn(3) {
+		case 0:
+			lines = append(lines[:pos], append([]string{fmt.Sprintf("new%d\n", rng.Intn(6))}, lines[pos:]...)...)
+		case 1:
+			if len(lines) > 0 {
+				lines = append(lines[:pos], lines[pos+1:]...)
+			}
+		case 2:
+			if len(lines) > 0 {
+				lines[pos] = fmt.Sprintf("changed%d\n", rng.Intn(6))
+			}
+		}
+	}
+	return strings.Join(lines, "")
+}
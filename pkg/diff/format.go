@@ -0,0 +1,225 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Segment is a run of text that both sides of a comparison share
+// (Kind ' '), or that only the old ('-') or the new ('+') side has
+type Segment struct {
+	Kind byte
+	Text string
+}
+
+// Words compares two texts word by word. Words are runs of letters,
+// digits and underscores; spaces, line breaks and punctuation are compared
+// on their own.
+func Words(a, b string) []Segment {
+	ta, tb := wordTokens(a), wordTokens(b)
+	keys := map[string]int{}
+	kinds := myersScript(lineKeys(ta, "", keys), lineKeys(tb, "", keys))
+
+	var segments []Segment
+	for _, op := range buildOps(kinds, ta, tb) {
+		if n := len(segments); n > 0 && segments[n-1].Kind == op.Kind {
+			segments[n-1].Text += op.Line
+			continue
+		}
+		segments = append(segments, Segment{Kind: op.Kind, Text: op.Line})
+	}
+	return segments
+}
+
+// wordTokens splits text into words, runs of spaces and single other characters
+func wordTokens(text string) []string {
+	var tokens []string
+	for len(text) > 0 {
+		r, size := utf8.DecodeRuneInString(text)
+		end := size
+		switch {
+		case isWordRune(r):
+			for end < len(text) {
+				next, n := utf8.DecodeRuneInString(text[end:])
+				if !isWordRune(next) {
+					break
+				}
+				end += n
+			}
+		case r == ' ' || r == '\t':
+			for end < len(text) && (text[end] == ' ' || text[end] == '\t') {
+				end++
+			}
+		}
+		tokens = append(tokens, text[:end])
+		text = text[end:]
+	}
+	return tokens
+}
+
+// isWordRune reports whether r can be part of a word
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// BlankChanges marks the changes that belong to runs of changes touching
+// only blank lines, so they can be left out of hunks
+func BlankChanges(ops []Op) []bool {
+	ignored := make([]bool, len(ops))
+	for start := 0; start < len(ops); {
+		if ops[start].Kind == ' ' {
+			start++
+			continue
+		}
+		end, blank := start, true
+		for ; end < len(ops) && ops[end].Kind != ' '; end++ {
+			blank = blank && strings.TrimSpace(ops[end].Line) == ""
+		}
+		for i := start; i < end; i++ {
+			ignored[i] = blank
+		}
+		start = end
+	}
+	return ignored
+}
+
+// changeGroups walks ops[start:end] in order, calling kept for each kept
+// line and changed for each run of changes
+func changeGroups(ops []Op, start, end int, kept func(op Op), changed func(removed, added []Op)) {
+	for i := start; i < end; {
+		if ops[i].Kind == ' ' {
+			kept(ops[i])
+			i++
+			continue
+		}
+		var removed, added []Op
+		for ; i < end && ops[i].Kind != ' '; i++ {
+			if ops[i].Kind == '-' {
+				removed = append(removed, ops[i])
+			} else {
+				added = append(added, ops[i])
+			}
+		}
+		changed(removed, added)
+	}
+}
+
+// FormatWords writes the hunks of an edit script with changed lines
+// merged and the changed words marked [-removed-] and {+added+}, like
+// git diff --word-diff=plain
+func FormatWords(out *strings.Builder, fromLabel, toLabel string, ops []Op, hunks []Hunk) {
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	for _, h := range hunks {
+		out.WriteString(h.header() + "\n")
+		changeGroups(ops, h.start, h.end,
+			func(op Op) {
+				out.WriteString(ensureNewline(op.Line))
+			},
+			func(removed, added []Op) {
+				var text strings.Builder
+				for _, segment := range Words(joinOps(removed), joinOps(added)) {
+					writeWordSegment(&text, segment)
+				}
+				out.WriteString(ensureNewline(text.String()))
+			})
+	}
+}
+
+// writeWordSegment writes a segment of a word diff, marking each changed
+// line piece separately so markers never span lines
+func writeWordSegment(out *strings.Builder, segment Segment) {
+	if segment.Kind == ' ' {
+		out.WriteString(segment.Text)
+		return
+	}
+	open, end := "[-", "-]"
+	if segment.Kind == '+' {
+		open, end = "{+", "+}"
+	}
+	pieces := strings.Split(segment.Text, "\n")
+	for i, piece := range pieces {
+		if piece != "" {
+			out.WriteString(open + piece + end)
+		}
+		if i < len(pieces)-1 {
+			out.WriteString("\n")
+		}
+	}
+}
+
+// FormatSideBySide writes the hunks of an edit script as two columns of
+// numbered lines, old on the left and new on the right. The marker between
+// them is '|' for a changed line, '<' for a removed one and '>' for an added one.
+func FormatSideBySide(out *strings.Builder, fromLabel, toLabel string, ops []Op, hunks []Hunk, width int) {
+	column := max((width-13)/2, 10)
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	for _, h := range hunks {
+		out.WriteString(h.header() + "\n")
+		aLine, bLine := h.aStart+1, h.bStart+1
+		row := func(left Op, marker byte, right Op) {
+			leftNumber, rightNumber := "", ""
+			if left.Kind != 0 {
+				leftNumber = fmt.Sprint(aLine)
+				aLine++
+			}
+			if right.Kind != 0 {
+				rightNumber = fmt.Sprint(bLine)
+				bLine++
+			}
+			line := fmt.Sprintf("%4s %s %c %4s %s", leftNumber, padColumn(left.Line, column), marker, rightNumber, fitColumn(right.Line, column))
+			out.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+
+		changeGroups(ops, h.start, h.end,
+			func(op Op) { row(op, ' ', op) },
+			func(removed, added []Op) {
+				for i := 0; i < max(len(removed), len(added)); i++ {
+					switch {
+					case i < len(removed) && i < len(added):
+						row(removed[i], '|', added[i])
+					case i < len(removed):
+						row(removed[i], '<', Op{})
+					default:
+						row(Op{}, '>', added[i])
+					}
+				}
+			})
+	}
+}
+
+// fitColumn prepares a line for a side-by-side column: tabs are expanded
+// and the line is cut to width characters
+func fitColumn(line string, width int) string {
+	line = strings.TrimRight(line, "\r\n")
+	line = strings.ReplaceAll(line, "\t", "    ")
+	if utf8.RuneCountInString(line) > width {
+		runes := []rune(line)
+		line = string(runes[:width])
+	}
+	return line
+}
+
+// padColumn fits a line to a column and pads it to the column width
+func padColumn(line string, width int) string {
+	line = fitColumn(line, width)
+	return line + strings.Repeat(" ", width-utf8.RuneCountInString(line))
+}
+
+// joinOps concatenates the lines of ops
+func joinOps(ops []Op) string {
+	var b strings.Builder
+	for _, op := range ops {
+		b.WriteString(op.Line)
+	}
+	return b.String()
+}
+
+// ensureNewline adds a newline to text that does not end in one
+func ensureNewline(text string) string {
+	if text == "" || strings.HasSuffix(text, "\n") {
+		return text
+	}
+	return text + "\n"
+}
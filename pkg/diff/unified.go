@@ -0,0 +1,131 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is one line of an edit script: kept (' '), deleted ('-') or inserted ('+')
+type Op struct {
+	Kind byte
+	Line string // The line including its newline, if it has one
+}
+
+// SplitLines splits text into lines that keep their trailing newline
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns the shortest edit script turning a into b
+func Lines(a, b []string) []Op {
+	return Compare(a, b, Options{})
+}
+
+// Unified returns the differences between two texts as a unified diff
+// with the given lines of context, or "" if they are equal
+func Unified(fromLabel, toLabel, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	ops := Lines(SplitLines(a), SplitLines(b))
+	var out strings.Builder
+	FormatUnified(&out, fromLabel, toLabel, ops, Hunks(ops, context, nil))
+	return out.String()
+}
+
+// Hunk is a run of ops shown together, with the lines of a and b it covers
+type Hunk struct {
+	start, end     int // Range of ops
+	aStart, aCount int // 0-based first line and number of lines in a
+	bStart, bCount int
+}
+
+// header returns the @@ line of the hunk
+func (h Hunk) header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.aStart, h.aCount), hunkRange(h.bStart, h.bCount))
+}
+
+// Hunks groups the changes of an edit script into hunks with the given
+// lines of context. Changes marked in ignored do not start a hunk, but are
+// shown when they fall inside one.
+func Hunks(ops []Op, context int, ignored []bool) []Hunk {
+	isChange := func(i int) bool {
+		return ops[i].Kind != ' ' && (ignored == nil || !ignored[i])
+	}
+
+	// Positions in a and b before each op
+	aPos := make([]int, len(ops)+1)
+	bPos := make([]int, len(ops)+1)
+	for i, op := range ops {
+		aPos[i+1], bPos[i+1] = aPos[i], bPos[i]
+		if op.Kind != '+' {
+			aPos[i+1]++
+		}
+		if op.Kind != '-' {
+			bPos[i+1]++
+		}
+	}
+
+	var hunks []Hunk
+	for i := 0; i < len(ops); {
+		if !isChange(i) {
+			i++
+			continue
+		}
+
+		// A hunk extends while the next change is within twice the context
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if isChange(j) {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		end = min(end+context, len(ops))
+
+		hunks = append(hunks, Hunk{
+			start: start, end: end,
+			aStart: aPos[start], aCount: aPos[end] - aPos[start],
+			bStart: bPos[start], bCount: bPos[end] - bPos[start],
+		})
+		i = end
+	}
+	return hunks
+}
+
+// FormatUnified writes the hunks of an edit script as a unified diff
+func FormatUnified(out *strings.Builder, fromLabel, toLabel string, ops []Op, hunks []Hunk) {
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	for _, h := range hunks {
+		out.WriteString(h.header() + "\n")
+		for _, op := range ops[h.start:h.end] {
+			out.WriteByte(op.Kind)
+			out.WriteString(op.Line)
+			if !strings.HasSuffix(op.Line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+}
+
+// hunkRange formats the start and length of a hunk side; an empty side
+// starts at the line before it
+func hunkRange(pos, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", pos)
+	case 1:
+		return fmt.Sprintf("%d", pos+1)
+	default:
+		return fmt.Sprintf("%d,%d", pos+1, count)
+	}
+}
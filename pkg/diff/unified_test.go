@@ -0,0 +1,65 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitLines(t *testing.T) {
+	assert.Nil(t, SplitLines(""))
+	assert.Equal(t, []string{"a\n", "b"}, SplitLines("a\nb"))
+	assert.Equal(t, []string{"a\n", "\n"}, SplitLines("a\n\n"))
+}
+
+func TestDiffLines(t *testing.T) {
+	ops := Lines(SplitLines("a\nb\nc\nd\n"), SplitLines("a\nx\nc\nd\ne\n"))
+
+	var script []string
+	for _, op := range ops {
+		script = append(script, string(op.Kind)+strings.TrimSuffix(op.Line, "\n"))
+	}
+	assert.Equal(t, []string{" a", "-b", "+x", " c", " d", "+e"}, script)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{name: "Equal", a: "a\n", b: "a\n", context: 3, want: ""},
+		{
+			name: "Replace", a: "1\n2\n3\n4\n5\n", b: "1\n2\nthree\n4\n5\n", context: 1,
+			want: "--- a\n+++ b\n@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n",
+		},
+		{
+			name: "SeparateHunks", a: "1\n2\n3\n4\n5\n6\n7\n8\n", b: "one\n2\n3\n4\n5\n6\n7\neight\n", context: 1,
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -7,2 +7,2 @@\n 7\n-8\n+eight\n",
+		},
+		{
+			name: "MergedHunks", a: "1\n2\n3\n4\n", b: "one\n2\n3\nfour\n", context: 1,
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n-4\n+four\n",
+		},
+		{
+			name: "Insert", a: "1\n2\n", b: "1\nnew\n2\n", context: 0,
+			want: "--- a\n+++ b\n@@ -1,0 +2 @@\n+new\n",
+		},
+		{
+			name: "FromEmpty", a: "", b: "x\n", context: 3,
+			want: "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n",
+		},
+		{
+			name: "NoFinalNewline", a: "1\n2", b: "1\n2\n", context: 3,
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n 1\n-2\n\\ No newline at end of file\n+2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Unified("a", "b", tt.a, tt.b, tt.context))
+		})
+	}
+}
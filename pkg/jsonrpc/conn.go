@@ -0,0 +1,235 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// ErrClosed is returned for calls made on, or pending when, a connection closes
+var ErrClosed = errors.New("jsonrpc: connection closed")
+
+// Handler serves requests and notifications sent by the peer. The result
+// is ignored for notifications. Returning an *Error controls the error code
+// sent to the peer; other errors are sent as internal errors.
+type Handler func(ctx context.Context, method string, params json.RawMessage) (interface{}, error)
+
+// Conn is a bidirectional JSON-RPC connection. Either side may send
+// requests; incoming requests are dispatched to the handler.
+type Conn struct {
+	stream  Stream
+	handler Handler
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[string]chan *Message
+	err     error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewConn starts serving a connection on stream. A nil handler answers
+// every request with a method not found error.
+func NewConn(stream Stream, handler Handler) *Conn {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Conn{
+		stream:  stream,
+		handler: handler,
+		pending: make(map[string]chan *Message),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	go c.readLoop()
+
+	return c
+}
+
+// Call sends a request and waits for its response. The result is decoded
+// into result unless it is nil.
+func (c *Conn) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := strconv.FormatInt(c.nextID, 10)
+	responses := make(chan *Message, 1)
+	c.pending[id] = responses
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	msg, err := newMessage(method, params)
+	if err != nil {
+		return err
+	}
+	msg.ID = json.RawMessage(id)
+
+	if err := c.send(msg); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-responses:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("jsonrpc: invalid result for %s: %w", method, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.ctx.Done():
+		return c.Err()
+	}
+}
+
+// Notify sends a notification, which has no response
+func (c *Conn) Notify(method string, params interface{}) error {
+	msg, err := newMessage(method, params)
+	if err != nil {
+		return err
+	}
+	return c.send(msg)
+}
+
+// Close closes the connection and fails all pending calls
+func (c *Conn) Close() error {
+	c.shutdown(ErrClosed)
+	return c.stream.Close()
+}
+
+// Done is closed when the connection stops reading, e.g. because the peer exited
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason the connection stopped, or nil while it is running
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// readLoop reads messages until the stream fails and dispatches them
+func (c *Conn) readLoop() {
+	defer close(c.done)
+
+	for {
+		data, err := c.stream.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = ErrClosed
+			}
+			c.shutdown(err)
+			return
+		}
+
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			// Report unparsable input without an ID, as the spec requires
+			_ = c.send(&Message{JSONRPC: Version, ID: json.RawMessage("null"), Error: NewError(CodeParseError, "%v", err)})
+			continue
+		}
+
+		switch {
+		case msg.IsResponse():
+			c.mu.Lock()
+			responses, ok := c.pending[string(msg.ID)]
+			c.mu.Unlock()
+			if ok {
+				responses <- &msg
+			}
+		case msg.IsRequest(), msg.IsNotification():
+			go c.handle(&msg)
+		}
+	}
+}
+
+// handle dispatches an incoming request or notification to the handler
+func (c *Conn) handle(msg *Message) {
+	var result interface{}
+	var err error
+	if c.handler == nil {
+		err = NewError(CodeMethodNotFound, "method not found: %s", msg.Method)
+	} else {
+		result, err = c.handler(c.ctx, msg.Method, msg.Params)
+	}
+
+	if msg.IsNotification() {
+		return
+	}
+
+	resp := &Message{JSONRPC: Version, ID: msg.ID}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = NewError(CodeInternalError, "%v", err)
+		}
+		resp.Error = rpcErr
+	} else {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			resp.Error = NewError(CodeInternalError, "failed to encode result: %v", marshalErr)
+		} else {
+			resp.Result = data
+		}
+	}
+
+	_ = c.send(resp)
+}
+
+// send encodes and writes a message
+func (c *Conn) send(msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("jsonrpc: failed to encode message: %w", err)
+	}
+
+	if err := c.stream.Write(data); err != nil {
+		return fmt.Errorf("jsonrpc: failed to send message: %w", err)
+	}
+	return nil
+}
+
+// shutdown records why the connection stopped, fails pending calls and cancels running handlers
+func (c *Conn) shutdown(err error) {
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+
+	c.cancel()
+}
+
+// newMessage creates a request or notification message with encoded params
+func newMessage(method string, params interface{}) (*Message, error) {
+	msg := &Message{JSONRPC: Version, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("jsonrpc: failed to encode params for %s: %w", method, err)
+		}
+		msg.Params = data
+	}
+	return msg, nil
+}
@@ -0,0 +1,112 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connPair connects two Conns with in-memory pipes
+func connPair(t *testing.T, serverHandler Handler) (client, server *Conn) {
+	t.Helper()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	client = NewConn(NewLineStream(clientReader, clientWriter), nil)
+	server = NewConn(NewLineStream(serverReader, serverWriter), serverHandler)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	return client, server
+}
+
+func TestConn(t *testing.T) {
+	notified := make(chan string, 1)
+	client, _ := connPair(t, func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		switch method {
+		case "add":
+			var args []int
+			if err := json.Unmarshal(params, &args); err != nil {
+				return nil, NewError(CodeInvalidParams, "%v", err)
+			}
+			return args[0] + args[1], nil
+		case "notify":
+			notified <- string(params)
+			return nil, nil
+		case "wait":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		default:
+			return nil, NewError(CodeMethodNotFound, "method not found: %s", method)
+		}
+	})
+
+	t.Run("Call", func(t *testing.T) {
+		var sum int
+		require.NoError(t, client.Call(context.Background(), "add", []int{2, 3}, &sum))
+		assert.Equal(t, 5, sum)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		err := client.Call(context.Background(), "missing", nil, nil)
+
+		var rpcErr *Error
+		require.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, CodeMethodNotFound, rpcErr.Code)
+	})
+
+	t.Run("Notify", func(t *testing.T) {
+		require.NoError(t, client.Notify("notify", "hello"))
+		select {
+		case params := <-notified:
+			assert.Equal(t, `"hello"`, params)
+		case <-time.After(5 * time.Second):
+			t.Fatal("notification was not delivered")
+		}
+	})
+
+	t.Run("CallCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := client.Call(ctx, "wait", nil, nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestConnClosedByPeer(t *testing.T) {
+	client, server := connPair(t, func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- client.Call(context.Background(), "wait", nil, nil)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	server.Close()
+
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrClosed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending call did not fail when the peer closed")
+	}
+
+	select {
+	case <-client.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection did not stop after the peer closed")
+	}
+}
@@ -0,0 +1,48 @@
+package jsonrpc
+
+import (
+	"strings"
+	"sync"
+)
+
+// RingBuffer keeps the last limit bytes written to it, such as the stderr of
+// a server process to include in errors when it fails
+type RingBuffer struct {
+	mu    sync.Mutex
+	data  []byte
+	limit int
+}
+
+// NewRingBuffer creates a buffer that holds at most limit bytes
+func NewRingBuffer(limit int) *RingBuffer {
+	return &RingBuffer{limit: limit}
+}
+
+// Write appends p, discarding the oldest data beyond the limit
+func (b *RingBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if excess := len(b.data) - b.limit; excess > 0 {
+		b.data = append(b.data[:0], b.data[excess:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the buffered data
+func (b *RingBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return string(b.data)
+}
+
+// Tail returns the last n lines of the buffered data
+func (b *RingBuffer) Tail(n int) string {
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
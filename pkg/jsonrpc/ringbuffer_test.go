@@ -0,0 +1,21 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingBuffer(t *testing.T) {
+	b := NewRingBuffer(16)
+	for i := 1; i <= 5; i++ {
+		fmt.Fprintf(b, "line %d\n", i)
+	}
+
+	// Only the last 16 bytes are kept, cutting the oldest kept line short
+	assert.Equal(t, "3\nline 4\nline 5\n", b.String())
+	assert.Equal(t, "line 5", b.Tail(1))
+	assert.Equal(t, "line 4\nline 5", b.Tail(2))
+	assert.Empty(t, NewRingBuffer(8).Tail(3))
+}
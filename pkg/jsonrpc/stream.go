@@ -0,0 +1,170 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxHeaderMessageSize bounds the Content-Length a header stream accepts
+const maxHeaderMessageSize = 64 * 1024 * 1024
+
+// Stream reads and writes framed JSON-RPC messages
+type Stream interface {
+	// Read returns the next encoded message
+	Read() ([]byte, error)
+
+	// Write sends one encoded message
+	Write(data []byte) error
+
+	// Close closes the underlying reader and writer
+	Close() error
+}
+
+// lineStream frames messages as newline-delimited JSON, as used by MCP over stdio
+type lineStream struct {
+	reader  *bufio.Reader
+	writer  io.Writer
+	closers []io.Closer
+	writeMu sync.Mutex
+}
+
+// NewLineStream creates a stream that frames each message as a single line of JSON.
+// Closing the stream closes r and w if they implement io.Closer.
+func NewLineStream(r io.Reader, w io.Writer) Stream {
+	s := &lineStream{
+		reader: bufio.NewReader(r),
+		writer: w,
+	}
+	if c, ok := w.(io.Closer); ok {
+		s.closers = append(s.closers, c)
+	}
+	if c, ok := r.(io.Closer); ok {
+		s.closers = append(s.closers, c)
+	}
+	return s
+}
+
+// Read returns the next non-empty line
+func (s *lineStream) Read() ([]byte, error) {
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			// Deliver a final line even if it was not newline terminated
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Write sends data followed by a newline
+func (s *lineStream) Write(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	buf := make([]byte, 0, len(data)+1)
+	buf = append(buf, data...)
+	buf = append(buf, '\n')
+	_, err := s.writer.Write(buf)
+	return err
+}
+
+// Close closes the writer and then the reader
+func (s *lineStream) Close() error {
+	var firstErr error
+	for _, c := range s.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// headerStream frames messages with a Content-Length header, as used by LSP over stdio
+type headerStream struct {
+	reader  *textproto.Reader
+	writer  io.Writer
+	closers []io.Closer
+	writeMu sync.Mutex
+}
+
+// NewHeaderStream creates a stream that frames each message with HTTP-style
+// headers, of which Content-Length is required. Closing the stream closes r
+// and w if they implement io.Closer.
+func NewHeaderStream(r io.Reader, w io.Writer) Stream {
+	s := &headerStream{
+		reader: textproto.NewReader(bufio.NewReader(r)),
+		writer: w,
+	}
+	if c, ok := w.(io.Closer); ok {
+		s.closers = append(s.closers, c)
+	}
+	if c, ok := r.(io.Closer); ok {
+		s.closers = append(s.closers, c)
+	}
+	return s
+}
+
+// Read parses the headers of the next message and returns its content
+func (s *headerStream) Read() ([]byte, error) {
+	header, err := s.reader.ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(header) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	value := header.Get("Content-Length")
+	if value == "" {
+		return nil, fmt.Errorf("message without Content-Length header")
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", value)
+	}
+	if length > maxHeaderMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the %d byte limit", length, maxHeaderMessageSize)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(s.reader.R, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// Write sends a Content-Length header followed by data
+func (s *headerStream) Write(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	header := fmt.Sprintf("Content-Length: %d\r\n\r\n", len(data))
+	buf := make([]byte, 0, len(header)+len(data))
+	buf = append(buf, header...)
+	buf = append(buf, data...)
+	_, err := s.writer.Write(buf)
+	return err
+}
+
+// Close closes the writer and then the reader
+func (s *headerStream) Close() error {
+	var firstErr error
+	for _, c := range s.closers {
+		if err := c.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,92 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineStream(t *testing.T) {
+	var out bytes.Buffer
+	stream := NewLineStream(strings.NewReader("{\"a\":1}\n\n  {\"b\":2}"), &out)
+
+	data, err := stream.Read()
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(data))
+
+	data, err = stream.Read()
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":2}`, string(data))
+
+	_, err = stream.Read()
+	assert.ErrorIs(t, err, io.EOF)
+
+	require.NoError(t, stream.Write([]byte(`{"c":3}`)))
+	assert.Equal(t, "{\"c\":3}\n", out.String())
+}
+
+func TestHeaderStream(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		var out bytes.Buffer
+		writer := NewHeaderStream(strings.NewReader(""), &out)
+		require.NoError(t, writer.Write([]byte(`{"a":1}`)))
+		require.NoError(t, writer.Write([]byte(`{"b":"é"}`)))
+		assert.Equal(t, "Content-Length: 7\r\n\r\n{\"a\":1}Content-Length: 10\r\n\r\n{\"b\":\"é\"}", out.String())
+
+		reader := NewHeaderStream(&out, io.Discard)
+		data, err := reader.Read()
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+		data, err = reader.Read()
+		require.NoError(t, err)
+		assert.Equal(t, `{"b":"é"}`, string(data))
+		_, err = reader.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("other headers", func(t *testing.T) {
+		input := "Content-Type: application/vscode-jsonrpc; charset=utf-8\r\ncontent-length: 2\r\n\r\n{}"
+		data, err := NewHeaderStream(strings.NewReader(input), io.Discard).Read()
+		require.NoError(t, err)
+		assert.Equal(t, "{}", string(data))
+	})
+
+	t.Run("missing length", func(t *testing.T) {
+		_, err := NewHeaderStream(strings.NewReader("Content-Type: x\r\n\r\n{}"), io.Discard).Read()
+		assert.ErrorContains(t, err, "Content-Length")
+	})
+
+	t.Run("invalid length", func(t *testing.T) {
+		_, err := NewHeaderStream(strings.NewReader("Content-Length: -1\r\n\r\n"), io.Discard).Read()
+		assert.ErrorContains(t, err, "invalid Content-Length")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := NewHeaderStream(strings.NewReader("Content-Length: 10\r\n\r\n{}"), io.Discard).Read()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
+func TestHeaderStreamConn(t *testing.T) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server := NewConn(NewHeaderStream(serverReader, serverWriter), func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		return method, nil
+	})
+	client := NewConn(NewHeaderStream(clientReader, clientWriter), nil)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	var result string
+	require.NoError(t, client.Call(context.Background(), "echo", nil, &result))
+	assert.Equal(t, "echo", result)
+}
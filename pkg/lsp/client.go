@@ -0,0 +1,736 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/jsonrpc"
+)
+
+// DefaultMaxRestarts is the number of times a crashed server is restarted
+const DefaultMaxRestarts = 3
+
+// DefaultDiagnosticsWait is how long to wait for a server to publish
+// diagnostics after a document is opened or changed
+const DefaultDiagnosticsWait = 5 * time.Second
+
+// stderrLimit is the amount of server stderr output kept for diagnostics
+const stderrLimit = 64 * 1024
+
+// shutdownGrace is how long a server may take to answer shutdown and to exit
+const shutdownGrace = 2 * time.Second
+
+// clientInfo identifies mcpterm to the servers it connects to
+var clientInfo = ClientInfo{Name: "mcpterm", Version: "0.1.0"}
+
+// clientCapabilities are the features mcpterm uses
+var clientCapabilities = map[string]interface{}{
+	"general": map[string]interface{}{
+		"positionEncodings": []string{"utf-16"},
+	},
+	"textDocument": map[string]interface{}{
+		"synchronization": map[string]interface{}{"didSave": false},
+		"definition":      map[string]interface{}{"linkSupport": true},
+		"references":      map[string]interface{}{},
+		"hover": map[string]interface{}{
+			"contentFormat": []string{"markdown", "plaintext"},
+		},
+		"documentSymbol": map[string]interface{}{
+			"hierarchicalDocumentSymbolSupport": true,
+		},
+		"publishDiagnostics": map[string]interface{}{"versionSupport": true},
+	},
+	"workspace": map[string]interface{}{
+		"symbol":           map[string]interface{}{},
+		"configuration":    true,
+		"workspaceFolders": true,
+	},
+	"window": map[string]interface{}{"workDoneProgress": true},
+}
+
+// ServerConfig describes how to launch a language server
+type ServerConfig struct {
+	Name                  string            // Unique name, used in tool output and errors
+	Command               string            // Executable to run
+	Args                  []string          // Command line arguments
+	Env                   map[string]string // Extra environment variables
+	RootDir               string            // Workspace root (default is the current directory)
+	Extensions            []string          // File extensions the server handles, such as ".go"
+	LanguageID            string            // languageId of opened documents (default derived from the extension)
+	InitializationOptions interface{}       // Server specific initialization options
+	MaxRestarts           int               // Restarts allowed after the server exits unexpectedly
+}
+
+// Client is a connection to a single language server running as a child
+// process. The server is started on first use and restarted if it exits, up
+// to the configured number of restarts. Documents are opened on demand and
+// kept in sync with the files on disk.
+type Client struct {
+	config ServerConfig
+	stderr *jsonrpc.RingBuffer
+
+	mu        sync.Mutex
+	cmd       *exec.Cmd
+	conn      *jsonrpc.Conn
+	exited    chan struct{}
+	info      InitializeResult
+	started   bool
+	restarts  int
+	closed    bool
+	documents map[string]*document
+
+	diagMu      sync.Mutex
+	diagnostics map[string]*publishedDiagnostics
+	published   uint64        // Number of diagnostics notifications received
+	diagUpdated chan struct{} // Closed and replaced when diagnostics arrive
+}
+
+// document is the state of a document the server has open
+type document struct {
+	version int
+	text    string
+	synced  uint64 // Value of published when the server last received the text
+}
+
+// publishedDiagnostics are the most recent diagnostics of a document
+type publishedDiagnostics struct {
+	version     *int
+	diagnostics []Diagnostic
+	sequence    uint64
+}
+
+// NewClient creates a client for the given server. The server is not started
+// until Start is called or a request is made.
+func NewClient(config ServerConfig) *Client {
+	if config.MaxRestarts == 0 {
+		config.MaxRestarts = DefaultMaxRestarts
+	}
+	if config.RootDir == "" {
+		config.RootDir = "."
+	}
+	if root, err := filepath.Abs(config.RootDir); err == nil {
+		config.RootDir = root
+	}
+
+	return &Client{
+		config:      config,
+		stderr:      jsonrpc.NewRingBuffer(stderrLimit),
+		documents:   make(map[string]*document),
+		diagnostics: make(map[string]*publishedDiagnostics),
+		diagUpdated: make(chan struct{}),
+	}
+}
+
+// Name returns the configured server name
+func (c *Client) Name() string {
+	return c.config.Name
+}
+
+// Config returns the server configuration
+func (c *Client) Config() ServerConfig {
+	return c.config
+}
+
+// Handles reports whether the server is configured for a file
+func (c *Client) Handles(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, handled := range c.config.Extensions {
+		if strings.ToLower(handled) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Start launches the server and performs the initialize handshake, unless it is already running
+func (c *Client) Start(ctx context.Context) error {
+	_, err := c.connection(ctx)
+	return err
+}
+
+// Running reports whether the server process is running
+func (c *Client) Running() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.conn != nil && !c.hasExited()
+}
+
+// ServerInfo returns the server's answer to the initialize request
+func (c *Client) ServerInfo() InitializeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.info
+}
+
+// Stderr returns the most recent stderr and log output of the server
+func (c *Client) Stderr() string {
+	return c.stderr.String()
+}
+
+// Definition returns the locations where the symbol at a position is defined
+func (c *Client) Definition(ctx context.Context, path string, pos Position) ([]Location, error) {
+	uri, err := c.sync(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw json.RawMessage
+	params := TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos}
+	if err := c.call(ctx, MethodDefinition, params, &raw); err != nil {
+		return nil, err
+	}
+	return parseLocations(raw)
+}
+
+// References returns the locations that refer to the symbol at a position
+func (c *Client) References(ctx context.Context, path string, pos Position, includeDeclaration bool) ([]Location, error) {
+	uri, err := c.sync(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw json.RawMessage
+	params := ReferenceParams{
+		TextDocumentPositionParams: TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos},
+		Context:                    ReferenceContext{IncludeDeclaration: includeDeclaration},
+	}
+	if err := c.call(ctx, MethodReferences, params, &raw); err != nil {
+		return nil, err
+	}
+	return parseLocations(raw)
+}
+
+// Hover returns the hover information for a position, or nil if there is none
+func (c *Client) Hover(ctx context.Context, path string, pos Position) (*Hover, error) {
+	uri, err := c.sync(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var hover *Hover
+	params := TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos}
+	if err := c.call(ctx, MethodHover, params, &hover); err != nil {
+		return nil, err
+	}
+	return hover, nil
+}
+
+// DocumentSymbols returns the symbols of a document. Servers that answer with
+// a flat list of symbols have it converted to symbols without children.
+func (c *Client) DocumentSymbols(ctx context.Context, path string) ([]DocumentSymbol, error) {
+	uri, err := c.sync(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []json.RawMessage
+	if err := c.call(ctx, MethodDocumentSymbol, DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &raw); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]DocumentSymbol, 0, len(raw))
+	for _, item := range raw {
+		var probe struct {
+			Location *Location `json:"location"`
+		}
+		if err := json.Unmarshal(item, &probe); err != nil {
+			return nil, fmt.Errorf("lsp server %s: invalid document symbol: %w", c.config.Name, err)
+		}
+
+		if probe.Location == nil {
+			var symbol DocumentSymbol
+			if err := json.Unmarshal(item, &symbol); err != nil {
+				return nil, fmt.Errorf("lsp server %s: invalid document symbol: %w", c.config.Name, err)
+			}
+			symbols = append(symbols, symbol)
+			continue
+		}
+
+		var info SymbolInformation
+		if err := json.Unmarshal(item, &info); err != nil {
+			return nil, fmt.Errorf("lsp server %s: invalid document symbol: %w", c.config.Name, err)
+		}
+		symbols = append(symbols, DocumentSymbol{
+			Name:           info.Name,
+			Detail:         info.ContainerName,
+			Kind:           info.Kind,
+			Range:          info.Location.Range,
+			SelectionRange: info.Location.Range,
+		})
+	}
+	return symbols, nil
+}
+
+// WorkspaceSymbols returns the symbols of the workspace that match a query
+func (c *Client) WorkspaceSymbols(ctx context.Context, query string) ([]SymbolInformation, error) {
+	var symbols []SymbolInformation
+	if err := c.call(ctx, MethodWorkspaceSymbol, WorkspaceSymbolParams{Query: query}, &symbols); err != nil {
+		return nil, err
+	}
+	return symbols, nil
+}
+
+// Diagnostics returns the diagnostics of a document. If the server has not
+// published diagnostics for the current contents of the file, it waits up to
+// wait for them to arrive and returns what is known then.
+func (c *Client) Diagnostics(ctx context.Context, path string, wait time.Duration) ([]Diagnostic, error) {
+	uri, err := c.sync(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		current, updated := c.currentDiagnostics(uri)
+		if current != nil {
+			return current, nil
+		}
+
+		select {
+		case <-updated:
+		case <-timer.C:
+			return c.cachedDiagnostics(uri), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// currentDiagnostics returns the diagnostics of a document if they were
+// published for its current contents, and a channel closed on the next update
+func (c *Client) currentDiagnostics(uri string) ([]Diagnostic, <-chan struct{}) {
+	c.mu.Lock()
+	doc := c.documents[uri]
+	c.mu.Unlock()
+
+	c.diagMu.Lock()
+	defer c.diagMu.Unlock()
+
+	published, ok := c.diagnostics[uri]
+	if !ok || doc == nil {
+		return nil, c.diagUpdated
+	}
+	if published.version != nil {
+		if *published.version >= doc.version {
+			return nonNil(published.diagnostics), nil
+		}
+	} else if published.sequence > doc.synced {
+		return nonNil(published.diagnostics), nil
+	}
+	return nil, c.diagUpdated
+}
+
+// cachedDiagnostics returns the last diagnostics published for a document
+func (c *Client) cachedDiagnostics(uri string) []Diagnostic {
+	c.diagMu.Lock()
+	defer c.diagMu.Unlock()
+
+	if published, ok := c.diagnostics[uri]; ok {
+		return nonNil(published.diagnostics)
+	}
+	return []Diagnostic{}
+}
+
+// nonNil returns diagnostics, or an empty slice if it is nil
+func nonNil(diagnostics []Diagnostic) []Diagnostic {
+	if diagnostics == nil {
+		return []Diagnostic{}
+	}
+	return diagnostics
+}
+
+// sync opens a document on the server, or sends its new contents if the file
+// changed since it was last sent, and returns its URI
+func (c *Client) sync(ctx context.Context, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %w", path, err)
+	}
+	content, err := os.ReadFile(abs)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", path, err)
+	}
+
+	conn, err := c.connection(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	uri := fileURI(abs)
+	c.mu.Lock()
+	err = c.sendLocked(conn, uri, abs, string(content))
+	c.mu.Unlock()
+	if err != nil {
+		if connectionLost(err) {
+			// Let the process finish so its stderr output is complete
+			c.waitExit(shutdownGrace)
+		}
+		return "", c.exitError(fmt.Errorf("failed to send %s: %w", path, err))
+	}
+	return uri, nil
+}
+
+// sendLocked sends the text of a document unless the server already has it
+func (c *Client) sendLocked(conn *jsonrpc.Conn, uri, path, text string) error {
+	doc, open := c.documents[uri]
+	if open && doc.text == text {
+		return nil
+	}
+
+	c.diagMu.Lock()
+	synced := c.published
+	c.diagMu.Unlock()
+
+	var err error
+	if !open {
+		doc = &document{version: 1, text: text, synced: synced}
+		err = conn.Notify(MethodDidOpen, DidOpenTextDocumentParams{TextDocument: TextDocumentItem{
+			URI:        uri,
+			LanguageID: c.languageID(path),
+			Version:    doc.version,
+			Text:       text,
+		}})
+	} else {
+		doc = &document{version: doc.version + 1, text: text, synced: synced}
+		err = conn.Notify(MethodDidChange, DidChangeTextDocumentParams{
+			TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: doc.version},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: text}},
+		})
+	}
+	if err != nil {
+		return err
+	}
+	c.documents[uri] = doc
+	return nil
+}
+
+// languageID returns the languageId of a file
+func (c *Client) languageID(path string) string {
+	if c.config.LanguageID != "" {
+		return c.config.LanguageID
+	}
+	return LanguageID(path)
+}
+
+// Close shuts down the server. The client cannot be used afterwards.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return c.stopLocked()
+}
+
+// call sends a request, starting or restarting the server if needed
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	conn, err := c.connection(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.Call(ctx, method, params, result); err != nil {
+		if connectionLost(err) {
+			// Let the process finish so its stderr output is complete
+			c.waitExit(shutdownGrace)
+			return c.exitError(err)
+		}
+		return fmt.Errorf("lsp server %s: %s failed: %w", c.config.Name, method, err)
+	}
+	return nil
+}
+
+// connectionLost reports whether err means the server's pipes were closed
+func connectionLost(err error) bool {
+	return errors.Is(err, jsonrpc.ErrClosed) || errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed)
+}
+
+// connection returns the connection to a running server, starting it if necessary
+func (c *Client) connection(ctx context.Context) (*jsonrpc.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("lsp server %s: client is closed", c.config.Name)
+	}
+
+	if c.conn != nil && !c.hasExited() && c.conn.Err() == nil {
+		return c.conn, nil
+	}
+
+	if c.started {
+		// The server exited since it was last used
+		if c.restarts >= c.config.MaxRestarts {
+			return nil, c.exitError(fmt.Errorf("server exited and the restart limit of %d was reached", c.config.MaxRestarts))
+		}
+		c.restarts++
+		_ = c.stopLocked()
+	}
+
+	if err := c.startLocked(ctx); err != nil {
+		return nil, err
+	}
+	return c.conn, nil
+}
+
+// startLocked launches the server process and initializes the session
+func (c *Client) startLocked(ctx context.Context) error {
+	if c.config.Command == "" {
+		return fmt.Errorf("lsp server %s: no command configured", c.config.Name)
+	}
+
+	// Use dedicated pipes so waiting for the process never races with reads
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("lsp server %s: %w", c.config.Name, err)
+	}
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		stdinReader.Close()
+		stdinWriter.Close()
+		return fmt.Errorf("lsp server %s: %w", c.config.Name, err)
+	}
+
+	cmd := exec.Command(c.config.Command, c.config.Args...)
+	cmd.Dir = c.config.RootDir
+	cmd.Env = os.Environ()
+	for key, value := range c.config.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stdin = stdinReader
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = c.stderr
+
+	err = cmd.Start()
+
+	// The child has its own copies of these ends
+	stdinReader.Close()
+	stdoutWriter.Close()
+
+	if err != nil {
+		stdinWriter.Close()
+		stdoutReader.Close()
+		return fmt.Errorf("lsp server %s: failed to start %s: %w", c.config.Name, c.config.Command, err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	// A new server knows no documents or diagnostics
+	c.documents = make(map[string]*document)
+	c.diagMu.Lock()
+	c.diagnostics = make(map[string]*publishedDiagnostics)
+	c.diagMu.Unlock()
+
+	c.cmd = cmd
+	c.exited = exited
+	c.conn = jsonrpc.NewConn(jsonrpc.NewHeaderStream(stdoutReader, stdinWriter), c.handle)
+	c.started = true
+
+	var info InitializeResult
+	params := InitializeParams{
+		ProcessID:             os.Getpid(),
+		ClientInfo:            clientInfo,
+		RootURI:               fileURI(c.config.RootDir),
+		WorkspaceFolders:      c.workspaceFolders(),
+		Capabilities:          clientCapabilities,
+		InitializationOptions: c.config.InitializationOptions,
+	}
+	if err := c.conn.Call(ctx, MethodInitialize, params, &info); err != nil {
+		_ = c.stopLocked()
+		return c.exitError(fmt.Errorf("initialize failed: %w", err))
+	}
+
+	if err := c.conn.Notify(MethodInitialized, struct{}{}); err != nil {
+		_ = c.stopLocked()
+		return c.exitError(fmt.Errorf("initialized notification failed: %w", err))
+	}
+
+	c.info = info
+	return nil
+}
+
+// workspaceFolders returns the single workspace folder of the server
+func (c *Client) workspaceFolders() []WorkspaceFolder {
+	return []WorkspaceFolder{{URI: fileURI(c.config.RootDir), Name: filepath.Base(c.config.RootDir)}}
+}
+
+// stopLocked asks the server to shut down and waits for the process to exit,
+// killing it if it does not exit in time
+func (c *Client) stopLocked() error {
+	if c.conn == nil {
+		return nil
+	}
+
+	if !c.hasExited() && c.conn.Err() == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+		if err := c.conn.Call(ctx, MethodShutdown, nil, nil); err == nil {
+			_ = c.conn.Notify(MethodExit, nil)
+		}
+		cancel()
+	}
+
+	// Closing stdin also asks servers that ignored exit to stop
+	err := c.conn.Close()
+	select {
+	case <-c.exited:
+	case <-time.After(shutdownGrace):
+		_ = c.cmd.Process.Kill()
+		<-c.exited
+	}
+
+	c.conn = nil
+	return err
+}
+
+// hasExited reports whether the server process has exited
+func (c *Client) hasExited() bool {
+	select {
+	case <-c.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitExit waits up to timeout for the current server process to exit
+func (c *Client) waitExit(timeout time.Duration) {
+	c.mu.Lock()
+	exited := c.exited
+	c.mu.Unlock()
+
+	if exited == nil {
+		return
+	}
+	select {
+	case <-exited:
+	case <-time.After(timeout):
+	}
+}
+
+// exitError wraps err with the server's recent stderr output
+func (c *Client) exitError(err error) error {
+	if tail := c.stderr.Tail(10); tail != "" {
+		return fmt.Errorf("lsp server %s: %w\nserver stderr:\n%s", c.config.Name, err, tail)
+	}
+	return fmt.Errorf("lsp server %s: %w", c.config.Name, err)
+}
+
+// handle serves requests and notifications sent by the server
+func (c *Client) handle(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case MethodPublishDiagnostics:
+		var published PublishDiagnosticsParams
+		if err := json.Unmarshal(params, &published); err != nil {
+			return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "%v", err)
+		}
+		c.publish(published)
+		return nil, nil
+	case MethodLogMessage, MethodShowMessage:
+		// Keep server messages with its stderr output
+		var msg LogMessageParams
+		if err := json.Unmarshal(params, &msg); err == nil {
+			fmt.Fprintf(c.stderr, "[%s] %s\n", messageType(msg.Type), msg.Message)
+		}
+		return nil, nil
+	case MethodConfiguration:
+		// No settings are configured: answer null for every section
+		var config ConfigurationParams
+		if err := json.Unmarshal(params, &config); err != nil {
+			return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "%v", err)
+		}
+		return make([]interface{}, len(config.Items)), nil
+	case MethodWorkspaceFolders:
+		return c.workspaceFolders(), nil
+	case MethodRegisterCapability, MethodUnregisterCapability, MethodWorkDoneProgress,
+		MethodShowMessageRequest, MethodProgress:
+		return nil, nil
+	default:
+		return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "method not supported by client: %s", method)
+	}
+}
+
+// publish records the diagnostics a server published for a document
+func (c *Client) publish(published PublishDiagnosticsParams) {
+	c.diagMu.Lock()
+	defer c.diagMu.Unlock()
+
+	// Notifications are handled concurrently, so an older version may arrive late
+	if previous, ok := c.diagnostics[published.URI]; ok && previous.version != nil && published.Version != nil &&
+		*published.Version < *previous.version {
+		return
+	}
+
+	c.published++
+	c.diagnostics[published.URI] = &publishedDiagnostics{
+		version:     published.Version,
+		diagnostics: published.Diagnostics,
+		sequence:    c.published,
+	}
+	close(c.diagUpdated)
+	c.diagUpdated = make(chan struct{})
+}
+
+// messageType names the type of a log message
+func messageType(t int) string {
+	switch t {
+	case 1:
+		return "error"
+	case 2:
+		return "warning"
+	case 3:
+		return "info"
+	default:
+		return "log"
+	}
+}
+
+// parseLocations decodes a result that may be null, a Location, an array of
+// Location or an array of LocationLink
+func parseLocations(raw json.RawMessage) ([]Location, error) {
+	locations := []Location{}
+	if len(raw) == 0 || string(raw) == "null" {
+		return locations, nil
+	}
+
+	var items []json.RawMessage
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, fmt.Errorf("invalid locations: %w", err)
+		}
+	} else {
+		items = []json.RawMessage{raw}
+	}
+
+	for _, item := range items {
+		var link LocationLink
+		if err := json.Unmarshal(item, &link); err != nil {
+			return nil, fmt.Errorf("invalid location: %w", err)
+		}
+		if link.TargetURI != "" {
+			locations = append(locations, Location{URI: link.TargetURI, Range: link.TargetSelectionRange})
+			continue
+		}
+
+		var location Location
+		if err := json.Unmarshal(item, &location); err != nil {
+			return nil, fmt.Errorf("invalid location: %w", err)
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
@@ -0,0 +1,258 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServerPath is the fake language server binary built by TestMain
+var fakeServerPath string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "lsp-fakeserver")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create temp dir: %v\n", err)
+		os.Exit(1)
+	}
+
+	fakeServerPath = filepath.Join(dir, "fakeserver")
+	build := exec.Command("go", "build", "-o", fakeServerPath, "./testdata/fakeserver")
+	if output, err := build.CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build fake server: %v\n%s", err, output)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// mainSource is a file of the fake server's toy language
+const mainSource = `class Greeter:
+    def greet(name):
+        return "hi " + name
+
+def main():
+    emoji = "🙂"; Greeter().greet(emoji)  # TODO polish
+`
+
+// otherSource calls main from a second file
+const otherSource = `def helper():
+    main()
+`
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// newWorkspace writes the test sources to a temporary directory
+func newWorkspace(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.fake"), mainSource)
+	writeFile(t, filepath.Join(dir, "other.fake"), otherSource)
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// testConfig configures the fake server for a workspace
+func testConfig(dir string) ServerConfig {
+	return ServerConfig{Name: "fake", Command: fakeServerPath, RootDir: dir, Extensions: []string{".fake"}, MaxRestarts: 1}
+}
+
+// columnOf returns the 1-based byte column of the first occurrence of s on a line of mainSource
+func columnOf(line int, s string) int {
+	text, _ := lineAt(mainSource, line-1)
+	return strings.Index(text, s) + 1
+}
+
+func TestClient(t *testing.T) {
+	ctx := testContext(t)
+	dir := newWorkspace(t)
+	main := filepath.Join(dir, "main.fake")
+	client := NewClient(testConfig(dir))
+	defer client.Close()
+
+	require.NoError(t, client.Start(ctx))
+	require.NotNil(t, client.ServerInfo().ServerInfo)
+	assert.Equal(t, "fakeserver", client.ServerInfo().ServerInfo.Name)
+	assert.True(t, client.Handles("x/y.FAKE"))
+	assert.False(t, client.Handles("x/y.go"))
+
+	// The greet call on line 6 follows a character outside the BMP
+	greetCall := Position{Line: 5, Character: utf16Offset(strings.Split(mainSource, "\n")[5], columnOf(6, "greet(emoji")-1)}
+
+	t.Run("Definition", func(t *testing.T) {
+		locations, err := client.Definition(ctx, main, greetCall)
+		require.NoError(t, err)
+		require.Len(t, locations, 1)
+		assert.Equal(t, fileURI(main), locations[0].URI)
+		assert.Equal(t, Position{Line: 1, Character: 8}, locations[0].Range.Start)
+	})
+
+	t.Run("References", func(t *testing.T) {
+		locations, err := client.References(ctx, main, greetCall, false)
+		require.NoError(t, err)
+		require.Len(t, locations, 1)
+		assert.Equal(t, greetCall, locations[0].Range.Start)
+
+		locations, err = client.References(ctx, main, greetCall, true)
+		require.NoError(t, err)
+		assert.Len(t, locations, 2)
+	})
+
+	t.Run("Hover", func(t *testing.T) {
+		hover, err := client.Hover(ctx, main, greetCall)
+		require.NoError(t, err)
+		require.NotNil(t, hover)
+		assert.Equal(t, "**greet**", hover.Text())
+
+		hover, err = client.Hover(ctx, main, Position{Line: 3, Character: 0})
+		require.NoError(t, err)
+		assert.Nil(t, hover)
+	})
+
+	t.Run("DocumentSymbols", func(t *testing.T) {
+		symbols, err := client.DocumentSymbols(ctx, main)
+		require.NoError(t, err)
+		require.Len(t, symbols, 2)
+		assert.Equal(t, "Greeter", symbols[0].Name)
+		assert.Equal(t, "class", symbols[0].Kind.String())
+		require.Len(t, symbols[0].Children, 1)
+		assert.Equal(t, "greet", symbols[0].Children[0].Name)
+		assert.Equal(t, "method", symbols[0].Children[0].Kind.String())
+		assert.Equal(t, "main", symbols[1].Name)
+	})
+
+	t.Run("WorkspaceSymbols", func(t *testing.T) {
+		// The server only knows the documents it has opened
+		_, err := client.DocumentSymbols(ctx, filepath.Join(dir, "other.fake"))
+		require.NoError(t, err)
+
+		symbols, err := client.WorkspaceSymbols(ctx, "e")
+		require.NoError(t, err)
+		var names []string
+		for _, symbol := range symbols {
+			names = append(names, symbol.Name)
+		}
+		assert.Equal(t, []string{"Greeter", "greet", "helper"}, names)
+		assert.Equal(t, "Greeter", symbols[1].ContainerName)
+	})
+
+	t.Run("Diagnostics", func(t *testing.T) {
+		diagnostics, err := client.Diagnostics(ctx, main, 5*time.Second)
+		require.NoError(t, err)
+		require.Len(t, diagnostics, 1)
+		assert.Equal(t, SeverityWarning, diagnostics[0].Severity)
+		assert.Equal(t, "unfinished work", diagnostics[0].Message)
+
+		// Changes on disk are sent to the server before the next request
+		writeFile(t, main, strings.Replace(mainSource, "TODO polish", "done", 1))
+		diagnostics, err = client.Diagnostics(ctx, main, 5*time.Second)
+		require.NoError(t, err)
+		assert.Empty(t, diagnostics)
+		assert.NotNil(t, diagnostics)
+	})
+
+	t.Run("ServerRequestsAnswered", func(t *testing.T) {
+		assert.Contains(t, client.Stderr(), "[info] fakeserver: initialized")
+		assert.Contains(t, client.Stderr(), "fakeserver: response \"config-1\": [null]")
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := client.Hover(ctx, filepath.Join(dir, "missing.fake"), Position{})
+		assert.ErrorContains(t, err, "cannot read")
+	})
+}
+
+func TestClientRestart(t *testing.T) {
+	ctx := testContext(t)
+	dir := newWorkspace(t)
+	crash := filepath.Join(dir, "crash.fake")
+	writeFile(t, crash, "CRASH\n")
+	client := NewClient(testConfig(dir))
+	defer client.Close()
+
+	_, err := client.DocumentSymbols(ctx, crash)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fakeserver: crashing")
+
+	// The next request restarts the server, which opens the document again
+	symbols, err := client.DocumentSymbols(ctx, filepath.Join(dir, "main.fake"))
+	require.NoError(t, err)
+	assert.Len(t, symbols, 2)
+
+	// The restart limit is enforced
+	_, err = client.DocumentSymbols(ctx, crash)
+	require.Error(t, err)
+	_, err = client.DocumentSymbols(ctx, filepath.Join(dir, "main.fake"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "restart limit")
+}
+
+func TestClientClose(t *testing.T) {
+	ctx := testContext(t)
+	client := NewClient(testConfig(newWorkspace(t)))
+
+	require.NoError(t, client.Start(ctx))
+	assert.True(t, client.Running())
+	require.NoError(t, client.Close())
+	assert.False(t, client.Running())
+
+	err := client.Start(ctx)
+	assert.ErrorContains(t, err, "client is closed")
+}
+
+func TestClientStartFailure(t *testing.T) {
+	client := NewClient(ServerConfig{Name: "missing", Command: filepath.Join(t.TempDir(), "does-not-exist")})
+	defer client.Close()
+
+	err := client.Start(testContext(t))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lsp server missing")
+}
+
+func TestParseLocations(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []Location
+	}{
+		{name: "Null", raw: `null`, want: []Location{}},
+		{name: "Single", raw: `{"uri":"file:///a","range":{"start":{"line":1,"character":2},"end":{"line":1,"character":3}}}`,
+			want: []Location{{URI: "file:///a", Range: Range{Start: Position{1, 2}, End: Position{1, 3}}}}},
+		{name: "Array", raw: `[{"uri":"file:///a","range":{"start":{"line":1,"character":2},"end":{"line":1,"character":3}}},{"uri":"file:///b","range":{"start":{"line":0,"character":0},"end":{"line":0,"character":1}}}]`,
+			want: []Location{
+				{URI: "file:///a", Range: Range{Start: Position{1, 2}, End: Position{1, 3}}},
+				{URI: "file:///b", Range: Range{End: Position{0, 1}}},
+			}},
+		{name: "Links", raw: `[{"targetUri":"file:///c","targetRange":{"start":{"line":4,"character":0},"end":{"line":9,"character":0}},"targetSelectionRange":{"start":{"line":4,"character":5},"end":{"line":4,"character":8}}}]`,
+			want: []Location{{URI: "file:///c", Range: Range{Start: Position{4, 5}, End: Position{4, 8}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLocations(json.RawMessage(tt.raw))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
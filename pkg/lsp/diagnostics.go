@@ -0,0 +1,131 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// DiagnosticsTool reports the errors and warnings a language server finds in a file
+type DiagnosticsTool struct {
+	core.BaseToolImpl
+	manager *Manager
+}
+
+// DiagnosticsInput represents parameters for the lsp_diagnostics tool
+type DiagnosticsInput struct {
+	Path string `json:"path"`
+}
+
+// FileDiagnostic is a problem in a file at 1-based positions
+type FileDiagnostic struct {
+	Severity  string `json:"severity"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"end_line"`
+	EndColumn int    `json:"end_column"`
+	Message   string `json:"message"`
+	Source    string `json:"source,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Text      string `json:"text,omitempty"`
+}
+
+// DiagnosticsResult lists the diagnostics of a file
+type DiagnosticsResult struct {
+	Server      string           `json:"server"`
+	Path        string           `json:"path"`
+	Errors      int              `json:"errors"`
+	Warnings    int              `json:"warnings"`
+	Diagnostics []FileDiagnostic `json:"diagnostics"`
+}
+
+// NewDiagnosticsTool creates a new lsp_diagnostics tool
+func NewDiagnosticsTool(manager *Manager) *DiagnosticsTool {
+	tool := &DiagnosticsTool{manager: manager}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"lsp_diagnostics",
+		"Report the errors, warnings and hints the language server finds in a file's current contents, "+
+			"such as type errors, unresolved names and lint findings.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "The source file to check",
+				},
+			},
+			"required": []string{"path"},
+		},
+	)
+	return tool
+}
+
+// Execute returns the diagnostics
+func (t *DiagnosticsTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params DiagnosticsInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for lsp_diagnostics tool: %w", err)
+	}
+	if params.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	client, err := t.manager.ClientFor(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics, err := client.Diagnostics(ctx, params.Path, t.manager.diagnosticsWait)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := pathURI(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := DiagnosticsResult{Server: client.Name(), Path: params.Path, Diagnostics: []FileDiagnostic{}}
+	reader := newSourceReader()
+	for _, diagnostic := range diagnostics {
+		start := reader.location(uri, diagnostic.Range.Start)
+		end := reader.location(uri, diagnostic.Range.End)
+		switch diagnostic.Severity {
+		case SeverityWarning:
+			result.Warnings++
+		case SeverityInformation, SeverityHint:
+		default:
+			result.Errors++
+		}
+		result.Diagnostics = append(result.Diagnostics, FileDiagnostic{
+			Severity:  diagnostic.Severity.String(),
+			Line:      start.Line,
+			Column:    start.Column,
+			EndLine:   end.Line,
+			EndColumn: end.Column,
+			Message:   diagnostic.Message,
+			Source:    diagnostic.Source,
+			Code:      diagnosticCode(diagnostic.Code),
+			Text:      start.Text,
+		})
+	}
+	return result, nil
+}
+
+// diagnosticCode renders a diagnostic code, which may be a string or a number
+func diagnosticCode(raw json.RawMessage) string {
+	var code string
+	if err := json.Unmarshal(raw, &code); err == nil {
+		return code
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return strings.TrimSpace(string(raw))
+}
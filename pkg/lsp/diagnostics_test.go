@@ -0,0 +1,54 @@
+package lsp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsTool(t *testing.T) {
+	dir := newWorkspace(t)
+	main := filepath.Join(dir, "main.fake")
+	tool := NewDiagnosticsTool(newTestManager(t, dir))
+
+	result, err := runTool(t, tool, DiagnosticsInput{Path: main})
+	require.NoError(t, err)
+	column := columnOf(6, "TODO")
+	assert.Equal(t, DiagnosticsResult{
+		Server:   "fake",
+		Path:     main,
+		Warnings: 1,
+		Diagnostics: []FileDiagnostic{{
+			Severity:  "warning",
+			Line:      6,
+			Column:    column,
+			EndLine:   6,
+			EndColumn: column + 4,
+			Message:   "unfinished work",
+			Source:    "fake",
+			Code:      "todo",
+			Text:      `emoji = "🙂"; Greeter().greet(emoji)  # TODO polish`,
+		}},
+	}, result)
+
+	// The file is resent when it changes on disk
+	writeFile(t, main, strings.ReplaceAll(mainSource, "TODO", "done"))
+	result, err = runTool(t, tool, DiagnosticsInput{Path: main})
+	require.NoError(t, err)
+	assert.Empty(t, result.(DiagnosticsResult).Diagnostics)
+	assert.Zero(t, result.(DiagnosticsResult).Warnings)
+
+	_, err = runTool(t, tool, DiagnosticsInput{})
+	assert.ErrorContains(t, err, "path is required")
+}
+
+func TestDiagnosticCode(t *testing.T) {
+	assert.Equal(t, "E100", diagnosticCode(json.RawMessage(`"E100"`)))
+	assert.Equal(t, "2322", diagnosticCode(json.RawMessage(`2322`)))
+	assert.Equal(t, "", diagnosticCode(json.RawMessage(`null`)))
+	assert.Equal(t, "", diagnosticCode(nil))
+}
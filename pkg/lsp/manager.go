@@ -0,0 +1,165 @@
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// CategoryID is the tool category of the language server tools
+const CategoryID = "lsp"
+
+// Manager owns the clients for all configured language servers. Servers are
+// started when a tool first needs them and stopped when the manager is closed.
+type Manager struct {
+	mu              sync.RWMutex
+	clients         []*Client
+	diagnosticsWait time.Duration
+}
+
+// NewManager creates a manager without any servers
+func NewManager() *Manager {
+	return &Manager{diagnosticsWait: DefaultDiagnosticsWait}
+}
+
+// Mount adds the configured servers and registers the language server tools
+// with the tool manager. The servers are not started until they are used.
+// Invalid server configurations are skipped and reported in the error.
+func (m *Manager) Mount(toolManager *tools.ToolManager, configs []ServerConfig) error {
+	err := m.Add(configs...)
+	if len(m.names()) == 0 {
+		return err
+	}
+
+	category := &tools.Category{
+		ID:          CategoryID,
+		Name:        "Language Servers",
+		Description: "Code navigation and diagnostics from language servers: " + strings.Join(m.names(), ", "),
+		Enabled:     true,
+		Permission:  core.PermissionReadOnly,
+		Tools:       []core.Tool{},
+	}
+	if err := toolManager.RegisterCategory(category); err != nil {
+		return err
+	}
+	for _, tool := range m.Tools() {
+		if err := toolManager.RegisterTool(CategoryID, tool); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// Add creates clients for the given servers, skipping invalid configurations
+func (m *Manager) Add(configs ...ServerConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for _, config := range configs {
+		if err := m.validate(config); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.clients = append(m.clients, NewClient(config))
+	}
+	return errors.Join(errs...)
+}
+
+// validate checks a server configuration against the servers already added
+func (m *Manager) validate(config ServerConfig) error {
+	if config.Name == "" {
+		return fmt.Errorf("lsp server with command %q has no name", config.Command)
+	}
+	if len(config.Extensions) == 0 {
+		return fmt.Errorf("lsp server %s has no file extensions", config.Name)
+	}
+	for _, client := range m.clients {
+		if client.Name() == config.Name {
+			return fmt.Errorf("lsp server %s is configured more than once", config.Name)
+		}
+	}
+	return nil
+}
+
+// Tools returns the tools backed by the manager's servers
+func (m *Manager) Tools() []core.Tool {
+	return []core.Tool{
+		NewDefinitionTool(m),
+		NewReferencesTool(m),
+		NewHoverTool(m),
+		NewDocumentSymbolsTool(m),
+		NewWorkspaceSymbolsTool(m),
+		NewDiagnosticsTool(m),
+	}
+}
+
+// Client returns the client for a server by name
+func (m *Manager) Client(name string) (*Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, client := range m.clients {
+		if client.Name() == name {
+			return client, true
+		}
+	}
+	return nil, false
+}
+
+// Clients returns the clients for all servers, sorted by name
+func (m *Manager) Clients() []*Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clients := append([]*Client(nil), m.clients...)
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].Name() < clients[j].Name()
+	})
+	return clients
+}
+
+// ClientFor returns the first configured server that handles a file
+func (m *Manager) ClientFor(path string) (*Client, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, client := range m.clients {
+		if client.Handles(path) {
+			return client, nil
+		}
+	}
+	return nil, fmt.Errorf("no language server is configured for %s files", LanguageID(path))
+}
+
+// names returns the names of the configured servers
+func (m *Manager) names() []string {
+	clients := m.Clients()
+	names := make([]string, len(clients))
+	for i, client := range clients {
+		names[i] = client.Name()
+	}
+	return names
+}
+
+// Close shuts down all servers
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for _, client := range clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
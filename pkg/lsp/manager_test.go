@@ -0,0 +1,124 @@
+package lsp
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestManager creates a manager for the fake server in a workspace
+func newTestManager(t *testing.T, dir string) *Manager {
+	t.Helper()
+
+	manager := NewManager()
+	manager.diagnosticsWait = 5 * time.Second
+	require.NoError(t, manager.Add(testConfig(dir)))
+	t.Cleanup(func() { manager.Close() })
+	return manager
+}
+
+// runTool executes a tool with JSON input
+func runTool(t *testing.T, tool core.Tool, input interface{}) (interface{}, error) {
+	t.Helper()
+
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	return tool.Execute(testContext(t), data)
+}
+
+func TestManagerMount(t *testing.T) {
+	ctx := testContext(t)
+	dir := newWorkspace(t)
+	toolManager, err := tools.Initialize()
+	require.NoError(t, err)
+
+	manager := NewManager()
+	defer manager.Close()
+	require.NoError(t, manager.Mount(toolManager, []ServerConfig{testConfig(dir)}))
+
+	// Servers start when a tool first needs them
+	client, ok := manager.Client("fake")
+	require.True(t, ok)
+	assert.False(t, client.Running())
+
+	var names []string
+	for _, tool := range toolManager.GetTools() {
+		names = append(names, tool.Name)
+	}
+	for _, name := range []string{"lsp_definition", "lsp_references", "lsp_hover", "lsp_document_symbols", "lsp_workspace_symbols", "lsp_diagnostics"} {
+		assert.Contains(t, names, name)
+	}
+
+	t.Run("CallThroughToolManager", func(t *testing.T) {
+		result, err := toolManager.HandleToolUse(ctx, &core.ToolUse{
+			Name:  "lsp_hover",
+			Input: json.RawMessage(`{"path": "` + filepath.Join(dir, "main.fake") + `", "line": 1, "name": "Greeter"}`),
+		})
+		require.NoError(t, err)
+		assert.Contains(t, string(result.Result), "**Greeter**")
+		assert.True(t, client.Running())
+	})
+
+	t.Run("SchemaValidated", func(t *testing.T) {
+		_, err := toolManager.HandleToolUse(ctx, &core.ToolUse{
+			Name:  "lsp_hover",
+			Input: json.RawMessage(`{"line": 1}`),
+		})
+		var validationErr *tools.InputValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("CloseStopsServers", func(t *testing.T) {
+		require.NoError(t, manager.Close())
+		assert.False(t, client.Running())
+	})
+}
+
+func TestManagerAdd(t *testing.T) {
+	manager := NewManager()
+	defer manager.Close()
+
+	assert.ErrorContains(t, manager.Add(ServerConfig{Command: "x", Extensions: []string{".x"}}), "has no name")
+	assert.ErrorContains(t, manager.Add(ServerConfig{Name: "x", Command: "x"}), "has no file extensions")
+
+	require.NoError(t, manager.Add(
+		ServerConfig{Name: "b", Command: "b", Extensions: []string{".b"}},
+		ServerConfig{Name: "a", Command: "a", Extensions: []string{".a", ".shared"}},
+		ServerConfig{Name: "c", Command: "c", Extensions: []string{".shared"}},
+	))
+	assert.ErrorContains(t, manager.Add(ServerConfig{Name: "a", Command: "a", Extensions: []string{".a"}}), "more than once")
+	assert.Equal(t, []string{"a", "b", "c"}, manager.names())
+
+	client, err := manager.ClientFor("dir/file.shared")
+	require.NoError(t, err)
+	assert.Equal(t, "a", client.Name(), "the first configured server wins")
+
+	_, err = manager.ClientFor("main.go")
+	assert.ErrorContains(t, err, "no language server is configured for go files")
+}
+
+func TestManagerMountSkipsInvalidServers(t *testing.T) {
+	toolManager, err := tools.Initialize()
+	require.NoError(t, err)
+
+	manager := NewManager()
+	defer manager.Close()
+	err = manager.Mount(toolManager, []ServerConfig{
+		{Name: "broken", Command: "x"},
+		testConfig(t.TempDir()),
+	})
+	assert.ErrorContains(t, err, "lsp server broken has no file extensions")
+	assert.Equal(t, []string{"fake"}, manager.names())
+
+	var names []string
+	for _, tool := range toolManager.GetTools() {
+		names = append(names, tool.Name)
+	}
+	assert.Contains(t, names, "lsp_definition")
+}
@@ -0,0 +1,204 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// Limits on the number of references returned
+const (
+	defaultReferenceLimit = 100
+	maxReferenceLimit     = 1000
+)
+
+// DefinitionTool finds where a symbol is defined
+type DefinitionTool struct {
+	core.BaseToolImpl
+	manager *Manager
+}
+
+// DefinitionResult lists the definitions of a symbol
+type DefinitionResult struct {
+	Server      string           `json:"server"`
+	Definitions []SourceLocation `json:"definitions"`
+}
+
+// NewDefinitionTool creates a new lsp_definition tool
+func NewDefinitionTool(manager *Manager) *DefinitionTool {
+	tool := &DefinitionTool{manager: manager}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"lsp_definition",
+		"Find where the symbol at a position is defined, using the language server for the file's language. "+
+			"Identify the symbol by path, line and either column or name.",
+		CategoryID,
+		map[string]interface{}{
+			"type":       "object",
+			"properties": positionProperties(nil),
+			"required":   []string{"path", "line"},
+		},
+	)
+	return tool
+}
+
+// Execute finds the definitions
+func (t *DefinitionTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params PositionInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for lsp_definition tool: %w", err)
+	}
+	client, pos, err := params.resolve(t.manager)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := client.Definition(ctx, params.Path, pos)
+	if err != nil {
+		return nil, err
+	}
+	return DefinitionResult{Server: client.Name(), Definitions: newSourceReader().locations(locations)}, nil
+}
+
+// ReferencesTool finds the references to a symbol
+type ReferencesTool struct {
+	core.BaseToolImpl
+	manager *Manager
+}
+
+// ReferencesInput represents parameters for the lsp_references tool
+type ReferencesInput struct {
+	PositionInput
+	IncludeDeclaration bool `json:"include_declaration,omitempty"`
+	Limit              int  `json:"limit,omitempty"`
+}
+
+// ReferencesResult lists the references to a symbol
+type ReferencesResult struct {
+	Server     string           `json:"server"`
+	References []SourceLocation `json:"references"`
+	Total      int              `json:"total"`
+	Truncated  bool             `json:"truncated,omitempty"`
+}
+
+// NewReferencesTool creates a new lsp_references tool
+func NewReferencesTool(manager *Manager) *ReferencesTool {
+	tool := &ReferencesTool{manager: manager}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"lsp_references",
+		"Find all references to the symbol at a position across the workspace, using the language server "+
+			"for the file's language. Identify the symbol by path, line and either column or name.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": positionProperties(map[string]interface{}{
+				"include_declaration": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also list the declaration itself",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of references to return (default %d, max %d)", defaultReferenceLimit, maxReferenceLimit),
+				},
+			}),
+			"required": []string{"path", "line"},
+		},
+	)
+	return tool
+}
+
+// Execute finds the references
+func (t *ReferencesTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params ReferencesInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for lsp_references tool: %w", err)
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultReferenceLimit
+	} else if limit > maxReferenceLimit {
+		limit = maxReferenceLimit
+	}
+	client, pos, err := params.resolve(t.manager)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := client.References(ctx, params.Path, pos, params.IncludeDeclaration)
+	if err != nil {
+		return nil, err
+	}
+	result := ReferencesResult{Server: client.Name(), Total: len(locations)}
+	if len(locations) > limit {
+		locations = locations[:limit]
+		result.Truncated = true
+	}
+	result.References = newSourceReader().locations(locations)
+	return result, nil
+}
+
+// HoverTool describes a symbol as an editor would on hover
+type HoverTool struct {
+	core.BaseToolImpl
+	manager *Manager
+}
+
+// HoverResult is the hover information of a symbol
+type HoverResult struct {
+	Server   string `json:"server"`
+	Found    bool   `json:"found"`
+	Contents string `json:"contents,omitempty"`
+}
+
+// NewHoverTool creates a new lsp_hover tool
+func NewHoverTool(manager *Manager) *HoverTool {
+	tool := &HoverTool{manager: manager}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"lsp_hover",
+		"Show the type, signature and documentation of the symbol at a position, as an editor does on hover. "+
+			"Identify the symbol by path, line and either column or name.",
+		CategoryID,
+		map[string]interface{}{
+			"type":       "object",
+			"properties": positionProperties(nil),
+			"required":   []string{"path", "line"},
+		},
+	)
+	return tool
+}
+
+// Execute returns the hover information
+func (t *HoverTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params PositionInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for lsp_hover tool: %w", err)
+	}
+	client, pos, err := params.resolve(t.manager)
+	if err != nil {
+		return nil, err
+	}
+
+	hover, err := client.Hover(ctx, params.Path, pos)
+	if err != nil {
+		return nil, err
+	}
+	result := HoverResult{Server: client.Name()}
+	if hover != nil {
+		result.Contents = hover.Text()
+		result.Found = result.Contents != ""
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+package lsp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefinitionTool(t *testing.T) {
+	dir := newWorkspace(t)
+	main := filepath.Join(dir, "main.fake")
+	tool := NewDefinitionTool(newTestManager(t, dir))
+
+	result, err := runTool(t, tool, PositionInput{Path: main, Line: 6, Name: "greet"})
+	require.NoError(t, err)
+	definition := result.(DefinitionResult)
+	assert.Equal(t, "fake", definition.Server)
+	assert.Equal(t, []SourceLocation{{Path: main, Line: 2, Column: 9, Text: "def greet(name):"}}, definition.Definitions)
+
+	// A column counts bytes, whatever precedes it on the line
+	result, err = runTool(t, tool, PositionInput{Path: main, Line: 6, Column: columnOf(6, "Greeter")})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.(DefinitionResult).Definitions[0].Line)
+
+	result, err = runTool(t, tool, PositionInput{Path: main, Line: 6, Name: "emoji"})
+	require.NoError(t, err)
+	assert.Empty(t, result.(DefinitionResult).Definitions)
+	assert.NotNil(t, result.(DefinitionResult).Definitions)
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			input PositionInput
+			want  string
+		}{
+			{name: "NoPath", input: PositionInput{Line: 1, Column: 1}, want: "path is required"},
+			{name: "NoLine", input: PositionInput{Path: main, Column: 1}, want: "line must be at least 1"},
+			{name: "NoColumnOrName", input: PositionInput{Path: main, Line: 1}, want: "either column or name is required"},
+			{name: "LineOutOfRange", input: PositionInput{Path: main, Line: 99, Column: 1}, want: "has no line 99"},
+			{name: "ColumnOutOfRange", input: PositionInput{Path: main, Line: 1, Column: 99}, want: "has only 14 columns"},
+			{name: "NameNotOnLine", input: PositionInput{Path: main, Line: 1, Name: "main"}, want: `"main" does not appear on line 1`},
+			{name: "NoServer", input: PositionInput{Path: filepath.Join(dir, "x.go"), Line: 1, Column: 1}, want: "no language server"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := runTool(t, tool, tt.input)
+				assert.ErrorContains(t, err, tt.want)
+			})
+		}
+	})
+}
+
+func TestReferencesTool(t *testing.T) {
+	dir := newWorkspace(t)
+	main := filepath.Join(dir, "main.fake")
+	other := filepath.Join(dir, "other.fake")
+	manager := newTestManager(t, dir)
+	tool := NewReferencesTool(manager)
+
+	// Open the second file so the fake server searches it too
+	_, err := runTool(t, NewDocumentSymbolsTool(manager), DocumentSymbolsInput{Path: other})
+	require.NoError(t, err)
+
+	result, err := runTool(t, tool, ReferencesInput{PositionInput: PositionInput{Path: main, Line: 5, Name: "main"}})
+	require.NoError(t, err)
+	references := result.(ReferencesResult)
+	assert.Equal(t, 1, references.Total)
+	assert.Equal(t, []SourceLocation{{Path: other, Line: 2, Column: 5, Text: "main()"}}, references.References)
+
+	result, err = runTool(t, tool, ReferencesInput{
+		PositionInput:      PositionInput{Path: main, Line: 2, Name: "greet"},
+		IncludeDeclaration: true,
+		Limit:              1,
+	})
+	require.NoError(t, err)
+	references = result.(ReferencesResult)
+	assert.Equal(t, 2, references.Total)
+	assert.True(t, references.Truncated)
+	require.Len(t, references.References, 1)
+	assert.Equal(t, 2, references.References[0].Line)
+}
+
+func TestHoverTool(t *testing.T) {
+	dir := newWorkspace(t)
+	main := filepath.Join(dir, "main.fake")
+	tool := NewHoverTool(newTestManager(t, dir))
+
+	result, err := runTool(t, tool, PositionInput{Path: main, Line: 2, Name: "name"})
+	require.NoError(t, err)
+	assert.Equal(t, HoverResult{Server: "fake", Found: true, Contents: "**name**"}, result)
+
+	result, err = runTool(t, tool, PositionInput{Path: main, Line: 4, Column: 1})
+	require.NoError(t, err)
+	assert.Equal(t, HoverResult{Server: "fake"}, result)
+}
@@ -0,0 +1,131 @@
+package lsp
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// languageIDs maps file extensions to LSP language identifiers
+var languageIDs = map[string]string{
+	".c":     "c",
+	".h":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".cxx":   "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".css":   "css",
+	".dart":  "dart",
+	".ex":    "elixir",
+	".exs":   "elixir",
+	".go":    "go",
+	".hs":    "haskell",
+	".html":  "html",
+	".java":  "java",
+	".js":    "javascript",
+	".jsx":   "javascriptreact",
+	".json":  "json",
+	".kt":    "kotlin",
+	".lua":   "lua",
+	".md":    "markdown",
+	".php":   "php",
+	".py":    "python",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".scala": "scala",
+	".sh":    "shellscript",
+	".swift": "swift",
+	".ts":    "typescript",
+	".tsx":   "typescriptreact",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".zig":   "zig",
+}
+
+// LanguageID returns the LSP language identifier of a file, based on its
+// extension, or the extension itself for unknown languages
+func LanguageID(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if id, ok := languageIDs[ext]; ok {
+		return id
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// lineAt returns the zero-based line of text, without its line ending
+func lineAt(text string, line int) (string, bool) {
+	if line < 0 {
+		return "", false
+	}
+	for i := 0; i < line; i++ {
+		next := strings.IndexByte(text, '\n')
+		if next < 0 {
+			return "", false
+		}
+		text = text[next+1:]
+	}
+	if end := strings.IndexByte(text, '\n'); end >= 0 {
+		text = text[:end]
+	}
+	return strings.TrimSuffix(text, "\r"), true
+}
+
+// utf16Offset converts a byte offset in a line to UTF-16 code units
+func utf16Offset(line string, offset int) int {
+	if offset > len(line) {
+		offset = len(line)
+	}
+	units := 0
+	for _, r := range line[:offset] {
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return units
+}
+
+// byteOffset converts an offset in UTF-16 code units to a byte offset in a line
+func byteOffset(line string, units int) int {
+	offset := 0
+	for units > 0 && offset < len(line) {
+		r, size := utf8.DecodeRuneInString(line[offset:])
+		if r >= 0x10000 {
+			units -= 2
+		} else {
+			units--
+		}
+		offset += size
+	}
+	return offset
+}
+
+// identifierOffset returns the byte offset of the first whole-word
+// occurrence of name in a line, or of any occurrence if none is a whole word
+func identifierOffset(line, name string) int {
+	first := -1
+	for start := 0; ; {
+		i := strings.Index(line[start:], name)
+		if i < 0 {
+			return first
+		}
+		i += start
+		if first < 0 {
+			first = i
+		}
+		before, _ := utf8.DecodeLastRuneInString(line[:i])
+		after, _ := utf8.DecodeRuneInString(line[i+len(name):])
+		if !isWordRune(before) && !isWordRune(after) {
+			return i
+		}
+		start = i + 1
+	}
+}
+
+// isWordRune reports whether r can be part of an identifier
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+		r >= utf8.RuneSelf && r != utf8.RuneError
+}
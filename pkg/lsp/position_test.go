@@ -0,0 +1,60 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageID(t *testing.T) {
+	assert.Equal(t, "go", LanguageID("main.go"))
+	assert.Equal(t, "typescriptreact", LanguageID("src/App.TSX"))
+	assert.Equal(t, "fake", LanguageID("x.fake"))
+	assert.Equal(t, "", LanguageID("Makefile"))
+}
+
+func TestLineAt(t *testing.T) {
+	text := "one\r\ntwo\nthree"
+
+	line, ok := lineAt(text, 0)
+	assert.True(t, ok)
+	assert.Equal(t, "one", line)
+
+	line, ok = lineAt(text, 2)
+	assert.True(t, ok)
+	assert.Equal(t, "three", line)
+
+	_, ok = lineAt(text, 3)
+	assert.False(t, ok)
+	_, ok = lineAt(text, -1)
+	assert.False(t, ok)
+}
+
+func TestUTF16Offsets(t *testing.T) {
+	line := "a é 🙂 b"
+
+	tests := []struct {
+		bytes int
+		units int
+	}{
+		{0, 0},
+		{2, 2},  // é is two bytes and one code unit
+		{5, 4},  // 🙂 is four bytes and two code units
+		{10, 7}, // b
+		{11, 8}, // end of line
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.units, utf16Offset(line, tt.bytes), "utf16Offset(%d)", tt.bytes)
+		assert.Equal(t, tt.bytes, byteOffset(line, tt.units), "byteOffset(%d)", tt.units)
+	}
+
+	assert.Equal(t, 8, utf16Offset(line, 100))
+	assert.Equal(t, len(line), byteOffset(line, 100))
+}
+
+func TestIdentifierOffset(t *testing.T) {
+	assert.Equal(t, 11, identifierOffset("greeting = greet(x)", "greet"))
+	assert.Equal(t, 0, identifierOffset("greeting = 1", "greet"), "falls back to a partial match")
+	assert.Equal(t, -1, identifierOffset("x = 1", "greet"))
+	assert.Equal(t, 6, identifierOffset("éé: greet", "greet"))
+}
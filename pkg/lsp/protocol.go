@@ -0,0 +1,320 @@
+// Package lsp implements a Language Server Protocol client for servers that
+// communicate over stdio, and exposes their code navigation and diagnostics
+// as tools in the registry.
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// LSP method names
+const (
+	MethodInitialize  = "initialize"
+	MethodInitialized = "initialized"
+	MethodShutdown    = "shutdown"
+	MethodExit        = "exit"
+
+	MethodDidOpen   = "textDocument/didOpen"
+	MethodDidChange = "textDocument/didChange"
+	MethodDidClose  = "textDocument/didClose"
+
+	MethodDefinition         = "textDocument/definition"
+	MethodReferences         = "textDocument/references"
+	MethodHover              = "textDocument/hover"
+	MethodDocumentSymbol     = "textDocument/documentSymbol"
+	MethodWorkspaceSymbol    = "workspace/symbol"
+	MethodPublishDiagnostics = "textDocument/publishDiagnostics"
+
+	MethodConfiguration        = "workspace/configuration"
+	MethodWorkspaceFolders     = "workspace/workspaceFolders"
+	MethodRegisterCapability   = "client/registerCapability"
+	MethodUnregisterCapability = "client/unregisterCapability"
+	MethodWorkDoneProgress     = "window/workDoneProgress/create"
+	MethodShowMessageRequest   = "window/showMessageRequest"
+	MethodShowMessage          = "window/showMessage"
+	MethodLogMessage           = "window/logMessage"
+	MethodProgress             = "$/progress"
+)
+
+// Position is a zero-based line and UTF-16 code unit offset in a document
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span of a document; End is exclusive
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a document
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// LocationLink is a location together with the range of the link's origin
+type LocationLink struct {
+	OriginSelectionRange *Range `json:"originSelectionRange,omitempty"`
+	TargetURI            string `json:"targetUri"`
+	TargetRange          Range  `json:"targetRange"`
+	TargetSelectionRange Range  `json:"targetSelectionRange"`
+}
+
+// TextDocumentIdentifier names a document
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// VersionedTextDocumentIdentifier names a version of a document
+type VersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+// TextDocumentItem is a document opened by the client
+type TextDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+// TextDocumentPositionParams identifies a position in a document
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// DidOpenTextDocumentParams announces an opened document
+type DidOpenTextDocumentParams struct {
+	TextDocument TextDocumentItem `json:"textDocument"`
+}
+
+// TextDocumentContentChangeEvent replaces the whole text of a document
+type TextDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+// DidChangeTextDocumentParams announces new contents of an open document
+type DidChangeTextDocumentParams struct {
+	TextDocument   VersionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+// DidCloseTextDocumentParams announces a closed document
+type DidCloseTextDocumentParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// ReferenceParams asks for the references to the symbol at a position
+type ReferenceParams struct {
+	TextDocumentPositionParams
+	Context ReferenceContext `json:"context"`
+}
+
+// ReferenceContext controls which references are returned
+type ReferenceContext struct {
+	IncludeDeclaration bool `json:"includeDeclaration"`
+}
+
+// DocumentSymbolParams asks for the symbols of a document
+type DocumentSymbolParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// WorkspaceSymbolParams asks for the workspace symbols matching a query
+type WorkspaceSymbolParams struct {
+	Query string `json:"query"`
+}
+
+// Hover is the information shown for a position
+type Hover struct {
+	Contents json.RawMessage `json:"contents"`
+	Range    *Range          `json:"range,omitempty"`
+}
+
+// Text returns the hover contents as text, whichever form the server used
+func (h *Hover) Text() string {
+	return hoverText(h.Contents)
+}
+
+// hoverText flattens MarkupContent, MarkedString and arrays of MarkedString
+func hoverText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(raw, &parts); err == nil {
+		texts := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if t := hoverText(part); t != "" {
+				texts = append(texts, t)
+			}
+		}
+		return strings.Join(texts, "\n\n")
+	}
+
+	var content struct {
+		Kind     string `json:"kind"`
+		Language string `json:"language"`
+		Value    string `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return ""
+	}
+	if content.Language != "" {
+		return "```" + content.Language + "\n" + content.Value + "\n```"
+	}
+	return content.Value
+}
+
+// SymbolKind is the kind of a symbol
+type SymbolKind int
+
+// symbolKinds names the symbol kinds, indexed by SymbolKind
+var symbolKinds = []string{
+	"", "file", "module", "namespace", "package", "class", "method", "property",
+	"field", "constructor", "enum", "interface", "function", "variable", "constant",
+	"string", "number", "boolean", "array", "object", "key", "null", "enum member",
+	"struct", "event", "operator", "type parameter",
+}
+
+// String returns the name of the kind
+func (k SymbolKind) String() string {
+	if k > 0 && int(k) < len(symbolKinds) {
+		return symbolKinds[k]
+	}
+	return fmt.Sprintf("kind %d", int(k))
+}
+
+// DocumentSymbol is a symbol of a document with its nested symbols
+type DocumentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           SymbolKind       `json:"kind"`
+	Range          Range            `json:"range"`
+	SelectionRange Range            `json:"selectionRange"`
+	Children       []DocumentSymbol `json:"children,omitempty"`
+}
+
+// SymbolInformation is a symbol with a location, as returned for workspace
+// symbols and by servers without hierarchical document symbols
+type SymbolInformation struct {
+	Name          string     `json:"name"`
+	Kind          SymbolKind `json:"kind"`
+	Location      Location   `json:"location"`
+	ContainerName string     `json:"containerName,omitempty"`
+}
+
+// DiagnosticSeverity is the severity of a diagnostic
+type DiagnosticSeverity int
+
+// Diagnostic severities
+const (
+	SeverityError       DiagnosticSeverity = 1
+	SeverityWarning     DiagnosticSeverity = 2
+	SeverityInformation DiagnosticSeverity = 3
+	SeverityHint        DiagnosticSeverity = 4
+)
+
+// String returns the name of the severity
+func (s DiagnosticSeverity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInformation:
+		return "information"
+	case SeverityHint:
+		return "hint"
+	default:
+		return "error"
+	}
+}
+
+// Diagnostic is a problem reported for a document
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity,omitempty"`
+	Code     json.RawMessage    `json:"code,omitempty"`
+	Source   string             `json:"source,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// PublishDiagnosticsParams carries the diagnostics of a document
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     *int         `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// ClientInfo identifies mcpterm to the servers it connects to
+type ClientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// WorkspaceFolder is a root folder of the workspace
+type WorkspaceFolder struct {
+	URI  string `json:"uri"`
+	Name string `json:"name"`
+}
+
+// InitializeParams starts a session
+type InitializeParams struct {
+	ProcessID             int                    `json:"processId"`
+	ClientInfo            ClientInfo             `json:"clientInfo"`
+	RootURI               string                 `json:"rootUri"`
+	WorkspaceFolders      []WorkspaceFolder      `json:"workspaceFolders"`
+	Capabilities          map[string]interface{} `json:"capabilities"`
+	InitializationOptions interface{}            `json:"initializationOptions,omitempty"`
+}
+
+// InitializeResult is the server's answer to initialize
+type InitializeResult struct {
+	Capabilities map[string]interface{} `json:"capabilities"`
+	ServerInfo   *ClientInfo            `json:"serverInfo,omitempty"`
+}
+
+// ConfigurationParams asks the client for configuration sections
+type ConfigurationParams struct {
+	Items []json.RawMessage `json:"items"`
+}
+
+// LogMessageParams is a message the server wants logged
+type LogMessageParams struct {
+	Type    int    `json:"type"`
+	Message string `json:"message"`
+}
+
+// fileURI returns the file URI of an absolute path
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows drive paths need a leading slash
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// uriPath returns the file path of a file URI, or the URI if it is not one
+func uriPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return uri
+	}
+	path := parsed.Path
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
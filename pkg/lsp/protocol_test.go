@@ -0,0 +1,44 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoverText(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{name: "MarkupContent", contents: `{"kind":"markdown","value":"**x**"}`, want: "**x**"},
+		{name: "String", contents: `"plain"`, want: "plain"},
+		{name: "MarkedString", contents: `{"language":"go","value":"func f()"}`, want: "```go\nfunc f()\n```"},
+		{name: "Array", contents: `[{"language":"go","value":"var x int"},"docs"]`, want: "```go\nvar x int\n```\n\ndocs"},
+		{name: "Invalid", contents: `42`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hover := Hover{Contents: json.RawMessage(tt.contents)}
+			assert.Equal(t, tt.want, hover.Text())
+		})
+	}
+}
+
+func TestFileURI(t *testing.T) {
+	uri := fileURI("/tmp/a dir/main.go")
+	assert.Equal(t, "file:///tmp/a%20dir/main.go", uri)
+	assert.Equal(t, "/tmp/a dir/main.go", uriPath(uri))
+	assert.Equal(t, "untitled:x", uriPath("untitled:x"))
+}
+
+func TestKindNames(t *testing.T) {
+	assert.Equal(t, "function", SymbolKind(12).String())
+	assert.Equal(t, "type parameter", SymbolKind(26).String())
+	assert.Equal(t, "kind 99", SymbolKind(99).String())
+	assert.Equal(t, "warning", SeverityWarning.String())
+	assert.Equal(t, "error", DiagnosticSeverity(0).String())
+}
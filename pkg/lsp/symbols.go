@@ -0,0 +1,254 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// Limits on the number of workspace symbols returned
+const (
+	defaultSymbolLimit = 100
+	maxSymbolLimit     = 1000
+)
+
+// DocumentSymbolsTool outlines the symbols of a file
+type DocumentSymbolsTool struct {
+	core.BaseToolImpl
+	manager *Manager
+}
+
+// DocumentSymbolsInput represents parameters for the lsp_document_symbols tool
+type DocumentSymbolsInput struct {
+	Path string `json:"path"`
+}
+
+// OutlineSymbol is a symbol of a file with its nested symbols
+type OutlineSymbol struct {
+	Name     string          `json:"name"`
+	Kind     string          `json:"kind"`
+	Detail   string          `json:"detail,omitempty"`
+	Line     int             `json:"line"`
+	Column   int             `json:"column"`
+	EndLine  int             `json:"end_line"`
+	Children []OutlineSymbol `json:"children,omitempty"`
+}
+
+// DocumentSymbolsResult is the outline of a file
+type DocumentSymbolsResult struct {
+	Server  string          `json:"server"`
+	Path    string          `json:"path"`
+	Symbols []OutlineSymbol `json:"symbols"`
+}
+
+// NewDocumentSymbolsTool creates a new lsp_document_symbols tool
+func NewDocumentSymbolsTool(manager *Manager) *DocumentSymbolsTool {
+	tool := &DocumentSymbolsTool{manager: manager}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"lsp_document_symbols",
+		"Outline the symbols declared in a file, such as classes, functions and their members, "+
+			"with their kinds and line ranges, using the language server for the file's language.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "The source file",
+				},
+			},
+			"required": []string{"path"},
+		},
+	)
+	return tool
+}
+
+// Execute outlines the file
+func (t *DocumentSymbolsTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params DocumentSymbolsInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for lsp_document_symbols tool: %w", err)
+	}
+	if params.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	client, err := t.manager.ClientFor(params.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols, err := client.DocumentSymbols(ctx, params.Path)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := pathURI(params.Path)
+	if err != nil {
+		return nil, err
+	}
+	reader := newSourceReader()
+	return DocumentSymbolsResult{
+		Server:  client.Name(),
+		Path:    params.Path,
+		Symbols: outline(reader, uri, symbols),
+	}, nil
+}
+
+// outline converts document symbols to 1-based positions
+func outline(reader *sourceReader, uri string, symbols []DocumentSymbol) []OutlineSymbol {
+	converted := make([]OutlineSymbol, len(symbols))
+	for i, symbol := range symbols {
+		location := reader.location(uri, symbol.SelectionRange.Start)
+		converted[i] = OutlineSymbol{
+			Name:    symbol.Name,
+			Kind:    symbol.Kind.String(),
+			Detail:  symbol.Detail,
+			Line:    location.Line,
+			Column:  location.Column,
+			EndLine: symbol.Range.End.Line + 1,
+		}
+		if len(symbol.Children) > 0 {
+			converted[i].Children = outline(reader, uri, symbol.Children)
+		}
+	}
+	return converted
+}
+
+// WorkspaceSymbolsTool searches the symbols of the workspace
+type WorkspaceSymbolsTool struct {
+	core.BaseToolImpl
+	manager *Manager
+}
+
+// WorkspaceSymbolsInput represents parameters for the lsp_workspace_symbols tool
+type WorkspaceSymbolsInput struct {
+	Query  string `json:"query"`
+	Server string `json:"server,omitempty"`
+	Path   string `json:"path,omitempty"` // Selects the server for this file's language
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// WorkspaceSymbol is a symbol found in the workspace
+type WorkspaceSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Container string `json:"container,omitempty"`
+	Server    string `json:"server"`
+	SourceLocation
+}
+
+// WorkspaceSymbolsResult lists the matching symbols
+type WorkspaceSymbolsResult struct {
+	Symbols   []WorkspaceSymbol `json:"symbols"`
+	Total     int               `json:"total"`
+	Truncated bool              `json:"truncated,omitempty"`
+	Errors    []string          `json:"errors,omitempty"` // Servers that failed to answer
+}
+
+// NewWorkspaceSymbolsTool creates a new lsp_workspace_symbols tool
+func NewWorkspaceSymbolsTool(manager *Manager) *WorkspaceSymbolsTool {
+	tool := &WorkspaceSymbolsTool{manager: manager}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"lsp_workspace_symbols",
+		"Search the whole workspace for symbols whose names match a query, using the configured language servers. "+
+			"Ask one server by name or by a file of its language, or all of them by default.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The name or part of a name to search for; servers may match fuzzily",
+				},
+				"server": map[string]interface{}{
+					"type":        "string",
+					"description": "Only ask the language server with this name",
+				},
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Only ask the language server for this file's language",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of symbols to return (default %d, max %d)", defaultSymbolLimit, maxSymbolLimit),
+				},
+			},
+			"required": []string{"query"},
+		},
+	)
+	return tool
+}
+
+// Execute searches the workspace
+func (t *WorkspaceSymbolsTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params WorkspaceSymbolsInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for lsp_workspace_symbols tool: %w", err)
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultSymbolLimit
+	} else if limit > maxSymbolLimit {
+		limit = maxSymbolLimit
+	}
+
+	var clients []*Client
+	switch {
+	case params.Server != "":
+		client, ok := t.manager.Client(params.Server)
+		if !ok {
+			return nil, fmt.Errorf("unknown language server %s", params.Server)
+		}
+		clients = []*Client{client}
+	case params.Path != "":
+		client, err := t.manager.ClientFor(params.Path)
+		if err != nil {
+			return nil, err
+		}
+		clients = []*Client{client}
+	default:
+		clients = t.manager.Clients()
+	}
+
+	result := WorkspaceSymbolsResult{Symbols: []WorkspaceSymbol{}}
+	reader := newSourceReader()
+	var errs []error
+	for _, client := range clients {
+		symbols, err := client.WorkspaceSymbols(ctx, params.Query)
+		if err != nil {
+			errs = append(errs, err)
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.Total += len(symbols)
+		for _, symbol := range symbols {
+			if len(result.Symbols) == limit {
+				result.Truncated = true
+				break
+			}
+			result.Symbols = append(result.Symbols, WorkspaceSymbol{
+				Name:           symbol.Name,
+				Kind:           symbol.Kind.String(),
+				Container:      symbol.ContainerName,
+				Server:         client.Name(),
+				SourceLocation: reader.location(symbol.Location.URI, symbol.Location.Range.Start),
+			})
+		}
+	}
+
+	// Only fail if no server could answer
+	if len(errs) == len(clients) && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
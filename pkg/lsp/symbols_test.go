@@ -0,0 +1,81 @@
+package lsp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentSymbolsTool(t *testing.T) {
+	dir := newWorkspace(t)
+	main := filepath.Join(dir, "main.fake")
+	tool := NewDocumentSymbolsTool(newTestManager(t, dir))
+
+	result, err := runTool(t, tool, DocumentSymbolsInput{Path: main})
+	require.NoError(t, err)
+	assert.Equal(t, DocumentSymbolsResult{
+		Server: "fake",
+		Path:   main,
+		Symbols: []OutlineSymbol{
+			{Name: "Greeter", Kind: "class", Line: 1, Column: 7, EndLine: 2, Children: []OutlineSymbol{
+				{Name: "greet", Kind: "method", Line: 2, Column: 9, EndLine: 2},
+			}},
+			{Name: "main", Kind: "function", Line: 5, Column: 5, EndLine: 5},
+		},
+	}, result)
+
+	_, err = runTool(t, tool, DocumentSymbolsInput{})
+	assert.ErrorContains(t, err, "path is required")
+}
+
+func TestWorkspaceSymbolsTool(t *testing.T) {
+	dir := newWorkspace(t)
+	manager := newTestManager(t, dir)
+	require.NoError(t, manager.Add(ServerConfig{Name: "broken", Command: filepath.Join(dir, "missing"), Extensions: []string{".broken"}}))
+	tool := NewWorkspaceSymbolsTool(manager)
+
+	// The fake server only searches open documents
+	for _, name := range []string{"main.fake", "other.fake"} {
+		_, err := runTool(t, NewDocumentSymbolsTool(manager), DocumentSymbolsInput{Path: filepath.Join(dir, name)})
+		require.NoError(t, err)
+	}
+
+	t.Run("AllServers", func(t *testing.T) {
+		result, err := runTool(t, tool, WorkspaceSymbolsInput{Query: "e"})
+		require.NoError(t, err)
+		symbols := result.(WorkspaceSymbolsResult)
+		assert.Equal(t, 3, symbols.Total)
+		require.Len(t, symbols.Errors, 1, "the broken server is reported")
+		assert.Contains(t, symbols.Errors[0], "lsp server broken")
+
+		require.Len(t, symbols.Symbols, 3)
+		assert.Equal(t, WorkspaceSymbol{
+			Name:           "greet",
+			Kind:           "method",
+			Container:      "Greeter",
+			Server:         "fake",
+			SourceLocation: SourceLocation{Path: filepath.Join(dir, "main.fake"), Line: 2, Column: 9, Text: "def greet(name):"},
+		}, symbols.Symbols[1])
+		assert.Equal(t, "helper", symbols.Symbols[2].Name)
+	})
+
+	t.Run("ByPathWithLimit", func(t *testing.T) {
+		result, err := runTool(t, tool, WorkspaceSymbolsInput{Query: "e", Path: "x.fake", Limit: 1})
+		require.NoError(t, err)
+		symbols := result.(WorkspaceSymbolsResult)
+		assert.Equal(t, 3, symbols.Total)
+		assert.True(t, symbols.Truncated)
+		assert.Len(t, symbols.Symbols, 1)
+		assert.Empty(t, symbols.Errors)
+	})
+
+	t.Run("ByServer", func(t *testing.T) {
+		_, err := runTool(t, tool, WorkspaceSymbolsInput{Query: "e", Server: "broken"})
+		assert.ErrorContains(t, err, "lsp server broken")
+
+		_, err = runTool(t, tool, WorkspaceSymbolsInput{Query: "e", Server: "unknown"})
+		assert.ErrorContains(t, err, "unknown language server unknown")
+	})
+}
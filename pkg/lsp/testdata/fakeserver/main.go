@@ -0,0 +1,397 @@
+// Command fakeserver is a minimal language server used by the lsp package
+// tests. It speaks JSON-RPC with Content-Length framing over stdio and
+// understands a toy language: "def name" declares a function, "class Name"
+// a class whose indented defs are its methods. Every word can be hovered,
+// defs can be found by definition, references and workspace symbol search,
+// and each line containing TODO gets a warning diagnostic. Opening a
+// document that contains CRASH makes the server exit.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/navicore/mcpterm-go/pkg/jsonrpc"
+)
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type rng struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string `json:"uri"`
+	Range rng    `json:"range"`
+}
+
+type positionParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Position position `json:"position"`
+	Context  struct {
+		IncludeDeclaration bool `json:"includeDeclaration"`
+	} `json:"context"`
+}
+
+// documents holds the text of the open documents by URI
+var documents = map[string]string{}
+
+var stream jsonrpc.Stream
+
+func main() {
+	fmt.Fprintln(os.Stderr, "fakeserver: started")
+	stream = jsonrpc.NewHeaderStream(os.Stdin, os.Stdout)
+
+	for {
+		data, err := stream.Read()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fakeserver: %v\n", err)
+			os.Exit(1)
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			fmt.Fprintf(os.Stderr, "fakeserver: bad message: %v\n", err)
+			continue
+		}
+		if msg.Method == "" {
+			// A response to one of our requests
+			fmt.Fprintf(os.Stderr, "fakeserver: response %s: %s\n", msg.ID, msg.Result)
+			continue
+		}
+
+		result := handle(msg.Method, msg.Params)
+		if len(msg.ID) > 0 {
+			send(map[string]interface{}{"jsonrpc": "2.0", "id": msg.ID, "result": result})
+		}
+	}
+}
+
+// send writes a message to the client
+func send(msg interface{}) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	if err := stream.Write(data); err != nil {
+		os.Exit(1)
+	}
+}
+
+// notify sends a notification to the client
+func notify(method string, params interface{}) {
+	send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// handle answers a request or processes a notification
+func handle(method string, raw json.RawMessage) interface{} {
+	switch method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":        1,
+				"definitionProvider":      true,
+				"referencesProvider":      true,
+				"hoverProvider":           true,
+				"documentSymbolProvider":  true,
+				"workspaceSymbolProvider": true,
+			},
+			"serverInfo": map[string]interface{}{"name": "fakeserver", "version": "1.0.0"},
+		}
+	case "initialized":
+		notify("window/logMessage", map[string]interface{}{"type": 3, "message": "fakeserver: initialized"})
+		send(map[string]interface{}{
+			"jsonrpc": "2.0", "id": "config-1", "method": "workspace/configuration",
+			"params": map[string]interface{}{"items": []interface{}{map[string]interface{}{"section": "fake"}}},
+		})
+	case "shutdown":
+		return nil
+	case "exit":
+		os.Exit(0)
+
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI     string `json:"uri"`
+				Version int    `json:"version"`
+				Text    string `json:"text"`
+			} `json:"textDocument"`
+		}
+		_ = json.Unmarshal(raw, &params)
+		if strings.Contains(params.TextDocument.Text, "CRASH") {
+			fmt.Fprintln(os.Stderr, "fakeserver: crashing")
+			os.Exit(2)
+		}
+		documents[params.TextDocument.URI] = params.TextDocument.Text
+		publish(params.TextDocument.URI, params.TextDocument.Version)
+	case "textDocument/didChange":
+		var params struct {
+			TextDocument struct {
+				URI     string `json:"uri"`
+				Version int    `json:"version"`
+			} `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		_ = json.Unmarshal(raw, &params)
+		for _, change := range params.ContentChanges {
+			documents[params.TextDocument.URI] = change.Text
+		}
+		publish(params.TextDocument.URI, params.TextDocument.Version)
+
+	case "textDocument/definition":
+		var params positionParams
+		_ = json.Unmarshal(raw, &params)
+		word := wordAt(params.TextDocument.URI, params.Position)
+		for _, decl := range declarations() {
+			if decl.name == word {
+				return decl.location
+			}
+		}
+		return nil
+	case "textDocument/references":
+		var params positionParams
+		_ = json.Unmarshal(raw, &params)
+		word := wordAt(params.TextDocument.URI, params.Position)
+		if word == "" {
+			return nil
+		}
+		return references(word, params.Context.IncludeDeclaration)
+	case "textDocument/hover":
+		var params positionParams
+		_ = json.Unmarshal(raw, &params)
+		word := wordAt(params.TextDocument.URI, params.Position)
+		if word == "" {
+			return nil
+		}
+		return map[string]interface{}{
+			"contents": map[string]interface{}{"kind": "markdown", "value": "**" + word + "**"},
+		}
+	case "textDocument/documentSymbol":
+		var params positionParams
+		_ = json.Unmarshal(raw, &params)
+		return documentSymbols(params.TextDocument.URI)
+	case "workspace/symbol":
+		var params struct {
+			Query string `json:"query"`
+		}
+		_ = json.Unmarshal(raw, &params)
+		symbols := []interface{}{}
+		for _, decl := range declarations() {
+			if strings.Contains(strings.ToLower(decl.name), strings.ToLower(params.Query)) {
+				symbols = append(symbols, map[string]interface{}{
+					"name": decl.name, "kind": decl.kind, "location": decl.location, "containerName": decl.container,
+				})
+			}
+		}
+		return symbols
+	}
+	return nil
+}
+
+// publish sends the TODO warnings of a document
+func publish(uri string, version int) {
+	diagnostics := []interface{}{}
+	for i, line := range strings.Split(documents[uri], "\n") {
+		if col := strings.Index(line, "TODO"); col >= 0 {
+			diagnostics = append(diagnostics, map[string]interface{}{
+				"range":    rng{Start: position{i, utf16Len(line[:col])}, End: position{i, utf16Len(line[:col]) + 4}},
+				"severity": 2,
+				"code":     "todo",
+				"source":   "fake",
+				"message":  "unfinished work",
+			})
+		}
+	}
+	notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri": uri, "version": version, "diagnostics": diagnostics,
+	})
+}
+
+type declaration struct {
+	name      string
+	kind      int
+	container string
+	location  location
+	end       int
+	children  []declaration
+}
+
+// parse returns the classes and functions of a document
+func parse(uri string) []declaration {
+	var decls []declaration
+	lines := strings.Split(documents[uri], "\n")
+	for i, line := range lines {
+		indented := strings.HasPrefix(line, " ")
+		fields := strings.Fields(line)
+		if len(fields) < 2 || (fields[0] != "def" && fields[0] != "class") {
+			continue
+		}
+		name := leadingWord(fields[1])
+		col := strings.Index(line, fields[1])
+		decl := declaration{
+			name:     name,
+			kind:     12,
+			location: location{URI: uri, Range: rng{Start: position{i, utf16Len(line[:col])}, End: position{i, utf16Len(line[:col]) + len(name)}}},
+			end:      i,
+		}
+		if fields[0] == "class" {
+			decl.kind = 5
+		}
+		if indented && len(decls) > 0 && decls[len(decls)-1].kind == 5 {
+			parent := &decls[len(decls)-1]
+			decl.kind = 6
+			decl.container = parent.name
+			parent.children = append(parent.children, decl)
+			parent.end = i
+			continue
+		}
+		decls = append(decls, decl)
+	}
+	return decls
+}
+
+// declarations returns all declarations of all open documents, in URI order
+func declarations() []declaration {
+	uris := make([]string, 0, len(documents))
+	for uri := range documents {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	var all []declaration
+	for _, uri := range uris {
+		for _, decl := range parse(uri) {
+			all = append(all, decl)
+			all = append(all, decl.children...)
+		}
+	}
+	return all
+}
+
+// documentSymbols returns the hierarchical symbols of a document
+func documentSymbols(uri string) []interface{} {
+	var convert func(decls []declaration) []interface{}
+	convert = func(decls []declaration) []interface{} {
+		symbols := []interface{}{}
+		for _, decl := range decls {
+			symbol := map[string]interface{}{
+				"name":           decl.name,
+				"kind":           decl.kind,
+				"range":          rng{Start: position{decl.location.Range.Start.Line, 0}, End: position{decl.end, 0}},
+				"selectionRange": decl.location.Range,
+			}
+			if len(decl.children) > 0 {
+				symbol["children"] = convert(decl.children)
+			}
+			symbols = append(symbols, symbol)
+		}
+		return symbols
+	}
+	return convert(parse(uri))
+}
+
+// references returns every whole-word occurrence of word in the open documents
+func references(word string, includeDeclaration bool) []location {
+	uris := make([]string, 0, len(documents))
+	for uri := range documents {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	locations := []location{}
+	for _, uri := range uris {
+		for i, line := range strings.Split(documents[uri], "\n") {
+			runes := []rune(line)
+			for start := 0; start < len(runes); {
+				end := start
+				for end < len(runes) && isWord(runes[end]) {
+					end++
+				}
+				if end == start {
+					start++
+					continue
+				}
+				fields := strings.Fields(line)
+				declares := len(fields) >= 2 && (fields[0] == "def" || fields[0] == "class") &&
+					leadingWord(fields[1]) == word
+				if string(runes[start:end]) == word && (includeDeclaration || !declares) {
+					col := utf16Len(string(runes[:start]))
+					locations = append(locations, location{URI: uri, Range: rng{Start: position{i, col}, End: position{i, col + len(word)}}})
+				}
+				start = end
+			}
+		}
+	}
+	return locations
+}
+
+// wordAt returns the word at a position, counting characters in UTF-16 code units
+func wordAt(uri string, pos position) string {
+	lines := strings.Split(documents[uri], "\n")
+	if pos.Line >= len(lines) {
+		return ""
+	}
+	runes := []rune(lines[pos.Line])
+	units, index := 0, 0
+	for index < len(runes) && units < pos.Character {
+		units += utf16Len(string(runes[index]))
+		index++
+	}
+	if index >= len(runes) || !isWord(runes[index]) {
+		return ""
+	}
+	start, end := index, index
+	for start > 0 && isWord(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isWord(runes[end]) {
+		end++
+	}
+	return string(runes[start:end])
+}
+
+// utf16Len returns the length of s in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// isWord reports whether r can be part of a name
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// leadingWord returns the name at the start of s
+func leadingWord(s string) string {
+	if end := strings.IndexFunc(s, func(r rune) bool { return !isWord(r) }); end >= 0 {
+		return s[:end]
+	}
+	return s
+}
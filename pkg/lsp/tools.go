@@ -0,0 +1,162 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineLength is the longest source line returned with a location
+const maxLineLength = 200
+
+// positionProperties are the schema properties that identify a position in a file
+func positionProperties(extra map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "The source file",
+		},
+		"line": map[string]interface{}{
+			"type":        "integer",
+			"description": "The 1-based line of the symbol",
+		},
+		"column": map[string]interface{}{
+			"type":        "integer",
+			"description": "The 1-based byte column of the symbol on the line",
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "The symbol's name as written on the line, instead of column",
+		},
+	}
+	for key, value := range extra {
+		properties[key] = value
+	}
+	return properties
+}
+
+// PositionInput identifies a symbol in a file by line and either column or name
+type PositionInput struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// resolve returns the server for the file and the LSP position of the symbol
+func (p PositionInput) resolve(manager *Manager) (*Client, Position, error) {
+	if p.Path == "" {
+		return nil, Position{}, fmt.Errorf("path is required")
+	}
+	if p.Line < 1 {
+		return nil, Position{}, fmt.Errorf("line must be at least 1")
+	}
+	if p.Column < 1 && p.Name == "" {
+		return nil, Position{}, fmt.Errorf("either column or name is required")
+	}
+
+	client, err := manager.ClientFor(p.Path)
+	if err != nil {
+		return nil, Position{}, err
+	}
+	content, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, Position{}, fmt.Errorf("cannot read %s: %w", p.Path, err)
+	}
+	line, ok := lineAt(string(content), p.Line-1)
+	if !ok {
+		return nil, Position{}, fmt.Errorf("%s has no line %d", p.Path, p.Line)
+	}
+
+	offset := p.Column - 1
+	if p.Name != "" {
+		if offset = identifierOffset(line, p.Name); offset < 0 {
+			return nil, Position{}, fmt.Errorf("%q does not appear on line %d of %s", p.Name, p.Line, p.Path)
+		}
+	} else if offset > len(line) {
+		return nil, Position{}, fmt.Errorf("line %d of %s has only %d columns", p.Line, p.Path, len(line))
+	}
+	return client, Position{Line: p.Line - 1, Character: utf16Offset(line, offset)}, nil
+}
+
+// SourceLocation is a 1-based position in a file with the text of its line
+type SourceLocation struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"` // Byte column
+	Text   string `json:"text,omitempty"`
+}
+
+// sourceReader converts LSP locations to source locations, reading each file once
+type sourceReader struct {
+	files map[string]string
+}
+
+// newSourceReader creates a reader with an empty file cache
+func newSourceReader() *sourceReader {
+	return &sourceReader{files: make(map[string]string)}
+}
+
+// location converts a URI and an LSP position
+func (r *sourceReader) location(uri string, pos Position) SourceLocation {
+	path := uriPath(uri)
+	text, ok := r.files[path]
+	if !ok {
+		if content, err := os.ReadFile(path); err == nil {
+			text = string(content)
+		}
+		r.files[path] = text
+	}
+
+	location := SourceLocation{Path: displayPath(path), Line: pos.Line + 1, Column: pos.Character + 1}
+	if line, ok := lineAt(text, pos.Line); ok && text != "" {
+		location.Column = byteOffset(line, pos.Character) + 1
+		location.Text = clipLine(strings.TrimSpace(line))
+	}
+	return location
+}
+
+// locations converts a list of LSP locations
+func (r *sourceReader) locations(locations []Location) []SourceLocation {
+	converted := make([]SourceLocation, len(locations))
+	for i, location := range locations {
+		converted[i] = r.location(location.URI, location.Range.Start)
+	}
+	return converted
+}
+
+// clipLine shortens a long source line
+func clipLine(line string) string {
+	if len(line) <= maxLineLength {
+		return line
+	}
+	cut := maxLineLength
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "..."
+}
+
+// displayPath returns path relative to the working directory if it is inside it
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// pathURI returns the file URI of a path
+func pathURI(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %w", path, err)
+	}
+	return fileURI(abs), nil
+}
@@ -0,0 +1,387 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/jsonrpc"
+)
+
+// DefaultMaxRestarts is the number of times a crashed server is restarted
+const DefaultMaxRestarts = 3
+
+// stderrLimit is the amount of server stderr output kept for diagnostics
+const stderrLimit = 64 * 1024
+
+// shutdownGrace is how long a server may take to exit after its stdin is closed
+const shutdownGrace = 2 * time.Second
+
+// ClientInfo identifies mcpterm to the servers it connects to
+var ClientInfo = Implementation{Name: "mcpterm", Version: "0.1.0"}
+
+// ServerConfig describes how to launch an MCP server
+type ServerConfig struct {
+	Name        string            // Unique name, used for the tool category and tool name prefix
+	Command     string            // Executable to run
+	Args        []string          // Command line arguments
+	Env         map[string]string // Extra environment variables
+	Dir         string            // Working directory (default is the current directory)
+	Permission  string            // Permission level of the server's tool category
+	MaxRestarts int               // Restarts allowed after the server exits unexpectedly
+}
+
+// Client is a connection to a single MCP server running as a child process.
+// The server is started on first use and restarted if it exits, up to the
+// configured number of restarts.
+type Client struct {
+	config ServerConfig
+	stderr *jsonrpc.RingBuffer
+
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	conn     *jsonrpc.Conn
+	exited   chan struct{}
+	info     InitializeResult
+	started  bool
+	restarts int
+	closed   bool
+}
+
+// NewClient creates a client for the given server. The server is not started
+// until Start is called or a request is made.
+func NewClient(config ServerConfig) *Client {
+	if config.MaxRestarts == 0 {
+		config.MaxRestarts = DefaultMaxRestarts
+	}
+
+	return &Client{
+		config: config,
+		stderr: jsonrpc.NewRingBuffer(stderrLimit),
+	}
+}
+
+// Name returns the configured server name
+func (c *Client) Name() string {
+	return c.config.Name
+}
+
+// Config returns the server configuration
+func (c *Client) Config() ServerConfig {
+	return c.config
+}
+
+// Start launches the server and performs the initialize handshake, unless it is already running
+func (c *Client) Start(ctx context.Context) error {
+	_, err := c.connection(ctx)
+	return err
+}
+
+// ServerInfo returns the server's answer to the initialize request
+func (c *Client) ServerInfo() InitializeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.info
+}
+
+// Stderr returns the most recent stderr output of the server
+func (c *Client) Stderr() string {
+	return c.stderr.String()
+}
+
+// ListTools returns all tools offered by the server
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	cursor := ""
+	for {
+		var page ListToolsResult
+		if err := c.call(ctx, MethodToolsList, ListToolsParams{Cursor: cursor}, &page); err != nil {
+			return nil, err
+		}
+
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool invokes a tool on the server with JSON encoded arguments
+func (c *Client) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*CallToolResult, error) {
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+
+	var result CallToolResult
+	if err := c.call(ctx, MethodToolsCall, CallToolParams{Name: name, Arguments: arguments}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// HasResources reports whether the server offers resources
+func (c *Client) HasResources() bool {
+	return c.ServerInfo().Capabilities.Resources != nil
+}
+
+// HasPrompts reports whether the server offers prompt templates
+func (c *Client) HasPrompts() bool {
+	return c.ServerInfo().Capabilities.Prompts != nil
+}
+
+// ListResources returns all resources offered by the server
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	cursor := ""
+	for {
+		var page ListResourcesResult
+		if err := c.call(ctx, MethodResourcesList, ListResourcesParams{Cursor: cursor}, &page); err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, page.Resources...)
+		if page.NextCursor == "" {
+			return resources, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// ReadResource returns the contents of a resource
+func (c *Client) ReadResource(ctx context.Context, uri string) (*ReadResourceResult, error) {
+	var result ReadResourceResult
+	if err := c.call(ctx, MethodResourcesRead, ReadResourceParams{URI: uri}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPrompts returns all prompt templates offered by the server
+func (c *Client) ListPrompts(ctx context.Context) ([]Prompt, error) {
+	var prompts []Prompt
+	cursor := ""
+	for {
+		var page ListPromptsResult
+		if err := c.call(ctx, MethodPromptsList, ListPromptsParams{Cursor: cursor}, &page); err != nil {
+			return nil, err
+		}
+
+		prompts = append(prompts, page.Prompts...)
+		if page.NextCursor == "" {
+			return prompts, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// GetPrompt renders a prompt template with the given arguments
+func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*GetPromptResult, error) {
+	var result GetPromptResult
+	if err := c.call(ctx, MethodPromptsGet, GetPromptParams{Name: name, Arguments: arguments}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Close shuts down the server. The client cannot be used afterwards.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return c.stopLocked()
+}
+
+// call sends a request, starting or restarting the server if needed
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	conn, err := c.connection(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.Call(ctx, method, params, result); err != nil {
+		if errors.Is(err, jsonrpc.ErrClosed) {
+			// Let the process finish so its stderr output is complete
+			c.waitExit(shutdownGrace)
+			return c.exitError(err)
+		}
+		return fmt.Errorf("mcp server %s: %s failed: %w", c.config.Name, method, err)
+	}
+	return nil
+}
+
+// connection returns the connection to a running server, starting it if necessary
+func (c *Client) connection(ctx context.Context) (*jsonrpc.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, fmt.Errorf("mcp server %s: client is closed", c.config.Name)
+	}
+
+	if c.conn != nil && !c.hasExited() && c.conn.Err() == nil {
+		return c.conn, nil
+	}
+
+	if c.started {
+		// The server exited since it was last used
+		if c.restarts >= c.config.MaxRestarts {
+			return nil, c.exitError(fmt.Errorf("server exited and the restart limit of %d was reached", c.config.MaxRestarts))
+		}
+		c.restarts++
+		_ = c.stopLocked()
+	}
+
+	if err := c.startLocked(ctx); err != nil {
+		return nil, err
+	}
+	return c.conn, nil
+}
+
+// startLocked launches the server process and initializes the session
+func (c *Client) startLocked(ctx context.Context) error {
+	if c.config.Command == "" {
+		return fmt.Errorf("mcp server %s: no command configured", c.config.Name)
+	}
+
+	// Use dedicated pipes so waiting for the process never races with reads
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("mcp server %s: %w", c.config.Name, err)
+	}
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		stdinReader.Close()
+		stdinWriter.Close()
+		return fmt.Errorf("mcp server %s: %w", c.config.Name, err)
+	}
+
+	cmd := exec.Command(c.config.Command, c.config.Args...)
+	cmd.Dir = c.config.Dir
+	cmd.Env = os.Environ()
+	for key, value := range c.config.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stdin = stdinReader
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = c.stderr
+
+	err = cmd.Start()
+
+	// The child has its own copies of these ends
+	stdinReader.Close()
+	stdoutWriter.Close()
+
+	if err != nil {
+		stdinWriter.Close()
+		stdoutReader.Close()
+		return fmt.Errorf("mcp server %s: failed to start %s: %w", c.config.Name, c.config.Command, err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	c.cmd = cmd
+	c.exited = exited
+	c.conn = jsonrpc.NewConn(jsonrpc.NewLineStream(stdoutReader, stdinWriter), c.handle)
+	c.started = true
+
+	var info InitializeResult
+	params := InitializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    ClientCapabilities{},
+		ClientInfo:      ClientInfo,
+	}
+	if err := c.conn.Call(ctx, MethodInitialize, params, &info); err != nil {
+		_ = c.stopLocked()
+		return c.exitError(fmt.Errorf("initialize failed: %w", err))
+	}
+
+	if err := c.conn.Notify(MethodInitialized, nil); err != nil {
+		_ = c.stopLocked()
+		return c.exitError(fmt.Errorf("initialized notification failed: %w", err))
+	}
+
+	c.info = info
+	return nil
+}
+
+// stopLocked closes the connection and waits for the process to exit,
+// killing it if it does not exit in time
+func (c *Client) stopLocked() error {
+	if c.conn == nil {
+		return nil
+	}
+
+	// Closing stdin asks the server to exit
+	err := c.conn.Close()
+	select {
+	case <-c.exited:
+	case <-time.After(shutdownGrace):
+		_ = c.cmd.Process.Kill()
+		<-c.exited
+	}
+
+	c.conn = nil
+	return err
+}
+
+// hasExited reports whether the server process has exited
+func (c *Client) hasExited() bool {
+	select {
+	case <-c.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitExit waits up to timeout for the current server process to exit
+func (c *Client) waitExit(timeout time.Duration) {
+	c.mu.Lock()
+	exited := c.exited
+	c.mu.Unlock()
+
+	if exited == nil {
+		return
+	}
+	select {
+	case <-exited:
+	case <-time.After(timeout):
+	}
+}
+
+// exitError wraps err with the server's recent stderr output
+func (c *Client) exitError(err error) error {
+	if tail := c.stderr.Tail(10); tail != "" {
+		return fmt.Errorf("mcp server %s: %w\nserver stderr:\n%s", c.config.Name, err, tail)
+	}
+	return fmt.Errorf("mcp server %s: %w", c.config.Name, err)
+}
+
+// handle serves requests and notifications sent by the server
+func (c *Client) handle(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case MethodPing:
+		return struct{}{}, nil
+	case MethodLogMessage:
+		// Keep server log messages with its stderr output
+		var msg LogMessageParams
+		if err := json.Unmarshal(params, &msg); err == nil {
+			fmt.Fprintf(c.stderr, "[%s] %s\n", msg.Level, string(msg.Data))
+		}
+		return nil, nil
+	default:
+		return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "method not supported by client: %s", method)
+	}
+}
@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServerPath is the fake MCP server binary built by TestMain
+var fakeServerPath string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "mcp-fakeserver")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create temp dir: %v\n", err)
+		os.Exit(1)
+	}
+
+	fakeServerPath = filepath.Join(dir, "fakeserver")
+	build := exec.Command("go", "build", "-o", fakeServerPath, "./testdata/fakeserver")
+	if output, err := build.CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build fake server: %v\n%s", err, output)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestClient(t *testing.T) {
+	ctx := testContext(t)
+	client := NewClient(ServerConfig{Name: "fake", Command: fakeServerPath, MaxRestarts: 1})
+	defer client.Close()
+
+	require.NoError(t, client.Start(ctx))
+	assert.Equal(t, "fakeserver", client.ServerInfo().ServerInfo.Name)
+
+	t.Run("ListTools", func(t *testing.T) {
+		serverTools, err := client.ListTools(ctx)
+		require.NoError(t, err)
+
+		var names []string
+		for _, tool := range serverTools {
+			names = append(names, tool.Name)
+		}
+		assert.Equal(t, []string{"echo", "fail", "crash"}, names)
+	})
+
+	t.Run("CallTool", func(t *testing.T) {
+		result, err := client.CallTool(ctx, "echo", json.RawMessage(`{"text": "hello"}`))
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Equal(t, "hello", result.Text())
+	})
+
+	t.Run("StderrCaptured", func(t *testing.T) {
+		assert.Contains(t, client.Stderr(), "fakeserver: started")
+	})
+
+	t.Run("RestartAfterCrash", func(t *testing.T) {
+		_, err := client.CallTool(ctx, "crash", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "fakeserver: crashing")
+
+		// The next call restarts the server
+		result, err := client.CallTool(ctx, "echo", json.RawMessage(`{"text": "back"}`))
+		require.NoError(t, err)
+		assert.Equal(t, "back", result.Text())
+
+		// The restart limit is enforced
+		_, err = client.CallTool(ctx, "crash", nil)
+		require.Error(t, err)
+		_, err = client.CallTool(ctx, "echo", json.RawMessage(`{"text": "again"}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "restart limit")
+	})
+}
+
+func TestResourcesAndPrompts(t *testing.T) {
+	ctx := testContext(t)
+	manager := NewManager()
+	defer manager.Close()
+
+	require.NoError(t, manager.Mount(ctx, tools.NewToolManager(), []ServerConfig{{Name: "fake", Command: fakeServerPath}}))
+
+	t.Run("ListResources", func(t *testing.T) {
+		resources, err := manager.ListResources(ctx)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, "fake", resources[0].Server)
+		assert.Equal(t, "fake://notes.txt", resources[0].URI)
+	})
+
+	t.Run("ReadResource", func(t *testing.T) {
+		server, result, err := manager.ReadResource(ctx, "fake://notes.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "fake", server)
+		require.Len(t, result.Contents, 1)
+		assert.Equal(t, "remember the milk", result.Contents[0].Text)
+
+		_, _, err = manager.ReadResource(ctx, "fake://missing.txt")
+		assert.ErrorContains(t, err, "resource fake://missing.txt is not available")
+	})
+
+	t.Run("GetPrompt", func(t *testing.T) {
+		prompts, err := manager.ListPrompts(ctx)
+		require.NoError(t, err)
+		require.Len(t, prompts, 1)
+		assert.Equal(t, "file [focus]", prompts[0].Usage())
+
+		client, prompt, err := manager.FindPrompt(ctx, "fake", "review")
+		require.NoError(t, err)
+
+		args, err := prompt.BindArguments([]string{"main.go", "error", "handling"})
+		require.NoError(t, err)
+
+		result, err := client.GetPrompt(ctx, prompt.Name, args)
+		require.NoError(t, err)
+		assert.Equal(t, "Please review main.go with a focus on error handling", result.Text())
+
+		_, _, err = manager.FindPrompt(ctx, "fake", "missing")
+		assert.Error(t, err)
+	})
+}
+
+func TestPromptBindArguments(t *testing.T) {
+	review := Prompt{Name: "review", Arguments: []PromptArgument{
+		{Name: "file", Required: true},
+		{Name: "focus"},
+	}}
+
+	tests := []struct {
+		name    string
+		prompt  Prompt
+		args    []string
+		want    map[string]string
+		wantErr string
+	}{
+		{name: "Positional", prompt: review, args: []string{"a.go", "tests"}, want: map[string]string{"file": "a.go", "focus": "tests"}},
+		{name: "Named", prompt: review, args: []string{"focus=style", "file=b.go"}, want: map[string]string{"file": "b.go", "focus": "style"}},
+		{name: "Mixed", prompt: review, args: []string{"focus=style", "c.go"}, want: map[string]string{"file": "c.go", "focus": "style"}},
+		{name: "ExtraWordsJoinLast", prompt: review, args: []string{"d.go", "naming", "and", "tests"}, want: map[string]string{"file": "d.go", "focus": "naming and tests"}},
+		{name: "UnknownNameIsPositional", prompt: review, args: []string{"x=y"}, want: map[string]string{"file": "x=y"}},
+		{name: "MissingRequired", prompt: review, args: []string{"focus=style"}, wantErr: "missing required argument file"},
+		{name: "NoArgumentsDeclared", prompt: Prompt{Name: "plain"}, args: []string{"extra"}, wantErr: "too many arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.prompt.BindArguments(tt.args)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClientStartFailure(t *testing.T) {
+	client := NewClient(ServerConfig{Name: "missing", Command: filepath.Join(t.TempDir(), "does-not-exist")})
+	defer client.Close()
+
+	err := client.Start(testContext(t))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mcp server missing")
+}
+
+func TestManagerMount(t *testing.T) {
+	ctx := testContext(t)
+	toolManager, err := tools.Initialize()
+	require.NoError(t, err)
+
+	manager := NewManager()
+	defer manager.Close()
+
+	err = manager.Mount(ctx, toolManager, []ServerConfig{
+		{Name: "fake", Command: fakeServerPath},
+		{Name: "broken", Command: filepath.Join(t.TempDir(), "does-not-exist")},
+	})
+	require.Error(t, err, "the broken server should be reported")
+	assert.Contains(t, manager.Errors(), "broken")
+	require.Len(t, manager.Clients(), 1)
+
+	// Tools are offered to the model under a prefixed name in their own category
+	var names []string
+	for _, tool := range toolManager.GetTools() {
+		names = append(names, tool.Name)
+	}
+	assert.Contains(t, names, "mcp__fake__echo")
+	assert.Contains(t, names, "mcp__fake__fail")
+
+	t.Run("CallThroughToolManager", func(t *testing.T) {
+		result, err := toolManager.HandleToolUse(ctx, &core.ToolUse{
+			Name:  "mcp__fake__echo",
+			Input: json.RawMessage(`{"text": "proxied"}`),
+		})
+		require.NoError(t, err)
+		assert.Contains(t, string(result.Result), "proxied")
+	})
+
+	t.Run("ToolErrorsBecomeErrors", func(t *testing.T) {
+		_, err := toolManager.HandleToolUse(ctx, &core.ToolUse{
+			Name:  "mcp__fake__fail",
+			Input: json.RawMessage(`{"text": "oops"}`),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed: oops")
+	})
+
+	t.Run("SchemaValidated", func(t *testing.T) {
+		_, err := toolManager.HandleToolUse(ctx, &core.ToolUse{
+			Name:  "mcp__fake__echo",
+			Input: json.RawMessage(`{}`),
+		})
+		var validationErr *tools.InputValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
+
+func TestToolName(t *testing.T) {
+	assert.Equal(t, "mcp__my_server__read_file", ToolName("my server", "read.file"))
+	assert.Len(t, ToolName("server", string(make([]byte, 100))), maxToolNameLength)
+}
@@ -0,0 +1,327 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// maxToolNameLength is the longest tool name accepted by the model API
+const maxToolNameLength = 64
+
+// invalidNameChars matches characters not allowed in tool names
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// CategoryID returns the tool category ID used for a server's tools
+func CategoryID(serverName string) string {
+	return "mcp_" + sanitizeName(serverName)
+}
+
+// ToolName returns the name under which a server's tool is offered to the model
+func ToolName(serverName, toolName string) string {
+	name := "mcp__" + sanitizeName(serverName) + "__" + sanitizeName(toolName)
+	if len(name) > maxToolNameLength {
+		name = name[:maxToolNameLength]
+	}
+	return name
+}
+
+// sanitizeName replaces characters that are not allowed in tool names
+func sanitizeName(name string) string {
+	return invalidNameChars.ReplaceAllString(name, "_")
+}
+
+// Manager owns the clients for all configured MCP servers
+type Manager struct {
+	mu      sync.RWMutex
+	clients map[string]*Client
+	errors  map[string]error
+}
+
+// NewManager creates a manager without any servers
+func NewManager() *Manager {
+	return &Manager{
+		clients: make(map[string]*Client),
+		errors:  make(map[string]error),
+	}
+}
+
+// Mount starts each server, discovers its tools and registers them with the
+// tool manager in a category of their own. A server that fails to start is
+// skipped; its error is returned and available from Errors.
+func (m *Manager) Mount(ctx context.Context, toolManager *tools.ToolManager, configs []ServerConfig) error {
+	var errs []error
+	for _, config := range configs {
+		if err := m.mount(ctx, toolManager, config); err != nil {
+			m.mu.Lock()
+			m.errors[config.Name] = err
+			m.mu.Unlock()
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mount starts a single server and registers its tools
+func (m *Manager) mount(ctx context.Context, toolManager *tools.ToolManager, config ServerConfig) error {
+	if config.Name == "" {
+		return fmt.Errorf("mcp server with command %q has no name", config.Command)
+	}
+
+	m.mu.Lock()
+	if _, exists := m.clients[config.Name]; exists {
+		m.mu.Unlock()
+		return fmt.Errorf("mcp server %s is configured more than once", config.Name)
+	}
+	client := NewClient(config)
+	m.clients[config.Name] = client
+	m.mu.Unlock()
+
+	if err := client.Start(ctx); err != nil {
+		return err
+	}
+
+	serverTools, err := client.ListTools(ctx)
+	if err != nil {
+		return err
+	}
+
+	permission := core.PermissionLevel(config.Permission)
+	if permission == "" {
+		permission = core.PermissionReadWrite
+	}
+
+	info := client.ServerInfo()
+	description := fmt.Sprintf("Tools provided by the MCP server %s", config.Name)
+	if info.ServerInfo.Name != "" {
+		description = fmt.Sprintf("Tools provided by the MCP server %s (%s %s)", config.Name, info.ServerInfo.Name, info.ServerInfo.Version)
+	}
+
+	category := &tools.Category{
+		ID:          CategoryID(config.Name),
+		Name:        fmt.Sprintf("MCP: %s", config.Name),
+		Description: description,
+		Enabled:     true,
+		Permission:  permission,
+		Tools:       []core.Tool{},
+	}
+	if err := toolManager.RegisterCategory(category); err != nil {
+		return err
+	}
+
+	for _, tool := range serverTools {
+		if err := toolManager.RegisterTool(category.ID, newProxyTool(client, category.ID, tool)); err != nil {
+			return fmt.Errorf("mcp server %s: %w", config.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Client returns the client for a server by name
+func (m *Manager) Client(name string) (*Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	client, ok := m.clients[name]
+	return client, ok
+}
+
+// Clients returns the clients for all servers that started successfully, sorted by name
+func (m *Manager) Clients() []*Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clients := make([]*Client, 0, len(m.clients))
+	for name, client := range m.clients {
+		if _, failed := m.errors[name]; !failed {
+			clients = append(clients, client)
+		}
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].Name() < clients[j].Name()
+	})
+	return clients
+}
+
+// Errors returns the errors of servers that failed to start, by server name
+func (m *Manager) Errors() map[string]error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	errs := make(map[string]error, len(m.errors))
+	for name, err := range m.errors {
+		errs[name] = err
+	}
+	return errs
+}
+
+// ServerResource is a resource together with the server offering it
+type ServerResource struct {
+	Server string
+	Resource
+}
+
+// ServerPrompt is a prompt template together with the server offering it
+type ServerPrompt struct {
+	Server string
+	Prompt
+}
+
+// ListResources returns the resources of all servers that offer them.
+// Servers that fail to answer are reported in the error; the resources of
+// the others are still returned.
+func (m *Manager) ListResources(ctx context.Context) ([]ServerResource, error) {
+	var resources []ServerResource
+	var errs []error
+	for _, client := range m.Clients() {
+		if !client.HasResources() {
+			continue
+		}
+
+		list, err := client.ListResources(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, resource := range list {
+			resources = append(resources, ServerResource{Server: client.Name(), Resource: resource})
+		}
+	}
+	return resources, errors.Join(errs...)
+}
+
+// ReadResource reads a resource from the first server that can provide it
+// and returns the server's name with the contents
+func (m *Manager) ReadResource(ctx context.Context, uri string) (string, *ReadResourceResult, error) {
+	var errs []error
+	for _, client := range m.Clients() {
+		if !client.HasResources() {
+			continue
+		}
+
+		result, err := client.ReadResource(ctx, uri)
+		if err == nil {
+			return client.Name(), result, nil
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return "", nil, fmt.Errorf("no connected MCP server offers resources")
+	}
+	return "", nil, fmt.Errorf("resource %s is not available: %w", uri, errors.Join(errs...))
+}
+
+// ListPrompts returns the prompt templates of all servers that offer them.
+// Servers that fail to answer are reported in the error; the prompts of the
+// others are still returned.
+func (m *Manager) ListPrompts(ctx context.Context) ([]ServerPrompt, error) {
+	var prompts []ServerPrompt
+	var errs []error
+	for _, client := range m.Clients() {
+		if !client.HasPrompts() {
+			continue
+		}
+
+		list, err := client.ListPrompts(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, prompt := range list {
+			prompts = append(prompts, ServerPrompt{Server: client.Name(), Prompt: prompt})
+		}
+	}
+	return prompts, errors.Join(errs...)
+}
+
+// FindPrompt returns a prompt template of a server by name
+func (m *Manager) FindPrompt(ctx context.Context, server, name string) (*Client, Prompt, error) {
+	client, ok := m.Client(server)
+	if !ok {
+		return nil, Prompt{}, fmt.Errorf("unknown MCP server %s", server)
+	}
+
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil {
+		return nil, Prompt{}, err
+	}
+	for _, prompt := range prompts {
+		if prompt.Name == name {
+			return client, prompt, nil
+		}
+	}
+	return nil, Prompt{}, fmt.Errorf("mcp server %s has no prompt %s", server, name)
+}
+
+// Close shuts down all servers
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.mu.Unlock()
+
+	var errs []error
+	for _, client := range clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// proxyTool exposes a server's tool through the core.Tool interface
+type proxyTool struct {
+	*core.BaseToolImpl
+	client     *Client
+	remoteName string
+}
+
+// newProxyTool creates a tool that forwards calls to the server
+func newProxyTool(client *Client, categoryID string, tool Tool) *proxyTool {
+	schema := tool.InputSchema
+	if schema == nil {
+		schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+
+	description := tool.Description
+	if description == "" {
+		description = fmt.Sprintf("The %s tool of the MCP server %s", tool.Name, client.Name())
+	}
+
+	return &proxyTool{
+		BaseToolImpl: core.NewBaseTool(ToolName(client.Name(), tool.Name), description, categoryID, schema),
+		client:       client,
+		remoteName:   tool.Name,
+	}
+}
+
+// Execute implements the Tool interface by calling the tool on the server
+func (t *proxyTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := t.client.CallTool(ctx, t.remoteName, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.IsError {
+		message := result.Text()
+		if message == "" {
+			message = "the tool reported an error"
+		}
+		return nil, fmt.Errorf("%s", message)
+	}
+
+	return result, nil
+}
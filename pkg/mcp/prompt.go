@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BindArguments maps command line style arguments to the prompt's arguments.
+// Arguments are given as name=value pairs or positionally in declaration
+// order; words beyond the last positional argument are appended to it, so
+// free text needs no quoting.
+func (p Prompt) BindArguments(args []string) (map[string]string, error) {
+	values := make(map[string]string)
+	var positional []string
+	for _, arg := range args {
+		if name, value, ok := strings.Cut(arg, "="); ok && p.hasArgument(name) {
+			values[name] = value
+			continue
+		}
+		positional = append(positional, arg)
+	}
+
+	last := ""
+	for _, arg := range p.Arguments {
+		if len(positional) == 0 {
+			break
+		}
+		if _, set := values[arg.Name]; set {
+			continue
+		}
+		values[arg.Name] = positional[0]
+		positional = positional[1:]
+		last = arg.Name
+	}
+
+	if len(positional) > 0 {
+		if last == "" {
+			return nil, fmt.Errorf("too many arguments for prompt %s, usage: %s", p.Name, p.Usage())
+		}
+		values[last] += " " + strings.Join(positional, " ")
+	}
+
+	for _, arg := range p.Arguments {
+		if _, set := values[arg.Name]; arg.Required && !set {
+			return nil, fmt.Errorf("missing required argument %s for prompt %s, usage: %s", arg.Name, p.Name, p.Usage())
+		}
+	}
+
+	return values, nil
+}
+
+// Usage describes the prompt's arguments, with optional ones in brackets
+func (p Prompt) Usage() string {
+	parts := make([]string, 0, len(p.Arguments))
+	for _, arg := range p.Arguments {
+		if arg.Required {
+			parts = append(parts, arg.Name)
+		} else {
+			parts = append(parts, "["+arg.Name+"]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// hasArgument reports whether the prompt declares an argument called name
+func (p Prompt) hasArgument(name string) bool {
+	for _, arg := range p.Arguments {
+		if arg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Text returns the text of the rendered prompt. Messages the prompt puts in
+// the assistant's mouth are labelled so the conversation stays readable.
+func (r *GetPromptResult) Text() string {
+	var parts []string
+	for _, msg := range r.Messages {
+		text := msg.Content.Text
+		if msg.Content.Type == "resource" && msg.Content.Resource != nil {
+			text = msg.Content.Resource.Text
+		}
+		if text == "" {
+			continue
+		}
+		if msg.Role == "assistant" {
+			text = "Assistant: " + text
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n")
+}
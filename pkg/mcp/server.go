@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/navicore/mcpterm-go/pkg/jsonrpc"
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// ServerInfo identifies mcpterm to the clients it serves
+var ServerInfo = Implementation{Name: "mcpterm", Version: "0.1.0"}
+
+// Server exposes the enabled tools of a tool manager to MCP clients.
+// Calls go through the tool manager, so the same enabled categories,
+// input validation, timeouts and checkpoints apply as in a chat.
+type Server struct {
+	toolManager *tools.ToolManager
+}
+
+// NewServer creates a server for the tools of toolManager
+func NewServer(toolManager *tools.ToolManager) *Server {
+	return &Server{toolManager: toolManager}
+}
+
+// Serve answers requests on stream until the client disconnects or ctx is
+// cancelled. Tool calls still running when the connection ends are cancelled.
+func (s *Server) Serve(ctx context.Context, stream jsonrpc.Stream) error {
+	conn := jsonrpc.NewConn(stream, s.handle)
+
+	select {
+	case <-conn.Done():
+		if err := conn.Err(); !errors.Is(err, jsonrpc.ErrClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		_ = conn.Close()
+		return ctx.Err()
+	}
+}
+
+// handle serves a single request or notification from the client
+func (s *Server) handle(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case MethodInitialize:
+		return InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			Capabilities:    ServerCapabilities{Tools: &ListChangedCapability{}},
+			ServerInfo:      ServerInfo,
+		}, nil
+	case MethodInitialized:
+		return nil, nil
+	case MethodPing:
+		return struct{}{}, nil
+	case MethodToolsList:
+		return ListToolsResult{Tools: s.listTools()}, nil
+	case MethodToolsCall:
+		var call CallToolParams
+		if err := json.Unmarshal(params, &call); err != nil {
+			return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "invalid tools/call params: %v", err)
+		}
+		return s.callTool(ctx, call)
+	default:
+		return nil, jsonrpc.NewError(jsonrpc.CodeMethodNotFound, "method not found: %s", method)
+	}
+}
+
+// listTools returns the enabled tools, sorted by name
+func (s *Server) listTools() []Tool {
+	enabled := s.toolManager.GetTools()
+
+	serverTools := make([]Tool, 0, len(enabled))
+	for _, tool := range enabled {
+		schema, _ := tool.InputSchema.(map[string]interface{})
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+
+		serverTools = append(serverTools, Tool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: schema,
+		})
+	}
+
+	sort.Slice(serverTools, func(i, j int) bool {
+		return serverTools[i].Name < serverTools[j].Name
+	})
+	return serverTools
+}
+
+// callTool runs a tool through the tool manager. Tool failures are reported
+// in the result so the client's model can see and correct them.
+func (s *Server) callTool(ctx context.Context, call CallToolParams) (*CallToolResult, error) {
+	if !s.hasTool(call.Name) {
+		return nil, jsonrpc.NewError(jsonrpc.CodeInvalidParams, "unknown tool: %s", call.Name)
+	}
+
+	input := call.Arguments
+	if len(input) == 0 {
+		input = json.RawMessage("{}")
+	}
+
+	result, err := s.toolManager.HandleToolUse(ctx, &core.ToolUse{Name: call.Name, Input: input})
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: resultText(result.Result)}},
+	}, nil
+}
+
+// hasTool reports whether name is one of the enabled tools
+func (s *Server) hasTool(name string) bool {
+	for _, tool := range s.toolManager.GetTools() {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resultText converts a JSON encoded tool result to text content.
+// Plain strings are unquoted; other values are sent as JSON.
+func resultText(result json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(result, &text); err == nil {
+		return text
+	}
+	return string(result)
+}
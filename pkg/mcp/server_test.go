@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/jsonrpc"
+	"github.com/navicore/mcpterm-go/pkg/tools"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hexTool hex encodes its input, or fails when asked to
+type hexTool struct {
+	*core.BaseToolImpl
+}
+
+func (t *hexTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	var params struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, err
+	}
+	if params.Text == "fail" {
+		return nil, fmt.Errorf("asked to fail")
+	}
+	return map[string]string{"hex": fmt.Sprintf("%X", params.Text)}, nil
+}
+
+func newHexTool(name, category string) *hexTool {
+	return &hexTool{core.NewBaseTool(name, "Hex encodes text", category, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"text": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"text"},
+	})}
+}
+
+// serve starts a server for toolManager and returns a client connection to it
+func serve(t *testing.T, toolManager *tools.ToolManager) *jsonrpc.Conn {
+	t.Helper()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- NewServer(toolManager).Serve(ctx, jsonrpc.NewLineStream(serverReader, serverWriter))
+	}()
+
+	conn := jsonrpc.NewConn(jsonrpc.NewLineStream(clientReader, clientWriter), nil)
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		<-served
+	})
+	return conn
+}
+
+func TestServer(t *testing.T) {
+	ctx := testContext(t)
+	toolManager := tools.NewToolManager()
+	require.NoError(t, toolManager.RegisterTool("filesystem", newHexTool("hex", "filesystem")))
+	require.NoError(t, toolManager.RegisterTool("development", newHexTool("hidden", "development")))
+
+	conn := serve(t, toolManager)
+
+	var info InitializeResult
+	require.NoError(t, conn.Call(ctx, MethodInitialize, InitializeParams{ProtocolVersion: ProtocolVersion, ClientInfo: ClientInfo}, &info))
+	assert.Equal(t, ServerInfo.Name, info.ServerInfo.Name)
+	assert.NotNil(t, info.Capabilities.Tools)
+	require.NoError(t, conn.Notify(MethodInitialized, nil))
+
+	t.Run("ListOnlyEnabledTools", func(t *testing.T) {
+		var result ListToolsResult
+		require.NoError(t, conn.Call(ctx, MethodToolsList, ListToolsParams{}, &result))
+		require.Len(t, result.Tools, 1)
+		assert.Equal(t, "hex", result.Tools[0].Name)
+		assert.Equal(t, "object", result.Tools[0].InputSchema["type"])
+	})
+
+	t.Run("Call", func(t *testing.T) {
+		var result CallToolResult
+		require.NoError(t, conn.Call(ctx, MethodToolsCall, CallToolParams{Name: "hex", Arguments: json.RawMessage(`{"text": "hi"}`)}, &result))
+		assert.False(t, result.IsError)
+		assert.JSONEq(t, `{"hex": "6869"}`, result.Text())
+	})
+
+	t.Run("ToolErrorInResult", func(t *testing.T) {
+		var result CallToolResult
+		require.NoError(t, conn.Call(ctx, MethodToolsCall, CallToolParams{Name: "hex", Arguments: json.RawMessage(`{"text": "fail"}`)}, &result))
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Text(), "asked to fail")
+	})
+
+	t.Run("InvalidInputInResult", func(t *testing.T) {
+		var result CallToolResult
+		require.NoError(t, conn.Call(ctx, MethodToolsCall, CallToolParams{Name: "hex"}, &result))
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Text(), "text: is required but missing")
+	})
+
+	t.Run("DisabledToolRejected", func(t *testing.T) {
+		err := conn.Call(ctx, MethodToolsCall, CallToolParams{Name: "hidden", Arguments: json.RawMessage(`{"text": "x"}`)}, nil)
+
+		var rpcErr *jsonrpc.Error
+		require.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+	})
+}
@@ -0,0 +1,183 @@
+// Command fakeserver is a minimal MCP server used by the mcp package tests.
+// It speaks newline-delimited JSON-RPC over stdio and offers three tools:
+// echo returns its text, fail reports a tool error, and crash exits the process.
+// It also offers a text resource and a review prompt template.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   interface{}     `json:"error,omitempty"`
+}
+
+func main() {
+	fmt.Fprintln(os.Stderr, "fakeserver: started")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	encoder := json.NewEncoder(os.Stdout)
+
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			fmt.Fprintf(os.Stderr, "fakeserver: bad message: %v\n", err)
+			continue
+		}
+		if len(msg.ID) == 0 {
+			// Notifications need no answer
+			continue
+		}
+
+		resp := message{JSONRPC: "2.0", ID: msg.ID}
+		switch msg.Method {
+		case "initialize":
+			resp.Result = map[string]interface{}{
+				"protocolVersion": "2024-11-05",
+				"capabilities": map[string]interface{}{
+					"tools":     map[string]interface{}{},
+					"resources": map[string]interface{}{},
+					"prompts":   map[string]interface{}{},
+				},
+				"serverInfo": map[string]interface{}{"name": "fakeserver", "version": "1.0.0"},
+			}
+		case "tools/list":
+			resp.Result = listTools(msg.Params)
+		case "tools/call":
+			resp.Result = callTool(msg.Params)
+		case "resources/list":
+			resp.Result = map[string]interface{}{
+				"resources": []interface{}{
+					map[string]interface{}{"uri": "fake://notes.txt", "name": "notes", "mimeType": "text/plain"},
+				},
+			}
+		case "resources/read":
+			resp.Result, resp.Error = readResource(msg.Params)
+		case "prompts/list":
+			resp.Result = map[string]interface{}{
+				"prompts": []interface{}{
+					map[string]interface{}{
+						"name":        "review",
+						"description": "Review a file",
+						"arguments": []interface{}{
+							map[string]interface{}{"name": "file", "required": true},
+							map[string]interface{}{"name": "focus"},
+						},
+					},
+				},
+			}
+		case "prompts/get":
+			resp.Result = getPrompt(msg.Params)
+		default:
+			resp.Error = map[string]interface{}{"code": -32601, "message": "method not found: " + msg.Method}
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			os.Exit(1)
+		}
+	}
+}
+
+// listTools returns the tools in two pages to exercise pagination
+func listTools(params json.RawMessage) interface{} {
+	var req struct {
+		Cursor string `json:"cursor"`
+	}
+	_ = json.Unmarshal(params, &req)
+
+	textSchema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"text": map[string]interface{}{"type": "string"}},
+		"required":   []string{"text"},
+	}
+
+	if req.Cursor == "" {
+		return map[string]interface{}{
+			"tools": []interface{}{
+				map[string]interface{}{"name": "echo", "description": "Echo the text back", "inputSchema": textSchema},
+				map[string]interface{}{"name": "fail", "description": "Always fail", "inputSchema": textSchema},
+			},
+			"nextCursor": "page2",
+		}
+	}
+
+	return map[string]interface{}{
+		"tools": []interface{}{
+			map[string]interface{}{"name": "crash", "description": "Exit the server", "inputSchema": map[string]interface{}{"type": "object"}},
+		},
+	}
+}
+
+// callTool runs one of the fake tools
+func callTool(params json.RawMessage) interface{} {
+	var req struct {
+		Name      string `json:"name"`
+		Arguments struct {
+			Text string `json:"text"`
+		} `json:"arguments"`
+	}
+	_ = json.Unmarshal(params, &req)
+
+	switch req.Name {
+	case "echo":
+		return textResult(req.Arguments.Text, false)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "fakeserver: fail tool called")
+		return textResult("failed: "+req.Arguments.Text, true)
+	case "crash":
+		fmt.Fprintln(os.Stderr, "fakeserver: crashing")
+		os.Exit(3)
+	}
+	return textResult("unknown tool "+req.Name, true)
+}
+
+func textResult(text string, isError bool) interface{} {
+	return map[string]interface{}{
+		"content": []interface{}{map[string]interface{}{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+// readResource returns the contents of the only resource
+func readResource(params json.RawMessage) (interface{}, interface{}) {
+	var req struct {
+		URI string `json:"uri"`
+	}
+	_ = json.Unmarshal(params, &req)
+
+	if req.URI != "fake://notes.txt" {
+		return nil, map[string]interface{}{"code": -32002, "message": "resource not found: " + req.URI}
+	}
+	return map[string]interface{}{
+		"contents": []interface{}{
+			map[string]interface{}{"uri": req.URI, "mimeType": "text/plain", "text": "remember the milk"},
+		},
+	}, nil
+}
+
+// getPrompt renders the review prompt
+func getPrompt(params json.RawMessage) interface{} {
+	var req struct {
+		Arguments map[string]string `json:"arguments"`
+	}
+	_ = json.Unmarshal(params, &req)
+
+	text := "Please review " + req.Arguments["file"]
+	if focus := req.Arguments["focus"]; focus != "" {
+		text += " with a focus on " + focus
+	}
+	return map[string]interface{}{
+		"messages": []interface{}{
+			map[string]interface{}{"role": "user", "content": map[string]interface{}{"type": "text", "text": text}},
+		},
+	}
+}
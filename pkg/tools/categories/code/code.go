@@ -0,0 +1,457 @@
+// Package code provides tools that navigate Go code through its syntax and
+// types rather than its text: the symbols a package declares, the
+// definition of an identifier, the references to it, and the signature and
+// documentation of a declaration. The tools share an Index that parses and
+// type checks each workspace once and updates it as files change.
+package code
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CategoryID is the category the code tools are registered in
+const CategoryID = "code"
+
+// targetSchema holds the parameters shared by the tools that look up a
+// declaration, by position or by name
+var targetSchema = map[string]interface{}{
+	"path": map[string]interface{}{
+		"type":        "string",
+		"description": "The file containing the identifier, or with symbol a file or directory inside the workspace (default current directory)",
+	},
+	"line": map[string]interface{}{
+		"type":        "integer",
+		"description": "Line of the identifier in path (1-based)",
+		"minimum":     1,
+	},
+	"name": map[string]interface{}{
+		"type":        "string",
+		"description": "The identifier on line; the first one on the line if omitted",
+	},
+	"column": map[string]interface{}{
+		"type":        "integer",
+		"description": "Column of the identifier on line (1-based), to choose between identifiers with the same name",
+		"minimum":     1,
+	},
+	"symbol": map[string]interface{}{
+		"type":        "string",
+		"description": "Instead of a position, a package-level name: 'Name', 'Type.Method', 'Type.Field' or 'pkg.Name', where pkg is a package name",
+	},
+}
+
+// targetProperties returns the schema properties of a target, with extra
+// properties of a tool
+func targetProperties(extra map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{}, len(targetSchema)+len(extra))
+	for name, schema := range targetSchema {
+		properties[name] = schema
+	}
+	for name, schema := range extra {
+		properties[name] = schema
+	}
+	return properties
+}
+
+// Target identifies a declaration: the identifier at a position in a file,
+// or a package-level symbol
+type Target struct {
+	Path   string `json:"path,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Column int    `json:"column,omitempty"`
+	Symbol string `json:"symbol,omitempty"`
+}
+
+// Location is a position in a source file
+type Location struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// Symbol describes a declared object
+type Symbol struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Package string `json:"package,omitempty"`
+	Location
+	Signature string `json:"signature"`
+}
+
+// validate checks that a target has a position or a symbol
+func (t Target) validate(tool string) error {
+	switch {
+	case t.Line > 0 && t.Symbol != "":
+		return fmt.Errorf("invalid input for %s tool: give either line or symbol, not both", tool)
+	case t.Line > 0 && t.Path == "":
+		return fmt.Errorf("invalid input for %s tool: line requires the path of a file", tool)
+	case t.Line <= 0 && t.Symbol == "":
+		return fmt.Errorf("invalid input for %s tool: give a line in path, or a symbol", tool)
+	}
+	return nil
+}
+
+// resolve returns the objects a target refers to: one for a position, and
+// every match for a symbol
+func (ws *workspace) resolve(t Target) ([]types.Object, error) {
+	if t.Line > 0 {
+		obj, err := ws.objectAt(t)
+		if err != nil {
+			return nil, err
+		}
+		return []types.Object{obj}, nil
+	}
+
+	objects := ws.lookupSymbol(t.Path, t.Symbol)
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("symbol %s not found in %s", t.Symbol, ws.root)
+	}
+	return objects, nil
+}
+
+// objectAt returns the object referred to or declared by the identifier at a position
+func (ws *workspace) objectAt(t Target) (types.Object, error) {
+	abs, err := filepath.Abs(t.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %s: %w", t.Path, err)
+	}
+	file, ok := ws.fileOf(abs)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a Go file in the workspace", t.Path)
+	}
+	if !file.matched {
+		return nil, fmt.Errorf("%s is excluded by build constraints", t.Path)
+	}
+	p, v, ok := ws.packageOf(file)
+	if !ok {
+		return nil, fmt.Errorf("%s does not belong to the package in its directory", t.Path)
+	}
+
+	var found *ast.Ident
+	ast.Inspect(file.ast, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || found != nil {
+			return found == nil
+		}
+		pos := ws.fset.Position(id.Pos())
+		switch {
+		case pos.Line != t.Line:
+		case t.Name != "" && id.Name != t.Name:
+		case t.Column > 0 && (t.Column < pos.Column || t.Column >= pos.Column+len(id.Name)):
+		default:
+			found = id
+		}
+		return true
+	})
+	if found == nil {
+		if t.Name != "" {
+			return nil, fmt.Errorf("no identifier %s on line %d of %s", t.Name, t.Line, t.Path)
+		}
+		return nil, fmt.Errorf("no identifier on line %d of %s", t.Line, t.Path)
+	}
+
+	info := ws.check(p, v).info
+	if obj := info.Defs[found]; obj != nil {
+		return obj, nil
+	}
+	if obj := info.Uses[found]; obj != nil {
+		return obj, nil
+	}
+	// The variable of a type switch is declared once per clause; use the first
+	var implicit types.Object
+	for node, obj := range info.Implicits {
+		clause, ok := node.(*ast.CaseClause)
+		if ok && obj.Name() == found.Name && clause.Pos() > found.Pos() && (implicit == nil || obj.Pos() < implicit.Pos()) {
+			implicit = obj
+		}
+	}
+	if implicit != nil {
+		return implicit, nil
+	}
+	return nil, fmt.Errorf("%s on line %d of %s does not refer to a declaration", found.Name, t.Line, t.Path)
+}
+
+// lookupSymbol finds the package-level objects, fields and methods named
+// by symbol. Packages in the directory of path are searched first.
+func (ws *workspace) lookupSymbol(path, symbol string) []types.Object {
+	parts := strings.Split(symbol, ".")
+	if len(parts) > 3 {
+		return nil
+	}
+
+	first := ""
+	if abs, err := filepath.Abs(dirOrCurrent(path)); err == nil {
+		if info, err := os.Stat(abs); err == nil && !info.IsDir() {
+			abs = filepath.Dir(abs)
+		}
+		first = abs
+	}
+	packages := ws.sortedPackages()
+	for i, p := range packages {
+		if p.dir == first {
+			packages = append([]*pkg{p}, append(packages[:i:i], packages[i+1:]...)...)
+			break
+		}
+	}
+
+	var objects []types.Object
+	for _, p := range packages {
+		names := parts
+		if len(parts) > 1 && parts[0] == p.name {
+			names = parts[1:]
+		} else if len(parts) == 3 {
+			continue
+		}
+
+		// Only type check packages that declare the name
+		if !declares(p, names[0]) {
+			continue
+		}
+		obj := ws.check(p, libVariant).types.Scope().Lookup(names[0])
+		if obj == nil {
+			continue
+		}
+		if len(names) == 2 {
+			if _, ok := obj.(*types.TypeName); !ok {
+				continue
+			}
+			obj, _, _ = types.LookupFieldOrMethod(obj.Type(), true, obj.Pkg(), names[1])
+			if obj == nil {
+				continue
+			}
+		}
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+// declares reports whether the non-test files of a package declare name at package level
+func declares(p *pkg, name string) bool {
+	for _, file := range p.files {
+		for _, decl := range file.ast.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil && decl.Name.Name == name {
+					return true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if spec.Name.Name == name {
+							return true
+						}
+					case *ast.ValueSpec:
+						for _, id := range spec.Names {
+							if id.Name == name {
+								return true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// describe returns the symbol of an object
+func (ws *workspace) describe(obj types.Object) Symbol {
+	symbol := Symbol{
+		Name:      obj.Name(),
+		Kind:      objectKind(obj),
+		Signature: types.ObjectString(obj, qualifier(obj.Pkg())),
+		Location:  ws.location(obj.Pos()),
+	}
+	if obj.Pkg() != nil {
+		symbol.Package = obj.Pkg().Path()
+	}
+	return symbol
+}
+
+// location returns the location of a position, with the file relative to
+// the current directory when it is inside it
+func (ws *workspace) location(pos token.Pos) Location {
+	position := ws.position(pos)
+	return Location{File: displayPath(position.Filename), Line: position.Line, Column: position.Column}
+}
+
+// position returns the file, line and column of a position. Export data
+// names the files of the standard library relative to $GOROOT.
+func (ws *workspace) position(pos token.Pos) token.Position {
+	position := ws.fset.Position(pos)
+	if rest, ok := strings.CutPrefix(position.Filename, "$GOROOT"); ok {
+		position.Filename = filepath.Join(build.Default.GOROOT, rest)
+	}
+	return position
+}
+
+// displayPath returns path relative to the current directory when it is inside it
+func displayPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// qualifier writes package names, leaving out the name of pkg itself
+func qualifier(pkg *types.Package) types.Qualifier {
+	return func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		return other.Name()
+	}
+}
+
+// objectKind names the kind of an object
+func objectKind(obj types.Object) string {
+	switch obj := obj.(type) {
+	case *types.Func:
+		if sig, ok := obj.Type().(*types.Signature); ok && sig.Recv() != nil {
+			return "method"
+		}
+		return "func"
+	case *types.TypeName:
+		if _, ok := obj.Type().Underlying().(*types.Interface); ok {
+			return "interface"
+		}
+		return "type"
+	case *types.Var:
+		if obj.IsField() {
+			return "field"
+		}
+		return "var"
+	case *types.Const:
+		return "const"
+	case *types.PkgName:
+		return "package"
+	case *types.Label:
+		return "label"
+	case *types.Builtin:
+		return "builtin"
+	default:
+		return "object"
+	}
+}
+
+// docOf returns the doc comment of the declaration of an object. Files
+// outside the workspace, such as those of the standard library, are parsed
+// for the purpose.
+func (ws *workspace) docOf(obj types.Object) string {
+	if !obj.Pos().IsValid() {
+		return ""
+	}
+	position := ws.position(obj.Pos())
+
+	var file *ast.File
+	fset := ws.fset
+	if source, ok := ws.fileOf(position.Filename); ok {
+		file = source.ast
+	} else {
+		fset = token.NewFileSet()
+		parsed, err := parser.ParseFile(fset, position.Filename, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return ""
+		}
+		file = parsed
+	}
+
+	// Export data does not record the column of a declaration, only its line
+	at := func(id *ast.Ident) bool {
+		return id.Name == obj.Name() && fset.Position(id.Pos()).Line == position.Line
+	}
+	var doc, declDoc *ast.CommentGroup
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.GenDecl:
+			// The comment of a declaration documents it when it has a single spec
+			declDoc = nil
+			if !n.Lparen.IsValid() {
+				declDoc = n.Doc
+			}
+		case *ast.FuncDecl:
+			if at(n.Name) {
+				doc, found = n.Doc, true
+			}
+		case *ast.TypeSpec:
+			if at(n.Name) {
+				doc, found = firstComment(n.Doc, declDoc, n.Comment), true
+			}
+		case *ast.ValueSpec:
+			for _, id := range n.Names {
+				if at(id) {
+					doc, found = firstComment(n.Doc, declDoc, n.Comment), true
+				}
+			}
+		case *ast.Field:
+			for _, id := range n.Names {
+				if at(id) {
+					doc, found = firstComment(n.Doc, n.Comment), true
+				}
+			}
+		}
+		return !found
+	})
+	return strings.TrimSpace(doc.Text())
+}
+
+// firstComment returns the first comment group that is not nil
+func firstComment(groups ...*ast.CommentGroup) *ast.CommentGroup {
+	for _, group := range groups {
+		if group != nil {
+			return group
+		}
+	}
+	return nil
+}
+
+// summary returns the first sentence of a doc comment
+func summary(doc string) string {
+	paragraph, _, _ := strings.Cut(doc, "\n\n")
+	paragraph = strings.Join(strings.Fields(paragraph), " ")
+	if i := strings.Index(paragraph, ". "); i >= 0 {
+		return paragraph[:i+1]
+	}
+	return paragraph
+}
+
+// sourceLines reads the lines of files, caching them for a single result
+type sourceLines map[string][][]byte
+
+// line returns a line of a file without its indentation, or "" if it cannot be read
+func (s sourceLines) line(path string, line int) string {
+	lines, ok := s[path]
+	if !ok {
+		content, err := os.ReadFile(path)
+		if err == nil {
+			lines = bytes.Split(content, []byte("\n"))
+		}
+		s[path] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(string(lines[line-1]))
+}
@@ -0,0 +1,81 @@
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// DefinitionTool finds where an identifier or symbol is declared
+type DefinitionTool struct {
+	core.BaseToolImpl
+	index *Index
+}
+
+// Definition is a declaration with its source line
+type Definition struct {
+	Symbol
+	Text string `json:"text"` // The declaring line
+	Doc  string `json:"doc,omitempty"`
+}
+
+// DefinitionResult lists the declarations a target refers to; a symbol may match several
+type DefinitionResult struct {
+	Definitions []Definition `json:"definitions"`
+}
+
+// NewDefinitionTool creates a new code_definition tool
+func NewDefinitionTool(index *Index) *DefinitionTool {
+	tool := &DefinitionTool{index: index}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"code_definition",
+		"Go to the definition of a Go identifier, given its file and line, or of a symbol such as 'Store.Get'. "+
+			"Returns the declaration's file, line, kind, signature and doc summary, including for the standard library.",
+		CategoryID,
+		map[string]interface{}{
+			"type":       "object",
+			"properties": targetProperties(nil),
+		},
+	)
+	return tool
+}
+
+// Execute finds the definitions
+func (t *DefinitionTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params Target
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for code_definition tool: %w", err)
+	}
+	if err := params.validate("code_definition"); err != nil {
+		return nil, err
+	}
+
+	result := DefinitionResult{Definitions: []Definition{}}
+	err := t.index.with(params.Path, func(ws *workspace) error {
+		objects, err := ws.resolve(params)
+		if err != nil {
+			return err
+		}
+
+		lines := sourceLines{}
+		for _, obj := range objects {
+			if !obj.Pos().IsValid() {
+				return fmt.Errorf("%s is predeclared and has no source", obj.Name())
+			}
+			definition := Definition{Symbol: ws.describe(obj), Doc: summary(ws.docOf(obj))}
+			definition.Text = lines.line(ws.position(obj.Pos()).Filename, definition.Line)
+			result.Definitions = append(result.Definitions, definition)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
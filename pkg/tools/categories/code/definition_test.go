@@ -0,0 +1,106 @@
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefinitionAtPosition(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	tool := NewDefinitionTool(testIndex)
+
+	// s.Get in another package
+	result := runTool(t, tool, Target{Path: filepath.Join(dir, "api", "api.go"), Line: 11, Name: "Get"}).(DefinitionResult)
+	require.Len(t, result.Definitions, 1)
+	definition := result.Definitions[0]
+	assert.Equal(t, "Get", definition.Name)
+	assert.Equal(t, "method", definition.Kind)
+	assert.Equal(t, "example.com/shop/store", definition.Package)
+	assert.Equal(t, filepath.Join(dir, "store", "store.go"), definition.File)
+	assert.Equal(t, 22, definition.Line)
+	assert.Equal(t, 17, definition.Column)
+	assert.Equal(t, "func (*Store).Get(key string) (int, error)", definition.Signature)
+	assert.Equal(t, "func (s *Store) Get(key string) (int, error) {", definition.Text)
+	assert.Equal(t, "Get returns the item stored under key.", definition.Doc)
+
+	// The first identifier on the line is v, a local variable
+	result = runTool(t, tool, Target{Path: filepath.Join(dir, "api", "api.go"), Line: 11}).(DefinitionResult)
+	require.Len(t, result.Definitions, 1)
+	assert.Equal(t, "v", result.Definitions[0].Name)
+	assert.Equal(t, "var", result.Definitions[0].Kind)
+	assert.Equal(t, 11, result.Definitions[0].Line)
+
+	// A declaration in the standard library
+	result = runTool(t, tool, Target{Path: filepath.Join(dir, "api", "api.go"), Line: 15, Name: "Sprint"}).(DefinitionResult)
+	require.Len(t, result.Definitions, 1)
+	assert.Equal(t, "fmt", result.Definitions[0].Package)
+	assert.Equal(t, "print.go", filepath.Base(result.Definitions[0].File))
+	assert.Contains(t, result.Definitions[0].Doc, "Sprint formats")
+}
+
+func TestDefinitionInTests(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	tool := NewDefinitionTool(testIndex)
+
+	result := runTool(t, tool, Target{Path: filepath.Join(dir, "store", "store_test.go"), Line: 8, Name: "ErrNotFound"}).(DefinitionResult)
+	require.Len(t, result.Definitions, 1)
+	assert.Equal(t, 7, result.Definitions[0].Line)
+
+	result = runTool(t, tool, Target{Path: filepath.Join(dir, "store", "example_test.go"), Line: 6, Name: "New"}).(DefinitionResult)
+	require.Len(t, result.Definitions, 1)
+	assert.Equal(t, 15, result.Definitions[0].Line)
+}
+
+func TestDefinitionOfSymbol(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	tool := NewDefinitionTool(testIndex)
+
+	for _, symbol := range []string{"Store.Put", "store.Store.Put"} {
+		result := runTool(t, tool, Target{Path: dir, Symbol: symbol}).(DefinitionResult)
+		require.Len(t, result.Definitions, 1, symbol)
+		assert.Equal(t, 31, result.Definitions[0].Line, symbol)
+	}
+
+	// Put is a method, not a package-level name
+	_, err := tool.Execute(context.Background(), mustMarshal(t, Target{Path: dir, Symbol: "store.Put"}))
+	assert.ErrorContains(t, err, "symbol store.Put not found")
+
+	result := runTool(t, tool, Target{Path: dir, Symbol: "Store.items"}).(DefinitionResult)
+	require.Len(t, result.Definitions, 1)
+	assert.Equal(t, "field", result.Definitions[0].Kind)
+	assert.Equal(t, "Items by key", result.Definitions[0].Doc)
+}
+
+func TestDefinitionErrors(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	tool := NewDefinitionTool(testIndex)
+	api := filepath.Join(dir, "api", "api.go")
+
+	for input, message := range map[string]string{
+		`{"path": "` + api + `"}`:                                   "give a line in path, or a symbol",
+		`{"path": "` + api + `", "line": 11, "symbol": "Describe"}`: "not both",
+		`{"path": "` + api + `", "line": 11, "name": "missing"}`:    "no identifier missing on line 11",
+		`{"path": "` + api + `", "line": 13, "name": "err"}`:        "",
+		`{"path": "` + api + `", "line": 13, "name": "Error"}`:      "predeclared",
+	} {
+		_, err := tool.Execute(context.Background(), json.RawMessage(input))
+		if message == "" {
+			assert.NoError(t, err, input)
+		} else {
+			assert.ErrorContains(t, err, message, input)
+		}
+	}
+}
+
+// mustMarshal encodes a value as JSON
+func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,580 @@
+package code
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Index caches a parsed and type-checked view of each workspace. It is
+// shared by the code tools, and refreshed from the files on disk each time
+// a tool uses it. Packages outside the workspaces, such as the standard
+// library, are imported once for all of them.
+type Index struct {
+	mu         sync.Mutex
+	fset       *token.FileSet
+	source     types.ImporterFrom    // Imports packages without export data from source
+	workspaces map[string]*workspace // By root directory
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	fset := token.NewFileSet()
+	return &Index{
+		fset:       fset,
+		source:     importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+		workspaces: make(map[string]*workspace),
+	}
+}
+
+// listTimeout limits the time go list may take to build the export data of
+// a workspace's dependencies
+const listTimeout = 5 * time.Minute
+
+// variant selects which files of a package are type checked together, as
+// go test does: the package alone, the package with its in-package tests,
+// or its external test package
+type variant int
+
+const (
+	libVariant variant = iota
+	testVariant
+	xtestVariant
+)
+
+// workspace is the Go module containing a path, or a single directory
+// outside any module
+type workspace struct {
+	root      string
+	module    string // Module path, empty outside a module
+	recursive bool   // The subdirectories of root are part of the workspace
+	fset      *token.FileSet
+	dirs      map[string]*sourceDir // By absolute directory
+	packages  map[string]*pkg       // By import path, rebuilt after files change
+	exports   map[string]string     // Export data files of dependencies, by import path
+	compiled  types.ImporterFrom    // Imports dependencies from their export data
+	source    types.ImporterFrom
+}
+
+// sourceDir holds the parsed Go files of a directory
+type sourceDir struct {
+	stamps map[string]fileStamp // By file name
+	files  []*sourceFile
+}
+
+// fileStamp identifies a version of a file on disk
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// sourceFile is a parsed Go file
+type sourceFile struct {
+	path    string // Absolute
+	ast     *ast.File
+	test    bool // A _test.go file
+	matched bool // Built for the current platform and build tags
+}
+
+// pkg is a package of the workspace: a directory's files with the same package name
+type pkg struct {
+	path       string // Import path
+	dir        string
+	name       string
+	files      []*sourceFile
+	testFiles  []*sourceFile // In-package tests
+	xtestFiles []*sourceFile // External tests, package name_test
+	imports    map[string]bool
+	checked    [3]*checkedPackage // By variant
+	checking   [3]bool            // Detects import cycles
+}
+
+// checkedPackage is the result of type checking a package variant
+type checkedPackage struct {
+	types *types.Package
+	info  *types.Info
+	files []*sourceFile
+}
+
+// with runs fn with the workspace containing path, refreshed against the
+// files on disk. The index is used by one tool at a time.
+func (x *Index) with(path string, fn func(ws *workspace) error) error {
+	abs, err := filepath.Abs(dirOrCurrent(path))
+	if err != nil {
+		return fmt.Errorf("invalid path %s: %w", path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return fmt.Errorf("cannot access %s: %w", path, err)
+	}
+	dir := abs
+	if !info.IsDir() {
+		dir = filepath.Dir(abs)
+	}
+
+	root, module, inModule := findModule(dir)
+	if !inModule {
+		root = dir
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	ws, ok := x.workspaces[root]
+	if !ok {
+		ws = &workspace{
+			root:      root,
+			module:    module,
+			recursive: inModule,
+			fset:      x.fset,
+			dirs:      make(map[string]*sourceDir),
+			source:    x.source,
+		}
+		ws.compiled = importer.ForCompiler(x.fset, "gc", ws.openExport).(types.ImporterFrom)
+		x.workspaces[root] = ws
+	}
+	if err := ws.refresh(); err != nil {
+		return err
+	}
+	return fn(ws)
+}
+
+// findModule returns the directory and path of the module containing dir
+func findModule(dir string) (string, string, bool) {
+	for current := dir; ; current = filepath.Dir(current) {
+		if module, ok := readModulePath(filepath.Join(current, "go.mod")); ok {
+			return current, module, true
+		}
+		if filepath.Dir(current) == current {
+			return "", "", false
+		}
+	}
+}
+
+// readModulePath reads the module path declared in a go.mod file
+func readModulePath(gomod string) (string, bool) {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			if i := strings.Index(rest, "//"); i >= 0 {
+				rest = rest[:i]
+			}
+			return strings.Trim(strings.TrimSpace(rest), `"`), true
+		}
+	}
+	return "", true
+}
+
+// refresh parses the files that changed since the last refresh. Any change
+// discards the type information, which is rebuilt when next needed.
+func (ws *workspace) refresh() error {
+	seen := make(map[string]bool)
+	changed := false
+
+	visit := func(dir string) error {
+		stamps, err := goFileStamps(dir)
+		if err != nil {
+			return err
+		}
+		if len(stamps) == 0 {
+			return nil
+		}
+		seen[dir] = true
+		if current, ok := ws.dirs[dir]; ok && sameStamps(current.stamps, stamps) {
+			return nil
+		}
+		ws.dirs[dir] = ws.parseDir(dir, stamps)
+		changed = true
+		return nil
+	}
+
+	if !ws.recursive {
+		if err := visit(ws.root); err != nil {
+			return err
+		}
+	} else {
+		err := filepath.WalkDir(ws.root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Unreadable directories are left out rather than failing the walk
+				if d != nil && d.IsDir() && path != ws.root {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			if path != ws.root && skipDir(path, d.Name()) {
+				return filepath.SkipDir
+			}
+			return visit(path)
+		})
+		if err != nil {
+			return fmt.Errorf("cannot index %s: %w", ws.root, err)
+		}
+	}
+
+	for dir := range ws.dirs {
+		if !seen[dir] {
+			delete(ws.dirs, dir)
+			changed = true
+		}
+	}
+	if changed {
+		ws.packages = nil
+	}
+	return nil
+}
+
+// skipDir reports whether a directory is outside the workspace's packages,
+// as the go command treats it: hidden, testdata, vendored or a nested module
+func skipDir(path, name string) bool {
+	switch {
+	case strings.HasPrefix(name, "."), strings.HasPrefix(name, "_"):
+		return true
+	case name == "testdata", name == "vendor", name == "node_modules":
+		return true
+	}
+	_, err := os.Stat(filepath.Join(path, "go.mod"))
+	return err == nil
+}
+
+// goFileStamps returns the stamps of the Go files in a directory
+func goFileStamps(dir string) (map[string]fileStamp, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", dir, err)
+	}
+	stamps := make(map[string]fileStamp)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		stamps[entry.Name()] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+// sameStamps reports whether two sets of stamps describe the same files
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, stamp := range a {
+		if other, ok := b[name]; !ok || !other.modTime.Equal(stamp.modTime) || other.size != stamp.size {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDir parses the Go files of a directory. A file with syntax errors
+// keeps what could be parsed.
+func (ws *workspace) parseDir(dir string, stamps map[string]fileStamp) *sourceDir {
+	names := make([]string, 0, len(stamps))
+	for name := range stamps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parsed := &sourceDir{stamps: stamps}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		file, _ := parser.ParseFile(ws.fset, path, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if file == nil {
+			continue
+		}
+		matched, err := build.Default.MatchFile(dir, name)
+		parsed.files = append(parsed.files, &sourceFile{
+			path:    path,
+			ast:     file,
+			test:    strings.HasSuffix(name, "_test.go"),
+			matched: matched && err == nil,
+		})
+	}
+	return parsed
+}
+
+// packageName returns the name of the package in a directory: that of its
+// non-test files, or of its tests if it has none
+func packageName(files []*sourceFile) string {
+	name := ""
+	for _, file := range files {
+		if !file.matched {
+			continue
+		}
+		if !file.test {
+			return file.ast.Name.Name
+		}
+		if name == "" {
+			name = strings.TrimSuffix(file.ast.Name.Name, "_test")
+		}
+	}
+	return name
+}
+
+// importPath returns the import path of the package in dir
+func (ws *workspace) importPath(dir string) string {
+	rel, err := filepath.Rel(ws.root, dir)
+	if err != nil || rel == "." {
+		if ws.module == "" {
+			return "."
+		}
+		return ws.module
+	}
+	if ws.module == "" {
+		return "./" + filepath.ToSlash(rel)
+	}
+	return ws.module + "/" + filepath.ToSlash(rel)
+}
+
+// allPackages returns the packages of the workspace by import path,
+// grouping the files of each directory by package name
+func (ws *workspace) allPackages() map[string]*pkg {
+	if ws.packages != nil {
+		return ws.packages
+	}
+
+	ws.packages = make(map[string]*pkg)
+	for dir, source := range ws.dirs {
+		p := &pkg{path: ws.importPath(dir), dir: dir, imports: make(map[string]bool)}
+
+		p.name = packageName(source.files)
+		for _, file := range source.files {
+			if !file.matched {
+				continue
+			}
+			switch name := file.ast.Name.Name; {
+			case name == p.name && !file.test:
+				p.files = append(p.files, file)
+			case name == p.name:
+				p.testFiles = append(p.testFiles, file)
+			case name == p.name+"_test" && file.test:
+				p.xtestFiles = append(p.xtestFiles, file)
+			default:
+				continue
+			}
+			for _, spec := range file.ast.Imports {
+				p.imports[strings.Trim(spec.Path.Value, "`\"")] = true
+			}
+		}
+		if p.name != "" {
+			ws.packages[p.path] = p
+		}
+	}
+	return ws.packages
+}
+
+// sortedPackages returns the packages of the workspace ordered by import path
+func (ws *workspace) sortedPackages() []*pkg {
+	packages := ws.allPackages()
+	sorted := make([]*pkg, 0, len(packages))
+	for _, p := range packages {
+		sorted = append(sorted, p)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].path < sorted[j].path })
+	return sorted
+}
+
+// fileOf returns the parsed file at an absolute path
+func (ws *workspace) fileOf(path string) (*sourceFile, bool) {
+	if source, ok := ws.dirs[filepath.Dir(path)]; ok {
+		for _, file := range source.files {
+			if file.path == path {
+				return file, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// packageOf returns the package and variant a file is type checked in
+func (ws *workspace) packageOf(file *sourceFile) (*pkg, variant, bool) {
+	p, ok := ws.allPackages()[ws.importPath(filepath.Dir(file.path))]
+	if !ok {
+		return nil, 0, false
+	}
+	for v, files := range [][]*sourceFile{p.files, p.testFiles, p.xtestFiles} {
+		for _, f := range files {
+			if f == file {
+				return p, variant(v), true
+			}
+		}
+	}
+	return nil, 0, false
+}
+
+// check type checks a variant of a package. Type errors do not stop the
+// check: the information gathered is still used.
+func (ws *workspace) check(p *pkg, v variant) *checkedPackage {
+	if c := p.checked[v]; c != nil {
+		return c
+	}
+
+	var files []*sourceFile
+	path := p.path
+	switch v {
+	case libVariant:
+		files = p.files
+	case testVariant:
+		files = append(append([]*sourceFile{}, p.files...), p.testFiles...)
+	case xtestVariant:
+		files = p.xtestFiles
+		path += "_test"
+	}
+
+	asts := make([]*ast.File, len(files))
+	for i, file := range files {
+		asts[i] = file.ast
+	}
+
+	info := &types.Info{
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	config := types.Config{
+		Importer:    ws.importer(p, v),
+		Error:       func(error) {},
+		FakeImportC: true,
+	}
+
+	p.checking[v] = true
+	checked, _ := config.Check(path, ws.fset, asts, info)
+	p.checking[v] = false
+
+	c := &checkedPackage{types: checked, info: info, files: files}
+	p.checked[v] = c
+	return c
+}
+
+// importer resolves the imports of a package variant: workspace packages
+// from the index, others from source
+func (ws *workspace) importer(from *pkg, v variant) types.Importer {
+	return importerFunc(func(path string) (*types.Package, error) {
+		if path == "unsafe" {
+			return types.Unsafe, nil
+		}
+
+		// An external test package sees the package with its in-package tests
+		if v == xtestVariant && path == from.path {
+			return ws.check(from, testVariant).types, nil
+		}
+
+		if p, ok := ws.allPackages()[path]; ok {
+			if p.checking[libVariant] {
+				return nil, fmt.Errorf("import cycle through %s", path)
+			}
+			return ws.check(p, libVariant).types, nil
+		}
+		if ws.exportFile(path) != "" {
+			return ws.compiled.ImportFrom(path, from.dir, 0)
+		}
+		return ws.source.ImportFrom(path, from.dir, 0)
+	})
+}
+
+// exportFile returns the file holding the compiler's export data for a
+// dependency of the workspace, or "" if there is none. The files are listed
+// by go list on first use; building them is what makes importing large
+// dependencies fast, where type checking their source could take minutes.
+func (ws *workspace) exportFile(path string) string {
+	if ws.exports == nil {
+		ws.exports = make(map[string]string)
+		if ws.module == "" {
+			return ""
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "go", "list", "-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}", "./...")
+		cmd.Dir = ws.root
+		out, _ := cmd.Output()
+		for _, line := range strings.Split(string(out), "\n") {
+			if importPath, file, ok := strings.Cut(line, "\t"); ok && file != "" {
+				ws.exports[importPath] = file
+			}
+		}
+	}
+	return ws.exports[path]
+}
+
+// openExport opens the export data of a dependency for the gc importer
+func (ws *workspace) openExport(path string) (io.ReadCloser, error) {
+	file := ws.exportFile(path)
+	if file == "" {
+		return nil, fmt.Errorf("no export data for %s", path)
+	}
+	return os.Open(file)
+}
+
+// importerFunc adapts a function to types.Importer
+type importerFunc func(path string) (*types.Package, error)
+
+// Import implements types.Importer
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+// dependents returns the workspace packages that import path, directly or
+// through other workspace packages, including the package itself
+func (ws *workspace) dependents(path string) []*pkg {
+	reached := map[string]bool{path: true}
+	for grew := true; grew; {
+		grew = false
+		for _, p := range ws.allPackages() {
+			if reached[p.path] {
+				continue
+			}
+			for imported := range p.imports {
+				if reached[imported] {
+					reached[p.path] = true
+					grew = true
+					break
+				}
+			}
+		}
+	}
+
+	var packages []*pkg
+	for _, p := range ws.sortedPackages() {
+		if reached[p.path] {
+			packages = append(packages, p)
+		}
+	}
+	return packages
+}
+
+// dirOrCurrent returns dir, or the current directory if dir is empty
+func dirOrCurrent(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
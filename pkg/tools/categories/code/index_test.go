@@ -0,0 +1,220 @@
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shopModule is a small module with a package, its importer and both kinds of tests
+var shopModule = map[string]string{
+	"go.mod": "module example.com/shop\n\ngo 1.21\n",
+	"store/store.go": `// Package store keeps items in memory.
+package store
+
+import "errors"
+
+// ErrNotFound is returned when an item does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Store holds items by key. It is safe for use by one goroutine.
+type Store struct {
+	items map[string]int // Items by key
+}
+
+// New creates an empty store.
+func New() *Store {
+	return &Store{items: make(map[string]int)}
+}
+
+// Get returns the item stored under key.
+//
+// It fails with ErrNotFound.
+func (s *Store) Get(key string) (int, error) {
+	v, ok := s.items[key]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return v, nil
+}
+
+// Put stores an item.
+func (s *Store) Put(key string, value int) {
+	s.items[key] = value
+}
+
+const maxItems = 10
+`,
+	"store/store_test.go": `package store
+
+import "testing"
+
+func TestGet(t *testing.T) {
+	s := New()
+	s.Put("a", 1)
+	if _, err := s.Get("b"); err != ErrNotFound {
+		t.Fatal(err)
+	}
+}
+`,
+	"store/example_test.go": `package store_test
+
+import "example.com/shop/store"
+
+func ExampleStore_Get() {
+	s := store.New()
+	s.Get("a")
+}
+`,
+	"api/api.go": `package api
+
+import (
+	"fmt"
+
+	"example.com/shop/store"
+)
+
+// Describe formats the item under key.
+func Describe(s *store.Store, key string) string {
+	v, err := s.Get(key)
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprint(v)
+}
+`,
+	"api/api_tagged.go":   "//go:build never\n\npackage api\n\nfunc Tagged() {}\n",
+	"testdata/ignored.go": "package ignored\n",
+}
+
+// testIndex is shared by tests that type check, so the standard library is imported once
+var testIndex = NewIndex()
+
+// writeModule writes files to a temporary directory and returns it
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+	return dir
+}
+
+// writeFile creates or replaces a file
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// runTool executes a tool with params and returns its result
+func runTool(t *testing.T, tool core.Tool, params interface{}) interface{} {
+	t.Helper()
+
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	result, err := tool.Execute(context.Background(), input)
+	require.NoError(t, err)
+	return result
+}
+
+func TestIndexGroupsPackages(t *testing.T) {
+	dir := writeModule(t, shopModule)
+
+	err := NewIndex().with(dir, func(ws *workspace) error {
+		assert.Equal(t, "example.com/shop", ws.module)
+		packages := ws.allPackages()
+		require.Len(t, packages, 2, "testdata is not part of the workspace")
+
+		store := packages["example.com/shop/store"]
+		require.NotNil(t, store)
+		assert.Equal(t, "store", store.name)
+		assert.Len(t, store.files, 1)
+		assert.Len(t, store.testFiles, 1)
+		assert.Len(t, store.xtestFiles, 1)
+
+		api := packages["example.com/shop/api"]
+		require.NotNil(t, api)
+		assert.Len(t, api.files, 1, "files excluded by build constraints are not type checked")
+		assert.True(t, api.imports["example.com/shop/store"])
+
+		var dependents []string
+		for _, p := range ws.dependents("example.com/shop/store") {
+			dependents = append(dependents, p.path)
+		}
+		assert.Equal(t, []string{"example.com/shop/api", "example.com/shop/store"}, dependents)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestIndexUpdatesWhenFilesChange(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	index := NewIndex()
+
+	var first *pkg
+	require.NoError(t, index.with(dir, func(ws *workspace) error {
+		first = ws.allPackages()["example.com/shop/store"]
+		assert.NotNil(t, ws.check(first, libVariant).types.Scope().Lookup("New"))
+		return nil
+	}))
+
+	// An unchanged workspace keeps its packages and their type information
+	require.NoError(t, index.with(dir, func(ws *workspace) error {
+		assert.Same(t, first, ws.allPackages()["example.com/shop/store"])
+		return nil
+	}))
+
+	writeFile(t, filepath.Join(dir, "store", "extra.go"), "package store\n\nfunc Extra() {}\n")
+	require.NoError(t, index.with(dir, func(ws *workspace) error {
+		p := ws.allPackages()["example.com/shop/store"]
+		assert.NotSame(t, first, p)
+		assert.NotNil(t, ws.check(p, libVariant).types.Scope().Lookup("Extra"))
+		return nil
+	}))
+
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "api")))
+	require.NoError(t, index.with(dir, func(ws *workspace) error {
+		assert.NotContains(t, ws.allPackages(), "example.com/shop/api")
+		return nil
+	}))
+}
+
+func TestIndexOutsideAModule(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"main.go":     "package main\n\nfunc main() {}\n",
+		"sub/sub.go":  "package sub\n",
+		"other/x.txt": "not go",
+	})
+
+	err := NewIndex().with(dir, func(ws *workspace) error {
+		assert.Empty(t, ws.module)
+		packages := ws.allPackages()
+		require.Len(t, packages, 1, "only the directory itself is indexed")
+		assert.Equal(t, "main", packages["."].name)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestReadModulePath(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "// comment\nmodule \"example.com/quoted\" // trailing\n\ngo 1.21\n",
+	})
+
+	module, ok := readModulePath(filepath.Join(dir, "go.mod"))
+	assert.True(t, ok)
+	assert.Equal(t, "example.com/quoted", module)
+
+	_, ok = readModulePath(filepath.Join(dir, "missing.mod"))
+	assert.False(t, ok)
+}
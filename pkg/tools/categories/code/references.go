@@ -0,0 +1,196 @@
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// defaultMaxReferences is the number of references returned by default
+const defaultMaxReferences = 100
+
+// maxReferences is the largest number of references a search may return
+const maxReferences = 1000
+
+// ReferencesTool finds the uses of a declaration across the workspace
+type ReferencesTool struct {
+	core.BaseToolImpl
+	index *Index
+}
+
+// ReferencesInput represents parameters for the code_references tool
+type ReferencesInput struct {
+	Target
+	IncludeDeclaration bool `json:"include_declaration,omitempty"`
+	MaxResults         int  `json:"max_results,omitempty"`
+}
+
+// Reference is a use of a declaration
+type Reference struct {
+	Location
+	Text string `json:"text"` // The line of the reference
+}
+
+// ReferencesResult lists the references to a declaration
+type ReferencesResult struct {
+	Symbol     Symbol      `json:"symbol"`
+	References []Reference `json:"references"`
+	Count      int         `json:"count"`
+	Truncated  bool        `json:"truncated,omitempty"`
+}
+
+// NewReferencesTool creates a new code_references tool
+func NewReferencesTool(index *Index) *ReferencesTool {
+	tool := &ReferencesTool{index: index}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"code_references",
+		"Find every reference to a Go declaration across the workspace, including tests, given an identifier's "+
+			"file and line or a symbol such as 'Store.Get'. Unlike grep, only uses of that exact declaration match.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": targetProperties(map[string]interface{}{
+				"include_declaration": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also return the declaration itself",
+				},
+				"max_results": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of references to return (default %d, at most %d)", defaultMaxReferences, maxReferences),
+					"minimum":     1,
+				},
+			}),
+		},
+	)
+	return tool
+}
+
+// Execute finds the references
+func (t *ReferencesTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params ReferencesInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for code_references tool: %w", err)
+	}
+	if err := params.validate("code_references"); err != nil {
+		return nil, err
+	}
+	limit := params.MaxResults
+	if limit <= 0 {
+		limit = defaultMaxReferences
+	}
+	if limit > maxReferences {
+		limit = maxReferences
+	}
+
+	var result ReferencesResult
+	err := t.index.with(params.Path, func(ws *workspace) error {
+		objects, err := ws.resolve(params.Target)
+		if err != nil {
+			return err
+		}
+		if len(objects) > 1 {
+			return fmt.Errorf("symbol %s is ambiguous: qualify it with its package name", params.Symbol)
+		}
+		obj := objects[0]
+		if !obj.Pos().IsValid() {
+			return fmt.Errorf("%s is predeclared; search for it with grep", obj.Name())
+		}
+
+		positions, err := ws.references(ctx, obj, params.IncludeDeclaration)
+		if err != nil {
+			return err
+		}
+
+		result = ReferencesResult{Symbol: ws.describe(obj), References: []Reference{}, Count: len(positions)}
+		lines := sourceLines{}
+		for _, pos := range positions {
+			if len(result.References) == limit {
+				result.Truncated = true
+				break
+			}
+			location := ws.location(pos)
+			result.References = append(result.References, Reference{
+				Location: location,
+				Text:     lines.line(ws.position(pos).Filename, location.Line),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// references returns the positions of the identifiers that refer to obj,
+// in file order. Only packages that can see obj are type checked.
+func (ws *workspace) references(ctx context.Context, obj types.Object, includeDeclaration bool) ([]token.Pos, error) {
+	declared := obj.Pos()
+	var packages []*pkg
+	if obj.Pkg() != nil {
+		// Declarations in an external test package belong to the package it tests
+		path := obj.Pkg().Path()
+		if _, ok := ws.allPackages()[path]; !ok {
+			path = strings.TrimSuffix(path, "_test")
+		}
+
+		// A declaration inside a function is only visible in its own package
+		if parent := obj.Parent(); parent != nil && parent != obj.Pkg().Scope() {
+			if p, ok := ws.allPackages()[path]; ok {
+				packages = []*pkg{p}
+			}
+		} else {
+			packages = ws.dependents(path)
+		}
+	}
+
+	// A package's test variant repeats its own files, so a position can be seen twice
+	seen := make(map[token.Pos]bool)
+	for _, p := range packages {
+		for _, v := range []variant{libVariant, testVariant, xtestVariant} {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if (v == testVariant && len(p.testFiles) == 0) || (v == xtestVariant && len(p.xtestFiles) == 0) {
+				continue
+			}
+
+			info := ws.check(p, v).info
+			for id, used := range info.Uses {
+				if used.Pos() == declared {
+					seen[id.Pos()] = true
+				}
+			}
+			if includeDeclaration {
+				for id, defined := range info.Defs {
+					if defined != nil && defined.Pos() == declared {
+						seen[id.Pos()] = true
+					}
+				}
+			}
+		}
+	}
+
+	positions := make([]token.Pos, 0, len(seen))
+	for pos := range seen {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		a, b := ws.fset.Position(positions[i]), ws.fset.Position(positions[j])
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return positions[i] < positions[j]
+	})
+	return positions, nil
+}
@@ -0,0 +1,90 @@
+package code
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferencesAcrossPackagesAndTests(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	tool := NewReferencesTool(testIndex)
+
+	result := runTool(t, tool, ReferencesInput{Target: Target{Path: dir, Symbol: "Store.Get"}}).(ReferencesResult)
+	assert.Equal(t, "Get", result.Symbol.Name)
+	assert.Equal(t, 3, result.Count)
+	assert.False(t, result.Truncated)
+
+	var found []string
+	for _, ref := range result.References {
+		rel, err := filepath.Rel(dir, ref.File)
+		require.NoError(t, err)
+		found = append(found, rel+":"+ref.Text)
+	}
+	assert.Equal(t, []string{
+		"api/api.go:v, err := s.Get(key)",
+		"store/example_test.go:s.Get(\"a\")",
+		"store/store_test.go:if _, err := s.Get(\"b\"); err != ErrNotFound {",
+	}, found)
+}
+
+func TestReferencesFromPosition(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	tool := NewReferencesTool(testIndex)
+
+	// ErrNotFound at its declaration, including the declaration itself
+	result := runTool(t, tool, ReferencesInput{
+		Target:             Target{Path: filepath.Join(dir, "store", "store.go"), Line: 7, Name: "ErrNotFound"},
+		IncludeDeclaration: true,
+	}).(ReferencesResult)
+	require.Equal(t, 3, result.Count)
+	assert.Equal(t, 7, result.References[0].Line)
+	assert.Equal(t, 25, result.References[1].Line)
+	assert.Equal(t, filepath.Join(dir, "store", "store_test.go"), result.References[2].File)
+
+	// A local variable
+	result = runTool(t, tool, ReferencesInput{Target: Target{Path: filepath.Join(dir, "api", "api.go"), Line: 11, Name: "err"}}).(ReferencesResult)
+	assert.Equal(t, 2, result.Count)
+}
+
+func TestReferencesLimit(t *testing.T) {
+	dir := writeModule(t, shopModule)
+
+	result := runTool(t, NewReferencesTool(testIndex), ReferencesInput{Target: Target{Path: dir, Symbol: "New"}, MaxResults: 1}).(ReferencesResult)
+	assert.Equal(t, 2, result.Count)
+	assert.Len(t, result.References, 1)
+	assert.True(t, result.Truncated)
+}
+
+func TestReferencesSeeChanges(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	tool := NewReferencesTool(testIndex)
+
+	result := runTool(t, tool, ReferencesInput{Target: Target{Path: dir, Symbol: "Store.Put"}}).(ReferencesResult)
+	assert.Equal(t, 1, result.Count)
+
+	writeFile(t, filepath.Join(dir, "api", "fill.go"), "package api\n\nimport \"example.com/shop/store\"\n\nfunc Fill(s *store.Store) {\n\ts.Put(\"x\", 1)\n\ts.Put(\"y\", 2)\n}\n")
+	result = runTool(t, tool, ReferencesInput{Target: Target{Path: dir, Symbol: "Store.Put"}}).(ReferencesResult)
+	assert.Equal(t, 3, result.Count)
+}
+
+func TestReferencesRejectAmbiguousSymbols(t *testing.T) {
+	files := map[string]string{
+		"go.mod":  "module example.com/twice\n",
+		"a/a.go":  "package a\n\nfunc Run() {}\n",
+		"b/b.go":  "package b\n\nfunc Run() {}\n",
+		"main.go": "package main\n\nimport (\n\t\"example.com/twice/a\"\n\t\"example.com/twice/b\"\n)\n\nfunc main() {\n\ta.Run()\n\tb.Run()\n}\n",
+	}
+	dir := writeModule(t, files)
+	tool := NewReferencesTool(testIndex)
+
+	_, err := tool.Execute(context.Background(), mustMarshal(t, ReferencesInput{Target: Target{Path: dir, Symbol: "Run"}}))
+	assert.ErrorContains(t, err, "ambiguous")
+
+	result := runTool(t, tool, ReferencesInput{Target: Target{Path: dir, Symbol: "b.Run"}}).(ReferencesResult)
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, 10, result.References[0].Line)
+}
@@ -0,0 +1,24 @@
+package code
+
+import (
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// Register registers code tools with the registry
+func Register(registry core.ToolRegistrar) error {
+	// The tools share one index, so each workspace is only parsed once
+	index := NewIndex()
+	tools := []core.Tool{
+		NewSymbolsTool(index),
+		NewDefinitionTool(index),
+		NewReferencesTool(index),
+		NewSignatureTool(index),
+	}
+
+	for _, tool := range tools {
+		if err := registry.RegisterTool(CategoryID, tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
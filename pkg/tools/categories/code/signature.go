@@ -0,0 +1,131 @@
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/types"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// SignatureTool shows the signature and documentation of a declaration
+type SignatureTool struct {
+	core.BaseToolImpl
+	index *Index
+}
+
+// Param is a parameter or result of a function
+type Param struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+}
+
+// SignatureResult describes a declaration in full
+type SignatureResult struct {
+	Symbol
+	Doc      string   `json:"doc,omitempty"`      // The whole doc comment
+	Receiver *Param   `json:"receiver,omitempty"` // For methods
+	Params   []Param  `json:"params,omitempty"`   // For functions and methods
+	Results  []Param  `json:"results,omitempty"`
+	Variadic bool     `json:"variadic,omitempty"` // The last parameter is variadic
+	Methods  []string `json:"methods,omitempty"`  // For types, the signatures of their methods
+}
+
+// SignatureResults lists the declarations a target refers to; a symbol may match several
+type SignatureResults struct {
+	Signatures []SignatureResult `json:"signatures"`
+}
+
+// NewSignatureTool creates a new code_signature tool
+func NewSignatureTool(index *Index) *SignatureTool {
+	tool := &SignatureTool{index: index}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"code_signature",
+		"Show the signature and full doc comment of a Go function, method, type, variable or constant, "+
+			"given an identifier's file and line or a symbol such as 'Store.Get'. "+
+			"Functions list their parameters and results; types list their methods.",
+		CategoryID,
+		map[string]interface{}{
+			"type":       "object",
+			"properties": targetProperties(nil),
+		},
+	)
+	return tool
+}
+
+// Execute describes the declarations
+func (t *SignatureTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params Target
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for code_signature tool: %w", err)
+	}
+	if err := params.validate("code_signature"); err != nil {
+		return nil, err
+	}
+
+	result := SignatureResults{Signatures: []SignatureResult{}}
+	err := t.index.with(params.Path, func(ws *workspace) error {
+		objects, err := ws.resolve(params)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objects {
+			result.Signatures = append(result.Signatures, ws.signature(obj))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// signature describes an object with its documentation and, for functions
+// and types, their parameters and methods
+func (ws *workspace) signature(obj types.Object) SignatureResult {
+	result := SignatureResult{Symbol: ws.describe(obj), Doc: ws.docOf(obj)}
+	qualify := qualifier(obj.Pkg())
+
+	switch obj := obj.(type) {
+	case *types.Func:
+		sig, ok := obj.Type().(*types.Signature)
+		if !ok {
+			break
+		}
+		if recv := sig.Recv(); recv != nil {
+			result.Receiver = &Param{Name: recv.Name(), Type: types.TypeString(recv.Type(), qualify)}
+		}
+		result.Params = params(sig.Params(), qualify)
+		result.Results = params(sig.Results(), qualify)
+		result.Variadic = sig.Variadic()
+
+	case *types.TypeName:
+		// The methods of *T include those of T
+		methods := types.NewMethodSet(types.NewPointer(obj.Type()))
+		if _, ok := obj.Type().Underlying().(*types.Interface); ok {
+			methods = types.NewMethodSet(obj.Type())
+		}
+		for i := 0; i < methods.Len(); i++ {
+			method := methods.At(i).Obj()
+			if method.Exported() || method.Pkg() == obj.Pkg() {
+				result.Methods = append(result.Methods, types.ObjectString(method, qualify))
+			}
+		}
+	}
+	return result
+}
+
+// params describes the variables of a parameter or result list
+func params(tuple *types.Tuple, qualify types.Qualifier) []Param {
+	var list []Param
+	for i := 0; i < tuple.Len(); i++ {
+		v := tuple.At(i)
+		list = append(list, Param{Name: v.Name(), Type: types.TypeString(v.Type(), qualify)})
+	}
+	return list
+}
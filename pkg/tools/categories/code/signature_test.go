@@ -0,0 +1,50 @@
+package code
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureOfAMethod(t *testing.T) {
+	dir := writeModule(t, shopModule)
+
+	result := runTool(t, NewSignatureTool(testIndex), Target{Path: filepath.Join(dir, "api", "api.go"), Line: 11, Name: "Get"}).(SignatureResults)
+	require.Len(t, result.Signatures, 1)
+	signature := result.Signatures[0]
+	assert.Equal(t, "func (*Store).Get(key string) (int, error)", signature.Signature)
+	assert.Equal(t, "Get returns the item stored under key.\n\nIt fails with ErrNotFound.", signature.Doc)
+	assert.Equal(t, &Param{Name: "s", Type: "*Store"}, signature.Receiver)
+	assert.Equal(t, []Param{{Name: "key", Type: "string"}}, signature.Params)
+	assert.Equal(t, []Param{{Type: "int"}, {Type: "error"}}, signature.Results)
+	assert.False(t, signature.Variadic)
+}
+
+func TestSignatureOfAType(t *testing.T) {
+	dir := writeModule(t, shopModule)
+
+	result := runTool(t, NewSignatureTool(testIndex), Target{Path: dir, Symbol: "store.Store"}).(SignatureResults)
+	require.Len(t, result.Signatures, 1)
+	signature := result.Signatures[0]
+	assert.Equal(t, "type", signature.Kind)
+	assert.Equal(t, "example.com/shop/store", signature.Package)
+	assert.Equal(t, "type Store struct{items map[string]int}", signature.Signature)
+	assert.Equal(t, "Store holds items by key. It is safe for use by one goroutine.", signature.Doc)
+	assert.Equal(t, []string{
+		"func (*Store).Get(key string) (int, error)",
+		"func (*Store).Put(key string, value int)",
+	}, signature.Methods)
+	assert.Empty(t, signature.Params)
+}
+
+func TestSignatureQualifiesOtherPackages(t *testing.T) {
+	dir := writeModule(t, shopModule)
+
+	result := runTool(t, NewSignatureTool(testIndex), Target{Path: dir, Symbol: "Describe"}).(SignatureResults)
+	require.Len(t, result.Signatures, 1)
+	signature := result.Signatures[0]
+	assert.Equal(t, "func Describe(s *store.Store, key string) string", signature.Signature)
+	assert.Equal(t, []Param{{Name: "s", Type: "*store.Store"}, {Name: "key", Type: "string"}}, signature.Params)
+}
@@ -0,0 +1,275 @@
+package code
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// maxValueLength is the longest constant value shown in a symbol's signature
+const maxValueLength = 40
+
+// SymbolsTool lists the declarations of a file or package
+type SymbolsTool struct {
+	core.BaseToolImpl
+	index *Index
+}
+
+// SymbolsInput represents parameters for the code_symbols tool
+type SymbolsInput struct {
+	Path         string `json:"path,omitempty"`
+	ExportedOnly bool   `json:"exported_only,omitempty"`
+	IncludeTests bool   `json:"include_tests,omitempty"`
+}
+
+// PackageSymbol is a package-level declaration
+type PackageSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`               // func, method, type, interface, var or const
+	Receiver  string `json:"receiver,omitempty"` // The receiver type of a method
+	Signature string `json:"signature"`
+	Doc       string `json:"doc,omitempty"` // The first sentence of its doc comment
+	Location
+}
+
+// SymbolsResult lists the declarations of each package
+type SymbolsResult struct {
+	Packages []PackageSymbols `json:"packages"`
+}
+
+// PackageSymbols are the declarations of one package
+type PackageSymbols struct {
+	Name    string          `json:"name"`
+	Path    string          `json:"path"`
+	Symbols []PackageSymbol `json:"symbols"`
+}
+
+// NewSymbolsTool creates a new code_symbols tool
+func NewSymbolsTool(index *Index) *SymbolsTool {
+	tool := &SymbolsTool{index: index}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"code_symbols",
+		"List the package-level declarations of a Go file or directory: functions, methods, types, "+
+			"variables and constants with their signatures, locations and doc summaries.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "A Go file, or a directory whose package to list (default current directory)",
+				},
+				"exported_only": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Only list exported declarations",
+				},
+				"include_tests": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also list the declarations of _test.go files in a directory",
+				},
+			},
+		},
+	)
+	return tool
+}
+
+// Execute lists the declarations
+func (t *SymbolsTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params SymbolsInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for code_symbols tool: %w", err)
+	}
+
+	abs, err := filepath.Abs(dirOrCurrent(params.Path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %s: %w", params.Path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access %s: %w", params.Path, err)
+	}
+
+	result := SymbolsResult{Packages: []PackageSymbols{}}
+	err = t.index.with(abs, func(ws *workspace) error {
+		var files []*sourceFile
+		if info.IsDir() {
+			if source, ok := ws.dirs[abs]; ok {
+				for _, file := range source.files {
+					if params.IncludeTests || !file.test {
+						files = append(files, file)
+					}
+				}
+			}
+		} else if file, ok := ws.fileOf(abs); ok {
+			files = []*sourceFile{file}
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no Go files in %s", dirOrCurrent(params.Path))
+		}
+
+		// Files of the same package are listed together
+		byName := make(map[string]int)
+		for _, file := range files {
+			name := file.ast.Name.Name
+			i, ok := byName[name]
+			if !ok {
+				i = len(result.Packages)
+				byName[name] = i
+				path := ws.importPath(filepath.Dir(file.path))
+				if file.test && strings.HasSuffix(name, "_test") {
+					path += "_test"
+				}
+				result.Packages = append(result.Packages, PackageSymbols{Name: name, Path: path, Symbols: []PackageSymbol{}})
+			}
+			symbols := ws.fileSymbols(file.ast, params.ExportedOnly)
+			result.Packages[i].Symbols = append(result.Packages[i].Symbols, symbols...)
+		}
+
+		// A package comes before its external tests
+		sort.SliceStable(result.Packages, func(i, j int) bool {
+			return result.Packages[i].Name < result.Packages[j].Name
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fileSymbols returns the package-level declarations of a file, in order
+func (ws *workspace) fileSymbols(file *ast.File, exportedOnly bool) []PackageSymbol {
+	var symbols []PackageSymbol
+	add := func(name *ast.Ident, kind, receiver, signature string, doc *ast.CommentGroup) {
+		if exportedOnly && !name.IsExported() {
+			return
+		}
+		symbols = append(symbols, PackageSymbol{
+			Name:      name.Name,
+			Kind:      kind,
+			Receiver:  receiver,
+			Signature: signature,
+			Doc:       summary(doc.Text()),
+			Location:  ws.location(name.Pos()),
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			header := *decl
+			header.Body = nil
+			header.Doc = nil
+			if decl.Recv == nil {
+				add(decl.Name, "func", "", ws.print(&header), decl.Doc)
+			} else {
+				add(decl.Name, "method", receiverType(decl.Recv), ws.print(&header), decl.Doc)
+			}
+
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					doc := firstComment(spec.Doc, singleSpecDoc(decl), spec.Comment)
+					kind := "type"
+					if _, ok := spec.Type.(*ast.InterfaceType); ok {
+						kind = "interface"
+					}
+					add(spec.Name, kind, "", ws.typeSignature(spec), doc)
+
+				case *ast.ValueSpec:
+					doc := firstComment(spec.Doc, singleSpecDoc(decl), spec.Comment)
+					for i, name := range spec.Names {
+						add(name, decl.Tok.String(), "", ws.valueSignature(decl.Tok, spec, i), doc)
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// singleSpecDoc returns the doc comment of a declaration that declares a
+// single spec, which documents that spec
+func singleSpecDoc(decl *ast.GenDecl) *ast.CommentGroup {
+	if decl.Lparen.IsValid() {
+		return nil
+	}
+	return decl.Doc
+}
+
+// typeSignature renders a type declaration, eliding the members of structs and interfaces
+func (ws *workspace) typeSignature(spec *ast.TypeSpec) string {
+	header := *spec
+	header.Doc = nil
+	header.Comment = nil
+	switch spec.Type.(type) {
+	case *ast.StructType:
+		header.Type = &ast.Ident{Name: "struct{...}"}
+	case *ast.InterfaceType:
+		header.Type = &ast.Ident{Name: "interface{...}"}
+	}
+	return "type " + ws.print(&header)
+}
+
+// valueSignature renders the i-th name of a var or const declaration, with
+// its type and, for short constants, its value
+func (ws *workspace) valueSignature(tok token.Token, spec *ast.ValueSpec, i int) string {
+	signature := tok.String() + " " + spec.Names[i].Name
+	if spec.Type != nil {
+		signature += " " + ws.print(spec.Type)
+	}
+	if tok == token.CONST && i < len(spec.Values) {
+		if value := ws.print(spec.Values[i]); len(value) <= maxValueLength {
+			signature += " = " + value
+		}
+	}
+	return signature
+}
+
+// receiverType returns the name of a method's receiver type, without pointer or type parameters
+func receiverType(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// print renders a syntax node as source
+func (ws *workspace) print(node interface{}) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, ws.fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,78 @@
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolsOfAPackage(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	tool := NewSymbolsTool(NewIndex())
+
+	result := runTool(t, tool, SymbolsInput{Path: filepath.Join(dir, "store")}).(SymbolsResult)
+	require.Len(t, result.Packages, 1)
+	assert.Equal(t, "store", result.Packages[0].Name)
+	assert.Equal(t, "example.com/shop/store", result.Packages[0].Path)
+
+	symbols := make(map[string]PackageSymbol)
+	for _, symbol := range result.Packages[0].Symbols {
+		symbols[symbol.Name] = symbol
+	}
+	assert.Len(t, symbols, 6)
+	assert.NotContains(t, symbols, "TestGet", "tests are left out by default")
+
+	get := symbols["Get"]
+	assert.Equal(t, "method", get.Kind)
+	assert.Equal(t, "Store", get.Receiver)
+	assert.Equal(t, "func (s *Store) Get(key string) (int, error)", get.Signature)
+	assert.Equal(t, "Get returns the item stored under key.", get.Doc)
+	assert.Equal(t, filepath.Join(dir, "store", "store.go"), get.File)
+	assert.Equal(t, 22, get.Line)
+
+	store := symbols["Store"]
+	assert.Equal(t, "type", store.Kind)
+	assert.Equal(t, "type Store struct{...}", store.Signature)
+	assert.Equal(t, "Store holds items by key.", store.Doc)
+
+	assert.Equal(t, "var ErrNotFound", symbols["ErrNotFound"].Signature)
+	assert.Equal(t, "const maxItems = 10", symbols["maxItems"].Signature)
+	assert.Equal(t, "func New() *Store", symbols["New"].Signature)
+}
+
+func TestSymbolsExportedOnlyWithTests(t *testing.T) {
+	dir := writeModule(t, shopModule)
+	tool := NewSymbolsTool(NewIndex())
+
+	result := runTool(t, tool, SymbolsInput{Path: filepath.Join(dir, "store"), ExportedOnly: true, IncludeTests: true}).(SymbolsResult)
+	require.Len(t, result.Packages, 2, "the external test package is listed separately")
+
+	var names []string
+	for _, symbol := range result.Packages[0].Symbols {
+		names = append(names, symbol.Name)
+	}
+	assert.ElementsMatch(t, []string{"ErrNotFound", "Store", "New", "Get", "Put", "TestGet"}, names)
+	assert.Equal(t, "store_test", result.Packages[1].Name)
+	assert.Equal(t, "example.com/shop/store_test", result.Packages[1].Path)
+	assert.Equal(t, "ExampleStore_Get", result.Packages[1].Symbols[0].Name)
+}
+
+func TestSymbolsOfAFile(t *testing.T) {
+	dir := writeModule(t, shopModule)
+
+	result := runTool(t, NewSymbolsTool(NewIndex()), SymbolsInput{Path: filepath.Join(dir, "api", "api_tagged.go")}).(SymbolsResult)
+	require.Len(t, result.Packages, 1)
+	require.Len(t, result.Packages[0].Symbols, 1, "files excluded by build constraints are listed")
+	assert.Equal(t, "Tagged", result.Packages[0].Symbols[0].Name)
+}
+
+func TestSymbolsWithoutGoFiles(t *testing.T) {
+	dir := writeModule(t, map[string]string{"go.mod": "module example.com/empty\n", "README": "hi"})
+
+	_, err := NewSymbolsTool(NewIndex()).Execute(context.Background(), json.RawMessage(`{"path": "`+dir+`"}`))
+	assert.ErrorContains(t, err, "no Go files")
+}
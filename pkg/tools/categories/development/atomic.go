@@ -0,0 +1,99 @@
+package development
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// stagedWrite is new content for a file, written to a temporary file next to
+// it until commit moves it into place
+type stagedWrite struct {
+	target string // Real path of the file, with symbolic links resolved
+	temp   string // Temporary file holding the content, or "" to write in place
+	data   []byte
+	perm   os.FileMode
+}
+
+// stageWrite writes data to a temporary file in the directory of the file
+// path refers to. Symbolic links are followed so that the file they point
+// to is replaced rather than the link. When the temporary file cannot be
+// given the owner and extended attributes of the existing file, the content
+// is kept to be written in place instead.
+func stageWrite(path string, data []byte, perm os.FileMode) (*stagedWrite, error) {
+	target := path
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		target = real
+	}
+	s := &stagedWrite{target: target, data: data, perm: perm}
+
+	temp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(temp.Name(), perm)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if info, err := os.Stat(target); err == nil {
+		if err := copyFileAttrs(target, temp.Name(), info); err != nil {
+			os.Remove(temp.Name())
+			return s, nil
+		}
+	}
+	s.temp = temp.Name()
+	return s, nil
+}
+
+// commit moves the staged content into place
+func (s *stagedWrite) commit() error {
+	if s.temp == "" {
+		if err := os.WriteFile(s.target, s.data, s.perm); err != nil {
+			return fmt.Errorf("failed to write %s: %w", s.target, err)
+		}
+		return os.Chmod(s.target, s.perm)
+	}
+	if err := os.Rename(s.temp, s.target); err != nil {
+		os.Remove(s.temp)
+		return fmt.Errorf("failed to write %s: %w", s.target, err)
+	}
+	return nil
+}
+
+// discard removes the temporary file of a write that is not committed
+func (s *stagedWrite) discard() {
+	if s.temp != "" {
+		os.Remove(s.temp)
+	}
+}
+
+// writtenPaths returns path and, if it is a symbolic link, the file that
+// stageWrite replaces through it, so checkpoints cover both
+func writtenPaths(path string) []string {
+	if info, err := os.Lstat(path); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return []string{path}
+	}
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return []string{path, real}
+	}
+	return []string{path}
+}
+
+// writeFileAtomic replaces a file with data by writing a temporary file in
+// the same directory and renaming it over the original, so readers never see
+// a partly written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	s, err := stageWrite(path, data, perm)
+	if err != nil {
+		return err
+	}
+	return s.commit()
+}
@@ -0,0 +1,68 @@
+package development
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolsHonorCancellation(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "file.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("hello\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		tool  core.Tool
+		input map[string]interface{}
+	}{
+		{NewDiffTool(), map[string]interface{}{"mode": "string", "original": "a\n", "modified": "b\n"}},
+		{NewFileWriteTool(), map[string]interface{}{"path": filePath, "content": "changed\n"}},
+		{NewPatchTool(), map[string]interface{}{"mode": "create", "path": filePath, "original": "a\n", "modified": "b\n"}},
+		{NewShellTool(), map[string]interface{}{"command": "echo", "args": []string{"hello"}}},
+		{NewShellSessionTool(), map[string]interface{}{"command": "echo hello"}},
+		{newJobStartTool(NewJobManager()), map[string]interface{}{"command": "echo hello"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tool.Name(), func(t *testing.T) {
+			input, err := json.Marshal(tc.input)
+			require.NoError(t, err)
+
+			_, err = tc.tool.Execute(ctx, input)
+			assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled, got %v", err)
+		})
+	}
+
+	// The cancelled write must not have modified the file
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(content))
+}
+
+func TestShellKilledOnDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	input, err := json.Marshal(ShellInput{Command: "sleep", Args: []string{"10"}, TimeoutSecs: 30})
+	require.NoError(t, err)
+
+	start := time.Now()
+	result, err := NewShellTool().Execute(ctx, input)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected context.DeadlineExceeded, got %v", err)
+	assert.Less(t, time.Since(start), 5*time.Second, "the process should be killed when the deadline expires")
+
+	output, ok := result.(ShellOutput)
+	require.True(t, ok)
+	assert.Equal(t, -1, output.ExitCode)
+}
@@ -1,28 +1,59 @@
 package development
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
 
+	"github.com/navicore/mcpterm-go/pkg/diff"
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
 )
 
+// maxDiffOutputBytes is the amount of diff text returned by the diff tool
+const maxDiffOutputBytes = 256 * 1024
+
 // DiffInput represents parameters for comparing content
 type DiffInput struct {
-	Mode         string `json:"mode"`                    // "string", "file", or "mixed"
-	Original     string `json:"original,omitempty"`      // Original content or file path (depending on mode)
-	Modified     string `json:"modified,omitempty"`      // Modified content or file path (depending on mode)
-	Context      int    `json:"context,omitempty"`       // Number of context lines (default: 3)
-	OutputFormat string `json:"output_format,omitempty"` // "unified" (default) or "side-by-side"
+	Mode             string `json:"mode"`                         // "string", "file", "mixed" or "directory"
+	Original         string `json:"original,omitempty"`           // Original content or path (depending on mode)
+	Modified         string `json:"modified,omitempty"`           // Modified content or path (depending on mode)
+	Context          int    `json:"context,omitempty"`            // Number of context lines (default: 3)
+	OutputFormat     string `json:"output_format,omitempty"`      // "unified" (default), "side-by-side" or "word"
+	Algorithm        string `json:"algorithm,omitempty"`          // "myers" (default) or "patience"
+	IgnoreWhitespace string `json:"ignore_whitespace,omitempty"`  // "trailing", "change" or "all"
+	IgnoreBlankLines bool   `json:"ignore_blank_lines,omitempty"` // Ignore changes that only add or remove blank lines
+	Width            int    `json:"width,omitempty"`              // Line width of side-by-side output (default: 160)
 }
 
 // DiffOutput represents the result of a diff operation
 type DiffOutput struct {
-	DiffExists bool   `json:"diff_exists"` // Whether differences were found
-	DiffOutput string `json:"diff_output"` // Output of the diff operation
+	DiffExists   bool             `json:"diff_exists"`         // Whether differences were found
+	DiffOutput   string           `json:"diff_output"`         // Output of the diff operation
+	LinesAdded   int              `json:"lines_added"`         // Lines only in the modified side
+	LinesRemoved int              `json:"lines_removed"`       // Lines only in the original side
+	Truncated    bool             `json:"truncated,omitempty"` // The diff output was cut short
+	Files        []DiffFileStatus `json:"files,omitempty"`     // Changed files, in directory mode
+	Summary      *DiffSummary     `json:"summary,omitempty"`   // File counts, in directory mode
+}
+
+// DiffFileStatus describes how a file differs between two directories
+type DiffFileStatus struct {
+	Path         string `json:"path"`             // Path relative to the compared directories
+	Status       string `json:"status"`           // "added", "deleted" or "modified"
+	Binary       bool   `json:"binary,omitempty"` // The file is not text, so no lines are compared
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+}
+
+// DiffSummary counts the files of a directory comparison by status
+type DiffSummary struct {
+	Added     int `json:"added"`
+	Deleted   int `json:"deleted"`
+	Modified  int `json:"modified"`
+	Unchanged int `json:"unchanged"`
 }
 
 // DiffTool compares two files or strings and shows their differences
@@ -35,24 +66,24 @@ func NewDiffTool() *DiffTool {
 	tool := &DiffTool{}
 	tool.BaseToolImpl = *core.NewBaseTool(
 		"diff",
-		"Compare two files or strings and show differences",
+		"Compare two strings, files or directories and show the differences as a unified, side-by-side or word-level diff",
 		"development",
 		map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
 				"mode": map[string]interface{}{
 					"type":        "string",
-					"description": "Mode of comparison: 'string' for string-to-string, 'file' for file-to-file, 'mixed' for string-to-file",
-					"enum":        []string{"string", "file", "mixed"},
+					"description": "Mode of comparison: 'string' for string-to-string, 'file' for file-to-file, 'mixed' for file-to-string, 'directory' for directory-to-directory",
+					"enum":        []string{"string", "file", "mixed", "directory"},
 					"default":     "string",
 				},
 				"original": map[string]interface{}{
 					"type":        "string",
-					"description": "Original content (for string mode) or file path (for file mode)",
+					"description": "Original content (for string mode) or path (for file, mixed and directory modes)",
 				},
 				"modified": map[string]interface{}{
 					"type":        "string",
-					"description": "Modified content (for string mode) or file path (for file mode)",
+					"description": "Modified content (for string and mixed modes) or path (for file and directory modes)",
 				},
 				"context": map[string]interface{}{
 					"type":        "integer",
@@ -61,10 +92,28 @@ func NewDiffTool() *DiffTool {
 				},
 				"output_format": map[string]interface{}{
 					"type":        "string",
-					"description": "Output format: 'unified' (default) or 'side-by-side'",
-					"enum":        []string{"unified", "side-by-side"},
+					"description": "Output format: 'unified' (default), 'side-by-side' columns, or 'word' to mark changed words inline as [-removed-]{+added+}",
+					"enum":        []string{"unified", "side-by-side", "word"},
 					"default":     "unified",
 				},
+				"algorithm": map[string]interface{}{
+					"type":        "string",
+					"description": "Diff algorithm: 'myers' (default) finds the fewest changed lines, 'patience' aligns unique lines such as function signatures and often reads better for code",
+					"enum":        []string{"myers", "patience"},
+				},
+				"ignore_whitespace": map[string]interface{}{
+					"type":        "string",
+					"description": "Whitespace differences to ignore: 'trailing' at line ends, 'change' in the amount of whitespace, or 'all' whitespace",
+					"enum":        []string{"none", "trailing", "change", "all"},
+				},
+				"ignore_blank_lines": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Ignore changes that only add or remove blank lines (default: false)",
+				},
+				"width": map[string]interface{}{
+					"type":        "integer",
+					"description": "Line width of side-by-side output (default: 160)",
+				},
 			},
 			"required": []string{"mode", "original", "modified"},
 		},
@@ -73,7 +122,11 @@ func NewDiffTool() *DiffTool {
 }
 
 // Execute implements the Tool interface
-func (t *DiffTool) Execute(input json.RawMessage) (interface{}, error) {
+func (t *DiffTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var params DiffInput
 	if err := json.Unmarshal(input, &params); err != nil {
 		return nil, fmt.Errorf("invalid input for diff tool: %w", err)
@@ -88,10 +141,22 @@ func (t *DiffTool) Execute(input json.RawMessage) (interface{}, error) {
 		params.OutputFormat = "unified" // Default output format
 	}
 
+	if params.Width <= 0 {
+		params.Width = 160
+	}
+
 	// Validate common parameters
 	if params.Original == "" || params.Modified == "" {
 		return nil, fmt.Errorf("both original and modified content/paths are required")
 	}
+	switch params.OutputFormat {
+	case "unified", "side-by-side", "word":
+	default:
+		return nil, fmt.Errorf("invalid output_format %q, must be 'unified', 'side-by-side' or 'word'", params.OutputFormat)
+	}
+	if err := params.options().Validate(); err != nil {
+		return nil, err
+	}
 
 	// Choose operation based on mode
 	switch params.Mode {
@@ -101,134 +166,142 @@ func (t *DiffTool) Execute(input json.RawMessage) (interface{}, error) {
 		return t.diffFiles(params)
 	case "mixed":
 		return t.diffMixed(params)
+	case "directory":
+		return t.diffDirectories(ctx, params)
 	default:
-		return nil, fmt.Errorf("invalid mode %q, must be 'string', 'file', or 'mixed'", params.Mode)
+		return nil, fmt.Errorf("invalid mode %q, must be 'string', 'file', 'mixed' or 'directory'", params.Mode)
+	}
+}
+
+// options returns the comparison options of the input
+func (p DiffInput) options() diff.Options {
+	return diff.Options{
+		Algorithm:        p.Algorithm,
+		IgnoreWhitespace: p.IgnoreWhitespace,
+		IgnoreBlankLines: p.IgnoreBlankLines,
+		Context:          p.Context,
 	}
 }
 
 // diffStrings compares two strings and returns their differences
 func (t *DiffTool) diffStrings(params DiffInput) (interface{}, error) {
-	// Create temporary files for diff
-	origFile, err := os.CreateTemp("", "diff-orig-*.txt")
+	return diffResult(formatDiff("string-a", "string-b", params.Original, params.Modified, params)), nil
+}
+
+// diffFiles compares two files and returns their differences
+func (t *DiffTool) diffFiles(params DiffInput) (interface{}, error) {
+	original, origPath, err := readDiffFile(params.Original, "original")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		return nil, err
 	}
-	defer os.Remove(origFile.Name())
-	defer origFile.Close()
-
-	modFile, err := os.CreateTemp("", "diff-mod-*.txt")
+	modified, modPath, err := readDiffFile(params.Modified, "modified")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+		return nil, err
 	}
-	defer os.Remove(modFile.Name())
-	defer modFile.Close()
 
-	// Write content to temp files
-	if _, err := origFile.WriteString(params.Original); err != nil {
-		return nil, fmt.Errorf("failed to write original content: %w", err)
-	}
+	return diffResult(formatDiff(filepath.Base(origPath), filepath.Base(modPath), original, modified, params)), nil
+}
 
-	if _, err := modFile.WriteString(params.Modified); err != nil {
-		return nil, fmt.Errorf("failed to write modified content: %w", err)
+// diffMixed compares a file with a string and returns their differences
+func (t *DiffTool) diffMixed(params DiffInput) (interface{}, error) {
+	original, filePath, err := readDiffFile(params.Original, "original")
+	if err != nil {
+		return nil, err
 	}
 
-	// Close files to ensure content is flushed
-	origFile.Close()
-	modFile.Close()
-
-	// Run diff command
-	return t.runDiff(origFile.Name(), modFile.Name(), "string-a", "string-b", params)
+	return diffResult(formatDiff(filepath.Base(filePath), "string", original, params.Modified, params)), nil
 }
 
-// diffFiles compares two files and returns their differences
-func (t *DiffTool) diffFiles(params DiffInput) (interface{}, error) {
-	// Get absolute paths
-	origPath, err := filepath.Abs(params.Original)
+// readDiffFile reads one side of a file comparison
+func readDiffFile(path, side string) (string, string, error) {
+	absPath, err := filepath.Abs(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get absolute path for original: %w", err)
+		return "", "", fmt.Errorf("failed to get absolute path for %s: %w", side, err)
+	}
+	info, err := os.Stat(absPath)
+	if os.IsNotExist(err) {
+		return "", "", fmt.Errorf("%s file does not exist: %s", side, absPath)
 	}
-
-	modPath, err := filepath.Abs(params.Modified)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get absolute path for modified: %w", err)
+		return "", "", fmt.Errorf("failed to check %s file: %w", side, err)
 	}
-
-	// Check if files exist
-	if _, err := os.Stat(origPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("original file does not exist: %s", origPath)
+	if info.IsDir() {
+		return "", "", fmt.Errorf("%s is a directory: %s; use directory mode to compare directories", side, absPath)
 	}
-	if _, err := os.Stat(modPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("modified file does not exist: %s", modPath)
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s file: %w", side, err)
 	}
+	return string(content), absPath, nil
+}
 
-	// Run diff command
-	return t.runDiff(origPath, modPath, filepath.Base(origPath), filepath.Base(modPath), params)
+// fileDiff is the formatted comparison of two texts
+type fileDiff struct {
+	text           string
+	added, removed int
 }
 
-// diffMixed compares a file with a string and returns their differences
-func (t *DiffTool) diffMixed(params DiffInput) (interface{}, error) {
-	// Get absolute path for file
-	filePath, err := filepath.Abs(params.Original)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get absolute path for file: %w", err)
+// diffResult turns the comparison of two texts into the tool output
+func diffResult(result fileDiff) DiffOutput {
+	text, truncated := clipDiffOutput(result.text, maxDiffOutputBytes)
+	return DiffOutput{
+		DiffExists:   result.text != "",
+		DiffOutput:   text,
+		LinesAdded:   result.added,
+		LinesRemoved: result.removed,
+		Truncated:    truncated,
 	}
+}
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("file does not exist: %s", filePath)
+// formatDiff compares two texts and formats the differences in the output
+// format of the input. Texts that differ only in ignored ways give no text.
+func formatDiff(fromLabel, toLabel, a, b string, params DiffInput) fileDiff {
+	if a == b {
+		return fileDiff{}
 	}
+	opts := params.options()
+	ops := diff.Compare(diff.SplitLines(a), diff.SplitLines(b), opts)
 
-	// Create temporary file for string content
-	stringFile, err := os.CreateTemp("", "diff-string-*.txt")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	var ignored []bool
+	if opts.IgnoreBlankLines {
+		ignored = diff.BlankChanges(ops)
 	}
-	defer os.Remove(stringFile.Name())
-	defer stringFile.Close()
-
-	// Write string content to temp file
-	if _, err := stringFile.WriteString(params.Modified); err != nil {
-		return nil, fmt.Errorf("failed to write string content: %w", err)
+	var result fileDiff
+	for i, op := range ops {
+		switch {
+		case ignored != nil && ignored[i]:
+		case op.Kind == '+':
+			result.added++
+		case op.Kind == '-':
+			result.removed++
+		}
+	}
+	if result.added+result.removed == 0 {
+		return fileDiff{}
 	}
-	stringFile.Close()
 
-	// Run diff command
-	return t.runDiff(filePath, stringFile.Name(), filepath.Base(filePath), "string", params)
+	var out strings.Builder
+	hunks := diff.Hunks(ops, opts.Context, ignored)
+	switch params.OutputFormat {
+	case "side-by-side":
+		diff.FormatSideBySide(&out, fromLabel, toLabel, ops, hunks, params.Width)
+	case "word":
+		diff.FormatWords(&out, fromLabel, toLabel, ops, hunks)
+	default:
+		diff.FormatUnified(&out, fromLabel, toLabel, ops, hunks)
+	}
+	result.text = out.String()
+	return result
 }
 
-// runDiff executes the diff command and returns its output
-func (t *DiffTool) runDiff(file1, file2, label1, label2 string, params DiffInput) (interface{}, error) {
-	// Build command args
-	args := []string{}
-
-	if params.OutputFormat == "unified" {
-		args = append(args, "-u", fmt.Sprintf("-U%d", params.Context))
-	} else if params.OutputFormat == "side-by-side" {
-		args = append(args, "-y", "-W", "160")
-		// Note: macOS diff doesn't support --left-column option
+// clipDiffOutput cuts diff text to at most limit bytes, at a line boundary
+func clipDiffOutput(text string, limit int) (string, bool) {
+	if len(text) <= limit {
+		return text, false
 	}
-
-	args = append(args, "--label", label1, "--label", label2, file1, file2)
-
-	// Run diff command
-	cmd := exec.Command("diff", args...)
-	output, err := cmd.CombinedOutput()
-
-	// diff returns exit code 1 when files differ, which is what we want
-	diffExists := false
-	if err != nil {
-		if cmd.ProcessState.ExitCode() == 1 {
-			// Files differ - this is normal
-			diffExists = true
-		} else {
-			// Other error occurred
-			return nil, fmt.Errorf("diff command failed: %w: %s", err, string(output))
-		}
+	cut := text[:limit]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i+1]
 	}
-
-	// Return diff output
-	return DiffOutput{
-		DiffExists: diffExists,
-		DiffOutput: string(output),
-	}, nil
+	return cut, true
 }
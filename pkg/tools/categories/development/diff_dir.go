@@ -0,0 +1,179 @@
+package development
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// binaryCheckBytes is how much of a file is searched for a NUL byte to
+// decide that it is binary
+const binaryCheckBytes = 8000
+
+// diffDirectories compares two directory trees file by file. The diff of
+// every changed file uses a/ and b/ labels, so unified output can be applied
+// with the patch tool.
+func (t *DiffTool) diffDirectories(ctx context.Context, params DiffInput) (interface{}, error) {
+	origDir, err := diffDirectory(params.Original, "original")
+	if err != nil {
+		return nil, err
+	}
+	modDir, err := diffDirectory(params.Modified, "modified")
+	if err != nil {
+		return nil, err
+	}
+
+	origFiles, err := treeFiles(origDir)
+	if err != nil {
+		return nil, err
+	}
+	modFiles, err := treeFiles(modDir)
+	if err != nil {
+		return nil, err
+	}
+
+	output := DiffOutput{Files: []DiffFileStatus{}, Summary: &DiffSummary{}}
+	var text strings.Builder
+	for _, rel := range unionPaths(origFiles, modFiles) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		status := DiffFileStatus{Path: rel}
+		var original, modified []byte
+		fromLabel, toLabel := "a/"+rel, "b/"+rel
+		if origFiles[rel] {
+			if original, err = os.ReadFile(filepath.Join(origDir, filepath.FromSlash(rel))); err != nil {
+				return nil, fmt.Errorf("failed to read original file: %w", err)
+			}
+		} else {
+			status.Status, fromLabel = "added", devNull
+		}
+		if modFiles[rel] {
+			if modified, err = os.ReadFile(filepath.Join(modDir, filepath.FromSlash(rel))); err != nil {
+				return nil, fmt.Errorf("failed to read modified file: %w", err)
+			}
+		} else {
+			status.Status, toLabel = "deleted", devNull
+		}
+		if status.Status == "" {
+			if bytes.Equal(original, modified) {
+				output.Summary.Unchanged++
+				continue
+			}
+			status.Status = "modified"
+		}
+
+		var diffText string
+		if isBinary(original) || isBinary(modified) {
+			status.Binary = true
+			diffText = fmt.Sprintf("Binary files %s and %s differ\n", fromLabel, toLabel)
+		} else {
+			diff := formatDiff(fromLabel, toLabel, string(original), string(modified), params)
+			if diff.text == "" && status.Status == "modified" {
+				// The only differences are ones the options ignore
+				output.Summary.Unchanged++
+				continue
+			}
+			diffText = diff.text
+			status.LinesAdded, status.LinesRemoved = diff.added, diff.removed
+		}
+
+		switch status.Status {
+		case "added":
+			output.Summary.Added++
+		case "deleted":
+			output.Summary.Deleted++
+		default:
+			output.Summary.Modified++
+		}
+		output.Files = append(output.Files, status)
+		output.LinesAdded += status.LinesAdded
+		output.LinesRemoved += status.LinesRemoved
+		output.DiffExists = true
+
+		if !output.Truncated {
+			if text.Len()+len(diffText) > maxDiffOutputBytes {
+				clipped, _ := clipDiffOutput(diffText, maxDiffOutputBytes-text.Len())
+				text.WriteString(clipped)
+				output.Truncated = true
+			} else {
+				text.WriteString(diffText)
+			}
+		}
+	}
+
+	output.DiffOutput = text.String()
+	return output, nil
+}
+
+// diffDirectory checks one side of a directory comparison
+func diffDirectory(path, side string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path for %s: %w", side, err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("%s directory cannot be read: %w", side, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory: %s; use file mode to compare files", side, absPath)
+	}
+	return absPath, nil
+}
+
+// treeFiles returns the regular files below dir as slash-separated relative
+// paths. Version control directories are skipped.
+func treeFiles(dir string) (map[string]bool, error) {
+	files := map[string]bool{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == ".git" || d.Name() == ".hg" || d.Name() == ".svn") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// unionPaths returns the paths in either set, sorted
+func unionPaths(a, b map[string]bool) []string {
+	paths := make([]string, 0, len(a)+len(b))
+	for path := range a {
+		paths = append(paths, path)
+	}
+	for path := range b {
+		if !a[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// isBinary reports whether content looks like binary data
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binaryCheckBytes)], 0) >= 0
+}
@@ -1,11 +1,15 @@
 package development
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDiffTool(t *testing.T) {
@@ -42,7 +46,7 @@ func TestDiffTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		result, err := diffTool.Execute(jsonInput)
+		result, err := diffTool.Execute(context.Background(), jsonInput)
 
 		if err != nil {
 			t.Fatalf("Failed to diff strings: %v", err)
@@ -78,7 +82,7 @@ func TestDiffTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		result, err := diffTool.Execute(jsonInput)
+		result, err := diffTool.Execute(context.Background(), jsonInput)
 
 		if err != nil {
 			t.Fatalf("Failed to diff files: %v", err)
@@ -114,7 +118,7 @@ func TestDiffTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		result, err := diffTool.Execute(jsonInput)
+		result, err := diffTool.Execute(context.Background(), jsonInput)
 
 		if err != nil {
 			t.Fatalf("Failed to diff in mixed mode: %v", err)
@@ -151,7 +155,7 @@ func TestDiffTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		result, err := diffTool.Execute(jsonInput)
+		result, err := diffTool.Execute(context.Background(), jsonInput)
 
 		if err != nil {
 			t.Fatalf("Failed to create side-by-side diff: %v", err)
@@ -182,7 +186,7 @@ func TestDiffTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		result, err := diffTool.Execute(jsonInput)
+		result, err := diffTool.Execute(context.Background(), jsonInput)
 
 		if err != nil {
 			t.Fatalf("Failed to diff identical content: %v", err)
@@ -211,7 +215,7 @@ func TestDiffTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		_, err := diffTool.Execute(jsonInput)
+		_, err := diffTool.Execute(context.Background(), jsonInput)
 
 		if err == nil {
 			t.Errorf("Expected error for non-existent file")
@@ -227,10 +231,152 @@ func TestDiffTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		_, err := diffTool.Execute(jsonInput)
+		_, err := diffTool.Execute(context.Background(), jsonInput)
 
 		if err == nil {
 			t.Errorf("Expected error for invalid mode")
 		}
 	})
 }
+
+// runDiff executes the diff tool with input
+func runDiff(t *testing.T, input DiffInput) (DiffOutput, error) {
+	t.Helper()
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	result, err := NewDiffTool().Execute(context.Background(), data)
+	if err != nil {
+		return DiffOutput{}, err
+	}
+	return result.(DiffOutput), nil
+}
+
+func TestDiffToolFormats(t *testing.T) {
+	original := "func main() {\n\tfmt.Println(\"hello world\")\n}\n"
+	modified := "func main() {\n\tfmt.Println(\"hello there\")\n}\n"
+
+	t.Run("Unified counts lines", func(t *testing.T) {
+		output, err := runDiff(t, DiffInput{Mode: "string", Original: original, Modified: modified})
+		require.NoError(t, err)
+		assert.True(t, output.DiffExists)
+		assert.Equal(t, 1, output.LinesAdded)
+		assert.Equal(t, 1, output.LinesRemoved)
+		assert.Contains(t, output.DiffOutput, "@@ -1,3 +1,3 @@\n")
+	})
+
+	t.Run("Word", func(t *testing.T) {
+		output, err := runDiff(t, DiffInput{Mode: "string", Original: original, Modified: modified, OutputFormat: "word"})
+		require.NoError(t, err)
+		assert.Contains(t, output.DiffOutput, "\tfmt.Println(\"hello [-world-]{+there+}\")\n")
+		assert.Contains(t, output.DiffOutput, "func main() {\n")
+	})
+
+	t.Run("Side by side", func(t *testing.T) {
+		output, err := runDiff(t, DiffInput{
+			Mode:         "string",
+			Original:     "a\nb\nc\n",
+			Modified:     "a\nB\nc\nd\n",
+			OutputFormat: "side-by-side",
+			Width:        40,
+		})
+		require.NoError(t, err)
+		lines := strings.Split(output.DiffOutput, "\n")
+		require.GreaterOrEqual(t, len(lines), 7)
+		assert.Equal(t, "   1 a                  1 a", lines[3])
+		assert.Equal(t, "   2 b             |    2 B", lines[4])
+		assert.Equal(t, "   3 c                  3 c", lines[5])
+		assert.Equal(t, "                   >    4 d", lines[6])
+	})
+
+	t.Run("Ignore whitespace", func(t *testing.T) {
+		output, err := runDiff(t, DiffInput{
+			Mode:             "string",
+			Original:         "if x {\n  return\n}\n",
+			Modified:         "if x {\n\treturn  \n}\n",
+			IgnoreWhitespace: "all",
+		})
+		require.NoError(t, err)
+		assert.False(t, output.DiffExists)
+		assert.Empty(t, output.DiffOutput)
+	})
+
+	t.Run("Ignore blank lines", func(t *testing.T) {
+		input := DiffInput{Mode: "string", Original: "a\nb\nc\n", Modified: "a\n\nb\nc\n", IgnoreBlankLines: true}
+		output, err := runDiff(t, input)
+		require.NoError(t, err)
+		assert.False(t, output.DiffExists)
+
+		input.Modified = "a\n\nb\nC\n"
+		output, err = runDiff(t, input)
+		require.NoError(t, err)
+		assert.Equal(t, 1, output.LinesAdded)
+		assert.Equal(t, 1, output.LinesRemoved)
+		assert.Contains(t, output.DiffOutput, "-c\n+C\n")
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		_, err := runDiff(t, DiffInput{Mode: "string", Original: "a", Modified: "b", Algorithm: "histogram"})
+		assert.Error(t, err)
+		_, err = runDiff(t, DiffInput{Mode: "string", Original: "a", Modified: "b", OutputFormat: "context"})
+		assert.Error(t, err)
+	})
+}
+
+func TestDiffToolDirectory(t *testing.T) {
+	writeTree := func(dir string, files map[string]string) {
+		for name, content := range files {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		}
+	}
+	original, modified := t.TempDir(), t.TempDir()
+	writeTree(original, map[string]string{
+		"same.txt":       "same\n",
+		"changed.txt":    "one\ntwo\n",
+		"old/gone.txt":   "gone\n",
+		"image.bin":      "\x00\x01",
+		".git/HEAD":      "ref: a\n",
+		"spaces.txt":     "a b\n",
+		"sub/nested.txt": "x\n",
+	})
+	writeTree(modified, map[string]string{
+		"same.txt":       "same\n",
+		"changed.txt":    "one\n2\n",
+		"new.txt":        "new\n",
+		"image.bin":      "\x00\x02",
+		".git/HEAD":      "ref: b\n",
+		"spaces.txt":     "a  b\n",
+		"sub/nested.txt": "x\n",
+	})
+
+	output, err := runDiff(t, DiffInput{Mode: "directory", Original: original, Modified: modified, IgnoreWhitespace: "change"})
+	require.NoError(t, err)
+	assert.True(t, output.DiffExists)
+	assert.Equal(t, &DiffSummary{Added: 1, Deleted: 1, Modified: 2, Unchanged: 3}, output.Summary)
+	assert.Equal(t, []DiffFileStatus{
+		{Path: "changed.txt", Status: "modified", LinesAdded: 1, LinesRemoved: 1},
+		{Path: "image.bin", Status: "modified", Binary: true},
+		{Path: "new.txt", Status: "added", LinesAdded: 1},
+		{Path: "old/gone.txt", Status: "deleted", LinesRemoved: 1},
+	}, output.Files)
+	assert.Equal(t, 2, output.LinesAdded)
+	assert.Equal(t, 2, output.LinesRemoved)
+	assert.Contains(t, output.DiffOutput, "--- a/changed.txt\n+++ b/changed.txt\n")
+	assert.Contains(t, output.DiffOutput, "Binary files a/image.bin and b/image.bin differ\n")
+	assert.Contains(t, output.DiffOutput, "--- /dev/null\n+++ b/new.txt\n")
+	assert.Contains(t, output.DiffOutput, "--- a/old/gone.txt\n+++ /dev/null\n")
+
+	// The unified output of a directory comparison applies as a patch
+	patched := t.TempDir()
+	writeTree(patched, map[string]string{"changed.txt": "one\ntwo\n", "old/gone.txt": "gone\n"})
+	text := strings.Replace(output.DiffOutput, "Binary files a/image.bin and b/image.bin differ\n", "", 1)
+	_, err = runPatch(t, PatchInput{Mode: "apply", Path: patched, Patch: text})
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(patched, "new.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(content))
+
+	_, err = runDiff(t, DiffInput{Mode: "directory", Original: filepath.Join(original, "same.txt"), Modified: modified})
+	assert.Error(t, err)
+}
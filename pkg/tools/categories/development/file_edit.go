@@ -0,0 +1,329 @@
+package development
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/diff"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// maxEditDiffBytes is the amount of diff text returned by file_edit
+const maxEditDiffBytes = 32 * 1024
+
+// maxListedMatches is the number of match lines named in an ambiguity error
+const maxListedMatches = 5
+
+// FileEdit is a single change to a file: a replacement of old_string, an
+// insertion before a line, or an addition at the end of the file
+type FileEdit struct {
+	OldString  string `json:"old_string,omitempty"`  // Exact text to replace
+	NewString  string `json:"new_string"`            // Replacement or inserted text
+	ReplaceAll bool   `json:"replace_all,omitempty"` // Replace every occurrence of old_string
+	InsertLine int    `json:"insert_line,omitempty"` // Insert new_string before this 1-based line
+	Append     bool   `json:"append,omitempty"`      // Add new_string at the end of the file
+}
+
+// FileEditInput represents parameters for editing a file. A single edit can
+// be given inline instead of in edits.
+type FileEditInput struct {
+	Path string `json:"path"`
+	FileEdit
+	Edits []FileEdit `json:"edits,omitempty"`
+}
+
+// FileEditResult describes where one edit was applied
+type FileEditResult struct {
+	Line         int `json:"line"`         // 1-based line of the first change when the edit was applied
+	Replacements int `json:"replacements"` // Occurrences replaced, or 1 for an insertion
+}
+
+// FileEditOutput represents the result of a file edit
+type FileEditOutput struct {
+	Path      string           `json:"path"`
+	Edits     []FileEditResult `json:"edits"`
+	Diff      string           `json:"diff"`
+	Truncated bool             `json:"truncated,omitempty"` // The diff was cut short
+}
+
+// FileEditTool applies exact search-and-replace edits and insertions to a file
+type FileEditTool struct {
+	core.BaseToolImpl
+}
+
+// NewFileEditTool creates a new file edit tool
+func NewFileEditTool() *FileEditTool {
+	editProperties := map[string]interface{}{
+		"old_string": map[string]interface{}{
+			"type":        "string",
+			"description": "Exact text to replace, including whitespace and indentation. It must occur exactly once unless replace_all is set",
+		},
+		"new_string": map[string]interface{}{
+			"type":        "string",
+			"description": "Text to put in place of old_string, or to insert or append",
+		},
+		"replace_all": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Replace every occurrence of old_string (default: false)",
+		},
+		"insert_line": map[string]interface{}{
+			"type":        "integer",
+			"description": "Insert new_string as lines before this 1-based line instead of replacing; one past the last line appends",
+		},
+		"append": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Add new_string as lines at the end of the file instead of replacing",
+		},
+	}
+
+	properties := map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Path to the file to edit",
+		},
+		"edits": map[string]interface{}{
+			"type":        "array",
+			"description": "Edits applied in order, each to the result of the previous ones. Use instead of the inline fields to make several edits at once",
+			"items": map[string]interface{}{
+				"type":       "object",
+				"properties": editProperties,
+				"required":   []string{"new_string"},
+			},
+		},
+	}
+	for key, value := range editProperties {
+		properties[key] = value
+	}
+
+	tool := &FileEditTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"file_edit",
+		"Edit a file by replacing exact text: each old_string must match exactly once (or set replace_all), "+
+			"so include enough surrounding lines to make it unique. Can also insert lines before a line number "+
+			"or append to the file. All edits are applied or none are, and a diff of the changes is returned.",
+		"development",
+		map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   []string{"path"},
+		},
+	)
+	return tool
+}
+
+// Execute implements the Tool interface
+func (t *FileEditTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params FileEditInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for file_edit tool: %w", err)
+	}
+	if params.Path == "" {
+		return nil, fmt.Errorf("path parameter is required")
+	}
+	edits, err := params.edits()
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(params.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot edit %s: %w", params.Path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("cannot edit %s: it is a directory", params.Path)
+	}
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Every edit is applied in memory before anything is written
+	original := string(content)
+	edited := original
+	results := make([]FileEditResult, len(edits))
+	for i, edit := range edits {
+		edited, results[i], err = applyEdit(edited, edit)
+		if err != nil {
+			if len(edits) > 1 {
+				return nil, fmt.Errorf("edit %d of %d in %s: %w; no edits were applied", i+1, len(edits), params.Path, err)
+			}
+			return nil, fmt.Errorf("%s: %w", params.Path, err)
+		}
+	}
+
+	if err := writeFileAtomic(absPath, []byte(edited), info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+
+	diff, truncated := clipEditDiff(diff.Unified("a/"+filepath.Base(absPath), "b/"+filepath.Base(absPath), original, edited, 3))
+	return FileEditOutput{
+		Path:      absPath,
+		Edits:     results,
+		Diff:      diff,
+		Truncated: truncated,
+	}, nil
+}
+
+// AffectedPaths implements core.FileMutator
+func (t *FileEditTool) AffectedPaths(input json.RawMessage) ([]string, error) {
+	var params FileEditInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for file_edit tool: %w", err)
+	}
+	if params.Path == "" {
+		return nil, nil
+	}
+	return writtenPaths(params.Path), nil
+}
+
+// edits returns the edits to apply, rejecting a mix of inline and listed edits
+func (p FileEditInput) edits() ([]FileEdit, error) {
+	inline := p.FileEdit != FileEdit{}
+	switch {
+	case inline && len(p.Edits) > 0:
+		return nil, fmt.Errorf("give either a single edit inline or a list of edits, not both")
+	case inline:
+		return []FileEdit{p.FileEdit}, nil
+	case len(p.Edits) == 0:
+		return nil, fmt.Errorf("no edits given: set old_string and new_string, insert_line, append or edits")
+	}
+	return p.Edits, nil
+}
+
+// applyEdit applies one edit to content
+func applyEdit(content string, edit FileEdit) (string, FileEditResult, error) {
+	modes := 0
+	for _, set := range []bool{edit.OldString != "", edit.InsertLine != 0, edit.Append} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return "", FileEditResult{}, fmt.Errorf("set exactly one of old_string, insert_line or append")
+	}
+
+	// Edits written with \n apply to files with Windows line endings
+	newString := edit.NewString
+	oldString := edit.OldString
+	if strings.Contains(content, "\r\n") && !strings.Contains(oldString+newString, "\r") {
+		oldString = strings.ReplaceAll(oldString, "\n", "\r\n")
+		newString = strings.ReplaceAll(newString, "\n", "\r\n")
+	}
+
+	switch {
+	case edit.Append:
+		return insertAt(content, len(diff.SplitLines(content))+1, newString)
+	case edit.InsertLine != 0:
+		return insertAt(content, edit.InsertLine, newString)
+	}
+
+	if oldString == newString {
+		return "", FileEditResult{}, fmt.Errorf("old_string and new_string are identical")
+	}
+	count := strings.Count(content, oldString)
+	switch {
+	case count == 0:
+		return "", FileEditResult{}, notFoundError(content, oldString)
+	case count > 1 && !edit.ReplaceAll:
+		return "", FileEditResult{}, fmt.Errorf("old_string matches %d times (%s); include more surrounding lines to make it unique, or set replace_all",
+			count, matchLines(content, oldString))
+	}
+
+	first := strings.Index(content, oldString)
+	return strings.ReplaceAll(content, oldString, newString), FileEditResult{
+		Line:         strings.Count(content[:first], "\n") + 1,
+		Replacements: count,
+	}, nil
+}
+
+// insertAt inserts text as whole lines before a 1-based line; one past the
+// last line appends. A file without a final newline keeps that style.
+func insertAt(content string, line int, text string) (string, FileEditResult, error) {
+	if text == "" {
+		return "", FileEditResult{}, fmt.Errorf("new_string is empty, there is nothing to insert")
+	}
+
+	lines := diff.SplitLines(content)
+	if line < 1 || line > len(lines)+1 {
+		return "", FileEditResult{}, fmt.Errorf("insert_line %d is out of range, the file has %d lines", line, len(lines))
+	}
+
+	newline := "\n"
+	if strings.Contains(content, "\r\n") {
+		newline = "\r\n"
+	}
+	before := strings.Join(lines[:line-1], "")
+	after := strings.Join(lines[line-1:], "")
+	result := FileEditResult{Line: line, Replacements: 1}
+	if after == "" && before != "" && !strings.HasSuffix(before, "\n") {
+		// The last line gets a line ending and the text inherits the missing one
+		return before + newline + text, result, nil
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += newline
+	}
+	return before + text + after, result, nil
+}
+
+// notFoundError explains a failed match, pointing out near misses
+func notFoundError(content, oldString string) error {
+	trimmed := strings.TrimSpace(oldString)
+	switch {
+	case trimmed != "" && trimmed != oldString && strings.Contains(content, trimmed):
+		return fmt.Errorf("old_string was not found, but it matches without its leading and trailing whitespace; check the line breaks and indentation")
+	case normalizeSpace(oldString) != "" && strings.Contains(normalizeSpace(content), normalizeSpace(oldString)):
+		return fmt.Errorf("old_string was not found, but it matches if whitespace is ignored; copy the exact indentation and spacing from the file")
+	case strings.Contains(strings.ToLower(content), strings.ToLower(oldString)):
+		return fmt.Errorf("old_string was not found, but it matches if case is ignored")
+	}
+	return fmt.Errorf("old_string was not found; read the file again to get its current contents")
+}
+
+// normalizeSpace collapses every run of whitespace to a single space
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// matchLines lists the 1-based lines on which each match of s starts
+func matchLines(content, s string) string {
+	var lines []string
+	line, offset := 1, 0
+	for len(lines) < maxListedMatches {
+		i := strings.Index(content[offset:], s)
+		if i < 0 {
+			break
+		}
+		line += strings.Count(content[offset:offset+i], "\n")
+		lines = append(lines, fmt.Sprint(line))
+		line += strings.Count(s, "\n")
+		offset += i + len(s)
+	}
+	if strings.Count(content, s) > len(lines) {
+		lines = append(lines, "...")
+	}
+	return "lines " + strings.Join(lines, ", ")
+}
+
+// clipEditDiff cuts a diff to at most maxEditDiffBytes, at a line boundary
+func clipEditDiff(diff string) (string, bool) {
+	if len(diff) <= maxEditDiffBytes {
+		return diff, false
+	}
+	cut := diff[:maxEditDiffBytes]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i+1]
+	}
+	return cut, true
+}
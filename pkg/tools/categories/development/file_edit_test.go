@@ -0,0 +1,196 @@
+package development
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const editSource = `package main
+
+func main() {
+	println("hello")
+	println("hello")
+}
+`
+
+// runFileEdit writes content to a file and applies input to it
+func runFileEdit(t *testing.T, content string, input FileEditInput) (string, interface{}, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0640))
+	input.Path = path
+
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	result, err := NewFileEditTool().Execute(context.Background(), data)
+	return path, result, err
+}
+
+func readString(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestFileEditReplace(t *testing.T) {
+	path, result, err := runFileEdit(t, editSource, FileEditInput{FileEdit: FileEdit{
+		OldString: "func main() {\n\tprintln(\"hello\")",
+		NewString: "func main() {\n\tprintln(\"hi\")",
+	}})
+	require.NoError(t, err)
+	assert.Contains(t, readString(t, path), "println(\"hi\")\n\tprintln(\"hello\")")
+
+	output := result.(FileEditOutput)
+	assert.Equal(t, []FileEditResult{{Line: 3, Replacements: 1}}, output.Edits)
+	assert.Equal(t, "--- a/main.go\n+++ b/main.go\n@@ -1,6 +1,6 @@\n package main\n \n func main() {\n-\tprintln(\"hello\")\n+\tprintln(\"hi\")\n \tprintln(\"hello\")\n }\n", output.Diff)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm(), "the file mode is kept")
+}
+
+func TestFileEditReplaceAll(t *testing.T) {
+	path, result, err := runFileEdit(t, editSource, FileEditInput{FileEdit: FileEdit{
+		OldString:  "hello",
+		NewString:  "bye",
+		ReplaceAll: true,
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []FileEditResult{{Line: 4, Replacements: 2}}, result.(FileEditOutput).Edits)
+	assert.NotContains(t, readString(t, path), "hello")
+}
+
+func TestFileEditMultipleEdits(t *testing.T) {
+	path, result, err := runFileEdit(t, editSource, FileEditInput{Edits: []FileEdit{
+		{OldString: "package main", NewString: "package app"},
+		{InsertLine: 2, NewString: "import \"fmt\"\n"},
+		{OldString: "func main()", NewString: "func Run()"},
+		{Append: true, NewString: "\nvar _ = fmt.Sprint"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "package app\nimport \"fmt\"\n\nfunc Run() {\n\tprintln(\"hello\")\n\tprintln(\"hello\")\n}\n\nvar _ = fmt.Sprint\n", readString(t, path))
+	assert.Len(t, result.(FileEditOutput).Edits, 4)
+}
+
+func TestFileEditInsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		edit    FileEdit
+		want    string
+	}{
+		{name: "First line", content: "a\nb\n", edit: FileEdit{InsertLine: 1, NewString: "x"}, want: "x\na\nb\n"},
+		{name: "Middle", content: "a\nb\n", edit: FileEdit{InsertLine: 2, NewString: "x\ny\n"}, want: "a\nx\ny\nb\n"},
+		{name: "Past last line", content: "a\nb\n", edit: FileEdit{InsertLine: 3, NewString: "x"}, want: "a\nb\nx\n"},
+		{name: "Append", content: "a\nb\n", edit: FileEdit{Append: true, NewString: "x"}, want: "a\nb\nx\n"},
+		{name: "Append without final newline", content: "a\nb", edit: FileEdit{Append: true, NewString: "x"}, want: "a\nb\nx"},
+		{name: "Append to empty file", content: "", edit: FileEdit{Append: true, NewString: "x"}, want: "x\n"},
+		{name: "Windows line endings", content: "a\r\nb\r\n", edit: FileEdit{InsertLine: 2, NewString: "x\ny"}, want: "a\r\nx\r\ny\r\nb\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, _, err := runFileEdit(t, tt.content, FileEditInput{FileEdit: tt.edit})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, readString(t, path))
+		})
+	}
+}
+
+func TestFileEditWindowsLineEndings(t *testing.T) {
+	path, _, err := runFileEdit(t, "one\r\ntwo\r\nthree\r\n", FileEditInput{FileEdit: FileEdit{
+		OldString: "one\ntwo\n",
+		NewString: "1\n2\n",
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "1\r\n2\r\nthree\r\n", readString(t, path))
+}
+
+func TestFileEditErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input FileEditInput
+		want  string
+	}{
+		{name: "Not found", input: FileEditInput{FileEdit: FileEdit{OldString: "goodbye", NewString: "x"}},
+			want: "old_string was not found; read the file again"},
+		{name: "Whitespace differs", input: FileEditInput{FileEdit: FileEdit{OldString: "func main() {\n    println", NewString: "x"}},
+			want: "matches if whitespace is ignored"},
+		{name: "Surrounding whitespace", input: FileEditInput{FileEdit: FileEdit{OldString: "\n\n\nfunc main()", NewString: "x"}},
+			want: "matches without its leading and trailing whitespace"},
+		{name: "Case differs", input: FileEditInput{FileEdit: FileEdit{OldString: "PRINTLN", NewString: "x"}},
+			want: "matches if case is ignored"},
+		{name: "Ambiguous", input: FileEditInput{FileEdit: FileEdit{OldString: "println(\"hello\")", NewString: "x"}},
+			want: "old_string matches 2 times (lines 4, 5)"},
+		{name: "Identical", input: FileEditInput{FileEdit: FileEdit{OldString: "main", NewString: "main"}},
+			want: "identical"},
+		{name: "No edits", input: FileEditInput{},
+			want: "no edits given"},
+		{name: "Several modes", input: FileEditInput{FileEdit: FileEdit{OldString: "main", NewString: "x", Append: true}},
+			want: "set exactly one of old_string, insert_line or append"},
+		{name: "Inline and list", input: FileEditInput{FileEdit: FileEdit{Append: true, NewString: "x"}, Edits: []FileEdit{{Append: true, NewString: "y"}}},
+			want: "not both"},
+		{name: "Line out of range", input: FileEditInput{FileEdit: FileEdit{InsertLine: 9, NewString: "x"}},
+			want: "insert_line 9 is out of range, the file has 6 lines"},
+		{name: "Nothing to insert", input: FileEditInput{FileEdit: FileEdit{Append: true}},
+			want: "nothing to insert"},
+		{name: "Later edit fails", input: FileEditInput{Edits: []FileEdit{
+			{OldString: "package main", NewString: "package app"},
+			{OldString: "package main", NewString: "package other"},
+		}}, want: "edit 2 of 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, _, err := runFileEdit(t, editSource, tt.input)
+			assert.ErrorContains(t, err, tt.want)
+			assert.Equal(t, editSource, readString(t, path), "a failed edit leaves the file unchanged")
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		input, _ := json.Marshal(FileEditInput{Path: filepath.Join(t.TempDir(), "missing.go"), FileEdit: FileEdit{Append: true, NewString: "x"}})
+		_, err := NewFileEditTool().Execute(context.Background(), input)
+		assert.ErrorContains(t, err, "cannot edit")
+	})
+}
+
+func TestFileEditAffectedPaths(t *testing.T) {
+	paths, err := NewFileEditTool().AffectedPaths(json.RawMessage(`{"path": "a.go", "old_string": "x", "new_string": "y"}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go"}, paths)
+}
+
+func TestFileEditSymlink(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	real := filepath.Join(dir, "real.txt")
+	link := filepath.Join(dir, "link.txt")
+	require.NoError(t, os.WriteFile(real, []byte("hello\n"), 0644))
+	require.NoError(t, os.Symlink("real.txt", link))
+
+	input, err := json.Marshal(FileEditInput{Path: link, FileEdit: FileEdit{OldString: "hello", NewString: "goodbye"}})
+	require.NoError(t, err)
+	paths, err := NewFileEditTool().AffectedPaths(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{link, real}, paths, "checkpoints cover the file written through the link")
+
+	_, err = NewFileEditTool().Execute(context.Background(), input)
+	require.NoError(t, err)
+
+	assert.Equal(t, "goodbye\n", readString(t, real), "the link's target is edited")
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type(), "the link is kept")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary files are left behind")
+}
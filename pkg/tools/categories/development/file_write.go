@@ -1,6 +1,7 @@
 package development
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -68,7 +69,11 @@ func NewFileWriteTool() *FileWriteTool {
 }
 
 // Execute implements the Tool interface
-func (t *FileWriteTool) Execute(input json.RawMessage) (interface{}, error) {
+func (t *FileWriteTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var params FileWriteInput
 	if err := json.Unmarshal(input, &params); err != nil {
 		return nil, fmt.Errorf("invalid input for file_write tool: %w", err)
@@ -145,3 +150,15 @@ func (t *FileWriteTool) Execute(input json.RawMessage) (interface{}, error) {
 		Created:      !fileExists,
 	}, nil
 }
+
+// AffectedPaths implements core.FileMutator
+func (t *FileWriteTool) AffectedPaths(input json.RawMessage) ([]string, error) {
+	var params FileWriteInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for file_write tool: %w", err)
+	}
+	if params.Path == "" {
+		return nil, nil
+	}
+	return []string{params.Path}, nil
+}
@@ -1,6 +1,7 @@
 package development
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -91,7 +92,7 @@ func TestFileWriteTool(t *testing.T) {
 			}
 
 			// Execute tool
-			result, err := fileWriteTool.Execute(jsonInput)
+			result, err := fileWriteTool.Execute(context.Background(), jsonInput)
 
 			// Check error
 			if tc.expectError && err == nil {
@@ -147,7 +148,7 @@ func TestFileWriteTool(t *testing.T) {
 		Path:    overwritePath,
 		Content: "New content",
 	})
-	fileWriteTool.Execute(jsonInput)
+	fileWriteTool.Execute(context.Background(), jsonInput)
 
 	// Verify overwritten content
 	content, _ := os.ReadFile(overwritePath)
@@ -162,7 +163,7 @@ func TestFileWriteTool(t *testing.T) {
 		Content: "Second line\n",
 		Append:  true,
 	})
-	fileWriteTool.Execute(jsonInput)
+	fileWriteTool.Execute(context.Background(), jsonInput)
 
 	// Verify appended content
 	content, _ = os.ReadFile(appendPath)
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package development
+
+import "os"
+
+// copyFileAttrs does nothing where files have no owner to keep
+func copyFileAttrs(from, to string, info os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package development
+
+import (
+	"os"
+	"syscall"
+)
+
+// copyFileAttrs gives the file at to the owner and extended attributes of
+// the file at from, described by info
+func copyFileAttrs(from, to string, info os.FileInfo) error {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		if int(st.Uid) != os.Getuid() || int(st.Gid) != os.Getgid() {
+			if err := os.Lchown(to, int(st.Uid), int(st.Gid)); err != nil {
+				return err
+			}
+		}
+	}
+	return copyXattrs(from, to)
+}
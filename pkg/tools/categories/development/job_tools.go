@@ -0,0 +1,391 @@
+package development
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
+)
+
+// defaultJobReadBytes is the amount of output returned by one job_output call
+const defaultJobReadBytes = 16 * 1024
+
+// maxJobReadBytes is the largest amount of output job_output may return
+const maxJobReadBytes = 64 * 1024
+
+// JobStartTool starts a command in the background. It owns the session's
+// jobs: closing it stops them all.
+type JobStartTool struct {
+	core.BaseToolImpl
+	commandPolicy
+	sandbox.Holder
+	jobs *JobManager
+}
+
+// JobOutputTool returns output a job wrote since the previous call
+type JobOutputTool struct {
+	core.BaseToolImpl
+	jobs *JobManager
+}
+
+// JobInputTool writes to the stdin of a job. The job may be a shell, so
+// each line written is checked against the command policy like job_start.
+type JobInputTool struct {
+	core.BaseToolImpl
+	commandPolicy
+	jobs *JobManager
+
+	mu sync.Mutex
+	// partial holds the unterminated last line written to each job, so a
+	// command split across calls is checked as a whole
+	partial map[int]string
+}
+
+// JobStatusTool reports the state of one or all jobs
+type JobStatusTool struct {
+	core.BaseToolImpl
+	jobs *JobManager
+}
+
+// JobKillTool stops a job
+type JobKillTool struct {
+	core.BaseToolImpl
+	jobs *JobManager
+}
+
+// JobStartInput represents parameters for starting a job
+type JobStartInput struct {
+	Command    string `json:"command"`
+	WorkingDir string `json:"working_dir,omitempty"`
+}
+
+// JobOutputInput represents parameters for reading job output
+type JobOutputInput struct {
+	ID       int `json:"id"`
+	WaitSecs int `json:"wait_secs,omitempty"`
+	MaxBytes int `json:"max_bytes,omitempty"`
+}
+
+// JobOutputResult represents new output of a job and its current state
+type JobOutputResult struct {
+	core.Job
+	Output       string `json:"output"`
+	DroppedBytes int64  `json:"dropped_bytes,omitempty"`
+	More         bool   `json:"more,omitempty"`
+}
+
+// JobInputInput represents parameters for writing to a job's stdin
+type JobInputInput struct {
+	ID         int    `json:"id"`
+	Input      string `json:"input"`
+	CloseStdin bool   `json:"close_stdin,omitempty"`
+}
+
+// JobInputResult represents the result of writing to a job's stdin
+type JobInputResult struct {
+	ID           int  `json:"id"`
+	BytesWritten int  `json:"bytes_written"`
+	StdinClosed  bool `json:"stdin_closed,omitempty"`
+}
+
+// JobIDInput represents parameters naming a job
+type JobIDInput struct {
+	ID int `json:"id"`
+}
+
+// JobStatusResult lists jobs
+type JobStatusResult struct {
+	Jobs []core.Job `json:"jobs"`
+}
+
+// jobIDSchema is the schema of the id parameter shared by the job tools
+var jobIDSchema = map[string]interface{}{
+	"type":        "integer",
+	"description": "ID of the job, as returned by job_start",
+	"minimum":     1,
+}
+
+// NewJobTools creates the job tools, sharing one set of jobs
+func NewJobTools(jobs *JobManager) []core.Tool {
+	return []core.Tool{
+		newJobStartTool(jobs),
+		newJobOutputTool(jobs),
+		newJobInputTool(jobs),
+		newJobStatusTool(jobs),
+		newJobKillTool(jobs),
+	}
+}
+
+func newJobStartTool(jobs *JobManager) *JobStartTool {
+	tool := &JobStartTool{jobs: jobs}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"job_start",
+		"Start a long-running command line, such as a dev server or a test run, in the background "+
+			"and return its job ID at once. Use job_output to read what it prints and job_kill to stop it.",
+		"development",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"command": map[string]interface{}{
+					"type":        "string",
+					"description": "The command line to run with bash -c",
+				},
+				"working_dir": map[string]interface{}{
+					"type":        "string",
+					"description": "Working directory for the job",
+				},
+			},
+			"required": []string{"command"},
+		},
+	)
+	return tool
+}
+
+func newJobOutputTool(jobs *JobManager) *JobOutputTool {
+	tool := &JobOutputTool{jobs: jobs}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"job_output",
+		"Read the output (stdout and stderr combined) a background job wrote since the previous call, "+
+			"and whether it is still running.",
+		"development",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": jobIDSchema,
+				"wait_secs": map[string]interface{}{
+					"type":        "integer",
+					"description": "Seconds to wait for new output or for the job to exit when there is none yet (default 0)",
+					"minimum":     0,
+				},
+				"max_bytes": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum bytes of output to return (default %d, at most %d)", defaultJobReadBytes, maxJobReadBytes),
+					"minimum":     1,
+				},
+			},
+			"required": []string{"id"},
+		},
+	)
+	return tool
+}
+
+func newJobInputTool(jobs *JobManager) *JobInputTool {
+	tool := &JobInputTool{jobs: jobs, partial: make(map[int]string)}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"job_input",
+		"Write text to the stdin of a background job. Include a trailing newline to submit a line. "+
+			"Each line is checked against the command policy, as the job may be a shell.",
+		"development",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": jobIDSchema,
+				"input": map[string]interface{}{
+					"type":        "string",
+					"description": "Text to write",
+				},
+				"close_stdin": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Close stdin after writing, signalling end of input",
+				},
+			},
+			"required": []string{"id", "input"},
+		},
+	)
+	return tool
+}
+
+func newJobStatusTool(jobs *JobManager) *JobStatusTool {
+	tool := &JobStatusTool{jobs: jobs}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"job_status",
+		"Show the state of a background job, or of all jobs started in this conversation.",
+		"development",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": map[string]interface{}{
+					"type":        "integer",
+					"description": "ID of the job (default is all jobs)",
+					"minimum":     1,
+				},
+			},
+		},
+	)
+	return tool
+}
+
+func newJobKillTool(jobs *JobManager) *JobKillTool {
+	tool := &JobKillTool{jobs: jobs}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"job_kill",
+		"Stop a background job and every process it started. The job gets SIGTERM, then SIGKILL if it does not exit.",
+		"development",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"id": jobIDSchema,
+			},
+			"required": []string{"id"},
+		},
+	)
+	return tool
+}
+
+// Jobs returns the jobs started in this session
+func (t *JobStartTool) Jobs() []core.Job {
+	return t.jobs.Jobs()
+}
+
+// Close stops all jobs started in this session
+func (t *JobStartTool) Close() error {
+	return t.jobs.Close()
+}
+
+// Execute starts a background job
+func (t *JobStartTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params JobStartInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for job_start tool: %w", err)
+	}
+	if params.Command == "" {
+		return nil, fmt.Errorf("command parameter is required")
+	}
+
+	if err := t.enforce(ctx, t.Name(), t.currentPolicy().EvaluateLine(params.Command)); err != nil {
+		return nil, err
+	}
+
+	return t.jobs.Start(params.Command, params.WorkingDir, t.SandboxProfile())
+}
+
+// Execute returns new output of a job, waiting for it if asked to
+func (t *JobOutputTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params JobOutputInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for job_output tool: %w", err)
+	}
+
+	j, err := t.jobs.get(params.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.WaitSecs > 0 {
+		if err := j.waitForOutput(ctx, time.Duration(params.WaitSecs)*time.Second); err != nil {
+			return nil, err
+		}
+	}
+
+	maxBytes := params.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultJobReadBytes
+	}
+	if maxBytes > maxJobReadBytes {
+		maxBytes = maxJobReadBytes
+	}
+
+	// Take the state first, so a job reported as exited has no unread output left behind
+	info := j.snapshot()
+	output, dropped, more := j.readNew(maxBytes)
+
+	return JobOutputResult{
+		Job:          info,
+		Output:       output,
+		DroppedBytes: dropped,
+		More:         more,
+	}, nil
+}
+
+// Execute writes to the stdin of a job
+func (t *JobInputTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params JobInputInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for job_input tool: %w", err)
+	}
+
+	j, err := t.jobs.get(params.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := strings.Split(t.partial[params.ID]+params.Input, "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := t.enforce(ctx, t.Name(), t.currentPolicy().EvaluateLine(line)); err != nil {
+			return nil, err
+		}
+	}
+
+	n, err := j.writeInput(params.Input, params.CloseStdin)
+	if err != nil {
+		return nil, err
+	}
+	t.partial[params.ID] = lines[len(lines)-1]
+
+	return JobInputResult{ID: params.ID, BytesWritten: n, StdinClosed: params.CloseStdin}, nil
+}
+
+// Execute reports the state of one or all jobs
+func (t *JobStatusTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params JobIDInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for job_status tool: %w", err)
+	}
+
+	if params.ID == 0 {
+		return JobStatusResult{Jobs: t.jobs.Jobs()}, nil
+	}
+
+	j, err := t.jobs.get(params.ID)
+	if err != nil {
+		return nil, err
+	}
+	return j.snapshot(), nil
+}
+
+// Execute stops a job and waits for it to exit
+func (t *JobKillTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params JobIDInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for job_kill tool: %w", err)
+	}
+
+	j, err := t.jobs.get(params.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	j.stop()
+	return j.snapshot(), nil
+}
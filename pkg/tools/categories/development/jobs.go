@@ -0,0 +1,335 @@
+package development
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
+)
+
+// maxRunningJobs is the number of background jobs that may run at the same time
+const maxRunningJobs = 10
+
+// jobOutputLimit is the amount of recent output kept for each job
+const jobOutputLimit = 1024 * 1024
+
+// jobStopGrace is how long a job may take to exit after SIGTERM before it is killed
+const jobStopGrace = 3 * time.Second
+
+// JobManager runs and tracks the background jobs of a session
+type JobManager struct {
+	mu     sync.Mutex
+	jobs   []*job
+	nextID int
+	closed bool
+}
+
+// job is a background process and the output it has written
+type job struct {
+	mu         sync.Mutex
+	info       core.Job
+	cmd        *exec.Cmd
+	inputMu    sync.Mutex // Serializes writes to stdin, which may block
+	stdin      io.WriteCloser
+	stdinOpen  bool
+	output     *jobOutput
+	readOffset int64 // Output before this offset has been returned to the model
+	done       chan struct{}
+}
+
+// NewJobManager creates an empty job manager
+func NewJobManager() *JobManager {
+	return &JobManager{nextID: 1}
+}
+
+// Start runs a command line in the background with sh -c, or bash -c where
+// available. The profile, which may be nil, restricts the job.
+func (m *JobManager) Start(command, dir string, profile *sandbox.Profile) (core.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return core.Job{}, fmt.Errorf("the job manager is closed")
+	}
+
+	running := 0
+	for _, j := range m.jobs {
+		if j.snapshot().Running {
+			running++
+		}
+	}
+	if running >= maxRunningJobs {
+		return core.Job{}, fmt.Errorf("%d jobs are already running; kill one before starting another", running)
+	}
+
+	shell := "sh"
+	if path, err := exec.LookPath("bash"); err == nil {
+		shell = path
+	}
+
+	output := newJobOutput(jobOutputLimit)
+	cmd := exec.Command(shell, "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "PAGER=cat", "GIT_PAGER=cat")
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Children that keep the output open must not keep the job running
+	cmd.WaitDelay = time.Second
+	setProcessGroup(cmd)
+	if err := profile.Prepare(cmd); err != nil {
+		return core.Job{}, fmt.Errorf("cannot apply the sandbox profile: %w", err)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return core.Job{}, fmt.Errorf("failed to create job stdin: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return core.Job{}, fmt.Errorf("failed to start job: %w", err)
+	}
+
+	j := &job{
+		info: core.Job{
+			ID:        m.nextID,
+			Command:   command,
+			Dir:       dir,
+			PID:       cmd.Process.Pid,
+			Running:   true,
+			StartedAt: time.Now(),
+		},
+		cmd:       cmd,
+		stdin:     stdin,
+		stdinOpen: true,
+		output:    output,
+		done:      make(chan struct{}),
+	}
+	m.nextID++
+	m.jobs = append(m.jobs, j)
+
+	go j.wait()
+
+	return j.snapshot(), nil
+}
+
+// Jobs returns all jobs of the session, oldest first
+func (m *JobManager) Jobs() []core.Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]core.Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j.snapshot())
+	}
+	return jobs
+}
+
+// Close stops every job, including processes left behind by jobs that have exited
+func (m *JobManager) Close() error {
+	m.mu.Lock()
+	m.closed = true
+	jobs := m.jobs
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			j.stop()
+		}(j)
+	}
+	wg.Wait()
+	return nil
+}
+
+// get returns the job with the given ID
+func (m *JobManager) get(id int) (*job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, j := range m.jobs {
+		if j.info.ID == id {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("no job with id %d", id)
+}
+
+// wait records the exit status once the process ends
+func (j *job) wait() {
+	_ = j.cmd.Wait()
+
+	ended := time.Now()
+	j.mu.Lock()
+	j.info.Running = false
+	j.info.ExitCode = j.cmd.ProcessState.ExitCode()
+	j.info.EndedAt = &ended
+	j.stdinOpen = false
+	j.mu.Unlock()
+
+	close(j.done)
+}
+
+// snapshot returns the current state of the job
+func (j *job) snapshot() core.Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.info
+}
+
+// stop asks the job's process group to terminate, killing it if it does not
+func (j *job) stop() {
+	_ = terminateProcessGroup(j.cmd)
+	select {
+	case <-j.done:
+	case <-time.After(jobStopGrace):
+	}
+
+	// Also catches children that outlived the job's main process
+	_ = killProcessGroup(j.cmd)
+	<-j.done
+}
+
+// readNew returns output written since the previous read, up to max bytes,
+// and the number of bytes dropped from the buffer before they could be read
+func (j *job) readNew(max int) (string, int64, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, next, dropped := j.output.readFrom(j.readOffset, max)
+	j.readOffset = next
+	return string(data), dropped, next < j.output.size()
+}
+
+// hasNewOutput reports whether output was written since the previous read
+func (j *job) hasNewOutput() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.readOffset < j.output.size()
+}
+
+// waitForOutput waits until the job writes new output, exits or the timeout expires
+func (j *job) waitForOutput(ctx context.Context, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		// Take the channel before checking, so a write in between is not missed
+		changes := j.output.changed()
+		if j.hasNewOutput() {
+			return nil
+		}
+
+		select {
+		case <-changes:
+		case <-j.done:
+			return nil
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// writeInput sends input to the job's stdin, optionally closing it afterwards
+func (j *job) writeInput(input string, closeStdin bool) (int, error) {
+	j.inputMu.Lock()
+	defer j.inputMu.Unlock()
+
+	j.mu.Lock()
+	open := j.stdinOpen
+	if closeStdin {
+		j.stdinOpen = false
+	}
+	j.mu.Unlock()
+
+	if !open {
+		return 0, fmt.Errorf("stdin of job %d is closed", j.info.ID)
+	}
+
+	n, err := io.WriteString(j.stdin, input)
+	if err != nil {
+		return n, fmt.Errorf("failed to write to job %d: %w", j.info.ID, err)
+	}
+
+	if closeStdin {
+		if err := j.stdin.Close(); err != nil {
+			return n, fmt.Errorf("failed to close stdin of job %d: %w", j.info.ID, err)
+		}
+	}
+	return n, nil
+}
+
+// jobOutput keeps the most recent output of a job. Offsets count every
+// byte ever written, so readers can tell when output was dropped.
+type jobOutput struct {
+	mu      sync.Mutex
+	limit   int
+	data    []byte
+	start   int64         // Offset of data[0]
+	changes chan struct{} // Closed and replaced on every write
+}
+
+func newJobOutput(limit int) *jobOutput {
+	return &jobOutput{limit: limit, changes: make(chan struct{})}
+}
+
+// Write appends output, dropping the oldest bytes beyond the limit
+func (o *jobOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.data = append(o.data, p...)
+	if excess := len(o.data) - o.limit; excess > 0 {
+		o.data = append(o.data[:0], o.data[excess:]...)
+		o.start += int64(excess)
+	}
+
+	close(o.changes)
+	o.changes = make(chan struct{})
+	return len(p), nil
+}
+
+// size returns the offset just past the last byte written
+func (o *jobOutput) size() int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.start + int64(len(o.data))
+}
+
+// changed returns a channel that is closed when output is next written
+func (o *jobOutput) changed() <-chan struct{} {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.changes
+}
+
+// readFrom returns up to max bytes starting at offset, the offset following
+// them, and how many bytes after offset were already dropped
+func (o *jobOutput) readFrom(offset int64, max int) ([]byte, int64, int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var dropped int64
+	if offset < o.start {
+		dropped = o.start - offset
+		offset = o.start
+	}
+
+	data := o.data[offset-o.start:]
+	if len(data) > max {
+		data = data[:max]
+	}
+	return append([]byte(nil), data...), offset + int64(len(data)), dropped
+}
@@ -0,0 +1,163 @@
+package development
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jobTools returns the job tools sharing a new job manager, by name
+func jobTools(t *testing.T) map[string]core.Tool {
+	jobs := NewJobManager()
+	t.Cleanup(func() { jobs.Close() })
+
+	tools := make(map[string]core.Tool)
+	for _, tool := range NewJobTools(jobs) {
+		tools[tool.Name()] = tool
+	}
+	return tools
+}
+
+// runJobTool executes a job tool and returns its result
+func runJobTool(t *testing.T, tool core.Tool, params interface{}) interface{} {
+	t.Helper()
+
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	result, err := tool.Execute(context.Background(), input)
+	require.NoError(t, err)
+	return result
+}
+
+func TestJobOutputAndExit(t *testing.T) {
+	tools := jobTools(t)
+
+	started := runJobTool(t, tools["job_start"], JobStartInput{Command: "echo one; sleep 0.2; echo two; exit 3"}).(core.Job)
+	assert.Equal(t, 1, started.ID)
+	assert.True(t, started.Running)
+
+	// Each read returns only output not seen before
+	var output string
+	var last JobOutputResult
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		last = runJobTool(t, tools["job_output"], JobOutputInput{ID: started.ID, WaitSecs: 1}).(JobOutputResult)
+		output += last.Output
+		if !last.Running {
+			break
+		}
+	}
+
+	assert.Equal(t, "one\ntwo\n", output)
+	assert.False(t, last.Running)
+	assert.Equal(t, 3, last.ExitCode)
+	assert.NotNil(t, last.EndedAt)
+
+	status := runJobTool(t, tools["job_status"], JobIDInput{}).(JobStatusResult)
+	require.Len(t, status.Jobs, 1)
+	assert.Equal(t, started.ID, status.Jobs[0].ID)
+}
+
+func TestJobInput(t *testing.T) {
+	tools := jobTools(t)
+
+	started := runJobTool(t, tools["job_start"], JobStartInput{Command: `read line; echo "got $line"; cat`}).(core.Job)
+
+	written := runJobTool(t, tools["job_input"], JobInputInput{ID: started.ID, Input: "hello\n"}).(JobInputResult)
+	assert.Equal(t, 6, written.BytesWritten)
+
+	result := runJobTool(t, tools["job_output"], JobOutputInput{ID: started.ID, WaitSecs: 5}).(JobOutputResult)
+	assert.Equal(t, "got hello\n", result.Output)
+	assert.True(t, result.Running)
+
+	// Closing stdin ends cat, and so the job
+	runJobTool(t, tools["job_input"], JobInputInput{ID: started.ID, Input: "bye\n", CloseStdin: true})
+	require.Eventually(t, func() bool {
+		return !runJobTool(t, tools["job_status"], JobIDInput{ID: started.ID}).(core.Job).Running
+	}, 5*time.Second, 50*time.Millisecond)
+
+	_, err := tools["job_input"].Execute(context.Background(), json.RawMessage(`{"id": 1, "input": "more"}`))
+	assert.Error(t, err)
+}
+
+func TestJobKillStopsProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+	tools := jobTools(t)
+
+	started := runJobTool(t, tools["job_start"], JobStartInput{
+		Command:    "(sleep 1; touch marker) & sleep 30",
+		WorkingDir: dir,
+	}).(core.Job)
+
+	start := time.Now()
+	killed := runJobTool(t, tools["job_kill"], JobIDInput{ID: started.ID}).(core.Job)
+	assert.Less(t, time.Since(start), jobStopGrace)
+	assert.False(t, killed.Running)
+
+	time.Sleep(1500 * time.Millisecond)
+	assert.NoFileExists(t, filepath.Join(dir, "marker"))
+}
+
+func TestJobManagerClose(t *testing.T) {
+	jobs := NewJobManager()
+	job, err := jobs.Start("sleep 30", "", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, jobs.Close())
+	assert.False(t, jobs.Jobs()[0].Running, "job %d should have been stopped", job.ID)
+
+	_, err = jobs.Start("true", "", nil)
+	assert.Error(t, err)
+}
+
+func TestJobSandboxProfile(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resource limits are only supported on Linux")
+	}
+
+	jobs := NewJobManager()
+	defer jobs.Close()
+
+	started, err := jobs.Start("ulimit -n; ulimit -t", "", &sandbox.Profile{OpenFiles: 32, CPUSecs: 30})
+	require.NoError(t, err)
+
+	j, err := jobs.get(started.ID)
+	require.NoError(t, err)
+	require.NoError(t, j.waitForOutput(context.Background(), 5*time.Second))
+	<-j.done
+
+	output, _, _ := j.readNew(maxJobReadBytes)
+	assert.Equal(t, "32\n30\n", output)
+}
+
+func TestJobUnknownID(t *testing.T) {
+	tools := jobTools(t)
+
+	_, err := tools["job_output"].Execute(context.Background(), json.RawMessage(`{"id": 42}`))
+	assert.Error(t, err)
+}
+
+func TestJobOutputDropsOldest(t *testing.T) {
+	output := newJobOutput(4)
+	output.Write([]byte("abc"))
+
+	data, next, dropped := output.readFrom(0, 2)
+	assert.Equal(t, "ab", string(data))
+	assert.Equal(t, int64(2), next)
+	assert.Equal(t, int64(0), dropped)
+
+	output.Write([]byte("defg"))
+	data, next, dropped = output.readFrom(next, 10)
+	assert.Equal(t, "defg", string(data))
+	assert.Equal(t, int64(7), next)
+	assert.Equal(t, int64(1), dropped)
+}
@@ -1,21 +1,22 @@
 package development
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"time"
+	"strings"
 
+	"github.com/navicore/mcpterm-go/pkg/diff"
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
 )
 
 // PatchInput represents parameters for creating or applying a patch
 type PatchInput struct {
 	Mode     string `json:"mode"`               // "create" or "apply"
-	Path     string `json:"path"`               // Path to the file to patch
+	Path     string `json:"path"`               // File to patch, or the directory a patch's file names are relative to
 	Original string `json:"original,omitempty"` // Original content (for create mode)
 	Modified string `json:"modified,omitempty"` // Modified content (for create mode)
 	Patch    string `json:"patch,omitempty"`    // Patch content (for apply mode)
@@ -25,11 +26,28 @@ type PatchInput struct {
 
 // PatchOutput represents the result of a patch operation
 type PatchOutput struct {
-	Success     bool   `json:"success"`      // Whether the operation succeeded
-	Mode        string `json:"mode"`         // Mode that was used ("create" or "apply")
-	Path        string `json:"path"`         // Path to the affected file
-	PatchOutput string `json:"patch_output"` // Output of the patch operation (unified diff or apply results)
-	DryRun      bool   `json:"dry_run"`      // Whether this was a dry run
+	Success     bool              `json:"success"`         // Whether the operation succeeded
+	Mode        string            `json:"mode"`            // Mode that was used ("create" or "apply")
+	Path        string            `json:"path"`            // Path to the affected file or directory
+	PatchOutput string            `json:"patch_output"`    // Output of the patch operation (unified diff or apply results)
+	DryRun      bool              `json:"dry_run"`         // Whether this was a dry run
+	Files       []PatchFileResult `json:"files,omitempty"` // Files changed by an applied patch
+}
+
+// PatchFileResult describes how a patch changed one file
+type PatchFileResult struct {
+	Path      string            `json:"path"`
+	Operation string            `json:"operation"`      // "modify", "create", "delete" or "rename"
+	From      string            `json:"from,omitempty"` // Original path of a renamed file
+	Hunks     []PatchHunkResult `json:"hunks,omitempty"`
+}
+
+// PatchHunkResult describes where one hunk was applied
+type PatchHunkResult struct {
+	Hunk   int `json:"hunk"`             // 1-based number of the hunk within its file
+	Line   int `json:"line"`             // Line of the original file where the hunk applied
+	Offset int `json:"offset,omitempty"` // Lines between where the hunk header placed it and where it applied
+	Fuzz   int `json:"fuzz,omitempty"`   // Context lines ignored at the ends of the hunk to make it apply
 }
 
 // PatchTool allows creating and applying patches to files
@@ -42,7 +60,9 @@ func NewPatchTool() *PatchTool {
 	tool := &PatchTool{}
 	tool.BaseToolImpl = *core.NewBaseTool(
 		"patch",
-		"Create or apply patches to files on macOS",
+		"Create a unified diff, or apply one to one or more files. Applying handles multi-file patches "+
+			"that create, delete and rename files, finds hunks whose line numbers are off, and changes "+
+			"nothing unless every hunk applies.",
 		"development",
 		map[string]interface{}{
 			"type": "object",
@@ -53,12 +73,13 @@ func NewPatchTool() *PatchTool {
 					"enum":        []string{"create", "apply"},
 				},
 				"path": map[string]interface{}{
-					"type":        "string",
-					"description": "Path to the file to patch",
+					"type": "string",
+					"description": "In create mode, the file the patch is for. In apply mode, the file to patch, " +
+						"or the directory the patch's file names are relative to (default: the current directory)",
 				},
 				"original": map[string]interface{}{
 					"type":        "string",
-					"description": "Original content (used in create mode)",
+					"description": "Original content (used in create mode; default: the content of path)",
 				},
 				"modified": map[string]interface{}{
 					"type":        "string",
@@ -66,25 +87,29 @@ func NewPatchTool() *PatchTool {
 				},
 				"patch": map[string]interface{}{
 					"type":        "string",
-					"description": "Patch content in unified diff format (used in apply mode)",
+					"description": "Patch content in unified diff format (used in apply mode); git a/ and b/ prefixes are removed",
 				},
 				"dry_run": map[string]interface{}{
 					"type":        "boolean",
-					"description": "Whether to perform a dry run without modifying files (default: false)",
+					"description": "Check that the patch applies and report where, without modifying files (default: false)",
 				},
 				"context": map[string]interface{}{
 					"type":        "integer",
 					"description": "Number of context lines in the patch (default: 3)",
 				},
 			},
-			"required": []string{"mode", "path"},
+			"required": []string{"mode"},
 		},
 	)
 	return tool
 }
 
 // Execute implements the Tool interface
-func (t *PatchTool) Execute(input json.RawMessage) (interface{}, error) {
+func (t *PatchTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var params PatchInput
 	if err := json.Unmarshal(input, &params); err != nil {
 		return nil, fmt.Errorf("invalid input for patch tool: %w", err)
@@ -95,35 +120,32 @@ func (t *PatchTool) Execute(input json.RawMessage) (interface{}, error) {
 		params.Context = 3 // Default context lines
 	}
 
-	// Validate common parameters
-	if params.Path == "" {
-		return nil, fmt.Errorf("path parameter is required")
-	}
-
-	// Get absolute path
-	absPath, err := filepath.Abs(params.Path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get absolute path: %w", err)
-	}
-
 	// Choose operation based on mode
 	switch params.Mode {
 	case "create":
-		return t.createPatch(params, absPath)
+		return t.createPatch(params)
 	case "apply":
-		return t.applyPatch(params, absPath)
+		return t.applyPatch(ctx, params)
 	default:
 		return nil, fmt.Errorf("invalid mode %q, must be 'create' or 'apply'", params.Mode)
 	}
 }
 
 // createPatch generates a unified diff between original and modified content
-func (t *PatchTool) createPatch(params PatchInput, absPath string) (interface{}, error) {
+func (t *PatchTool) createPatch(params PatchInput) (interface{}, error) {
 	// Validate create mode parameters
+	if params.Path == "" {
+		return nil, fmt.Errorf("path parameter is required")
+	}
 	if params.Modified == "" {
 		return nil, fmt.Errorf("modified content is required in create mode")
 	}
 
+	absPath, err := filepath.Abs(params.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
 	// If original is not provided, try to read the file content as original
 	if params.Original == "" {
 		content, err := os.ReadFile(absPath)
@@ -133,161 +155,229 @@ func (t *PatchTool) createPatch(params PatchInput, absPath string) (interface{},
 		params.Original = string(content)
 	}
 
-	// Create temporary files for diff
-	origFile, err := os.CreateTemp("", "patch-orig-*.txt")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer os.Remove(origFile.Name())
-	defer origFile.Close()
-
-	modFile, err := os.CreateTemp("", "patch-mod-*.txt")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer os.Remove(modFile.Name())
-	defer modFile.Close()
-
-	// Write content to temp files
-	if _, err := origFile.WriteString(params.Original); err != nil {
-		return nil, fmt.Errorf("failed to write original content: %w", err)
-	}
-
-	if _, err := modFile.WriteString(params.Modified); err != nil {
-		return nil, fmt.Errorf("failed to write modified content: %w", err)
-	}
-
-	// Close files to ensure content is flushed
-	origFile.Close()
-	modFile.Close()
-
-	// Run diff command to generate unified diff
-	cmd := exec.Command(
-		"diff",
-		"-u",
-		fmt.Sprintf("-U%d", params.Context),
-		"--label", filepath.Base(absPath),
-		"--label", filepath.Base(absPath),
-		origFile.Name(),
-		modFile.Name(),
-	)
-
-	output, err := cmd.CombinedOutput()
-	// diff returns exit code 1 when files differ, which is what we want
-	if err != nil && cmd.ProcessState.ExitCode() > 1 {
-		return nil, fmt.Errorf("diff command failed: %w", err)
-	}
-
-	// Return patch output
+	name := filepath.Base(absPath)
 	return PatchOutput{
 		Success:     true,
 		Mode:        "create",
 		Path:        absPath,
-		PatchOutput: string(output),
+		PatchOutput: diff.Unified(name, name, params.Original, params.Modified, params.Context),
 		DryRun:      params.DryRun,
 	}, nil
 }
 
-// applyPatch applies a patch to a file
-func (t *PatchTool) applyPatch(params PatchInput, absPath string) (interface{}, error) {
+// applyPatch applies a patch to the files it names. Every file patch is
+// applied in memory first, so a patch that does not apply completely leaves
+// all files unchanged.
+func (t *PatchTool) applyPatch(ctx context.Context, params PatchInput) (interface{}, error) {
 	// Validate apply mode parameters
 	if params.Patch == "" {
 		return nil, fmt.Errorf("patch content is required in apply mode")
 	}
 
-	// Check if file exists
-	_, err := os.Stat(absPath)
+	patches, err := parsePatch(params.Patch)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("file %s does not exist", absPath)
-		}
-		return nil, fmt.Errorf("failed to check if file exists: %w", err)
+		return nil, fmt.Errorf("invalid patch: %w", err)
 	}
-
-	// Create temp directory for working files
-	tempDir, err := os.MkdirTemp("", "patch-apply")
+	base, targets, err := resolvePatchTargets(params.Path, patches)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+		return nil, err
 	}
-	defer os.RemoveAll(tempDir)
 
-	// Create patch file
-	patchFile := filepath.Join(tempDir, "patch.diff")
-	if err := os.WriteFile(patchFile, []byte(params.Patch), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write patch file: %w", err)
+	workspace := newPatchWorkspace()
+	var files []PatchFileResult
+	var errs []error
+	for i, p := range patches {
+		result, err := workspace.apply(p, targets[i].oldPath, targets[i].newPath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		files = append(files, result)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("patch does not apply, no files were changed: %w", errors.Join(errs...))
 	}
 
-	// Create a backup of the original file
-	backupPath := ""
 	if !params.DryRun {
-		backupPath = absPath + ".bak." + time.Now().Format("20060102_150405")
-		if err := copyFile(absPath, backupPath); err != nil {
-			return nil, fmt.Errorf("failed to create backup: %w", err)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := workspace.commit(); err != nil {
+			return nil, err
 		}
 	}
 
-	// Build patch command
-	args := []string{"-p0", "-u"}
-	if params.DryRun {
-		args = append(args, "--dry-run")
-	}
-	args = append(args, "-i", patchFile)
+	return PatchOutput{
+		Success:     true,
+		Mode:        "apply",
+		Path:        base,
+		PatchOutput: patchSummary(files, params.DryRun),
+		DryRun:      params.DryRun,
+		Files:       files,
+	}, nil
+}
 
-	// Run patch command
-	cmd := exec.Command("patch", args...)
-	cmd.Dir = filepath.Dir(absPath) // Run in the directory of the target file
-	output, err := cmd.CombinedOutput()
+// patchTarget is the file a file patch reads and the file it writes
+type patchTarget struct {
+	oldPath string // "" when the file is created
+	newPath string // "" when the file is deleted
+}
 
+// resolvePatchTargets finds the files changed by each file patch. A path
+// naming a directory, or no path, resolves the names in the patch against
+// that directory; any other path is the file a single-file patch changes.
+func resolvePatchTargets(path string, patches []*filePatch) (string, []patchTarget, error) {
+	if path == "" {
+		path = "."
+	}
+	base, err := filepath.Abs(path)
 	if err != nil {
-		// If patch failed and we made a backup, restore it
-		if !params.DryRun && backupPath != "" {
-			_ = copyFile(backupPath, absPath) // Try to restore, but don't throw error if this fails
+		return "", nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	targets := make([]patchTarget, len(patches))
+	if info, err := os.Stat(base); err != nil || !info.IsDir() {
+		if len(patches) != 1 || patches[0].isRename() {
+			return "", nil, fmt.Errorf("the patch changes %d files; set path to the directory their names are relative to, not to a file", len(patches))
+		}
+		p := patches[0]
+		if !p.isCreate() {
+			targets[0].oldPath = base
 		}
-		return nil, fmt.Errorf("patch command failed: %s: %w", string(output), err)
+		if !p.isDelete() {
+			targets[0].newPath = base
+		}
+		return base, targets, nil
 	}
 
-	// If dry run is successful, report it
-	if params.DryRun {
-		return PatchOutput{
-			Success:     true,
-			Mode:        "apply",
-			Path:        absPath,
-			PatchOutput: string(output),
-			DryRun:      true,
-		}, nil
+	for i, p := range patches {
+		strip := patchStrip(base, p)
+		if p.OldName != "" {
+			if targets[i].oldPath, err = patchPath(base, p.OldName, strip); err != nil {
+				return "", nil, err
+			}
+		}
+		if p.NewName != "" {
+			if targets[i].newPath, err = patchPath(base, p.NewName, strip); err != nil {
+				return "", nil, err
+			}
+		}
 	}
+	return base, targets, nil
+}
 
-	// Clean up backup if everything was successful and return result
-	if backupPath != "" {
-		os.Remove(backupPath)
+// patchStrip returns the number of leading directories to remove from the
+// names of a file patch: one for the a/ and b/ prefixes of git diffs, or
+// when only the stripped name of an existing file exists, otherwise none
+func patchStrip(base string, p *filePatch) int {
+	if p.Git || ((p.OldName == "" || strings.HasPrefix(p.OldName, "a/")) && (p.NewName == "" || strings.HasPrefix(p.NewName, "b/"))) {
+		return 1
+	}
+	if p.OldName == "" {
+		return 0
+	}
+	if _, err := os.Stat(filepath.Join(base, filepath.FromSlash(p.OldName))); err == nil {
+		return 0
 	}
+	stripped := stripComponents(p.OldName, 1)
+	if _, err := os.Stat(filepath.Join(base, filepath.FromSlash(stripped))); stripped != "" && err == nil {
+		return 1
+	}
+	return 0
+}
 
-	return PatchOutput{
-		Success:     true,
-		Mode:        "apply",
-		Path:        absPath,
-		PatchOutput: string(output),
-		DryRun:      false,
-	}, nil
+// patchPath resolves a file name from a patch against base, refusing names
+// that would reach outside it
+func patchPath(base, name string, strip int) (string, error) {
+	stripped := stripComponents(name, strip)
+	clean := filepath.Clean(filepath.FromSlash(stripped))
+	if stripped == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing to patch %q: file names in a patch must be relative and stay inside %s", name, base)
+	}
+	return filepath.Join(base, clean), nil
 }
 
-// copyFile copies a file from src to dst
-func copyFile(src, dst string) error {
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return fmt.Errorf("failed to open source file: %w", err)
+// stripComponents removes n leading directories from a slash-separated name
+func stripComponents(name string, n int) string {
+	for ; n > 0; n-- {
+		i := strings.Index(name, "/")
+		if i < 0 {
+			return ""
+		}
+		name = name[i+1:]
 	}
-	defer srcFile.Close()
+	return name
+}
 
-	dstFile, err := os.Create(dst)
-	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
+// patchSummary describes the applied file patches the way GNU patch does
+func patchSummary(files []PatchFileResult, dryRun bool) string {
+	var out strings.Builder
+	if dryRun {
+		out.WriteString("dry run, no files were changed\n")
+	}
+	for _, file := range files {
+		switch file.Operation {
+		case "create":
+			fmt.Fprintf(&out, "creating file %s\n", file.Path)
+		case "delete":
+			fmt.Fprintf(&out, "deleting file %s\n", file.Path)
+		case "rename":
+			fmt.Fprintf(&out, "patching file %s (renamed from %s)\n", file.Path, file.From)
+		default:
+			fmt.Fprintf(&out, "patching file %s\n", file.Path)
+		}
+		for _, h := range file.Hunks {
+			if h.Offset == 0 && h.Fuzz == 0 {
+				continue
+			}
+			fmt.Fprintf(&out, "Hunk #%d succeeded at %d", h.Hunk, h.Line)
+			if h.Fuzz > 0 {
+				fmt.Fprintf(&out, " with fuzz %d", h.Fuzz)
+			}
+			if h.Offset != 0 {
+				fmt.Fprintf(&out, " (offset %d lines)", h.Offset)
+			}
+			out.WriteString(".\n")
+		}
+	}
+	return out.String()
+}
+
+// AffectedPaths implements core.FileMutator
+func (t *PatchTool) AffectedPaths(input json.RawMessage) ([]string, error) {
+	var params PatchInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for patch tool: %w", err)
 	}
-	defer dstFile.Close()
 
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		return fmt.Errorf("failed to copy file: %w", err)
+	// Only applying a patch for real touches the disk
+	if params.Mode != "apply" || params.DryRun || params.Patch == "" {
+		return nil, nil
+	}
+	patches, err := parsePatch(params.Patch)
+	if err != nil {
+		return nil, err
+	}
+	_, targets, err := resolvePatchTargets(params.Path, patches)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	var paths []string
+	seen := map[string]bool{}
+	for _, target := range targets {
+		for _, path := range []string{target.oldPath, target.newPath} {
+			if path == "" {
+				continue
+			}
+			// Patches are written through symbolic links
+			for _, written := range writtenPaths(path) {
+				if !seen[written] {
+					seen[written] = true
+					paths = append(paths, written)
+				}
+			}
+		}
+	}
+	return paths, nil
 }
@@ -0,0 +1,387 @@
+package development
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/diff"
+)
+
+// maxPatchFuzz is the number of context lines that may be ignored at each
+// end of a hunk when it does not apply exactly, as in GNU patch
+const maxPatchFuzz = 2
+
+// defaultPatchFileMode is the mode of files created by a patch that does not give one
+const defaultPatchFileMode os.FileMode = 0644
+
+// hunkError describes a hunk that could not be applied
+type hunkError struct {
+	Hunk   int
+	Header string
+	Reason string
+}
+
+// hunksError reports every hunk of a file that did not apply
+type hunksError struct {
+	Hunks []hunkError
+	Total int
+}
+
+func (e *hunksError) Error() string {
+	parts := make([]string, len(e.Hunks))
+	for i, h := range e.Hunks {
+		parts[i] = fmt.Sprintf("hunk #%d of %d (%s) failed: %s", h.Hunk, e.Total, h.Header, h.Reason)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// applyHunks applies the hunks of a file patch to content. Hunks are found
+// near the line their header gives, at any offset, and with up to
+// maxPatchFuzz lines of context ignored at each end when needed.
+func applyHunks(content string, hunks []hunk) (string, []PatchHunkResult, error) {
+	lines := diff.SplitLines(content)
+	crlf := strings.Contains(content, "\r\n")
+
+	var out []string
+	var failed []hunkError
+	results := make([]PatchHunkResult, 0, len(hunks))
+	prev, delta := 0, 0
+	for i, h := range hunks {
+		old, replacement := h.oldLines(), h.newLines()
+		if crlf && !hunkHasCR(h) {
+			// Patches written with \n apply to files with Windows line endings
+			old, replacement = toCRLF(old), toCRLF(replacement)
+		}
+		lead, trail := contextLines(h)
+
+		// A hunk without old lines inserts after line OldStart
+		want := h.OldStart - 1
+		if h.OldLines == 0 {
+			want = h.OldStart
+		}
+
+		found, trimStart, trimEnd := -1, 0, 0
+		for fuzz := 0; fuzz <= maxPatchFuzz && found < 0; fuzz++ {
+			// At least one line of context is kept at each end that has any
+			start, end := min(fuzz, max(lead-1, 0)), min(fuzz, max(trail-1, 0))
+			if fuzz > 0 && start == trimStart && end == trimEnd {
+				continue
+			}
+			trimStart, trimEnd = start, end
+			found = findLines(lines, old[start:len(old)-end], prev, want+delta+start)
+		}
+		if found < 0 {
+			failed = append(failed, hunkError{Hunk: i + 1, Header: h.Header, Reason: mismatchReason(lines, old, max(want+delta, prev))})
+			continue
+		}
+
+		applied := found - trimStart
+		out = append(out, lines[prev:found]...)
+		out = append(out, replacement[trimStart:len(replacement)-trimEnd]...)
+		prev = found + len(old) - trimStart - trimEnd
+		delta = applied - want
+		results = append(results, PatchHunkResult{
+			Hunk:   i + 1,
+			Line:   applied + 1,
+			Offset: delta,
+			Fuzz:   max(trimStart, trimEnd),
+		})
+	}
+	if len(failed) > 0 {
+		return "", nil, &hunksError{Hunks: failed, Total: len(hunks)}
+	}
+	out = append(out, lines[prev:]...)
+	return strings.Join(out, ""), results, nil
+}
+
+// contextLines counts the context lines at the start and end of a hunk
+func contextLines(h hunk) (int, int) {
+	lead := 0
+	for lead < len(h.Ops) && h.Ops[lead].Kind == ' ' {
+		lead++
+	}
+	trail := 0
+	for trail < len(h.Ops)-lead && h.Ops[len(h.Ops)-1-trail].Kind == ' ' {
+		trail++
+	}
+	return lead, trail
+}
+
+// findLines returns the position at or after from where pattern occurs in
+// lines, searching outward from want, or -1
+func findLines(lines, pattern []string, from, want int) int {
+	last := len(lines) - len(pattern)
+	if last < from {
+		return -1
+	}
+	want = min(max(want, from), last)
+	for d := 0; want+d <= last || want-d >= from; d++ {
+		if pos := want + d; pos <= last && linesMatch(lines, pattern, pos) {
+			return pos
+		}
+		if pos := want - d; d > 0 && pos >= from && linesMatch(lines, pattern, pos) {
+			return pos
+		}
+	}
+	return -1
+}
+
+// linesMatch reports whether pattern occurs in lines at pos. The last line
+// of a file matches a patch line even if the patch adds a final newline the
+// file lacks.
+func linesMatch(lines, pattern []string, pos int) bool {
+	for i, line := range pattern {
+		got := lines[pos+i]
+		if got != line && !(pos+i == len(lines)-1 && got == strings.TrimSuffix(line, "\n")) {
+			return false
+		}
+	}
+	return true
+}
+
+// mismatchReason explains why a hunk's old lines are not at pos
+func mismatchReason(lines, old []string, pos int) string {
+	if pos >= len(lines) && len(old) > 0 {
+		return fmt.Sprintf("its lines were not found; the file has only %d lines", len(lines))
+	}
+	for i, line := range old {
+		if pos+i >= len(lines) {
+			return fmt.Sprintf("its lines were not found; the file ends where the patch expects %q", strings.TrimRight(line, "\r\n"))
+		}
+		if got := lines[pos+i]; got != line {
+			return fmt.Sprintf("its lines were not found; at line %d the file has %q where the patch expects %q",
+				pos+i+1, strings.TrimRight(got, "\r\n"), strings.TrimRight(line, "\r\n"))
+		}
+	}
+	return "its lines were not found after the previous hunk"
+}
+
+// hunkHasCR reports whether any line of a hunk has a carriage return
+func hunkHasCR(h hunk) bool {
+	for _, op := range h.Ops {
+		if strings.Contains(op.Line, "\r") {
+			return true
+		}
+	}
+	return false
+}
+
+// toCRLF converts the line endings of lines to \r\n
+func toCRLF(lines []string) []string {
+	converted := make([]string, len(lines))
+	for i, line := range lines {
+		if strings.HasSuffix(line, "\n") {
+			line = strings.TrimSuffix(line, "\n") + "\r\n"
+		}
+		converted[i] = line
+	}
+	return converted
+}
+
+// patchFile is a file as it is on disk and as the patch leaves it
+type patchFile struct {
+	path string
+
+	existed  bool
+	original string
+	perm     os.FileMode
+
+	exists  bool
+	content string
+	mode    os.FileMode
+}
+
+// changed reports whether the patch changes the file on disk
+func (f *patchFile) changed() bool {
+	return f.exists != f.existed || (f.exists && (f.content != f.original || f.mode != f.perm))
+}
+
+// patchWorkspace holds the files a patch touches in memory, so that every
+// file patch is checked before anything is written and a file can be
+// changed by several file patches
+type patchWorkspace struct {
+	files map[string]*patchFile
+	order []string
+}
+
+func newPatchWorkspace() *patchWorkspace {
+	return &patchWorkspace{files: map[string]*patchFile{}}
+}
+
+// file returns a file of the workspace, reading it from disk on first use
+func (w *patchWorkspace) file(path string) (*patchFile, error) {
+	if f, ok := w.files[path]; ok {
+		return f, nil
+	}
+
+	f := &patchFile{path: path}
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return nil, fmt.Errorf("%s is a directory", path)
+	case err == nil:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		f.existed, f.exists = true, true
+		f.original, f.content = string(content), string(content)
+		f.perm, f.mode = info.Mode().Perm(), info.Mode().Perm()
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to check %s: %w", path, err)
+	}
+
+	w.files[path] = f
+	w.order = append(w.order, path)
+	return f, nil
+}
+
+// apply applies one file patch to the workspace
+func (w *patchWorkspace) apply(p *filePatch, oldPath, newPath string) (PatchFileResult, error) {
+	if p.isCreate() {
+		target, err := w.file(newPath)
+		if err != nil {
+			return PatchFileResult{}, err
+		}
+		if target.exists {
+			return PatchFileResult{}, fmt.Errorf("cannot create %s: it already exists", newPath)
+		}
+		content, hunks, err := applyHunks("", p.Hunks)
+		if err != nil {
+			return PatchFileResult{}, fmt.Errorf("%s: %w", newPath, err)
+		}
+		target.exists, target.content, target.mode = true, content, defaultPatchFileMode
+		if p.Mode != 0 {
+			target.mode = p.Mode
+		}
+		return PatchFileResult{Path: newPath, Operation: "create", Hunks: hunks}, nil
+	}
+
+	source, err := w.file(oldPath)
+	if err != nil {
+		return PatchFileResult{}, err
+	}
+	if !source.exists {
+		return PatchFileResult{}, fmt.Errorf("file %s does not exist", oldPath)
+	}
+	content, hunks, err := applyHunks(source.content, p.Hunks)
+	if err != nil {
+		return PatchFileResult{}, fmt.Errorf("%s: %w", oldPath, err)
+	}
+
+	switch {
+	case p.isDelete():
+		if content != "" {
+			return PatchFileResult{}, fmt.Errorf("cannot delete %s: the patch does not remove all of its lines", oldPath)
+		}
+		source.exists, source.content = false, ""
+		return PatchFileResult{Path: oldPath, Operation: "delete", Hunks: hunks}, nil
+
+	case oldPath != newPath:
+		target, err := w.file(newPath)
+		if err != nil {
+			return PatchFileResult{}, err
+		}
+		if target.exists {
+			return PatchFileResult{}, fmt.Errorf("cannot rename %s to %s: it already exists", oldPath, newPath)
+		}
+		target.exists, target.content, target.mode = true, content, source.mode
+		if p.Mode != 0 {
+			target.mode = p.Mode
+		}
+		source.exists, source.content = false, ""
+		return PatchFileResult{Path: newPath, Operation: "rename", From: oldPath, Hunks: hunks}, nil
+	}
+
+	source.content = content
+	if p.Mode != 0 {
+		source.mode = p.Mode
+	}
+	return PatchFileResult{Path: oldPath, Operation: "modify", Hunks: hunks}, nil
+}
+
+// commit writes the workspace to disk. New contents are first written to
+// temporary files next to their targets, so a failure leaves every file as
+// it was; if moving them into place fails part way, the files already
+// replaced are restored.
+func (w *patchWorkspace) commit() error {
+	staged := map[string]*stagedWrite{}
+	var errs []error
+	for _, path := range w.order {
+		f := w.files[path]
+		if !f.changed() || !f.exists {
+			continue
+		}
+		s, err := stageFile(f)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		staged[path] = s
+	}
+	if len(errs) > 0 {
+		for _, s := range staged {
+			s.discard()
+		}
+		return errors.Join(errs...)
+	}
+
+	var done []*patchFile
+	for _, path := range w.order {
+		f := w.files[path]
+		if !f.changed() {
+			continue
+		}
+		var err error
+		if f.exists {
+			err = staged[path].commit()
+			delete(staged, path)
+		} else {
+			err = os.Remove(path)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update %s: %w", path, err))
+			break
+		}
+		done = append(done, f)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for _, s := range staged {
+		s.discard()
+	}
+	for _, f := range done {
+		if err := restoreFile(f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stageFile writes the new content of a file to a temporary file next to
+// the file it replaces
+func stageFile(f *patchFile) (*stagedWrite, error) {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", f.path, err)
+	}
+	return stageWrite(f.path, []byte(f.content), f.mode)
+}
+
+// restoreFile puts a file back the way it was before the patch
+func restoreFile(f *patchFile) error {
+	if !f.existed {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s while undoing the patch: %w", f.path, err)
+		}
+		return nil
+	}
+	if err := writeFileAtomic(f.path, []byte(f.original), f.perm); err != nil {
+		return fmt.Errorf("failed to restore %s while undoing the patch: %w", f.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package development
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// numberedLines returns "line 1\n" through "line n\n"
+func numberedLines(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+// parseHunks parses a single-file patch and returns its hunks
+func parseHunks(t *testing.T, patch string) []hunk {
+	t.Helper()
+	patches, err := parsePatch("--- f\n+++ f\n" + patch)
+	require.NoError(t, err)
+	require.Len(t, patches, 1)
+	return patches[0].Hunks
+}
+
+func TestApplyHunks(t *testing.T) {
+	original := numberedLines(20)
+	patch := "@@ -4,3 +4,3 @@\n line 4\n-line 5\n+line five\n line 6\n" +
+		"@@ -15,3 +15,4 @@\n line 15\n line 16\n+line 16.5\n line 17\n"
+
+	tests := []struct {
+		name    string
+		content string
+		want    []PatchHunkResult
+	}{
+		{
+			name:    "Exact",
+			content: original,
+			want:    []PatchHunkResult{{Hunk: 1, Line: 4}, {Hunk: 2, Line: 15}},
+		},
+		{
+			name:    "Offset",
+			content: "extra 1\nextra 2\n" + original,
+			want:    []PatchHunkResult{{Hunk: 1, Line: 6, Offset: 2}, {Hunk: 2, Line: 17, Offset: 2}},
+		},
+		{
+			name:    "Offset after earlier change",
+			content: strings.Replace(original, "line 10\n", "", 1),
+			want:    []PatchHunkResult{{Hunk: 1, Line: 4}, {Hunk: 2, Line: 14, Offset: -1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, results, err := applyHunks(tt.content, parseHunks(t, patch))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, results)
+			assert.Contains(t, got, "line five\n")
+			assert.Contains(t, got, "line 16\nline 16.5\nline 17\n")
+			assert.NotContains(t, got, "line 5\n")
+		})
+	}
+}
+
+func TestApplyHunksFuzz(t *testing.T) {
+	// Both ends of the hunk's context differ from the file
+	patch := "@@ -3,7 +3,7 @@\n line 3\n line 4\n line 5\n-line 6\n+line six\n line 7\n line 8\n line 9\n"
+	content := strings.NewReplacer("line 3\n", "line III\n", "line 9\n", "line IX\n").Replace(numberedLines(12))
+
+	got, results, err := applyHunks(content, parseHunks(t, patch))
+	require.NoError(t, err)
+	assert.Equal(t, []PatchHunkResult{{Hunk: 1, Line: 3, Fuzz: 1}}, results)
+	assert.Equal(t, strings.Replace(content, "line 6\n", "line six\n", 1), got)
+
+	// Fuzz never drops the last line of context
+	_, _, err = applyHunks("a\nb\nc\n", parseHunks(t, "@@ -1,3 +1,3 @@\n x\n-b\n+B\n y\n"))
+	assert.Error(t, err)
+}
+
+func TestApplyHunksErrors(t *testing.T) {
+	patch := "@@ -2,3 +2,3 @@\n line 2\n-line 3\n+line three\n line 4\n" +
+		"@@ -8,2 +8,2 @@\n-line 8\n+line eight\n line 9\n" +
+		"@@ -30,2 +30,2 @@\n-line 30\n+line thirty\n line 31\n"
+
+	_, _, err := applyHunks(strings.Replace(numberedLines(10), "line 3\n", "line 3!\n", 1), parseHunks(t, patch))
+	require.Error(t, err)
+	var hunksErr *hunksError
+	require.ErrorAs(t, err, &hunksErr)
+	require.Len(t, hunksErr.Hunks, 2)
+	assert.Equal(t, 1, hunksErr.Hunks[0].Hunk)
+	assert.Equal(t, `its lines were not found; at line 3 the file has "line 3!" where the patch expects "line 3"`, hunksErr.Hunks[0].Reason)
+	assert.Equal(t, 3, hunksErr.Hunks[1].Hunk)
+	assert.Contains(t, err.Error(), "hunk #3 of 3 (@@ -30,2 +30,2 @@) failed: its lines were not found; the file has only 10 lines")
+}
+
+func TestApplyHunksLineEndings(t *testing.T) {
+	t.Run("Windows line endings", func(t *testing.T) {
+		got, _, err := applyHunks("a\r\nb\r\nc\r\n", parseHunks(t, "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "a\r\nB\r\nc\r\n", got)
+	})
+
+	t.Run("Missing final newline", func(t *testing.T) {
+		got, _, err := applyHunks("a\nb", parseHunks(t, "@@ -1,2 +1,3 @@\n a\n b\n+c\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "a\nb\nc\n", got)
+	})
+
+	t.Run("Marker", func(t *testing.T) {
+		got, _, err := applyHunks("a\nb", parseHunks(t, "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "a\nb\n", got)
+	})
+
+	t.Run("Insert into empty file", func(t *testing.T) {
+		got, _, err := applyHunks("", parseHunks(t, "@@ -0,0 +1,2 @@\n+a\n+b\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "a\nb\n", got)
+	})
+}
+
+func TestApplyHunksRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		a := randomText(rng)
+		b := mutateText(rng, a)
+		patch := diff.Unified("f", "f", a, b, rng.Intn(4))
+		if patch == "" {
+			continue
+		}
+
+		patches, err := parsePatch(patch)
+		require.NoError(t, err, patch)
+		got, _, err := applyHunks(a, patches[0].Hunks)
+		require.NoError(t, err, patch)
+		require.Equal(t, b, got, patch)
+	}
+}
+
+func TestPatchWorkspaceCommit(t *testing.T) {
+	dir := t.TempDir()
+	keep := filepath.Join(dir, "keep.txt")
+	gone := filepath.Join(dir, "gone.txt")
+	created := filepath.Join(dir, "sub", "new.txt")
+	require.NoError(t, os.WriteFile(keep, []byte("a\n"), 0600))
+	require.NoError(t, os.WriteFile(gone, []byte("b\n"), 0644))
+
+	w := newPatchWorkspace()
+	f, err := w.file(keep)
+	require.NoError(t, err)
+	f.content = "A\n"
+	f, err = w.file(gone)
+	require.NoError(t, err)
+	f.exists = false
+	f, err = w.file(created)
+	require.NoError(t, err)
+	f.exists, f.content, f.mode = true, "c\n", 0755
+	require.NoError(t, w.commit())
+
+	content, err := os.ReadFile(keep)
+	require.NoError(t, err)
+	assert.Equal(t, "A\n", string(content))
+	info, err := os.Stat(keep)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.NoFileExists(t, gone)
+	info, err = os.Stat(created)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary files are left behind")
+}
+
+func TestPatchWorkspaceCommitSymlink(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "real.txt")
+	link := filepath.Join(dir, "link.txt")
+	require.NoError(t, os.WriteFile(real, []byte("a\n"), 0644))
+	require.NoError(t, os.Symlink("real.txt", link))
+
+	w := newPatchWorkspace()
+	f, err := w.file(link)
+	require.NoError(t, err)
+	f.content = "A\n"
+	require.NoError(t, w.commit())
+
+	content, err := os.ReadFile(real)
+	require.NoError(t, err)
+	assert.Equal(t, "A\n", string(content), "the link's target is patched")
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type(), "the link is kept")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary files are left behind")
+}
+
+// randomText returns up to 30 lines drawn from a small vocabulary, so that
+// lines repeat, sometimes without a final newline
+func randomText(rng *rand.Rand) string {
+	var b strings.Builder
+	for i := rng.Intn(30); i > 0; i-- {
+		fmt.Fprintf(&b, "w%d\n", rng.Intn(6))
+	}
+	text := b.String()
+	if rng.Intn(4) == 0 {
+		text = strings.TrimSuffix(text, "\n")
+	}
+	return text
+}
+
+// mutateText inserts, deletes and replaces random lines of text
+func mutateText(rng *rand.Rand, text string) string {
+	lines := diff.SplitLines(text)
+	for i := rng.Intn(5); i > 0; i-- {
+		pos := 0
+		if len(lines) > 0 {
+			pos = rng.Intn(len(lines))
+		}
+		switch rng.Intn(3) {
+		case 0:
+			lines = append(lines[:pos], append([]string{fmt.Sprintf("new%d\n", rng.Intn(6))}, lines[pos:]...)...)
+		case 1:
+			if len(lines) > 0 {
+				lines = append(lines[:pos], lines[pos+1:]...)
+			}
+		case 2:
+			if len(lines) > 0 {
+				lines[pos] = fmt.Sprintf("changed%d\n", rng.Intn(6))
+			}
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,312 @@
+package development
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/diff"
+)
+
+// devNull is the name diff uses for the missing side of a created or deleted file
+const devNull = "/dev/null"
+
+// filePatch is the part of a unified diff that changes one file
+type filePatch struct {
+	OldName string // Name on the --- line or in "rename from", "" for a created file
+	NewName string // Name on the +++ line or in "rename to", "" for a deleted file
+	Git     bool   // The patch has a "diff --git" header with a/ and b/ prefixes
+	Mode    os.FileMode
+	Hunks   []hunk
+}
+
+// hunk is one @@ section of a file patch
+type hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Ops                []diff.Op
+	Header             string // The @@ line, for error messages
+}
+
+// isCreate reports whether the patch creates its file
+func (p *filePatch) isCreate() bool {
+	return p.OldName == "" && p.NewName != ""
+}
+
+// isDelete reports whether the patch deletes its file
+func (p *filePatch) isDelete() bool {
+	return p.NewName == "" && p.OldName != ""
+}
+
+// isRename reports whether the patch moves its file
+func (p *filePatch) isRename() bool {
+	return p.OldName != "" && p.NewName != "" && p.OldName != p.NewName
+}
+
+// oldLines returns the lines a hunk expects to find
+func (h *hunk) oldLines() []string {
+	var lines []string
+	for _, op := range h.Ops {
+		if op.Kind != '+' {
+			lines = append(lines, op.Line)
+		}
+	}
+	return lines
+}
+
+// newLines returns the lines a hunk puts in place of its old lines
+func (h *hunk) newLines() []string {
+	var lines []string
+	for _, op := range h.Ops {
+		if op.Kind != '-' {
+			lines = append(lines, op.Line)
+		}
+	}
+	return lines
+}
+
+// parsePatch reads the file patches of a unified diff. Text between file
+// patches, such as a commit message or "index" lines, is skipped.
+func parsePatch(text string) ([]*filePatch, error) {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	lines := diff.SplitLines(text)
+	var patches []*filePatch
+	var current *filePatch
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			oldName, newName := parseGitNames(strings.TrimPrefix(line, "diff --git "))
+			current = &filePatch{OldName: oldName, NewName: newName, Git: true}
+			patches = append(patches, current)
+
+		case current != nil && current.Git && len(current.Hunks) == 0 && gitExtendedHeader(current, line):
+
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			oldName := parseHeaderName(strings.TrimPrefix(line, "--- "))
+			newName := parseHeaderName(strings.TrimPrefix(strings.TrimRight(lines[i+1], "\r\n"), "+++ "))
+			i++
+			// A git header already started this file; otherwise this is a new one
+			if current == nil || !current.Git || len(current.Hunks) > 0 {
+				current = &filePatch{}
+				patches = append(patches, current)
+			}
+			current.OldName, current.NewName = oldName, newName
+			if oldName == devNull {
+				current.OldName = ""
+			}
+			if newName == devNull {
+				current.NewName = ""
+			}
+
+		case strings.HasPrefix(line, "@@ "):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: hunk without a --- and +++ file header", i+1)
+			}
+			h, next, err := parseHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			current.Hunks = append(current.Hunks, h)
+			i = next - 1
+
+		case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+			return nil, fmt.Errorf("line %d: binary patches are not supported", i+1)
+		}
+	}
+
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no file patches found; expected unified diff headers (--- old, +++ new) followed by @@ hunks")
+	}
+	for _, p := range patches {
+		if p.OldName == "" && p.NewName == "" {
+			return nil, fmt.Errorf("a file patch has neither an old nor a new file name")
+		}
+		if len(p.Hunks) == 0 && p.Mode == 0 && !p.isRename() && !p.isCreate() && !p.isDelete() {
+			return nil, fmt.Errorf("patch for %s has no hunks", p.displayName())
+		}
+	}
+	return patches, nil
+}
+
+// gitExtendedHeader records a line of the extended header that follows
+// "diff --git", reporting whether it was one
+func gitExtendedHeader(p *filePatch, line string) bool {
+	switch {
+	case strings.HasPrefix(line, "new file mode "):
+		p.OldName = ""
+		p.Mode = parseGitMode(strings.TrimPrefix(line, "new file mode "))
+	case strings.HasPrefix(line, "deleted file mode "):
+		p.NewName = ""
+	case strings.HasPrefix(line, "new mode "):
+		p.Mode = parseGitMode(strings.TrimPrefix(line, "new mode "))
+	case strings.HasPrefix(line, "rename from "):
+		p.OldName = "a/" + unquoteName(strings.TrimPrefix(line, "rename from "))
+	case strings.HasPrefix(line, "rename to "):
+		p.NewName = "b/" + unquoteName(strings.TrimPrefix(line, "rename to "))
+	case strings.HasPrefix(line, "old mode "), strings.HasPrefix(line, "index "),
+		strings.HasPrefix(line, "similarity index "), strings.HasPrefix(line, "dissimilarity index "),
+		strings.HasPrefix(line, "copy from "), strings.HasPrefix(line, "copy to "):
+	default:
+		return false
+	}
+	return true
+}
+
+// parseHunk reads the hunk whose @@ line is lines[start], returning the
+// index of the line after it
+func parseHunk(lines []string, start int) (hunk, int, error) {
+	header := strings.TrimRight(lines[start], "\r\n")
+	h := hunk{Header: header, OldLines: 1, NewLines: 1}
+	ranges := strings.Fields(header)
+	if len(ranges) < 3 || !strings.HasPrefix(ranges[1], "-") || !strings.HasPrefix(ranges[2], "+") {
+		return h, 0, fmt.Errorf("line %d: malformed hunk header %q", start+1, header)
+	}
+	var err error
+	if h.OldStart, h.OldLines, err = parseRange(ranges[1][1:]); err == nil {
+		h.NewStart, h.NewLines, err = parseRange(ranges[2][1:])
+	}
+	if err != nil {
+		return h, 0, fmt.Errorf("line %d: malformed hunk header %q: %w", start+1, header, err)
+	}
+
+	oldCount, newCount := 0, 0
+	i := start + 1
+	for ; i < len(lines) && (oldCount < h.OldLines || newCount < h.NewLines); i++ {
+		line := lines[i]
+		kind := byte(' ')
+		text := "\n"
+		// Editors often strip the space from an empty context line
+		if line != "\n" && line != "\r\n" {
+			kind, text = line[0], line[1:]
+		}
+		switch kind {
+		case ' ':
+			oldCount++
+			newCount++
+		case '-':
+			oldCount++
+		case '+':
+			newCount++
+		case '\\':
+			markNoNewline(&h)
+			continue
+		default:
+			return h, 0, fmt.Errorf("line %d: hunk %s ends after %d of %d old and %d of %d new lines; check the line counts in its header",
+				i+1, header, oldCount, h.OldLines, newCount, h.NewLines)
+		}
+		h.Ops = append(h.Ops, diff.Op{Kind: kind, Line: text})
+	}
+	if oldCount != h.OldLines || newCount != h.NewLines {
+		return h, 0, fmt.Errorf("hunk %s has %d old and %d new lines but its header says %d and %d",
+			header, oldCount, newCount, h.OldLines, h.NewLines)
+	}
+	if i < len(lines) && strings.HasPrefix(lines[i], "\\") {
+		markNoNewline(&h)
+		i++
+	}
+	return h, i, nil
+}
+
+// markNoNewline applies a "\ No newline at end of file" marker to the last line of a hunk
+func markNoNewline(h *hunk) {
+	if len(h.Ops) > 0 {
+		last := &h.Ops[len(h.Ops)-1]
+		last.Line = strings.TrimSuffix(strings.TrimSuffix(last.Line, "\n"), "\r")
+	}
+}
+
+// parseRange parses the "start,count" of a hunk header; a missing count is 1
+func parseRange(s string) (int, int, error) {
+	startText, countText, found := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, err
+	}
+	count := 1
+	if found {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, err
+		}
+	}
+	if start < 0 || count < 0 {
+		return 0, 0, fmt.Errorf("negative line number")
+	}
+	return start, count, nil
+}
+
+// parseHeaderName returns the file name of a --- or +++ line, without the
+// timestamp diff puts after a tab
+func parseHeaderName(s string) string {
+	if name, _, found := strings.Cut(s, "\t"); found {
+		s = name
+	}
+	return unquoteName(strings.TrimSpace(s))
+}
+
+// parseGitNames splits the "a/old b/new" of a diff --git line
+func parseGitNames(s string) (string, string) {
+	if strings.HasPrefix(s, `"`) {
+		// Quoted names have no ambiguous spaces
+		if end := closingQuote(s); end > 0 {
+			return unquoteName(s[:end+1]), unquoteName(strings.TrimSpace(s[end+1:]))
+		}
+	}
+	// Both names are the same unless the file is renamed, which the
+	// extended header reports separately
+	if len(s)%2 == 1 {
+		half := len(s) / 2
+		if s[half] == ' ' && strings.TrimPrefix(s[:half], "a/") == strings.TrimPrefix(s[half+1:], "b/") {
+			return s[:half], s[half+1:]
+		}
+	}
+	if i := strings.LastIndex(s, " b/"); i > 0 {
+		return s[:i], s[i+1:]
+	}
+	oldName, newName, _ := strings.Cut(s, " ")
+	return oldName, newName
+}
+
+// closingQuote returns the index of the quote ending a quoted name at the start of s
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteName removes the C-style quoting git uses for unusual file names
+func unquoteName(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		if name, err := strconv.Unquote(s); err == nil {
+			return name
+		}
+	}
+	return s
+}
+
+// parseGitMode converts a git file mode such as 100755 to its permission bits
+func parseGitMode(s string) os.FileMode {
+	mode, err := strconv.ParseUint(strings.TrimSpace(s), 8, 32)
+	if err != nil {
+		return 0
+	}
+	return os.FileMode(mode) & os.ModePerm
+}
+
+// displayName returns the name of the file a patch changes, for messages
+func (p *filePatch) displayName() string {
+	if p.NewName != "" {
+		return p.NewName
+	}
+	return p.OldName
+}
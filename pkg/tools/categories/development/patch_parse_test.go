@@ -0,0 +1,159 @@
+package development
+
+import (
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gitPatch = `From 1234 Mon Sep 17 00:00:00 2001
+Subject: [PATCH] Reorganize
+
+diff --git a/main.go b/main.go
+index 83db48f..bf269f4 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-import "fmt"
++import "log"
+ 
+diff --git a/docs/new.md b/docs/new.md
+new file mode 100755
+index 0000000..e69de29
+--- /dev/null
++++ b/docs/new.md
+@@ -0,0 +1,2 @@
++# New
++text
+\ No newline at end of file
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+diff --git a/util.go b/pkg/util.go
+similarity index 90%
+rename from util.go
+rename to pkg/util.go
+--- a/util.go
++++ b/pkg/util.go
+@@ -1,2 +1,2 @@
+-package main
++package pkg
+ 
+diff --git a/with space.txt b/with space.txt
+similarity index 100%
+rename from with space.txt
+rename to moved space.txt
+`
+
+func TestParsePatch(t *testing.T) {
+	patches, err := parsePatch(gitPatch)
+	require.NoError(t, err)
+	require.Len(t, patches, 5)
+
+	modify := patches[0]
+	assert.Equal(t, "a/main.go", modify.OldName)
+	assert.Equal(t, "b/main.go", modify.NewName)
+	assert.True(t, modify.Git)
+	require.Len(t, modify.Hunks, 1)
+	assert.Equal(t, hunk{
+		OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+		Ops: []diff.Op{
+			{Kind: ' ', Line: "package main\n"},
+			{Kind: '-', Line: "import \"fmt\"\n"},
+			{Kind: '+', Line: "import \"log\"\n"},
+			{Kind: ' ', Line: "\n"},
+		},
+		Header: "@@ -1,3 +1,3 @@",
+	}, modify.Hunks[0])
+	assert.Equal(t, []string{"package main\n", "import \"fmt\"\n", "\n"}, modify.Hunks[0].oldLines())
+	assert.Equal(t, []string{"package main\n", "import \"log\"\n", "\n"}, modify.Hunks[0].newLines())
+
+	create := patches[1]
+	assert.True(t, create.isCreate())
+	assert.Equal(t, "b/docs/new.md", create.NewName)
+	assert.Equal(t, 0755, int(create.Mode))
+	assert.Equal(t, []string{"# New\n", "text"}, create.Hunks[0].newLines(), "the last line has no newline")
+
+	assert.True(t, patches[2].isDelete())
+	assert.Equal(t, "a/old.txt", patches[2].OldName)
+
+	rename := patches[3]
+	assert.True(t, rename.isRename())
+	assert.Equal(t, "a/util.go", rename.OldName)
+	assert.Equal(t, "b/pkg/util.go", rename.NewName)
+	assert.Len(t, rename.Hunks, 1)
+
+	pureRename := patches[4]
+	assert.True(t, pureRename.isRename())
+	assert.Equal(t, "a/with space.txt", pureRename.OldName)
+	assert.Equal(t, "b/moved space.txt", pureRename.NewName)
+	assert.Empty(t, pureRename.Hunks)
+}
+
+func TestParsePatchPlain(t *testing.T) {
+	patch := "--- src/a.c\t2024-01-01 10:00:00.000000000 +0000\n" +
+		"+++ src/a.c\t2024-01-02 10:00:00.000000000 +0000\n" +
+		"@@ -2 +2,2 @@\n" +
+		"-old\n" +
+		"+new\n" +
+		"+more\n" +
+		"@@ -10,2 +11,2 @@\n" +
+		"\n" +
+		"-x\n" +
+		"+y" // No final newline on the patch text itself
+
+	patches, err := parsePatch(patch)
+	require.NoError(t, err)
+	require.Len(t, patches, 1)
+	assert.Equal(t, "src/a.c", patches[0].OldName)
+	assert.False(t, patches[0].Git)
+	require.Len(t, patches[0].Hunks, 2)
+	assert.Equal(t, 2, patches[0].Hunks[0].OldStart)
+	assert.Equal(t, 1, patches[0].Hunks[0].OldLines)
+	assert.Equal(t, []diff.Op{{Kind: ' ', Line: "\n"}, {Kind: '-', Line: "x\n"}, {Kind: '+', Line: "y\n"}}, patches[0].Hunks[1].Ops, "an empty line is context")
+}
+
+func TestParsePatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{name: "Empty", patch: "just some text\n", want: "no file patches found"},
+		{name: "Hunk without header", patch: "@@ -1 +1 @@\n-a\n+b\n", want: "hunk without a --- and +++ file header"},
+		{name: "Bad header", patch: "--- a\n+++ a\n@@ -x +1 @@\n", want: "malformed hunk header"},
+		{name: "Short hunk", patch: "--- a\n+++ a\n@@ -1,3 +1,3 @@\n a\n-b\n+c\n", want: "has 2 old and 2 new lines but its header says 3 and 3"},
+		{name: "Bad line", patch: "--- a\n+++ a\n@@ -1,2 +1,2 @@\n a\n*b\n", want: "check the line counts"},
+		{name: "Binary", patch: "diff --git a/x.png b/x.png\nBinary files a/x.png and b/x.png differ\n", want: "binary patches are not supported"},
+		{name: "No hunks", patch: "--- a\n+++ a\n", want: "has no hunks"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePatch(tt.patch)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestParseGitNames(t *testing.T) {
+	tests := []struct {
+		line, oldName, newName string
+	}{
+		{"a/x.go b/x.go", "a/x.go", "b/x.go"},
+		{"a/dir b/x b/dir b/x", "a/dir b/x", "b/dir b/x"},
+		{"a/old.go b/new.go", "a/old.go", "b/new.go"},
+		{`"a/tab\there" "b/tab\there"`, "a/tab\there", "b/tab\there"},
+	}
+	for _, tt := range tests {
+		oldName, newName := parseGitNames(tt.line)
+		assert.Equal(t, tt.oldName, oldName, tt.line)
+		assert.Equal(t, tt.newName, newName, tt.line)
+	}
+}
@@ -1,11 +1,15 @@
 package development
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPatchTool(t *testing.T) {
@@ -38,7 +42,7 @@ func TestPatchTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		result, err := patchTool.Execute(jsonInput)
+		result, err := patchTool.Execute(context.Background(), jsonInput)
 
 		if err != nil {
 			t.Fatalf("Failed to create patch: %v", err)
@@ -75,7 +79,7 @@ func TestPatchTool(t *testing.T) {
 			}
 
 			jsonInput, _ := json.Marshal(input)
-			result, err := patchTool.Execute(jsonInput)
+			result, err := patchTool.Execute(context.Background(), jsonInput)
 
 			if err != nil {
 				t.Fatalf("Failed to apply patch (dry run): %v", err)
@@ -104,7 +108,7 @@ func TestPatchTool(t *testing.T) {
 			}
 
 			jsonInput, _ := json.Marshal(input)
-			result, err := patchTool.Execute(jsonInput)
+			result, err := patchTool.Execute(context.Background(), jsonInput)
 
 			if err != nil {
 				t.Fatalf("Failed to apply patch: %v", err)
@@ -141,7 +145,7 @@ func TestPatchTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		result, err := patchTool.Execute(jsonInput)
+		result, err := patchTool.Execute(context.Background(), jsonInput)
 
 		if err != nil {
 			t.Fatalf("Failed to create patch from file: %v", err)
@@ -177,7 +181,7 @@ func TestPatchTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		_, err := patchTool.Execute(jsonInput)
+		_, err := patchTool.Execute(context.Background(), jsonInput)
 
 		if err == nil {
 			t.Errorf("Expected error when applying patch to non-existent file")
@@ -192,10 +196,194 @@ func TestPatchTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(input)
-		_, err := patchTool.Execute(jsonInput)
+		_, err := patchTool.Execute(context.Background(), jsonInput)
 
 		if err == nil {
 			t.Errorf("Expected error for invalid mode")
 		}
 	})
 }
+
+// patchTree writes the files a multi-file patch test starts from
+func patchTree(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n",
+		"old.txt": "gone\n",
+		"util.go": "package main\n\nfunc util() {}\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+const treePatch = `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,7 +1,7 @@
+ package main
+ 
+-import "fmt"
++import "log"
+ 
+ func main() {
+-	fmt.Println("hi")
++	log.Println("hi")
+ }
+diff --git a/docs/new.md b/docs/new.md
+new file mode 100644
+--- /dev/null
++++ b/docs/new.md
+@@ -0,0 +1 @@
++# New
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+diff --git a/util.go b/pkg/util.go
+similarity index 80%
+rename from util.go
+rename to pkg/util.go
+--- a/util.go
++++ b/pkg/util.go
+@@ -1,3 +1,3 @@
+-package main
++package pkg
+ 
+ func util() {}
+`
+
+func runPatch(t *testing.T, input PatchInput) (PatchOutput, error) {
+	t.Helper()
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	result, err := NewPatchTool().Execute(context.Background(), data)
+	if err != nil {
+		return PatchOutput{}, err
+	}
+	return result.(PatchOutput), nil
+}
+
+func TestPatchToolMultipleFiles(t *testing.T) {
+	dir := patchTree(t)
+
+	output, err := runPatch(t, PatchInput{Mode: "apply", Path: dir, Patch: treePatch, DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, output.DryRun)
+	assert.Equal(t, []PatchFileResult{
+		{Path: filepath.Join(dir, "main.go"), Operation: "modify", Hunks: []PatchHunkResult{{Hunk: 1, Line: 1}}},
+		{Path: filepath.Join(dir, "docs", "new.md"), Operation: "create", Hunks: []PatchHunkResult{{Hunk: 1, Line: 1}}},
+		{Path: filepath.Join(dir, "old.txt"), Operation: "delete", Hunks: []PatchHunkResult{{Hunk: 1, Line: 1}}},
+		{Path: filepath.Join(dir, "pkg", "util.go"), Operation: "rename", From: filepath.Join(dir, "util.go"), Hunks: []PatchHunkResult{{Hunk: 1, Line: 1}}},
+	}, output.Files)
+	assert.FileExists(t, filepath.Join(dir, "old.txt"), "a dry run changes nothing")
+	assert.NoFileExists(t, filepath.Join(dir, "docs", "new.md"))
+
+	output, err = runPatch(t, PatchInput{Mode: "apply", Path: dir, Patch: treePatch})
+	require.NoError(t, err)
+	assert.Len(t, output.Files, 4)
+	assert.Contains(t, output.PatchOutput, "creating file "+filepath.Join(dir, "docs", "new.md"))
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nimport \"log\"\n\nfunc main() {\n\tlog.Println(\"hi\")\n}\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "docs", "new.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# New\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "pkg", "util.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package pkg\n\nfunc util() {}\n", string(content))
+	assert.NoFileExists(t, filepath.Join(dir, "old.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "util.go"))
+}
+
+func TestPatchToolAtomic(t *testing.T) {
+	dir := patchTree(t)
+	// The second file no longer matches the patch
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.txt"), []byte("changed\n"), 0644))
+
+	_, err := runPatch(t, PatchInput{Mode: "apply", Path: dir, Patch: treePatch})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no files were changed")
+	assert.Contains(t, err.Error(), `at line 1 the file has "changed" where the patch expects "gone"`)
+
+	content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "fmt", "files that would have applied are unchanged")
+	assert.NoFileExists(t, filepath.Join(dir, "docs", "new.md"))
+	assert.FileExists(t, filepath.Join(dir, "util.go"))
+}
+
+func TestPatchToolOffset(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("new first line\n"+numberedLines(10)), 0644))
+
+	// The patch was made before the first line was added
+	patch := "--- notes.txt\n+++ notes.txt\n@@ -4,3 +4,3 @@\n line 4\n-line 5\n+line five\n line 6\n"
+	output, err := runPatch(t, PatchInput{Mode: "apply", Path: path, Patch: patch})
+	require.NoError(t, err)
+	assert.Equal(t, []PatchHunkResult{{Hunk: 1, Line: 5, Offset: 1}}, output.Files[0].Hunks)
+	assert.Contains(t, output.PatchOutput, "Hunk #1 succeeded at 5 (offset 1 lines).")
+}
+
+func TestPatchToolPaths(t *testing.T) {
+	dir := patchTree(t)
+
+	t.Run("Outside directory", func(t *testing.T) {
+		_, err := runPatch(t, PatchInput{Mode: "apply", Path: dir, Patch: "--- ../escape.txt\n+++ ../escape.txt\n@@ -1 +1 @@\n-a\n+b\n"})
+		assert.ErrorContains(t, err, "must be relative and stay inside")
+	})
+
+	t.Run("Multiple files with file path", func(t *testing.T) {
+		_, err := runPatch(t, PatchInput{Mode: "apply", Path: filepath.Join(dir, "main.go"), Patch: treePatch})
+		assert.ErrorContains(t, err, "the patch changes 4 files")
+	})
+
+	t.Run("Existing file to create", func(t *testing.T) {
+		_, err := runPatch(t, PatchInput{Mode: "apply", Path: dir, Patch: "--- /dev/null\n+++ util.go\n@@ -0,0 +1 @@\n+x\n"})
+		assert.ErrorContains(t, err, "it already exists")
+	})
+
+	t.Run("Prefixed names without git header", func(t *testing.T) {
+		patch := "--- x/util.go\n+++ y/util.go\n@@ -1 +1 @@\n-package main\n+package util\n"
+		output, err := runPatch(t, PatchInput{Mode: "apply", Path: dir, Patch: patch, DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "util.go"), output.Files[0].Path)
+	})
+}
+
+func TestPatchToolAffectedPaths(t *testing.T) {
+	dir := patchTree(t)
+	tool := NewPatchTool()
+
+	input, _ := json.Marshal(PatchInput{Mode: "apply", Path: dir, Patch: treePatch})
+	paths, err := tool.AffectedPaths(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "main.go"),
+		filepath.Join(dir, "docs", "new.md"),
+		filepath.Join(dir, "old.txt"),
+		filepath.Join(dir, "util.go"),
+		filepath.Join(dir, "pkg", "util.go"),
+	}, paths)
+
+	input, _ = json.Marshal(PatchInput{Mode: "apply", Path: dir, Patch: treePatch, DryRun: true})
+	paths, err = tool.AffectedPaths(input)
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+
+	// The file behind a symbolic link is reported too, as the patch is written through it
+	linkDir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(linkDir, "real.txt"), []byte("a\n"), 0644))
+	require.NoError(t, os.Symlink("real.txt", filepath.Join(linkDir, "link.txt")))
+	input, _ = json.Marshal(PatchInput{Mode: "apply", Path: linkDir, Patch: "--- a/link.txt\n+++ b/link.txt\n@@ -1 +1 @@\n-a\n+b\n"})
+	paths, err = tool.AffectedPaths(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(linkDir, "link.txt"), filepath.Join(linkDir, "real.txt")}, paths)
+}
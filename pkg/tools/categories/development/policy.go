@@ -0,0 +1,65 @@
+package development
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/policy"
+)
+
+// commandPolicy is embedded in tools that run shell commands. It holds the
+// policy set by the tool manager and enforces its decisions.
+type commandPolicy struct {
+	mu     sync.RWMutex
+	policy *policy.Policy
+}
+
+// SetCommandPolicy sets the policy commands are checked against; nil allows all commands
+func (c *commandPolicy) SetCommandPolicy(p *policy.Policy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.policy = p
+}
+
+// currentPolicy returns the current policy, which may be nil
+func (c *commandPolicy) currentPolicy() *policy.Policy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.policy
+}
+
+// enforce returns nil if a command may run. Commands the policy marks "ask"
+// run only if the user approves them. The errors explain the refusal to the
+// model so it does not try to work around it.
+func (c *commandPolicy) enforce(ctx context.Context, tool string, decision policy.Decision) error {
+	switch decision.Action {
+	case policy.Allow:
+		return nil
+
+	case policy.Ask:
+		approved, asked := core.RequestApproval(ctx, core.ApprovalRequest{
+			Tool:   tool,
+			Action: decision.Command,
+			Reason: decision.Reason(),
+		})
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !asked {
+			return fmt.Errorf("the command policy requires the user's approval to run `%s` (%s), "+
+				"but there is no user to ask; tell the user the command you need instead", decision.Command, decision.Reason())
+		}
+		if !approved {
+			return fmt.Errorf("the user declined to run `%s`; do not retry it, ask the user how to proceed", decision.Command)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("the command policy does not allow `%s`: %s. Do not try to run it another way; "+
+			"tell the user what you wanted to run and why", decision.Command, decision.Reason())
+	}
+}
@@ -11,11 +11,28 @@ func Register(registry core.ToolRegistrar) error {
 		return err
 	}
 
+	// Register shell_session tool
+	if err := registry.RegisterTool("development", NewShellSessionTool()); err != nil {
+		return err
+	}
+
+	// Register background job tools, which share the jobs of the session
+	for _, tool := range NewJobTools(NewJobManager()) {
+		if err := registry.RegisterTool("development", tool); err != nil {
+			return err
+		}
+	}
+
 	// Register file_write tool
 	if err := registry.RegisterTool("development", NewFileWriteTool()); err != nil {
 		return err
 	}
 
+	// Register file_edit tool
+	if err := registry.RegisterTool("development", NewFileEditTool()); err != nil {
+		return err
+	}
+
 	// Register patch tool
 	if err := registry.RegisterTool("development", NewPatchTool()); err != nil {
 		return err
@@ -2,6 +2,7 @@ package development
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
@@ -9,11 +10,14 @@ import (
 	"time"
 
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
 )
 
 // ShellTool allows executing shell commands
 type ShellTool struct {
 	core.BaseToolImpl
+	commandPolicy
+	sandbox.Holder
 }
 
 // ShellInput represents parameters for running a shell command
@@ -37,14 +41,15 @@ func NewShellTool() *ShellTool {
 	tool := &ShellTool{}
 	tool.BaseToolImpl = *core.NewBaseTool(
 		"shell",
-		"Execute a shell command on macOS",
+		"Execute a single command without a shell. Use shell_session for pipelines, globs, "+
+			"redirection, or state such as the working directory that persists between calls.",
 		"development",
 		map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
 				"command": map[string]interface{}{
 					"type":        "string",
-					"description": "The shell command to execute",
+					"description": "The program to execute",
 				},
 				"args": map[string]interface{}{
 					"type":        "array",
@@ -69,7 +74,11 @@ func NewShellTool() *ShellTool {
 }
 
 // Execute runs a shell command based on the provided input
-func (t *ShellTool) Execute(input json.RawMessage) (interface{}, error) {
+func (t *ShellTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var params ShellInput
 	if err := json.Unmarshal(input, &params); err != nil {
 		return nil, fmt.Errorf("invalid input for shell tool: %w", err)
@@ -80,23 +89,34 @@ func (t *ShellTool) Execute(input json.RawMessage) (interface{}, error) {
 		return nil, fmt.Errorf("command parameter is required")
 	}
 
+	// Check the command policy before starting anything
+	argv := append([]string{params.Command}, params.Args...)
+	if err := t.enforce(ctx, t.Name(), t.currentPolicy().Evaluate(argv)); err != nil {
+		return nil, err
+	}
+
 	// Set default timeout
 	timeoutSecs := 10
 	if params.TimeoutSecs > 0 {
 		timeoutSecs = params.TimeoutSecs
 	}
-	if timeoutSecs > 60 {
-		timeoutSecs = 60 // Maximum timeout of 60 seconds
-	}
 
 	// Create command
 	cmd := exec.Command(params.Command, params.Args...)
 
+	// Run in a process group of its own so a timeout also kills its children
+	setProcessGroup(cmd)
+
 	// Set working directory if specified
 	if params.WorkingDir != "" {
 		cmd.Dir = params.WorkingDir
 	}
 
+	// Apply the limits and environment of the category's sandbox profile
+	if err := t.SandboxProfile().Prepare(cmd); err != nil {
+		return nil, fmt.Errorf("cannot apply the sandbox profile: %w", err)
+	}
+
 	// Set up buffers for stdout and stderr
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -134,12 +154,23 @@ func (t *ShellTool) Execute(input json.RawMessage) (interface{}, error) {
 		}
 	case <-time.After(time.Duration(timeoutSecs) * time.Second):
 		// Kill the process on timeout
-		if err := cmd.Process.Kill(); err != nil {
+		if err := killProcessGroup(cmd); err != nil {
 			result.Error = fmt.Sprintf("command timed out after %d seconds and failed to kill: %v", timeoutSecs, err)
 		} else {
 			result.Error = fmt.Sprintf("command timed out after %d seconds", timeoutSecs)
+			<-done
 		}
 		result.ExitCode = -1
+	case <-ctx.Done():
+		// Kill the process if the caller gives up
+		_ = killProcessGroup(cmd)
+		<-done
+		return ShellOutput{
+			ExitCode: -1,
+			Stdout:   strings.TrimSpace(stdout.String()),
+			Stderr:   strings.TrimSpace(stderr.String()),
+			Error:    fmt.Sprintf("command cancelled: %v", ctx.Err()),
+		}, ctx.Err()
 	}
 
 	// Get command output
@@ -0,0 +1,541 @@
+package development
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
+)
+
+// shellSessionOutputLimit is the amount of stdout and of stderr kept per command.
+// Half comes from the start of the output and half from the end.
+const shellSessionOutputLimit = 64 * 1024
+
+// shellSessionStopGrace is how long the shell may take to exit when the session is closed
+const shellSessionStopGrace = time.Second
+
+// errShellExited is returned when the shell process ends while running a command
+var errShellExited = errors.New("the shell exited")
+
+// ShellSessionTool runs commands in a long-lived shell, so the working
+// directory, environment variables and other shell state carry over
+// between calls. Each tool manager, and so each conversation, has its own session.
+type ShellSessionTool struct {
+	core.BaseToolImpl
+	commandPolicy
+	sandbox.Holder
+
+	mu         sync.Mutex
+	session    *shellSession
+	workingDir string // Last known working directory, restored when the shell restarts
+	stateFile  string // Exported variables, restored when the shell restarts
+	restarted  bool   // Set when the previous shell died, to tell the model about lost state
+	closed     bool
+}
+
+// ShellSessionInput represents parameters for running a command in the session
+type ShellSessionInput struct {
+	Command     string `json:"command"`
+	WorkingDir  string `json:"working_dir,omitempty"`
+	TimeoutSecs int    `json:"timeout_secs,omitempty"`
+}
+
+// ShellSessionOutput represents the result of a command run in the session
+type ShellSessionOutput struct {
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	WorkingDir string `json:"working_dir"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	Notice     string `json:"notice,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// NewShellSessionTool creates a new shell session tool
+func NewShellSessionTool() *ShellSessionTool {
+	tool := &ShellSessionTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"shell_session",
+		"Run a command line in a persistent shell session. Pipelines, globs, redirection and "+
+			"variables work as in a terminal, and the working directory and environment carry "+
+			"over to the next call. Commands cannot read from stdin.",
+		"development",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"command": map[string]interface{}{
+					"type":        "string",
+					"description": "The command line to run, e.g. \"cd src && grep -rn TODO . | wc -l\"",
+				},
+				"working_dir": map[string]interface{}{
+					"type":        "string",
+					"description": "Directory to change to before running the command; the change persists",
+				},
+				"timeout_secs": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum execution time in seconds (default is the tool timeout)",
+					"minimum":     1,
+				},
+			},
+			"required": []string{"command"},
+		},
+	)
+	return tool
+}
+
+// Execute runs a command line in the session shell, starting the shell if needed
+func (t *ShellSessionTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params ShellSessionInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for shell_session tool: %w", err)
+	}
+	if strings.TrimSpace(params.Command) == "" {
+		return nil, fmt.Errorf("command parameter is required")
+	}
+
+	// Check every command of the line against the command policy
+	if err := t.enforce(ctx, t.Name(), t.currentPolicy().EvaluateLine(params.Command)); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, fmt.Errorf("the shell session is closed")
+	}
+
+	var result ShellSessionOutput
+	if t.session == nil {
+		if err := t.start(); err != nil {
+			return nil, err
+		}
+		if t.restarted {
+			result.Notice = "A new shell session was started because the previous one ended. " +
+				"The working directory and exported variables were restored; functions, aliases and unexported variables were lost."
+			t.restarted = false
+		}
+	}
+
+	runCtx := ctx
+	if params.TimeoutSecs > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, time.Duration(params.TimeoutSecs)*time.Second)
+		defer cancel()
+	}
+
+	run, err := t.session.run(runCtx, t.script(params))
+	result.Stdout = run.stdout
+	result.Stderr = run.stderr
+	result.Truncated = run.truncated
+	result.ExitCode = run.status
+
+	if err != nil {
+		// The shell is gone or must be killed; the next call starts a new one
+		t.stop()
+		t.restarted = true
+		result.ExitCode = -1
+		result.WorkingDir = t.workingDir
+
+		switch {
+		case errors.Is(err, errShellExited):
+			result.ExitCode = run.status
+			result.Error = "the shell exited; a new session will be started on the next call"
+			return result, nil
+		case ctx.Err() != nil:
+			result.Error = fmt.Sprintf("command cancelled: %v; the shell session was killed", ctx.Err())
+			return result, ctx.Err()
+		default:
+			result.Error = fmt.Sprintf("command timed out after %d seconds; the shell session was killed", params.TimeoutSecs)
+			return result, nil
+		}
+	}
+
+	t.workingDir = run.workingDir
+	result.WorkingDir = run.workingDir
+	return result, nil
+}
+
+// Close ends the shell session and removes its state
+func (t *ShellSessionTool) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	t.stop()
+	if t.stateFile != "" {
+		os.Remove(t.stateFile)
+		t.stateFile = ""
+	}
+	return nil
+}
+
+// start launches the shell, restoring the working directory and exported
+// variables of a previous session
+func (t *ShellSessionTool) start() error {
+	if t.stateFile == "" {
+		file, err := os.CreateTemp("", "mcpterm-shell-*.env")
+		if err != nil {
+			return fmt.Errorf("failed to create shell session state file: %w", err)
+		}
+		file.Close()
+		t.stateFile = file.Name()
+	}
+
+	dir := t.workingDir
+	if info, err := os.Stat(dir); dir != "" && (err != nil || !info.IsDir()) {
+		dir = ""
+	}
+
+	session, err := startShellSession(dir, t.SandboxProfile())
+	if err != nil {
+		return err
+	}
+
+	// Restore the exported variables of the previous session
+	if err := session.write(fmt.Sprintf(". %s 2>/dev/null\n", shellQuote(t.stateFile))); err != nil {
+		session.kill()
+		return err
+	}
+
+	t.session = session
+	return nil
+}
+
+// stop ends the current shell, if any
+func (t *ShellSessionTool) stop() {
+	if t.session != nil {
+		t.session.close()
+		t.session = nil
+	}
+}
+
+// script wraps a command line so its status, the working directory and the
+// exported variables are reported after it finishes. Running it through eval
+// keeps syntax errors from ending the shell.
+func (t *ShellSessionTool) script(params ShellSessionInput) string {
+	command := "eval " + shellQuote(params.Command) + " </dev/null"
+	if params.WorkingDir != "" {
+		command = "cd -- " + shellQuote(params.WorkingDir) + " && " + command
+	}
+
+	marker := t.session.marker
+	return command + "\n" +
+		"__mcpterm_status=$?\n" +
+		"export -p > " + shellQuote(t.stateFile) + " 2>/dev/null\n" +
+		"printf '\\n%s %s %s\\n' " + marker + " \"$__mcpterm_status\" \"$PWD\"\n" +
+		"printf '\\n%s\\n' " + marker + " >&2\n"
+}
+
+// shellQuote quotes s as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellSession is a running shell process
+type shellSession struct {
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	chunks   chan outputChunk
+	exited   chan struct{}
+	exitCode int
+	marker   string
+}
+
+// outputChunk is data read from the shell's stdout or stderr
+type outputChunk struct {
+	stderr bool
+	data   []byte
+}
+
+// commandRun is the outcome of a command run in the shell
+type commandRun struct {
+	stdout     string
+	stderr     string
+	truncated  bool
+	status     int
+	workingDir string
+}
+
+// startShellSession starts bash, or sh if bash is not installed, reading
+// commands from a pipe. The profile restricts the shell and its commands.
+func startShellSession(dir string, profile *sandbox.Profile) (*shellSession, error) {
+	shell, args := "sh", []string{}
+	if path, err := exec.LookPath("bash"); err == nil {
+		shell, args = path, []string{"--noprofile", "--norc"}
+	}
+
+	markerBytes := make([]byte, 8)
+	if _, err := rand.Read(markerBytes); err != nil {
+		return nil, fmt.Errorf("failed to create shell session marker: %w", err)
+	}
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shell session pipe: %w", err)
+	}
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		stdoutReader.Close()
+		stdoutWriter.Close()
+		return nil, fmt.Errorf("failed to create shell session pipe: %w", err)
+	}
+
+	cmd := exec.Command(shell, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "PAGER=cat", "GIT_PAGER=cat")
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+	setProcessGroup(cmd)
+
+	var stdin io.WriteCloser
+	err = profile.Prepare(cmd)
+	if err == nil {
+		stdin, err = cmd.StdinPipe()
+	}
+	if err == nil {
+		err = cmd.Start()
+	}
+
+	// The shell has its own copies of the write ends
+	stdoutWriter.Close()
+	stderrWriter.Close()
+
+	if err != nil {
+		stdoutReader.Close()
+		stderrReader.Close()
+		return nil, fmt.Errorf("failed to start shell %s: %w", shell, err)
+	}
+
+	s := &shellSession{
+		cmd:    cmd,
+		stdin:  stdin,
+		chunks: make(chan outputChunk, 64),
+		exited: make(chan struct{}),
+		marker: "__MCPTERM_" + hex.EncodeToString(markerBytes) + "__",
+	}
+
+	go s.read(stdoutReader, false)
+	go s.read(stderrReader, true)
+	go func() {
+		_ = cmd.Wait()
+		s.exitCode = cmd.ProcessState.ExitCode()
+		close(s.exited)
+	}()
+
+	return s, nil
+}
+
+// read forwards output from one of the shell's streams until it is closed
+func (s *shellSession) read(r *os.File, stderr bool) {
+	defer r.Close()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			select {
+			case s.chunks <- outputChunk{stderr: stderr, data: data}:
+			case <-s.exited:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// write sends input to the shell
+func (s *shellSession) write(input string) error {
+	if _, err := io.WriteString(s.stdin, input); err != nil {
+		return fmt.Errorf("failed to send command to the shell: %w", err)
+	}
+	return nil
+}
+
+// run sends a script and collects its output until the script reports completion
+func (s *shellSession) run(ctx context.Context, script string) (commandRun, error) {
+	stdout := newCappedOutput(shellSessionOutputLimit, len(s.marker)+4096)
+	stderr := newCappedOutput(shellSessionOutputLimit, len(s.marker)+4096)
+
+	result := func(status int, workingDir string) commandRun {
+		return commandRun{
+			stdout:     strings.TrimRight(stripMarker(stdout.String(), s.marker), "\n"),
+			stderr:     strings.TrimRight(stripMarker(stderr.String(), s.marker), "\n"),
+			truncated:  stdout.truncated() || stderr.truncated(),
+			status:     status,
+			workingDir: workingDir,
+		}
+	}
+
+	if err := s.write(script); err != nil {
+		return result(-1, ""), errShellExited
+	}
+
+	var status int
+	var workingDir string
+	stdoutDone, stderrDone := false, false
+	for !stdoutDone || !stderrDone {
+		select {
+		case chunk := <-s.chunks:
+			if chunk.stderr {
+				stderr.write(chunk.data)
+				stderrDone = stderrDone || strings.Contains(stderr.recent(), "\n"+s.marker+"\n")
+			} else {
+				stdout.write(chunk.data)
+				if !stdoutDone {
+					status, workingDir, stdoutDone = parseMarker(stdout.recent(), s.marker)
+				}
+			}
+		case <-s.exited:
+			// Collect what the shell wrote before it ended
+			s.drain(stdout, stderr)
+			return result(s.exitCode, ""), errShellExited
+		case <-ctx.Done():
+			s.kill()
+			s.drain(stdout, stderr)
+			return result(-1, ""), ctx.Err()
+		}
+	}
+
+	return result(status, workingDir), nil
+}
+
+// drain collects output that is still buffered after the shell ended
+func (s *shellSession) drain(stdout, stderr *cappedOutput) {
+	for {
+		select {
+		case chunk := <-s.chunks:
+			if chunk.stderr {
+				stderr.write(chunk.data)
+			} else {
+				stdout.write(chunk.data)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// kill kills the shell and every process it started
+func (s *shellSession) kill() {
+	_ = killProcessGroup(s.cmd)
+	select {
+	case <-s.exited:
+	case <-time.After(shellSessionStopGrace):
+	}
+}
+
+// close asks the shell to exit by closing its input, killing it if it does not
+func (s *shellSession) close() {
+	s.stdin.Close()
+	select {
+	case <-s.exited:
+		// Background jobs of the shell must not outlive the session
+		_ = killProcessGroup(s.cmd)
+	case <-time.After(shellSessionStopGrace):
+		s.kill()
+	}
+}
+
+// parseMarker looks for the status line printed to stdout after a command
+// and returns the exit status and working directory it reports
+func parseMarker(output, marker string) (int, string, bool) {
+	start := strings.LastIndex(output, "\n"+marker+" ")
+	if start < 0 {
+		return 0, "", false
+	}
+
+	line := output[start+len(marker)+2:]
+	end := strings.Index(line, "\n")
+	if end < 0 {
+		return 0, "", false
+	}
+
+	fields := strings.SplitN(line[:end], " ", 2)
+	status, err := strconv.Atoi(fields[0])
+	if err != nil || len(fields) < 2 {
+		return 0, "", false
+	}
+	return status, fields[1], true
+}
+
+// stripMarker removes the completion marker and everything after it
+func stripMarker(output, marker string) string {
+	if i := strings.LastIndex(output, "\n"+marker); i >= 0 {
+		return output[:i]
+	}
+	return output
+}
+
+// cappedOutput keeps the start and the end of a stream, dropping the middle
+// once it exceeds its limit
+type cappedOutput struct {
+	headLimit int
+	tailLimit int
+	head      []byte
+	tail      []byte // The most recent bytes of the whole stream
+	total     int
+}
+
+// newCappedOutput creates a buffer keeping about limit bytes plus slack extra
+// bytes at the end, which leaves room for the completion marker
+func newCappedOutput(limit, slack int) *cappedOutput {
+	return &cappedOutput{headLimit: limit / 2, tailLimit: limit/2 + slack}
+}
+
+func (o *cappedOutput) write(p []byte) {
+	o.total += len(p)
+
+	if room := o.headLimit - len(o.head); room > 0 {
+		o.head = append(o.head, p[:min(room, len(p))]...)
+	}
+
+	o.tail = append(o.tail, p...)
+	if len(o.tail) > o.tailLimit {
+		o.tail = o.tail[len(o.tail)-o.tailLimit:]
+	}
+}
+
+// recent returns the most recent output, where the completion marker appears
+func (o *cappedOutput) recent() string {
+	return string(o.tail)
+}
+
+// truncated reports whether part of the output was dropped
+func (o *cappedOutput) truncated() bool {
+	return o.total > o.headLimit+o.tailLimit
+}
+
+// String returns the kept output with a note where output was dropped
+func (o *cappedOutput) String() string {
+	switch {
+	case o.total <= len(o.tail):
+		return string(o.tail)
+	case !o.truncated():
+		// The head and the tail overlap or touch
+		return string(o.head) + string(o.tail[len(o.tail)-(o.total-len(o.head)):])
+	default:
+		omitted := o.total - len(o.head) - len(o.tail)
+		return fmt.Sprintf("%s\n[... %d bytes omitted ...]\n%s", o.head, omitted, o.tail)
+	}
+}
@@ -0,0 +1,164 @@
+package development
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runSession executes a command in the session and returns its output
+func runSession(t *testing.T, tool *ShellSessionTool, params ShellSessionInput) ShellSessionOutput {
+	t.Helper()
+
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	result, err := tool.Execute(context.Background(), input)
+	require.NoError(t, err)
+
+	output, ok := result.(ShellSessionOutput)
+	require.True(t, ok, "unexpected result type %T", result)
+	return output
+}
+
+func newTestSession(t *testing.T) *ShellSessionTool {
+	tool := NewShellSessionTool()
+	t.Cleanup(func() { tool.Close() })
+	return tool
+}
+
+func TestShellSessionKeepsState(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\ntwo\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("three\n"), 0644))
+
+	tool := newTestSession(t)
+
+	output := runSession(t, tool, ShellSessionInput{Command: "cd " + shellQuote(dir) + " && export GREETING=hello"})
+	assert.Equal(t, 0, output.ExitCode)
+	assert.Equal(t, dir, output.WorkingDir)
+
+	output = runSession(t, tool, ShellSessionInput{Command: "cat *.txt | wc -l | tr -d ' '"})
+	assert.Equal(t, "3", output.Stdout)
+	assert.Equal(t, dir, output.WorkingDir)
+
+	output = runSession(t, tool, ShellSessionInput{Command: `echo "$GREETING from $(basename "$PWD")"`})
+	assert.Equal(t, "hello from "+filepath.Base(dir), output.Stdout)
+}
+
+func TestShellSessionReportsFailures(t *testing.T) {
+	tool := newTestSession(t)
+
+	output := runSession(t, tool, ShellSessionInput{Command: "echo out; echo err >&2; false"})
+	assert.Equal(t, 1, output.ExitCode)
+	assert.Equal(t, "out", output.Stdout)
+	assert.Equal(t, "err", output.Stderr)
+
+	// A syntax error fails the command without ending the session
+	output = runSession(t, tool, ShellSessionInput{Command: "if then fi ("})
+	assert.NotEqual(t, 0, output.ExitCode)
+	assert.NotEmpty(t, output.Stderr)
+	assert.Empty(t, output.Notice)
+
+	output = runSession(t, tool, ShellSessionInput{Command: "echo still here"})
+	assert.Equal(t, "still here", output.Stdout)
+	assert.Empty(t, output.Notice)
+
+	output = runSession(t, tool, ShellSessionInput{Command: "cd /does/not/exist"})
+	assert.NotEqual(t, 0, output.ExitCode)
+}
+
+func TestShellSessionWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	tool := newTestSession(t)
+
+	output := runSession(t, tool, ShellSessionInput{Command: "pwd", WorkingDir: dir})
+	assert.Equal(t, dir, output.Stdout)
+
+	// The directory change persists
+	output = runSession(t, tool, ShellSessionInput{Command: "pwd"})
+	assert.Equal(t, dir, output.Stdout)
+}
+
+func TestShellSessionTruncatesOutput(t *testing.T) {
+	tool := newTestSession(t)
+
+	output := runSession(t, tool, ShellSessionInput{Command: "echo first; head -c 200000 /dev/zero | tr '\\0' x; echo; echo last"})
+	assert.Equal(t, 0, output.ExitCode)
+	assert.True(t, output.Truncated)
+	assert.Less(t, len(output.Stdout), 80*1024)
+	assert.True(t, strings.HasPrefix(output.Stdout, "first\n"))
+	assert.True(t, strings.HasSuffix(output.Stdout, "\nlast"))
+	assert.Contains(t, output.Stdout, "bytes omitted")
+}
+
+func TestShellSessionTimeoutKillsProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+	tool := newTestSession(t)
+
+	runSession(t, tool, ShellSessionInput{Command: "export KEEP=kept", WorkingDir: dir})
+
+	start := time.Now()
+	output := runSession(t, tool, ShellSessionInput{
+		Command:     "(sleep 2; touch marker) & sleep 30",
+		TimeoutSecs: 1,
+	})
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Equal(t, -1, output.ExitCode)
+	assert.Contains(t, output.Error, "timed out")
+
+	// The next command runs in a new shell with the directory and exports restored
+	output = runSession(t, tool, ShellSessionInput{Command: `echo "$KEEP"; pwd`})
+	assert.Equal(t, "kept\n"+dir, output.Stdout)
+	assert.NotEmpty(t, output.Notice)
+
+	// The background job was killed with the shell
+	time.Sleep(2500 * time.Millisecond)
+	assert.NoFileExists(t, marker)
+}
+
+func TestShellSessionExit(t *testing.T) {
+	tool := newTestSession(t)
+
+	output := runSession(t, tool, ShellSessionInput{Command: "echo bye; exit 3"})
+	assert.Equal(t, 3, output.ExitCode)
+	assert.Equal(t, "bye", output.Stdout)
+	assert.Contains(t, output.Error, "exited")
+
+	output = runSession(t, tool, ShellSessionInput{Command: "echo back"})
+	assert.Equal(t, 0, output.ExitCode)
+	assert.Equal(t, "back", output.Stdout)
+	assert.NotEmpty(t, output.Notice)
+}
+
+func TestShellSessionClosed(t *testing.T) {
+	tool := NewShellSessionTool()
+	runSession(t, tool, ShellSessionInput{Command: "true"})
+	require.NoError(t, tool.Close())
+
+	_, err := tool.Execute(context.Background(), json.RawMessage(`{"command": "true"}`))
+	assert.Error(t, err)
+}
+
+func TestCappedOutput(t *testing.T) {
+	out := newCappedOutput(10, 2)
+	out.write([]byte("abc"))
+	assert.Equal(t, "abc", out.String())
+	assert.False(t, out.truncated())
+
+	out.write([]byte("defghij"))
+	assert.Equal(t, "abcdefghij", out.String())
+	assert.False(t, out.truncated())
+
+	out.write([]byte("klmnopqrstuvwxyz"))
+	assert.True(t, out.truncated())
+	assert.Equal(t, "abcde\n[... 14 bytes omitted ...]\ntuvwxyz", out.String())
+}
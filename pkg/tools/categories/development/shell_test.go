@@ -1,6 +1,7 @@
 package development
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"strings"
@@ -89,7 +90,7 @@ func TestShellTool(t *testing.T) {
 			startTime := time.Now()
 
 			// Execute tool
-			result, err := shellTool.Execute(jsonInput)
+			result, err := shellTool.Execute(context.Background(), jsonInput)
 
 			duration := time.Since(startTime)
 
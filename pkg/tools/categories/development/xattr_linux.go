@@ -0,0 +1,40 @@
+package development
+
+import (
+	"bytes"
+	"syscall"
+)
+
+// copyXattrs copies the extended attributes of the file at from to the
+// file at to
+func copyXattrs(from, to string) error {
+	size, err := syscall.Listxattr(from, nil)
+	if err == syscall.ENOTSUP || size == 0 {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	names := make([]byte, size)
+	if size, err = syscall.Listxattr(from, names); err != nil {
+		return err
+	}
+
+	for _, name := range bytes.Split(names[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		attr := string(name)
+		n, err := syscall.Getxattr(from, attr, nil)
+		if err != nil {
+			return err
+		}
+		value := make([]byte, n)
+		if n, err = syscall.Getxattr(from, attr, value); err != nil {
+			return err
+		}
+		if err := syscall.Setxattr(to, attr, value[:n], 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build unix && !linux
+
+package development
+
+// copyXattrs does nothing where extended attributes are not supported
+func copyXattrs(from, to string) error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolsHonorCancellation(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "file.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("hello\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		tool  core.Tool
+		input map[string]interface{}
+	}{
+		{NewDirectoryListTool(), map[string]interface{}{"path": tempDir}},
+		{NewFileDeleteTool(), map[string]interface{}{"path": filePath}},
+		{NewFileReadTool(), map[string]interface{}{"path": filePath}},
+		{NewFileRenameTool(), map[string]interface{}{"old_path": filePath, "new_path": filePath + ".new"}},
+		{NewFindTool(), map[string]interface{}{"directory": tempDir}},
+		{NewGrepTool(), map[string]interface{}{"pattern": "hello", "path": tempDir}},
+		{NewMkdirTool(), map[string]interface{}{"path": filepath.Join(tempDir, "dir")}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tool.Name(), func(t *testing.T) {
+			input, err := json.Marshal(tc.input)
+			require.NoError(t, err)
+
+			_, err = tc.tool.Execute(ctx, input)
+			assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled, got %v", err)
+		})
+	}
+
+	// Cancelled tools must not have touched the file system
+	_, err := os.Stat(filePath)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(tempDir, "dir"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGrepCancelledDuringWalk(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < grepProgressInterval*3; i++ {
+		path := filepath.Join(tempDir, fmt.Sprintf("file%03d.txt", i))
+		require.NoError(t, os.WriteFile(path, []byte("needle\n"), 0644))
+	}
+
+	// Cancel the search as soon as the first progress update arrives
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var updates []core.Progress
+	ctx = core.WithProgress(ctx, func(p core.Progress) {
+		updates = append(updates, p)
+		cancel()
+	})
+
+	input, err := json.Marshal(GrepInput{Pattern: "needle", Path: tempDir, Recursive: true})
+	require.NoError(t, err)
+
+	_, err = NewGrepTool().Execute(ctx, input)
+	assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled, got %v", err)
+	assert.Len(t, updates, 1)
+}
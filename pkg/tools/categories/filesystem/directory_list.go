@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -11,11 +12,15 @@ import (
 
 // DirectoryListInput represents parameters for listing a directory
 type DirectoryListInput struct {
-	Path    string `json:"path"`
-	Pattern string `json:"pattern,omitempty"`
+	Path       string `json:"path"`
+	Pattern    string `json:"pattern,omitempty"`
+	Mode       string `json:"mode,omitempty"`        // "list" (default) or "tree"
+	MaxDepth   int    `json:"max_depth,omitempty"`   // Levels listed in tree mode (default 3)
+	MaxEntries int    `json:"max_entries,omitempty"` // Tree mode collapses directories with more entries (default 50)
+	NoIgnore   bool   `json:"no_ignore,omitempty"`   // Tree mode also lists paths excluded by .gitignore and .ignore
 }
 
-// DirectoryListTool is a placeholder implementation of the directory listing tool
+// DirectoryListTool lists the entries of a directory, or the tree below it
 type DirectoryListTool struct {
 	core.BaseToolImpl
 }
@@ -33,7 +38,7 @@ func NewDirectoryListTool() *DirectoryListTool {
 	tool := &DirectoryListTool{}
 	tool.BaseToolImpl = *core.NewBaseTool(
 		"directory_list",
-		"List files and directories in a specified path",
+		"List files and directories in a specified path, or with mode 'tree' the whole layout below it with file counts and sizes per directory",
 		"filesystem",
 		map[string]interface{}{
 			"type": "object",
@@ -46,6 +51,23 @@ func NewDirectoryListTool() *DirectoryListTool {
 					"type":        "string",
 					"description": "Pattern to filter files (e.g., '*.go')",
 				},
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"description": "'list' (default) for the entries of the directory, or 'tree' for a recursive tree with file counts and sizes per directory, as JSON and as indented text",
+					"enum":        []string{"list", "tree"},
+				},
+				"max_depth": map[string]interface{}{
+					"type":        "integer",
+					"description": "Levels listed in tree mode (default: 3); deeper entries are still counted",
+				},
+				"max_entries": map[string]interface{}{
+					"type":        "integer",
+					"description": "In tree mode, directories with more entries are collapsed to their totals (default: 50)",
+				},
+				"no_ignore": map[string]interface{}{
+					"type":        "boolean",
+					"description": "In tree mode, also list paths excluded by .gitignore and .ignore files and node_modules (default: false)",
+				},
 			},
 			"required": []string{"path"},
 		},
@@ -54,7 +76,11 @@ func NewDirectoryListTool() *DirectoryListTool {
 }
 
 // Execute implements the Tool interface
-func (t *DirectoryListTool) Execute(input json.RawMessage) (interface{}, error) {
+func (t *DirectoryListTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var params DirectoryListInput
 	if err := json.Unmarshal(input, &params); err != nil {
 		return nil, fmt.Errorf("invalid input for directory_list tool: %w", err)
@@ -75,6 +101,18 @@ func (t *DirectoryListTool) Execute(input json.RawMessage) (interface{}, error)
 		return nil, fmt.Errorf("%s is not a directory", params.Path)
 	}
 
+	if _, err := filepath.Match(params.Pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	switch params.Mode {
+	case "", "list":
+	case "tree":
+		return t.listTree(ctx, params)
+	default:
+		return nil, fmt.Errorf("invalid mode %q, must be 'list' or 'tree'", params.Mode)
+	}
+
 	// Read directory
 	entries, err := os.ReadDir(params.Path)
 	if err != nil {
@@ -100,6 +138,10 @@ func (t *DirectoryListTool) Execute(input json.RawMessage) (interface{}, error)
 	// Convert to our output format
 	result := make([]FileEntry, 0, len(filtered))
 	for _, entry := range filtered {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		info, err := entry.Info()
 		if err != nil {
 			// Skip entries we can't get info for
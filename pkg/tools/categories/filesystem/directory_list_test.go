@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -105,7 +106,7 @@ func TestDirectoryListTool(t *testing.T) {
 			}
 
 			// Execute tool
-			result, err := dirListTool.Execute(jsonInput)
+			result, err := dirListTool.Execute(context.Background(), jsonInput)
 
 			// Check error
 			if tc.expectError && err == nil {
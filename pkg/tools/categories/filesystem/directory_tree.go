@@ -0,0 +1,196 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultTreeDepth      = 3      // Levels listed in tree mode unless told otherwise
+	defaultTreeMaxEntries = 50     // Directories with more entries are collapsed
+	maxTreeWalkEntries    = 200000 // Entries counted before a tree walk stops
+)
+
+// TreeNode is a file or directory in a directory tree. Directory sizes and
+// counts include everything below them, listed or not.
+type TreeNode struct {
+	Name      string      `json:"name"`
+	IsDir     bool        `json:"is_dir"`
+	Size      int64       `json:"size"`                // File size, or total size of the files below a directory
+	Files     int         `json:"files,omitempty"`     // Files below a directory
+	Dirs      int         `json:"dirs,omitempty"`      // Directories below a directory
+	Collapsed bool        `json:"collapsed,omitempty"` // Entries not listed: too deep or too many
+	Children  []*TreeNode `json:"children,omitempty"`
+
+	hidden treeTotals // Totals of the entries below that are not listed
+}
+
+// treeTotals counts files and directories and sums file sizes
+type treeTotals struct {
+	size        int64
+	files, dirs int
+}
+
+// DirectoryTree represents the result of listing a directory in tree mode
+type DirectoryTree struct {
+	Path      string    `json:"path"`
+	Root      *TreeNode `json:"root"`
+	Text      string    `json:"text"`                // Indented text form of the tree
+	Truncated bool      `json:"truncated,omitempty"` // The walk stopped early, so totals are incomplete
+}
+
+// listTree walks a directory and returns it as a tree with per-directory
+// totals. Entries deeper than max_depth are counted into their deepest
+// listed directory, and directories with more than max_entries entries are
+// collapsed after the walk.
+func (t *DirectoryListTool) listTree(ctx context.Context, params DirectoryListInput) (interface{}, error) {
+	maxDepth := params.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultTreeDepth
+	}
+	maxEntries := params.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultTreeMaxEntries
+	}
+
+	root := &TreeNode{Name: filepath.Base(filepath.Clean(params.Path)), IsDir: true}
+	dirs := map[string]*TreeNode{filepath.Clean(params.Path): root}
+	result := DirectoryTree{Path: params.Path, Root: root}
+
+	walked := 0
+	err := walkTree(ctx, params.Path, walkOptions{NoIgnore: params.NoIgnore}, func(path string, entry fs.DirEntry, depth int) error {
+		if walked++; walked > maxTreeWalkEntries {
+			result.Truncated = true
+			return filepath.SkipAll
+		}
+		if !entry.IsDir() && params.Pattern != "" {
+			if matched, _ := filepath.Match(params.Pattern, entry.Name()); !matched {
+				return nil
+			}
+		}
+		var size int64
+		if !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				size = info.Size()
+			}
+		}
+
+		parent := dirs[filepath.Dir(path)]
+		if depth > maxDepth || parent == nil {
+			// Count the entry into its deepest listed ancestor
+			dir := filepath.Dir(path)
+			for dirs[dir] == nil && dir != filepath.Dir(dir) {
+				dir = filepath.Dir(dir)
+			}
+			if ancestor := dirs[dir]; ancestor != nil {
+				ancestor.Collapsed = true
+				ancestor.hidden.add(entry.IsDir(), size)
+			}
+			return nil
+		}
+
+		node := &TreeNode{Name: entry.Name(), IsDir: entry.IsDir(), Size: size}
+		parent.Children = append(parent.Children, node)
+		if node.IsDir {
+			dirs[path] = node
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	root.total(maxEntries)
+	var text strings.Builder
+	root.writeText(&text, 0)
+	result.Text = text.String()
+	return result, nil
+}
+
+// add counts a file or directory of the given size
+func (t *treeTotals) add(isDir bool, size int64) {
+	if isDir {
+		t.dirs++
+	} else {
+		t.files++
+	}
+	t.size += size
+}
+
+// total works out the sizes and counts of a directory from its entries,
+// collapsing directories with more than maxEntries entries
+func (n *TreeNode) total(maxEntries int) {
+	if !n.IsDir {
+		return
+	}
+	totals := n.hidden
+	for _, child := range n.Children {
+		child.total(maxEntries)
+		totals.add(child.IsDir, child.Size)
+		totals.files += child.Files
+		totals.dirs += child.Dirs
+	}
+	n.Size, n.Files, n.Dirs = totals.size, totals.files, totals.dirs
+
+	// Directories first, then files, each by name
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		if n.Children[i].IsDir != n.Children[j].IsDir {
+			return n.Children[i].IsDir
+		}
+		return n.Children[i].Name < n.Children[j].Name
+	})
+	if len(n.Children) > maxEntries {
+		n.Children = nil
+		n.Collapsed = true
+	}
+}
+
+// writeText writes the node and its listed entries, indenting two spaces
+// per level. Directories end in "/" and show their totals; collapsed ones
+// are marked with "…".
+func (n *TreeNode) writeText(out *strings.Builder, level int) {
+	out.WriteString(strings.Repeat("  ", level))
+	if !n.IsDir {
+		fmt.Fprintf(out, "%s (%s)\n", n.Name, formatSize(n.Size))
+		return
+	}
+
+	counts := pluralize(n.Files, "file")
+	if n.Dirs > 0 {
+		counts += ", " + pluralize(n.Dirs, "dir")
+	}
+	fmt.Fprintf(out, "%s/ (%s, %s)", n.Name, counts, formatSize(n.Size))
+	if n.Collapsed {
+		out.WriteString(" …")
+	}
+	out.WriteString("\n")
+	for _, child := range n.Children {
+		child.writeText(out, level+1)
+	}
+}
+
+// pluralize formats a count of things
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// formatSize formats a byte count for people, e.g. "512 B" or "1.5 KB"
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTP"[exp])
+}
@@ -0,0 +1,95 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runTree lists a directory in tree mode
+func runTree(t *testing.T, input DirectoryListInput) DirectoryTree {
+	t.Helper()
+	input.Mode = "tree"
+	jsonInput, err := json.Marshal(input)
+	require.NoError(t, err)
+	result, err := NewDirectoryListTool().Execute(context.Background(), jsonInput)
+	require.NoError(t, err)
+	tree, ok := result.(DirectoryTree)
+	require.True(t, ok, "expected DirectoryTree, got %T", result)
+	return tree
+}
+
+func TestDirectoryListTree(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".gitignore":        "build/\n",
+		"a.txt":             "hello",
+		"build/out":         "ignored",
+		"many/f0":           "x",
+		"many/f1":           "x",
+		"many/f2":           "x",
+		"many/f3":           "x",
+		"many/f4":           "x",
+		"src/main.go":       "package m\n",
+		"src/pkg/deep/x.go": "abc",
+	})
+	name := filepath.Base(dir)
+
+	t.Run("Depth and entry limits", func(t *testing.T) {
+		tree := runTree(t, DirectoryListInput{Path: dir, MaxDepth: 2, MaxEntries: 4})
+		assert.Equal(t, name+"/ (9 files, 4 dirs, 30 B)\n"+
+			"  many/ (5 files, 5 B) …\n"+
+			"  src/ (2 files, 2 dirs, 13 B)\n"+
+			"    pkg/ (1 file, 1 dir, 3 B) …\n"+
+			"    main.go (10 B)\n"+
+			"  .gitignore (7 B)\n"+
+			"  a.txt (5 B)\n", tree.Text)
+
+		root := tree.Root
+		assert.Equal(t, 9, root.Files)
+		assert.Equal(t, 4, root.Dirs)
+		assert.EqualValues(t, 30, root.Size)
+		require.Len(t, root.Children, 4)
+		many := root.Children[0]
+		assert.True(t, many.Collapsed)
+		assert.Empty(t, many.Children)
+		pkg := root.Children[1].Children[0]
+		assert.Equal(t, "pkg", pkg.Name)
+		assert.True(t, pkg.Collapsed)
+		assert.Equal(t, 1, pkg.Files)
+		assert.False(t, tree.Truncated)
+	})
+
+	t.Run("No ignore", func(t *testing.T) {
+		tree := runTree(t, DirectoryListInput{Path: dir, NoIgnore: true})
+		assert.Equal(t, 10, tree.Root.Files)
+		assert.Equal(t, 5, tree.Root.Dirs)
+		assert.Contains(t, tree.Text, "  build/ (1 file, 7 B)\n    out (7 B)\n")
+	})
+
+	t.Run("Pattern", func(t *testing.T) {
+		tree := runTree(t, DirectoryListInput{Path: dir, Pattern: "*.go"})
+		assert.Equal(t, 2, tree.Root.Files)
+		assert.Contains(t, tree.Text, "      deep/ (1 file, 3 B) …\n")
+		assert.NotContains(t, tree.Text, "a.txt")
+	})
+
+	t.Run("Invalid mode", func(t *testing.T) {
+		jsonInput, err := json.Marshal(DirectoryListInput{Path: dir, Mode: "forest"})
+		require.NoError(t, err)
+		_, err = NewDirectoryListTool().Execute(context.Background(), jsonInput)
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "0 B", formatSize(0))
+	assert.Equal(t, "1023 B", formatSize(1023))
+	assert.Equal(t, "1.5 KB", formatSize(1536))
+	assert.Equal(t, "2.0 MB", formatSize(2<<20))
+	assert.Equal(t, "3.0 GB", formatSize(3<<30))
+}
@@ -0,0 +1,224 @@
+package filesystem
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// sniffBytes is how much of a file is examined to detect its encoding and
+// whether it is binary
+const sniffBytes = 8000
+
+// Text encodings understood by file_read
+const (
+	encodingUTF8    = "utf-8"
+	encodingUTF16LE = "utf-16le"
+	encodingUTF16BE = "utf-16be"
+	encodingLatin1  = "latin-1"
+)
+
+// textEncoding describes how a file's bytes are decoded
+type textEncoding struct {
+	Name   string // One of the encoding constants
+	BOM    int    // Length of the byte order mark to skip
+	Binary bool   // The file is not text in any supported encoding
+}
+
+// isBinaryData reports whether content looks like binary data: it has a NUL
+// byte near the start
+func isBinaryData(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), sniffBytes)], 0) >= 0
+}
+
+// parseEncoding checks an encoding name given as input. An empty name or
+// "auto" means the encoding is detected.
+func parseEncoding(name string) (string, error) {
+	switch name {
+	case "", "auto":
+		return "", nil
+	case "utf-8", "utf8":
+		return encodingUTF8, nil
+	case "utf-16le", "utf16le":
+		return encodingUTF16LE, nil
+	case "utf-16be", "utf16be":
+		return encodingUTF16BE, nil
+	case "latin-1", "latin1", "iso-8859-1":
+		return encodingLatin1, nil
+	default:
+		return "", fmt.Errorf("unsupported encoding %q, must be 'auto', 'utf-8', 'utf-16le', 'utf-16be' or 'latin-1'", name)
+	}
+}
+
+// detectEncoding works out the encoding of a file from its first bytes. A
+// byte order mark decides; otherwise NUL bytes mean UTF-16 when they fall
+// on alternate bytes and binary data when they do not, and text that is not
+// valid UTF-8 is read as Latin-1.
+func detectEncoding(head []byte) textEncoding {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return textEncoding{Name: encodingUTF8, BOM: 3}
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return textEncoding{Name: encodingUTF16LE, BOM: 2}
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return textEncoding{Name: encodingUTF16BE, BOM: 2}
+	}
+
+	if bytes.IndexByte(head, 0) >= 0 {
+		if name := guessUTF16(head); name != "" {
+			return textEncoding{Name: name}
+		}
+		return textEncoding{Binary: true}
+	}
+	if !validUTF8Prefix(head) {
+		return textEncoding{Name: encodingLatin1}
+	}
+	return textEncoding{Name: encodingUTF8}
+}
+
+// withBOM returns the encoding for a name given as input, skipping a byte
+// order mark at the start of head that matches it
+func withBOM(name string, head []byte) textEncoding {
+	enc := textEncoding{Name: name}
+	switch {
+	case name == encodingUTF8 && bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		enc.BOM = 3
+	case name == encodingUTF16LE && bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		enc.BOM = 2
+	case name == encodingUTF16BE && bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		enc.BOM = 2
+	}
+	return enc
+}
+
+// guessUTF16 recognizes UTF-16 text without a byte order mark, which for
+// mostly ASCII text has a NUL in every other byte
+func guessUTF16(head []byte) string {
+	pairs := len(head) / 2
+	if pairs == 0 {
+		return ""
+	}
+	var evenZeros, oddZeros int
+	for i := 0; i+1 < len(head); i += 2 {
+		if head[i] == 0 {
+			evenZeros++
+		}
+		if head[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	switch {
+	case oddZeros*10 >= pairs*4 && evenZeros*50 <= pairs:
+		return encodingUTF16LE
+	case evenZeros*10 >= pairs*4 && oddZeros*50 <= pairs:
+		return encodingUTF16BE
+	}
+	return ""
+}
+
+// validUTF8Prefix reports whether data is valid UTF-8, allowing a character
+// cut short at the end
+func validUTF8Prefix(data []byte) bool {
+	if utf8.Valid(data) {
+		return true
+	}
+	for cut := 1; cut < utf8.UTFMax && cut <= len(data); cut++ {
+		tail := data[len(data)-cut:]
+		if utf8.RuneStart(tail[0]) && !utf8.FullRune(tail) {
+			return utf8.Valid(data[:len(data)-cut])
+		}
+	}
+	return false
+}
+
+// decodingReader turns text in a supported encoding into UTF-8
+func decodingReader(r io.Reader, encoding string) io.Reader {
+	src := bufio.NewReader(r)
+	switch encoding {
+	case encodingLatin1:
+		return &runeReader{src: src, next: readLatin1}
+	case encodingUTF16LE:
+		return &runeReader{src: src, next: func(src *bufio.Reader) (rune, error) { return readUTF16(src, false) }}
+	case encodingUTF16BE:
+		return &runeReader{src: src, next: func(src *bufio.Reader) (rune, error) { return readUTF16(src, true) }}
+	}
+	return src
+}
+
+// runeReader is an io.Reader that produces UTF-8 from the characters
+// decoded by next
+type runeReader struct {
+	src     *bufio.Reader
+	next    func(*bufio.Reader) (rune, error)
+	pending []byte
+	err     error
+}
+
+// Read implements io.Reader
+func (r *runeReader) Read(p []byte) (int, error) {
+	for len(r.pending) < len(p) && r.err == nil {
+		c, err := r.next(r.src)
+		if err != nil {
+			r.err = err
+			break
+		}
+		r.pending = utf8.AppendRune(r.pending, c)
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	if n == 0 && r.err != nil {
+		return 0, r.err
+	}
+	return n, nil
+}
+
+// readLatin1 decodes one Latin-1 character, which is its own code point
+func readLatin1(src *bufio.Reader) (rune, error) {
+	b, err := src.ReadByte()
+	return rune(b), err
+}
+
+// readUTF16 decodes one UTF-16 character, combining surrogate pairs. An odd
+// final byte or an unpaired surrogate decodes as the replacement character.
+func readUTF16(src *bufio.Reader, bigEndian bool) (rune, error) {
+	unit, err := readUTF16Unit(src, bigEndian)
+	if err != nil {
+		return 0, err
+	}
+	if !utf16.IsSurrogate(rune(unit)) {
+		return rune(unit), nil
+	}
+	if next, err := src.Peek(2); err == nil {
+		low := uint16(next[0]) | uint16(next[1])<<8
+		if bigEndian {
+			low = uint16(next[0])<<8 | uint16(next[1])
+		}
+		if c := utf16.DecodeRune(rune(unit), rune(low)); c != utf8.RuneError {
+			_, _ = src.Discard(2)
+			return c, nil
+		}
+	}
+	return utf8.RuneError, nil
+}
+
+// readUTF16Unit reads one 16-bit code unit
+func readUTF16Unit(src *bufio.Reader, bigEndian bool) (uint16, error) {
+	b0, err := src.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	b1, err := src.ReadByte()
+	if err == io.EOF {
+		return utf8.RuneError, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if bigEndian {
+		return uint16(b0)<<8 | uint16(b1), nil
+	}
+	return uint16(b1)<<8 | uint16(b0), nil
+}
@@ -0,0 +1,49 @@
+package filesystem
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		head     []byte
+		expected textEncoding
+	}{
+		{"Empty", nil, textEncoding{Name: encodingUTF8}},
+		{"ASCII", []byte("hello\n"), textEncoding{Name: encodingUTF8}},
+		{"UTF-8 BOM", []byte("\xEF\xBB\xBFhi"), textEncoding{Name: encodingUTF8, BOM: 3}},
+		{"UTF-8 cut at the end", []byte("caf\xc3"), textEncoding{Name: encodingUTF8}},
+		{"UTF-16LE BOM", []byte{0xFF, 0xFE, 'a', 0}, textEncoding{Name: encodingUTF16LE, BOM: 2}},
+		{"UTF-16BE BOM", []byte{0xFE, 0xFF, 0, 'a'}, textEncoding{Name: encodingUTF16BE, BOM: 2}},
+		{"UTF-16LE without BOM", []byte{'a', 0, 'b', 0, 'c', 0}, textEncoding{Name: encodingUTF16LE}},
+		{"UTF-16BE without BOM", []byte{0, 'a', 0, 'b', 0, 'c'}, textEncoding{Name: encodingUTF16BE}},
+		{"Latin-1", []byte("na\xefve"), textEncoding{Name: encodingLatin1}},
+		{"Binary", []byte{0x7F, 'E', 'L', 'F', 2, 1, 1, 0, 0, 0, 0, 0}, textEncoding{Binary: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, detectEncoding(tc.head))
+		})
+	}
+}
+
+func TestDecodingReader(t *testing.T) {
+	decode := func(data []byte, encoding string) string {
+		text, err := io.ReadAll(decodingReader(strings.NewReader(string(data)), encoding))
+		require.NoError(t, err)
+		return string(text)
+	}
+
+	assert.Equal(t, "éÿ", decode([]byte{0xE9, 0xFF}, encodingLatin1))
+	assert.Equal(t, "a\U0001F600", decode([]byte{0, 'a', 0xD8, 0x3D, 0xDE, 0x00}, encodingUTF16BE))
+	// An unpaired surrogate and an odd final byte become replacement characters
+	assert.Equal(t, "�a�", decode([]byte{0x3D, 0xD8, 'a', 0, 'b'}, encodingUTF16LE))
+	assert.Equal(t, "plain", decode([]byte("plain"), encodingUTF8))
+}
@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,22 +10,26 @@ import (
 	"runtime"
 
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/trash"
 )
 
 // FileDeleteInput represents parameters for deleting a file or directory
 type FileDeleteInput struct {
-	Path string `json:"path"` // Path of the file or directory to delete
+	Path      string `json:"path"`                // Path of the file or directory to delete
+	Permanent bool   `json:"permanent,omitempty"` // Delete instead of moving to the trash
 }
 
 // FileDeleteOutput represents the result of a file deletion operation
 type FileDeleteOutput struct {
-	Path         string `json:"path"`            // Path that was processed
-	Deleted      bool   `json:"deleted"`         // Whether the file/directory was deleted
-	MovedToTrash bool   `json:"moved_to_trash"`  // Whether the file was moved to trash (macOS)
-	Error        string `json:"error,omitempty"` // Error message, if any
+	Path         string `json:"path"`                 // Path that was processed
+	Deleted      bool   `json:"deleted"`              // Whether the file/directory was deleted
+	MovedToTrash bool   `json:"moved_to_trash"`       // Whether the file was moved to trash
+	TrashPath    string `json:"trash_path,omitempty"` // Where the file is kept in the freedesktop trash
+	Warning      string `json:"warning,omitempty"`    // Problem that did not stop the deletion, if any
 }
 
-// FileDeleteTool implements a tool for deleting files by moving them to trash on macOS
+// FileDeleteTool implements a tool for deleting files by moving them to the
+// macOS Trash or the freedesktop trash
 type FileDeleteTool struct {
 	core.BaseToolImpl
 }
@@ -34,15 +39,20 @@ func NewFileDeleteTool() *FileDeleteTool {
 	tool := &FileDeleteTool{}
 	tool.BaseToolImpl = *core.NewBaseTool(
 		"file_delete",
-		"Move a file or directory to the trash (on macOS) or delete it",
+		"Move a file or directory to the trash (the Finder Trash on macOS, the freedesktop trash on Linux) so it can be restored with file_restore, or delete it permanently",
 		"filesystem",
 		map[string]interface{}{
 			"type": "object",
 			"properties": map[string]interface{}{
 				"path": map[string]interface{}{
 					"type":        "string",
+					"minLength":   1,
 					"description": "Path of the file or directory to delete/move to trash",
 				},
+				"permanent": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Delete permanently instead of moving to the trash (default: false)",
+				},
 			},
 			"required": []string{"path"},
 		},
@@ -51,52 +61,38 @@ func NewFileDeleteTool() *FileDeleteTool {
 }
 
 // Execute implements the Tool interface
-func (t *FileDeleteTool) Execute(input json.RawMessage) (interface{}, error) {
-	var params FileDeleteInput
-	if err := json.Unmarshal(input, &params); err != nil {
-		return FileDeleteOutput{
-			Deleted:      false,
-			MovedToTrash: false,
-			Error:        fmt.Sprintf("Invalid input: %v", err),
-		}, fmt.Errorf("invalid input for file_delete tool: %w", err)
+func (t *FileDeleteTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	// Validate path
-	if params.Path == "" {
-		return FileDeleteOutput{
-			Deleted:      false,
-			MovedToTrash: false,
-			Error:        "Path parameter is required",
-		}, fmt.Errorf("path parameter is required")
+	var params FileDeleteInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for file_delete tool: %w", err)
 	}
 
 	// Clean the path to make it consistent
 	cleanPath := filepath.Clean(params.Path)
 
 	// Check if file/directory exists
-	if _, err := os.Stat(cleanPath); os.IsNotExist(err) {
-		return FileDeleteOutput{
-			Path:         cleanPath,
-			Deleted:      false,
-			MovedToTrash: false,
-			Error:        fmt.Sprintf("Path %s does not exist", cleanPath),
-		}, nil
+	if _, err := os.Lstat(cleanPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("path %s does not exist", cleanPath)
 	}
 
 	// Try to use platform-specific trash functionality
-	if runtime.GOOS == "darwin" {
-		// On macOS, we can use the built-in "move to trash" AppleScript functionality
-		return moveToMacOSTrash(cleanPath)
+	if !params.Permanent {
+		if runtime.GOOS == "darwin" {
+			// On macOS, we can use the built-in "move to trash" AppleScript functionality
+			return moveToMacOSTrash(ctx, cleanPath)
+		}
+		if trash.Supported() {
+			return moveToFreedesktopTrash(cleanPath)
+		}
 	}
 
-	// On other platforms, just delete the file
+	// Otherwise, just delete the file
 	if err := os.RemoveAll(cleanPath); err != nil {
-		return FileDeleteOutput{
-			Path:         cleanPath,
-			Deleted:      false,
-			MovedToTrash: false,
-			Error:        err.Error(),
-		}, fmt.Errorf("failed to delete %s: %w", cleanPath, err)
+		return nil, fmt.Errorf("failed to delete %s: %w", cleanPath, err)
 	}
 
 	return FileDeleteOutput{
@@ -107,26 +103,11 @@ func (t *FileDeleteTool) Execute(input json.RawMessage) (interface{}, error) {
 }
 
 // moveToMacOSTrash moves a file/directory to the macOS Trash using osascript
-func moveToMacOSTrash(path string) (interface{}, error) {
-	// Check if path exists first
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return FileDeleteOutput{
-			Path:         path,
-			Deleted:      false,
-			MovedToTrash: false,
-			Error:        fmt.Sprintf("Path %s does not exist", path),
-		}, nil
-	}
-
+func moveToMacOSTrash(ctx context.Context, path string) (interface{}, error) {
 	// Get absolute path for AppleScript
 	absPath, err := filepath.Abs(path)
 	if err != nil {
-		return FileDeleteOutput{
-			Path:         path,
-			Deleted:      false,
-			MovedToTrash: false,
-			Error:        fmt.Sprintf("Failed to get absolute path: %v", err),
-		}, fmt.Errorf("failed to get absolute path: %w", err)
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
 	}
 
 	// AppleScript to move file to trash
@@ -138,23 +119,23 @@ func moveToMacOSTrash(path string) (interface{}, error) {
 	`, absPath)
 
 	// Execute the AppleScript
-	cmd := exec.Command("osascript", "-e", script)
+	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
 	if err := cmd.Run(); err != nil {
+		// Don't fall back to a permanent delete if we were cancelled
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
 		// If AppleScript fails, fall back to regular delete
 		if err := os.RemoveAll(path); err != nil {
-			return FileDeleteOutput{
-				Path:         path,
-				Deleted:      false,
-				MovedToTrash: false,
-				Error:        fmt.Sprintf("Failed to delete or move to trash: %v", err),
-			}, fmt.Errorf("failed to delete or move to trash: %w", err)
+			return nil, fmt.Errorf("failed to delete or move to trash: %w", err)
 		}
 
 		return FileDeleteOutput{
 			Path:         path,
 			Deleted:      true,
 			MovedToTrash: false,
-			Error:        "AppleScript failed, file was deleted permanently",
+			Warning:      "AppleScript failed, file was deleted permanently",
 		}, nil
 	}
 
@@ -164,3 +145,32 @@ func moveToMacOSTrash(path string) (interface{}, error) {
 		MovedToTrash: true,
 	}, nil
 }
+
+// moveToFreedesktopTrash moves a file/directory to the freedesktop trash.
+// Unlike on macOS there is no fallback: if the trash cannot be used, the
+// file is left alone and the caller can ask for a permanent delete.
+func moveToFreedesktopTrash(path string) (interface{}, error) {
+	item, err := trash.Move(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move %s to trash, use permanent to delete instead: %w", path, err)
+	}
+
+	return FileDeleteOutput{
+		Path:         path,
+		Deleted:      true,
+		MovedToTrash: true,
+		TrashPath:    item.FilesPath(),
+	}, nil
+}
+
+// AffectedPaths implements core.FileMutator
+func (t *FileDeleteTool) AffectedPaths(input json.RawMessage) ([]string, error) {
+	var params FileDeleteInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for file_delete tool: %w", err)
+	}
+	if params.Path == "" {
+		return nil, nil
+	}
+	return []string{filepath.Clean(params.Path)}, nil
+}
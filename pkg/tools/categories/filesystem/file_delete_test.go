@@ -1,12 +1,14 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
 
+	"github.com/navicore/mcpterm-go/pkg/trash"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,6 +22,9 @@ func TestFileDeleteTool(t *testing.T) {
 	require.NoError(t, err)
 	defer os.RemoveAll(tempDir)
 
+	// Keep the freedesktop trash inside the temporary directory
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tempDir, "data"))
+
 	tool := NewFileDeleteTool()
 
 	t.Run("DeleteFile", func(t *testing.T) {
@@ -38,7 +43,7 @@ func TestFileDeleteTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -56,6 +61,35 @@ func TestFileDeleteTool(t *testing.T) {
 		if isMacOS {
 			t.Log("On macOS, the file should have been moved to trash")
 		}
+
+		// The freedesktop trash keeps the file where we can check it
+		if trash.Supported() {
+			assert.True(t, deleteOut.MovedToTrash)
+			content, err := os.ReadFile(deleteOut.TrashPath)
+			require.NoError(t, err)
+			assert.Equal(t, "test content", string(content))
+		}
+	})
+
+	t.Run("DeletePermanently", func(t *testing.T) {
+		testFilePath := filepath.Join(tempDir, "permanent.txt")
+		require.NoError(t, os.WriteFile(testFilePath, []byte("gone"), 0644))
+
+		input, err := json.Marshal(FileDeleteInput{
+			Path:      testFilePath,
+			Permanent: true,
+		})
+		require.NoError(t, err)
+
+		result, err := tool.Execute(context.Background(), input)
+		require.NoError(t, err)
+
+		deleteOut, ok := result.(FileDeleteOutput)
+		require.True(t, ok)
+		assert.True(t, deleteOut.Deleted)
+		assert.False(t, deleteOut.MovedToTrash)
+		assert.Empty(t, deleteOut.TrashPath)
+		assert.NoFileExists(t, testFilePath)
 	})
 
 	t.Run("DeleteNonExistentFile", func(t *testing.T) {
@@ -67,15 +101,9 @@ func TestFileDeleteTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
-		require.NoError(t, err) // Should not return an error, just indicate it wasn't deleted
-
-		// Check the result
-		deleteOut, ok := result.(FileDeleteOutput)
-		require.True(t, ok)
-		assert.Equal(t, nonExistentPath, deleteOut.Path)
-		assert.False(t, deleteOut.Deleted)
-		assert.Contains(t, deleteOut.Error, "does not exist")
+		result, err := tool.Execute(context.Background(), input)
+		assert.ErrorContains(t, err, "does not exist")
+		assert.Nil(t, result, "a failure returns only the error")
 	})
 
 	t.Run("DeleteDirectory", func(t *testing.T) {
@@ -95,7 +123,7 @@ func TestFileDeleteTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -109,34 +137,10 @@ func TestFileDeleteTool(t *testing.T) {
 		assert.True(t, os.IsNotExist(err), "Directory should no longer exist")
 	})
 
-	t.Run("EmptyPath", func(t *testing.T) {
-		// Test error when path is empty
-		input, err := json.Marshal(FileDeleteInput{
-			Path: "",
-		})
-		require.NoError(t, err)
-
-		result, err := tool.Execute(input)
-		assert.Error(t, err)
-
-		// Check the result
-		deleteOut, ok := result.(FileDeleteOutput)
-		require.True(t, ok)
-		assert.False(t, deleteOut.Deleted)
-		assert.False(t, deleteOut.MovedToTrash)
-		assert.Contains(t, deleteOut.Error, "required")
-	})
-
 	t.Run("InvalidInput", func(t *testing.T) {
 		// Test error on invalid JSON input
-		result, err := tool.Execute([]byte(`{invalid json`))
-		assert.Error(t, err)
-
-		// Check the result
-		deleteOut, ok := result.(FileDeleteOutput)
-		require.True(t, ok)
-		assert.False(t, deleteOut.Deleted)
-		assert.False(t, deleteOut.MovedToTrash)
-		assert.Contains(t, deleteOut.Error, "Invalid input")
+		result, err := tool.Execute(context.Background(), []byte(`{invalid json`))
+		assert.ErrorContains(t, err, "invalid input")
+		assert.Nil(t, result)
 	})
 }
@@ -1,19 +1,50 @@
 package filesystem
 
 import (
+	"bytes"
+	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
 )
 
+// maxFileReadBytes is the most content file_read returns at once; the rest
+// of a file is read by continuing from next_offset
+const maxFileReadBytes = 100 * 1024
+
+// binaryDumpBytes is how much of a binary file is shown as a hex dump
+const binaryDumpBytes = 256
+
 // FileReadInput represents parameters for reading a file
 type FileReadInput struct {
-	Path   string `json:"path"`
-	Offset int    `json:"offset,omitempty"`
-	Limit  int    `json:"limit,omitempty"`
+	Path        string `json:"path"`
+	Offset      int    `json:"offset,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+	LineNumbers bool   `json:"line_numbers,omitempty"`
+	Encoding    string `json:"encoding,omitempty"`
+}
+
+// FileReadOutput represents the result of reading a file
+type FileReadOutput struct {
+	Path          string `json:"path"`
+	Content       string `json:"content"`               // Lines read, decoded to UTF-8
+	Encoding      string `json:"encoding,omitempty"`    // Encoding the file was decoded from
+	Size          int64  `json:"size"`                  // File size in bytes
+	TotalLines    int    `json:"total_lines"`           // Lines in the whole file
+	Offset        int    `json:"offset"`                // First line read (0-based)
+	LinesReturned int    `json:"lines_returned"`        // Lines in content
+	Truncated     bool   `json:"truncated"`             // Content was cut at the byte cap
+	NextOffset    int    `json:"next_offset,omitempty"` // Offset to continue from, when lines remain
+	Binary        bool   `json:"binary,omitempty"`      // The file is binary, so only a summary is returned
+	MimeType      string `json:"mime_type,omitempty"`   // Detected content type of a binary file
+	HexDump       string `json:"hex_dump,omitempty"`    // Hex dump of the start of a binary file
 }
 
 // FileReadTool allows reading file contents
@@ -26,7 +57,7 @@ func NewFileReadTool() *FileReadTool {
 	tool := &FileReadTool{}
 	tool.BaseToolImpl = *core.NewBaseTool(
 		"file_read",
-		"Read the contents of a file",
+		"Read the contents of a text file, a range of lines at a time. Binary files are summarized with their type and a hex dump of the first bytes.",
 		"filesystem",
 		map[string]interface{}{
 			"type": "object",
@@ -37,12 +68,21 @@ func NewFileReadTool() *FileReadTool {
 				},
 				"offset": map[string]interface{}{
 					"type":        "integer",
-					"description": "Line number to start reading from (0-based)",
+					"description": "Line number to start reading from (0-based); use next_offset from a previous result to continue",
 				},
 				"limit": map[string]interface{}{
 					"type":        "integer",
 					"description": "Maximum number of lines to read",
 				},
+				"line_numbers": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Prefix each line with its 1-based line number and a tab (default: false)",
+				},
+				"encoding": map[string]interface{}{
+					"type":        "string",
+					"description": "Text encoding of the file; 'auto' (default) detects UTF-8, UTF-16 with or without a byte order mark, and Latin-1",
+					"enum":        []string{"auto", "utf-8", "utf-16le", "utf-16be", "latin-1"},
+				},
 			},
 			"required": []string{"path"},
 		},
@@ -51,7 +91,11 @@ func NewFileReadTool() *FileReadTool {
 }
 
 // Execute reads a file based on the provided input
-func (t *FileReadTool) Execute(input json.RawMessage) (interface{}, error) {
+func (t *FileReadTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var params FileReadInput
 	if err := json.Unmarshal(input, &params); err != nil {
 		return nil, fmt.Errorf("invalid input for file_read tool: %w", err)
@@ -61,6 +105,13 @@ func (t *FileReadTool) Execute(input json.RawMessage) (interface{}, error) {
 	if params.Path == "" {
 		return nil, fmt.Errorf("path parameter is required")
 	}
+	if params.Offset < 0 || params.Limit < 0 {
+		return nil, fmt.Errorf("offset and limit must not be negative")
+	}
+	encoding, err := parseEncoding(params.Encoding)
+	if err != nil {
+		return nil, err
+	}
 
 	// Expand ~ to home directory if present
 	if strings.HasPrefix(params.Path, "~") {
@@ -71,29 +122,170 @@ func (t *FileReadTool) Execute(input json.RawMessage) (interface{}, error) {
 		params.Path = strings.Replace(params.Path, "~", homeDir, 1)
 	}
 
-	// Read file
-	content, err := os.ReadFile(params.Path)
+	file, err := os.Open(params.Path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("failed to read file: %s is a directory", params.Path)
+	}
+
+	// Look at the start of the file to work out how to read it
+	head := make([]byte, sniffBytes)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	head = head[:n]
+
+	enc := withBOM(encoding, head)
+	if encoding == "" {
+		enc = detectEncoding(head)
+	}
+
+	output := FileReadOutput{
+		Path:   params.Path,
+		Size:   info.Size(),
+		Offset: params.Offset,
+	}
+	if enc.Binary {
+		output.Binary = true
+		output.MimeType = http.DetectContentType(head)
+		output.HexDump = hex.Dump(head[:min(len(head), binaryDumpBytes)])
+		return output, nil
+	}
+	output.Encoding = enc.Name
+
+	if _, err := file.Seek(int64(enc.BOM), io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if err := readLines(ctx, decodingReader(file, enc.Name), params, &output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// readLines reads the lines selected by offset and limit into the output,
+// up to maxFileReadBytes of content. The whole file is scanned so that the
+// total line count is known.
+func readLines(ctx context.Context, r io.Reader, params FileReadInput, output *FileReadOutput) error {
+	first := params.Offset
+	end := -1 // One past the last line wanted, or -1 for all
+	if params.Limit > 0 {
+		end = first + params.Limit
+	}
+	wanted := func(line int) bool {
+		return line >= first && (end < 0 || line < end)
+	}
 
-	// Convert to string and split into lines
-	lines := strings.Split(string(content), "\n")
+	var content strings.Builder
+	line, atLineStart := 0, true
+	lineStart := 0 // Length of content when the current line began
+	cutLine := -1  // Line cut short at the byte cap
+	next := -1     // First wanted line that did not fit
+	endLine := func() {
+		if cutLine != line {
+			return
+		}
+		if line > first {
+			// Drop the partial line; reading continues with it
+			partial := content.String()[:lineStart]
+			content.Reset()
+			content.WriteString(partial)
+			next = line
+		} else {
+			// A single line longer than the cap is returned in part
+			next = line + 1
+		}
+	}
 
-	// Apply offset and limit if provided
-	if params.Offset > 0 || params.Limit > 0 {
-		if params.Offset >= len(lines) {
-			return "", nil
+	buf := make([]byte, 32*1024)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
+		n, err := r.Read(buf)
+		data := buf[:n]
+		for len(data) > 0 {
+			piece := data
+			newline := false
+			if i := bytes.IndexByte(data, '\n'); i >= 0 {
+				piece, newline = data[:i+1], true
+			}
+			data = data[len(piece):]
 
-		end := len(lines)
-		if params.Limit > 0 && params.Offset+params.Limit < end {
-			end = params.Offset + params.Limit
+			if atLineStart {
+				atLineStart = false
+				lineStart = content.Len()
+				if wanted(line) && next < 0 && params.LineNumbers {
+					if !appendCapped(&content, []byte(fmt.Sprintf("%6d\t", line+1))) {
+						cutLine = line
+					}
+				}
+			}
+			if wanted(line) && next < 0 && cutLine < 0 {
+				if !appendCapped(&content, piece) {
+					cutLine = line
+				}
+			}
+			if newline {
+				endLine()
+				line++
+				atLineStart = true
+			}
 		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	if !atLineStart {
+		// The last line has no newline
+		endLine()
+		line++
+	}
+	output.TotalLines = line
+	output.Truncated = cutLine >= 0
 
-		lines = lines[params.Offset:end]
+	stop := line
+	if end >= 0 && end < stop {
+		stop = end
 	}
+	if next >= 0 {
+		stop = next
+	}
+	if stop < line {
+		output.NextOffset = stop
+	}
+	if stop > first {
+		output.LinesReturned = stop - first
+	}
+	output.Content = content.String()
+	return nil
+}
 
-	// Join lines back together
-	return strings.Join(lines, "\n"), nil
+// appendCapped appends data to content unless that takes it past
+// maxFileReadBytes, in which case as much as fits is appended, cut at a
+// character boundary, and false is returned
+func appendCapped(content *strings.Builder, data []byte) bool {
+	room := maxFileReadBytes - content.Len()
+	if len(data) <= room {
+		content.Write(data)
+		return true
+	}
+	cut := max(room, 0)
+	for cut > 0 && !utf8.RuneStart(data[cut]) {
+		cut--
+	}
+	content.Write(data[:cut])
+	return false
 }
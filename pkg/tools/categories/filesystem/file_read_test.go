@@ -1,11 +1,16 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFileReadTool(t *testing.T) {
@@ -93,7 +98,7 @@ func TestFileReadTool(t *testing.T) {
 			}
 
 			// Execute tool
-			result, err := fileReadTool.Execute(jsonInput)
+			result, err := fileReadTool.Execute(context.Background(), jsonInput)
 
 			// Check error
 			if tc.expectError && err == nil {
@@ -109,11 +114,12 @@ func TestFileReadTool(t *testing.T) {
 			}
 
 			// Check result type
-			content, ok := result.(string)
+			output, ok := result.(FileReadOutput)
 			if !ok {
-				t.Errorf("Expected result of type string, got %T", result)
+				t.Errorf("Expected result of type FileReadOutput, got %T", result)
 				return
 			}
+			content := output.Content
 
 			// Count lines
 			lines := strings.Split(content, "\n")
@@ -149,18 +155,19 @@ func TestFileReadTool(t *testing.T) {
 		}
 
 		jsonInput, _ := json.Marshal(goInput)
-		result, err := fileReadTool.Execute(jsonInput)
+		result, err := fileReadTool.Execute(context.Background(), jsonInput)
 
 		if err != nil {
 			t.Logf("Note: Could not read go.mod: %v", err)
 			return
 		}
 
-		content, ok := result.(string)
+		output, ok := result.(FileReadOutput)
 		if !ok {
-			t.Errorf("Expected result of type string, got %T", result)
+			t.Errorf("Expected result of type FileReadOutput, got %T", result)
 			return
 		}
+		content := output.Content
 
 		// Verify we got some content
 		if len(content) == 0 {
@@ -174,3 +181,110 @@ func TestFileReadTool(t *testing.T) {
 		}
 	})
 }
+
+// readFile runs the file_read tool on a file holding content
+func readFile(t *testing.T, content []byte, input FileReadInput) FileReadOutput {
+	t.Helper()
+	input.Path = filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(input.Path, content, 0644))
+
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	result, err := NewFileReadTool().Execute(context.Background(), data)
+	require.NoError(t, err)
+	return result.(FileReadOutput)
+}
+
+func TestFileReadLines(t *testing.T) {
+	content := []byte("one\ntwo\nthree\nfour\nfive")
+
+	output := readFile(t, content, FileReadInput{})
+	assert.Equal(t, string(content), output.Content)
+	assert.Equal(t, 5, output.TotalLines)
+	assert.Equal(t, 5, output.LinesReturned)
+	assert.Equal(t, "utf-8", output.Encoding)
+	assert.Equal(t, int64(len(content)), output.Size)
+	assert.False(t, output.Truncated)
+	assert.Zero(t, output.NextOffset)
+
+	output = readFile(t, content, FileReadInput{Offset: 1, Limit: 2, LineNumbers: true})
+	assert.Equal(t, "     2\ttwo\n     3\tthree\n", output.Content)
+	assert.Equal(t, 2, output.LinesReturned)
+	assert.Equal(t, 3, output.NextOffset)
+	assert.False(t, output.Truncated)
+
+	output = readFile(t, content, FileReadInput{Offset: 9})
+	assert.Empty(t, output.Content)
+	assert.Equal(t, 5, output.TotalLines)
+	assert.Zero(t, output.LinesReturned)
+
+	output = readFile(t, nil, FileReadInput{})
+	assert.Empty(t, output.Content)
+	assert.Zero(t, output.TotalLines)
+}
+
+func TestFileReadByteCap(t *testing.T) {
+	line := strings.Repeat("x", 999) + "\n"
+	content := []byte(strings.Repeat(line, 150))
+
+	output := readFile(t, content, FileReadInput{})
+	assert.True(t, output.Truncated)
+	assert.Equal(t, 150, output.TotalLines)
+	assert.Equal(t, maxFileReadBytes/len(line), output.LinesReturned)
+	assert.Equal(t, strings.Repeat(line, output.LinesReturned), output.Content)
+	assert.Equal(t, output.LinesReturned, output.NextOffset)
+
+	// Continuing from next_offset reads the rest
+	output = readFile(t, content, FileReadInput{Offset: output.NextOffset})
+	assert.False(t, output.Truncated)
+	assert.Equal(t, 150-maxFileReadBytes/len(line), output.LinesReturned)
+	assert.Zero(t, output.NextOffset)
+
+	// A line longer than the cap is returned in part, cut between characters
+	long := []byte(strings.Repeat("é", maxFileReadBytes) + "\nend\n")
+	output = readFile(t, long, FileReadInput{})
+	assert.True(t, output.Truncated)
+	assert.Equal(t, 1, output.LinesReturned)
+	assert.Equal(t, 1, output.NextOffset)
+	assert.Equal(t, maxFileReadBytes, len(output.Content))
+	assert.True(t, utf8.ValidString(output.Content))
+}
+
+func TestFileReadBinary(t *testing.T) {
+	content := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), make([]byte, 1000)...)
+
+	output := readFile(t, content, FileReadInput{})
+	assert.True(t, output.Binary)
+	assert.Equal(t, "image/png", output.MimeType)
+	assert.Empty(t, output.Content)
+	assert.Equal(t, int64(len(content)), output.Size)
+	assert.Contains(t, output.HexDump, "00000000  89 50 4e 47")
+	assert.Len(t, strings.Split(strings.TrimSpace(output.HexDump), "\n"), binaryDumpBytes/16)
+}
+
+func TestFileReadEncodings(t *testing.T) {
+	utf16le := []byte{0xFF, 0xFE, 'h', 0, 'i', 0, '\n', 0, 0x3D, 0xD8, 0x00, 0xDE, '\n', 0}
+	output := readFile(t, utf16le, FileReadInput{})
+	assert.Equal(t, "utf-16le", output.Encoding)
+	assert.Equal(t, "hi\n\U0001F600\n", output.Content)
+	assert.Equal(t, 2, output.TotalLines)
+
+	utf16be := []byte{0, 'h', 0, 'i', 0, '\n', 0, 'y', 0, 'o'}
+	output = readFile(t, utf16be, FileReadInput{})
+	assert.Equal(t, "utf-16be", output.Encoding)
+	assert.Equal(t, "hi\nyo", output.Content)
+
+	latin1 := []byte("caf\xe9\n")
+	output = readFile(t, latin1, FileReadInput{})
+	assert.Equal(t, "latin-1", output.Encoding)
+	assert.Equal(t, "café\n", output.Content)
+
+	output = readFile(t, []byte("\xEF\xBB\xBFbom\n"), FileReadInput{})
+	assert.Equal(t, "bom\n", output.Content)
+
+	output = readFile(t, []byte("caf\xc3\xa9\n"), FileReadInput{Encoding: "latin-1"})
+	assert.Equal(t, "cafÃ©\n", output.Content)
+
+	_, err := NewFileReadTool().Execute(context.Background(), json.RawMessage(`{"path": "x", "encoding": "ebcdic"}`))
+	assert.Error(t, err)
+}
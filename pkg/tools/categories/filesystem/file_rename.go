@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -55,7 +56,14 @@ func NewFileRenameTool() *FileRenameTool {
 }
 
 // Execute implements the Tool interface
-func (t *FileRenameTool) Execute(input json.RawMessage) (interface{}, error) {
+func (t *FileRenameTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return FileRenameOutput{
+			Renamed: false,
+			Error:   err.Error(),
+		}, err
+	}
+
 	var params FileRenameInput
 	if err := json.Unmarshal(input, &params); err != nil {
 		return FileRenameOutput{
@@ -152,3 +160,15 @@ func (t *FileRenameTool) Execute(input json.RawMessage) (interface{}, error) {
 		IsDirectory: fileInfo.IsDir(),
 	}, nil
 }
+
+// AffectedPaths implements core.FileMutator
+func (t *FileRenameTool) AffectedPaths(input json.RawMessage) ([]string, error) {
+	var params FileRenameInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for file_rename tool: %w", err)
+	}
+	if params.OldPath == "" || params.NewPath == "" {
+		return nil, nil
+	}
+	return []string{filepath.Clean(params.OldPath), filepath.Clean(params.NewPath)}, nil
+}
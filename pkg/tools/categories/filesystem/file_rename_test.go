@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -36,7 +37,7 @@ func TestFileRenameTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -76,7 +77,7 @@ func TestFileRenameTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -123,7 +124,7 @@ func TestFileRenameTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -151,7 +152,7 @@ func TestFileRenameTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		assert.Error(t, err) // Should return an error for non-existent source
 
 		// Check the result
@@ -178,7 +179,7 @@ func TestFileRenameTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		assert.Error(t, err) // Should return an error for non-existent parent dir
 
 		// Check the result
@@ -207,7 +208,7 @@ func TestFileRenameTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		assert.Error(t, err) // Should return an error when destination exists
 
 		// Check the result
@@ -225,7 +226,7 @@ func TestFileRenameTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		assert.Error(t, err)
 		renameOut, ok := result.(FileRenameOutput)
 		require.True(t, ok)
@@ -239,7 +240,7 @@ func TestFileRenameTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err = tool.Execute(input)
+		result, err = tool.Execute(context.Background(), input)
 		assert.Error(t, err)
 		renameOut, ok = result.(FileRenameOutput)
 		require.True(t, ok)
@@ -249,7 +250,7 @@ func TestFileRenameTool(t *testing.T) {
 
 	t.Run("InvalidInput", func(t *testing.T) {
 		// Test error on invalid JSON input
-		result, err := tool.Execute([]byte(`{invalid json`))
+		result, err := tool.Execute(context.Background(), []byte(`{invalid json`))
 		assert.Error(t, err)
 
 		// Check the result
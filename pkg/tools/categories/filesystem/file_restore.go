@@ -0,0 +1,127 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/trash"
+)
+
+// FileRestoreInput represents parameters for restoring a file from the trash
+type FileRestoreInput struct {
+	Path        string `json:"path"`                  // Original path of the item, or its name in the trash
+	Destination string `json:"destination,omitempty"` // Where to restore to instead of the original path
+}
+
+// FileRestoreOutput represents the result of restoring a file from the trash
+type FileRestoreOutput struct {
+	Path         string    `json:"path"`                    // Path the item was deleted from
+	RestoredTo   string    `json:"restored_to,omitempty"`   // Path the item was restored to
+	Restored     bool      `json:"restored"`                // Whether the item was restored
+	IsDirectory  bool      `json:"is_directory"`            // Whether the item is a directory
+	DeletionDate time.Time `json:"deletion_date,omitempty"` // When the item was moved to the trash
+	Error        string    `json:"error,omitempty"`         // Error message, if any
+}
+
+// FileRestoreTool implements a tool for restoring files from the
+// freedesktop trash
+type FileRestoreTool struct {
+	core.BaseToolImpl
+}
+
+// NewFileRestoreTool creates a new file restore tool
+func NewFileRestoreTool() *FileRestoreTool {
+	tool := &FileRestoreTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"file_restore",
+		"Restore a file or directory that file_delete moved to the trash (freedesktop trash on Linux). The most recently deleted item with the path is restored; an existing file is never overwritten.",
+		"filesystem",
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Original path of the deleted file or directory, or its name in the trash",
+				},
+				"destination": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to restore to instead of the original path (must not exist)",
+				},
+			},
+			"required": []string{"path"},
+		},
+	)
+	return tool
+}
+
+// Execute implements the Tool interface
+func (t *FileRestoreTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return FileRestoreOutput{
+			Restored: false,
+			Error:    err.Error(),
+		}, err
+	}
+
+	var params FileRestoreInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return FileRestoreOutput{
+			Restored: false,
+			Error:    fmt.Sprintf("Invalid input: %v", err),
+		}, fmt.Errorf("invalid input for file_restore tool: %w", err)
+	}
+
+	if params.Path == "" {
+		return FileRestoreOutput{
+			Restored: false,
+			Error:    "Path parameter is required",
+		}, fmt.Errorf("path parameter is required")
+	}
+
+	item, err := trash.Find(params.Path)
+	if err != nil {
+		return FileRestoreOutput{
+			Path:     params.Path,
+			Restored: false,
+			Error:    err.Error(),
+		}, err
+	}
+
+	output := FileRestoreOutput{
+		Path:         item.Path,
+		IsDirectory:  item.IsDir,
+		DeletionDate: item.DeletionDate,
+	}
+	dest, err := trash.Restore(item, params.Destination)
+	if err != nil {
+		output.Error = err.Error()
+		return output, err
+	}
+
+	output.RestoredTo = dest
+	output.Restored = true
+	return output, nil
+}
+
+// AffectedPaths implements core.FileMutator
+func (t *FileRestoreTool) AffectedPaths(input json.RawMessage) ([]string, error) {
+	var params FileRestoreInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for file_restore tool: %w", err)
+	}
+	if params.Destination != "" {
+		return []string{filepath.Clean(params.Destination)}, nil
+	}
+	if params.Path == "" {
+		return nil, nil
+	}
+	// The path may be a name in the trash rather than the original path
+	if item, err := trash.Find(params.Path); err == nil {
+		return []string{item.Path}, nil
+	}
+	return []string{filepath.Clean(params.Path)}, nil
+}
@@ -0,0 +1,104 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/trash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRestoreTool(t *testing.T) {
+	if !trash.Supported() {
+		t.Skip("the freedesktop trash is not used on this platform")
+	}
+	tempDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tempDir, "data"))
+
+	deleteTool := NewFileDeleteTool()
+	restoreTool := NewFileRestoreTool()
+
+	// deletePath moves a path to the trash with file_delete
+	deletePath := func(t *testing.T, path string) {
+		t.Helper()
+		input, err := json.Marshal(FileDeleteInput{Path: path})
+		require.NoError(t, err)
+		_, err = deleteTool.Execute(context.Background(), input)
+		require.NoError(t, err)
+	}
+
+	// restore runs file_restore
+	restore := func(t *testing.T, params FileRestoreInput) (FileRestoreOutput, error) {
+		t.Helper()
+		input, err := json.Marshal(params)
+		require.NoError(t, err)
+		result, err := restoreTool.Execute(context.Background(), input)
+		out, ok := result.(FileRestoreOutput)
+		require.True(t, ok)
+		return out, err
+	}
+
+	t.Run("RestoreToOriginalPath", func(t *testing.T) {
+		dir := filepath.Join(tempDir, "project")
+		writeFiles(t, dir, map[string]string{"a.txt": "a", "sub/b.txt": "b"})
+		deletePath(t, dir)
+		require.NoDirExists(t, dir)
+
+		out, err := restore(t, FileRestoreInput{Path: dir})
+		require.NoError(t, err)
+		assert.True(t, out.Restored)
+		assert.True(t, out.IsDirectory)
+		assert.Equal(t, dir, out.RestoredTo)
+		assert.False(t, out.DeletionDate.IsZero())
+		content, err := os.ReadFile(filepath.Join(dir, "sub", "b.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "b", string(content))
+	})
+
+	t.Run("MostRecentFirst", func(t *testing.T) {
+		path := filepath.Join(tempDir, "note.txt")
+		for _, content := range []string{"old", "new"} {
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+			deletePath(t, path)
+		}
+
+		out, err := restore(t, FileRestoreInput{Path: path, Destination: filepath.Join(tempDir, "restored", "note.txt")})
+		require.NoError(t, err)
+		content, err := os.ReadFile(out.RestoredTo)
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(content))
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("ExistingPath", func(t *testing.T) {
+		path := filepath.Join(tempDir, "clash.txt")
+		require.NoError(t, os.WriteFile(path, []byte("trashed"), 0644))
+		deletePath(t, path)
+		require.NoError(t, os.WriteFile(path, []byte("replacement"), 0644))
+
+		out, err := restore(t, FileRestoreInput{Path: path})
+		assert.Error(t, err)
+		assert.False(t, out.Restored)
+		assert.Contains(t, out.Error, "already exists")
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "replacement", string(content))
+	})
+
+	t.Run("NotInTrash", func(t *testing.T) {
+		out, err := restore(t, FileRestoreInput{Path: filepath.Join(tempDir, "never.txt")})
+		assert.Error(t, err)
+		assert.False(t, out.Restored)
+		assert.Contains(t, out.Error, "not in the trash")
+	})
+
+	t.Run("AffectedPaths", func(t *testing.T) {
+		paths, err := restoreTool.AffectedPaths(json.RawMessage(`{"path":"x","destination":"/tmp/y/../z"}`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/tmp/z"}, paths)
+	})
+}
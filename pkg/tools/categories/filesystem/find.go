@@ -1,25 +1,55 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
 )
 
-// FindInput represents parameters for the find command
+// defaultFindLimit is the number of entries find returns unless told otherwise
+const defaultFindLimit = 200
+
+// FindInput represents parameters for the find tool
 type FindInput struct {
 	Directory string   `json:"directory"`
-	Name      string   `json:"name,omitempty"`
-	Type      string   `json:"type,omitempty"`
-	Maxdepth  int      `json:"maxdepth,omitempty"`
-	Pattern   string   `json:"pattern,omitempty"`
-	Size      string   `json:"size,omitempty"`  // Size (e.g., "+1k" for > 1KB)
-	Mtime     string   `json:"mtime,omitempty"` // Modified time (e.g., "-1" for last day)
-	Path      string   `json:"path,omitempty"`  // Path pattern to match/exclude
-	Args      []string `json:"args,omitempty"`
+	Name      string   `json:"name,omitempty"`      // Glob matched against the base name (e.g., "*.go")
+	Pattern   string   `json:"pattern,omitempty"`   // Glob matched against the path below directory (e.g., "cmd/**/*.go")
+	Exclude   []string `json:"exclude,omitempty"`   // Globs of paths to leave out
+	Type      string   `json:"type,omitempty"`      // "f" for files, "d" for directories, "l" for symlinks
+	Maxdepth  int      `json:"maxdepth,omitempty"`  // Deepest level searched, directory's entries being level 1
+	Size      string   `json:"size,omitempty"`      // Size (e.g., "+1k" for > 1KB)
+	Mtime     string   `json:"mtime,omitempty"`     // Modified time (e.g., "-1" for last day, "-2h" for last two hours)
+	NoIgnore  bool     `json:"no_ignore,omitempty"` // Also find paths excluded by .gitignore and .ignore
+	Limit     int      `json:"limit,omitempty"`     // Maximum number of entries returned (default 200)
+	Sort      string   `json:"sort,omitempty"`      // "modified" (default, newest first) or "path"
+}
+
+// FindEntry is a file or directory found by the find tool
+type FindEntry struct {
+	Path    string    `json:"path"`
+	Type    string    `json:"type"` // "file", "dir" or "symlink"
+	Size    int64     `json:"size,omitempty"`
+	ModTime time.Time `json:"mod_time"`
 }
 
-// FindTool is a placeholder implementation of the find tool
+// FindResult represents the result of the find tool
+type FindResult struct {
+	Entries   []FindEntry `json:"entries"`
+	Total     int         `json:"total"`     // Entries that matched, including those past the limit
+	Truncated bool        `json:"truncated"` // Some matching entries were left out
+}
+
+// FindTool searches a directory tree for files and directories
 type FindTool struct {
 	core.BaseToolImpl
 }
@@ -29,7 +59,7 @@ func NewFindTool() *FindTool {
 	tool := &FindTool{}
 	tool.BaseToolImpl = *core.NewBaseTool(
 		"find",
-		"Execute the find command to search for files and directories",
+		"Find files and directories by name, path glob, type, size and modification time, most recently modified first. Paths excluded by .gitignore and .ignore files are skipped.",
 		"filesystem",
 		map[string]interface{}{
 			"type": "object",
@@ -42,6 +72,47 @@ func NewFindTool() *FindTool {
 					"type":        "string",
 					"description": "File name pattern to search for (e.g., '*.go')",
 				},
+				"pattern": map[string]interface{}{
+					"type":        "string",
+					"description": "Glob matched against the path below directory; '**' matches any number of directories (e.g., 'cmd/**/*.go'). A glob without '/' matches names at any depth.",
+				},
+				"exclude": map[string]interface{}{
+					"type":        "array",
+					"description": "Globs of paths to leave out (e.g., ['*_test.go', 'testdata/']); excluded directories are not searched",
+					"items": map[string]interface{}{
+						"type": "string",
+					},
+				},
+				"type": map[string]interface{}{
+					"type":        "string",
+					"description": "Type of entry: 'f' for regular files, 'd' for directories, 'l' for symbolic links",
+					"enum":        []string{"f", "d", "l"},
+				},
+				"maxdepth": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum depth to search, 1 being the entries of directory",
+				},
+				"size": map[string]interface{}{
+					"type":        "string",
+					"description": "Size filter as in find(1): '+1k' for more than 1KB, '-10M' for less than 10MB, '512c' for exactly 512 bytes. Units are c (bytes, the default), k, M and G.",
+				},
+				"mtime": map[string]interface{}{
+					"type":        "string",
+					"description": "Modification time filter: '-1' for modified within the last day, '+7' for more than 7 days ago. Units are d (days, the default), h, m and s, e.g. '-2h'.",
+				},
+				"no_ignore": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also find paths excluded by .gitignore and .ignore files and node_modules (default: false)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum number of entries to return (default: 200)",
+				},
+				"sort": map[string]interface{}{
+					"type":        "string",
+					"description": "Order of the entries: 'modified' (default) for the most recently modified first, or 'path'",
+					"enum":        []string{"modified", "path"},
+				},
 			},
 			"required": []string{"directory"},
 		},
@@ -49,8 +120,259 @@ func NewFindTool() *FindTool {
 	return tool
 }
 
-// Execute implements the Tool interface (placeholder)
-func (t *FindTool) Execute(input json.RawMessage) (interface{}, error) {
-	// This is a placeholder implementation
-	return []string{"placeholder-implementation.go"}, nil
+// findFilter holds the compiled filters of a find
+type findFilter struct {
+	name    string
+	pattern *regexp.Regexp
+	exclude *ignoreRules
+	kind    string
+	size    func(int64) bool
+	mtime   func(time.Time) bool
+}
+
+// Execute implements the Tool interface
+func (t *FindTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params FindInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for find tool: %w", err)
+	}
+
+	if params.Directory == "" {
+		return nil, fmt.Errorf("directory parameter is required")
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultFindLimit
+	}
+
+	filter, err := newFindFilter(params, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(params.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", params.Directory)
+	}
+
+	entries := []FindEntry{}
+	opts := walkOptions{NoIgnore: params.NoIgnore, MaxDepth: params.Maxdepth}
+	err = walkTree(ctx, params.Directory, opts, func(path string, entry fs.DirEntry, depth int) error {
+		rel, err := filepath.Rel(params.Directory, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if filter.exclude.ignored(rel, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil // Skip entries that vanished
+		}
+		if found, ok := filter.match(rel, entry, info); ok {
+			found.Path = path
+			entries = append(entries, found)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find failed: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if params.Sort != "path" && !entries[i].ModTime.Equal(entries[j].ModTime) {
+			return entries[i].ModTime.After(entries[j].ModTime)
+		}
+		return entries[i].Path < entries[j].Path
+	})
+
+	result := FindResult{Entries: entries, Total: len(entries)}
+	if len(entries) > params.Limit {
+		result.Entries = entries[:params.Limit]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+// newFindFilter checks and compiles the filters of the input
+func newFindFilter(params FindInput, now time.Time) (*findFilter, error) {
+	filter := &findFilter{name: params.Name}
+	if _, err := filepath.Match(params.Name, ""); err != nil {
+		return nil, fmt.Errorf("invalid name pattern %q: %w", params.Name, err)
+	}
+
+	if params.Pattern != "" {
+		regex, err := compileGlob(params.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", params.Pattern, err)
+		}
+		filter.pattern = regex
+	}
+	// Exclude globs follow ignore file rules, so "testdata/" names a directory
+	filter.exclude = &ignoreRules{}
+	for _, glob := range params.Exclude {
+		rule, ok := parseIgnorePattern(glob)
+		if !ok {
+			return nil, fmt.Errorf("invalid exclude pattern %q", glob)
+		}
+		filter.exclude.rules = append(filter.exclude.rules, rule)
+	}
+
+	switch params.Type {
+	case "":
+	case "f", "file":
+		filter.kind = "file"
+	case "d", "dir", "directory":
+		filter.kind = "dir"
+	case "l", "symlink":
+		filter.kind = "symlink"
+	default:
+		return nil, fmt.Errorf("invalid type %q, must be 'f', 'd' or 'l'", params.Type)
+	}
+
+	switch params.Sort {
+	case "", "modified", "path":
+	default:
+		return nil, fmt.Errorf("invalid sort %q, must be 'modified' or 'path'", params.Sort)
+	}
+
+	var err error
+	if params.Size != "" {
+		if filter.size, err = parseSizeFilter(params.Size); err != nil {
+			return nil, err
+		}
+	}
+	if params.Mtime != "" {
+		if filter.mtime, err = parseMtimeFilter(params.Mtime, now); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// match checks an entry against the filters, returning it as a FindEntry
+// without its path when it matches
+func (f *findFilter) match(rel string, entry fs.DirEntry, info fs.FileInfo) (FindEntry, bool) {
+	kind := "file"
+	switch {
+	case entry.Type()&fs.ModeSymlink != 0:
+		kind = "symlink"
+	case entry.IsDir():
+		kind = "dir"
+	case !entry.Type().IsRegular():
+		kind = "other"
+	}
+
+	if f.kind != "" && f.kind != kind {
+		return FindEntry{}, false
+	}
+	if f.name != "" {
+		if matched, _ := filepath.Match(f.name, entry.Name()); !matched {
+			return FindEntry{}, false
+		}
+	}
+	if f.pattern != nil && !f.pattern.MatchString(rel) {
+		return FindEntry{}, false
+	}
+	if f.size != nil && (kind == "dir" || !f.size(info.Size())) {
+		return FindEntry{}, false
+	}
+	if f.mtime != nil && !f.mtime(info.ModTime()) {
+		return FindEntry{}, false
+	}
+
+	found := FindEntry{Type: kind, ModTime: info.ModTime()}
+	if kind != "dir" {
+		found.Size = info.Size()
+	}
+	return found, true
+}
+
+// parseSizeFilter parses a find(1) style size: a number with an optional
+// unit (c, k, M or G), prefixed with + for larger or - for smaller sizes
+func parseSizeFilter(spec string) (func(int64) bool, error) {
+	sign, number := splitSign(spec)
+	unit := int64(1)
+	if number != "" {
+		switch number[len(number)-1] {
+		case 'c':
+			number = number[:len(number)-1]
+		case 'k', 'K':
+			unit, number = 1<<10, number[:len(number)-1]
+		case 'M':
+			unit, number = 1<<20, number[:len(number)-1]
+		case 'G':
+			unit, number = 1<<30, number[:len(number)-1]
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid size %q, use a number with an optional unit and sign such as '+1k' or '-10M'", spec)
+	}
+	limit := n * unit
+
+	switch sign {
+	case '+':
+		return func(size int64) bool { return size > limit }, nil
+	case '-':
+		return func(size int64) bool { return size < limit }, nil
+	}
+	return func(size int64) bool { return size == limit }, nil
+}
+
+// parseMtimeFilter parses a modification time filter: a number of days, or
+// of hours, minutes or seconds with an h, m or s unit, prefixed with - for
+// more recent or + for older times. Without a sign the file must have been
+// modified between n and n+1 units ago.
+func parseMtimeFilter(spec string, now time.Time) (func(time.Time) bool, error) {
+	sign, number := splitSign(spec)
+	unit := 24 * time.Hour
+	if number != "" {
+		switch number[len(number)-1] {
+		case 'd':
+			number = number[:len(number)-1]
+		case 'h':
+			unit, number = time.Hour, number[:len(number)-1]
+		case 'm':
+			unit, number = time.Minute, number[:len(number)-1]
+		case 's':
+			unit, number = time.Second, number[:len(number)-1]
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid mtime %q, use a number of days such as '-1' or '+7', or a unit such as '-2h'", spec)
+	}
+	age := time.Duration(n) * unit
+
+	switch sign {
+	case '-':
+		return func(modTime time.Time) bool { return now.Sub(modTime) < age }, nil
+	case '+':
+		return func(modTime time.Time) bool { return now.Sub(modTime) > age }, nil
+	}
+	return func(modTime time.Time) bool {
+		elapsed := now.Sub(modTime)
+		return elapsed >= age && elapsed < age+unit
+	}, nil
+}
+
+// splitSign separates a leading + or - from a filter value
+func splitSign(spec string) (byte, string) {
+	spec = strings.TrimSpace(spec)
+	if spec != "" && (spec[0] == '+' || spec[0] == '-') {
+		return spec[0], spec[1:]
+	}
+	return 0, spec
 }
@@ -1,8 +1,15 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFindTool(t *testing.T) {
@@ -56,7 +63,7 @@ func TestFindTool(t *testing.T) {
 			input: FindInput{
 				Directory: ".",
 				Type:      "f",
-				Path:      "!*/\\.git/*",
+				Exclude:   []string{"testdata/"},
 				Maxdepth:  3,
 			},
 			expectError: false,
@@ -87,7 +94,7 @@ func TestFindTool(t *testing.T) {
 			}
 
 			// Execute tool
-			result, err := findTool.Execute(jsonInput)
+			result, err := findTool.Execute(context.Background(), jsonInput)
 
 			// Check error
 			if tc.expectError && err == nil {
@@ -103,11 +110,12 @@ func TestFindTool(t *testing.T) {
 			}
 
 			// Check result type
-			files, ok := result.([]string)
+			found, ok := result.(FindResult)
 			if !ok {
-				t.Errorf("Expected result of type []string, got %T", result)
+				t.Errorf("Expected result of type FindResult, got %T", result)
 				return
 			}
+			files := found.Entries
 
 			// Check minimum number of results
 			if len(files) < tc.minResults {
@@ -119,3 +127,126 @@ func TestFindTool(t *testing.T) {
 		})
 	}
 }
+
+// runFind executes the find tool and returns the found paths relative to dir
+func runFind(t *testing.T, dir string, input FindInput) (FindResult, []string) {
+	t.Helper()
+	input.Directory = dir
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	result, err := NewFindTool().Execute(context.Background(), data)
+	require.NoError(t, err)
+
+	found := result.(FindResult)
+	paths := []string{}
+	for _, entry := range found.Entries {
+		rel, err := filepath.Rel(dir, entry.Path)
+		require.NoError(t, err)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return found, paths
+}
+
+func TestFindFilters(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".gitignore":              "*.log\n",
+		"main.go":                 "package main\n",
+		"main_test.go":            "package main\n",
+		"cmd/tool/tool.go":        "package tool\n",
+		"cmd/tool/big.go":         string(make([]byte, 4096)),
+		"testdata/fixture.go":     "package testdata\n",
+		"debug.log":               "log\n",
+		"node_modules/m/index.js": "x\n",
+	})
+	require.NoError(t, os.Symlink("main.go", filepath.Join(dir, "link.go")))
+
+	// Give every file its own age so the newest-first order is known
+	now := time.Now()
+	for i, name := range []string{"main.go", "main_test.go", "cmd/tool/tool.go", "cmd/tool/big.go", "testdata/fixture.go"} {
+		modTime := now.Add(-time.Duration(i+1) * time.Hour)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, filepath.FromSlash(name)), modTime, modTime))
+	}
+
+	_, paths := runFind(t, dir, FindInput{Type: "f", Name: "*.go"})
+	assert.Equal(t, []string{"main.go", "main_test.go", "cmd/tool/tool.go", "cmd/tool/big.go", "testdata/fixture.go"}, paths)
+
+	_, paths = runFind(t, dir, FindInput{Pattern: "cmd/**/*.go", Sort: "path"})
+	assert.Equal(t, []string{"cmd/tool/big.go", "cmd/tool/tool.go"}, paths)
+
+	_, paths = runFind(t, dir, FindInput{Name: "*.go", Exclude: []string{"*_test.go", "testdata/"}, Sort: "path", Type: "f"})
+	assert.Equal(t, []string{"cmd/tool/big.go", "cmd/tool/tool.go", "main.go"}, paths)
+
+	_, paths = runFind(t, dir, FindInput{Type: "d", Sort: "path"})
+	assert.Equal(t, []string{"cmd", "cmd/tool", "testdata"}, paths)
+
+	_, paths = runFind(t, dir, FindInput{Type: "l"})
+	assert.Equal(t, []string{"link.go"}, paths)
+
+	_, paths = runFind(t, dir, FindInput{Size: "+1k"})
+	assert.Equal(t, []string{"cmd/tool/big.go"}, paths)
+
+	_, paths = runFind(t, dir, FindInput{Type: "f", Mtime: "-90m", Name: "*.go"})
+	assert.Equal(t, []string{"main.go"}, paths)
+
+	_, paths = runFind(t, dir, FindInput{Type: "f", Maxdepth: 1, Sort: "path"})
+	assert.Equal(t, []string{".gitignore", "main.go", "main_test.go"}, paths)
+
+	_, paths = runFind(t, dir, FindInput{Name: "*.log", NoIgnore: true})
+	assert.Equal(t, []string{"debug.log"}, paths)
+	_, paths = runFind(t, dir, FindInput{Name: "index.js", NoIgnore: true})
+	assert.Equal(t, []string{"node_modules/m/index.js"}, paths)
+
+	found, paths := runFind(t, dir, FindInput{Type: "f", Name: "*.go", Limit: 2})
+	assert.Equal(t, []string{"main.go", "main_test.go"}, paths)
+	assert.Equal(t, 5, found.Total)
+	assert.True(t, found.Truncated)
+}
+
+func TestFindFilterParsing(t *testing.T) {
+	now := time.Now()
+
+	size, err := parseSizeFilter("+1k")
+	require.NoError(t, err)
+	assert.True(t, size(1025))
+	assert.False(t, size(1024))
+
+	size, err = parseSizeFilter("-2M")
+	require.NoError(t, err)
+	assert.True(t, size(2<<20-1))
+	assert.False(t, size(2<<20))
+
+	size, err = parseSizeFilter("512c")
+	require.NoError(t, err)
+	assert.True(t, size(512))
+	assert.False(t, size(513))
+
+	mtime, err := parseMtimeFilter("-1", now)
+	require.NoError(t, err)
+	assert.True(t, mtime(now.Add(-23*time.Hour)))
+	assert.False(t, mtime(now.Add(-25*time.Hour)))
+
+	mtime, err = parseMtimeFilter("+7", now)
+	require.NoError(t, err)
+	assert.True(t, mtime(now.Add(-8*24*time.Hour)))
+	assert.False(t, mtime(now.Add(-6*24*time.Hour)))
+
+	mtime, err = parseMtimeFilter("2h", now)
+	require.NoError(t, err)
+	assert.True(t, mtime(now.Add(-150*time.Minute)))
+	assert.False(t, mtime(now.Add(-90*time.Minute)))
+
+	for _, spec := range []string{"", "+", "1x", "-k", "+-1"} {
+		_, err := parseSizeFilter(spec)
+		assert.Error(t, err, spec)
+	}
+	for _, spec := range []string{"", "-", "1w", "+1.5"} {
+		_, err := parseMtimeFilter(spec, now)
+		assert.Error(t, err, spec)
+	}
+
+	_, err = NewFindTool().Execute(context.Background(), json.RawMessage(`{"directory": ".", "type": "x"}`))
+	assert.Error(t, err)
+	_, err = NewFindTool().Execute(context.Background(), json.RawMessage(`{"directory": ".", "name": "[a"}`))
+	assert.Error(t, err)
+}
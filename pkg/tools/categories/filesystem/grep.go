@@ -1,14 +1,18 @@
 package filesystem
 
 import (
-	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
 )
@@ -23,30 +27,50 @@ type GrepInput struct {
 	IgnoreCase bool   `json:"ignore_case,omitempty"` // Case insensitive search
 	MaxFiles   int    `json:"max_files,omitempty"`   // Maximum number of files to search
 	MaxResults int    `json:"max_results,omitempty"` // Maximum number of results to return
+
+	Context       int    `json:"context,omitempty"`        // Lines of context before and after each match
+	BeforeContext int    `json:"before_context,omitempty"` // Lines of context before each match (overrides context)
+	AfterContext  int    `json:"after_context,omitempty"`  // Lines of context after each match (overrides context)
+	Multiline     bool   `json:"multiline,omitempty"`      // Let matches span lines
+	NoIgnore      bool   `json:"no_ignore,omitempty"`      // Also search files excluded by .gitignore and .ignore
+	Sort          string `json:"sort,omitempty"`           // "path" (default) or "modified", newest first
 }
 
 // GrepMatch represents a single match in a file
 type GrepMatch struct {
-	LineNumber int    `json:"line_number"` // Line number where the match was found
-	LineText   string `json:"line_text"`   // The text of the matched line
+	LineNumber    int      `json:"line_number"`               // Line number where the match was found
+	LineText      string   `json:"line_text"`                 // The text of the matched line, or lines for a multiline match
+	EndLineNumber int      `json:"end_line_number,omitempty"` // Last line of a match spanning lines
+	ContextBefore []string `json:"context_before,omitempty"`  // Lines before the match
+	ContextAfter  []string `json:"context_after,omitempty"`   // Lines after the match
 }
 
 // GrepFileResult represents the grep results for a single file
 type GrepFileResult struct {
 	FilePath string      `json:"file_path"` // Path to the file
+	ModTime  time.Time   `json:"mod_time"`  // When the file was last modified
 	Matches  []GrepMatch `json:"matches"`   // Matches found in the file
 }
 
 // GrepResult represents the complete grep results
 type GrepResult struct {
-	Pattern      string           `json:"pattern"`             // The pattern that was searched for
-	TotalMatches int              `json:"total_matches"`       // Total number of matches found
-	FilesMatched int              `json:"files_matched"`       // Number of files with matches
-	Results      []GrepFileResult `json:"results"`             // Results for each file
-	Error        string           `json:"error,omitempty"`     // Error message, if any
-	Truncated    bool             `json:"truncated,omitempty"` // Whether results were truncated
+	Pattern       string           `json:"pattern"`                 // The pattern that was searched for
+	TotalMatches  int              `json:"total_matches"`           // Total number of matches found
+	FilesMatched  int              `json:"files_matched"`           // Number of files with matches
+	FilesSearched int              `json:"files_searched"`          // Number of files searched
+	SkippedFiles  int              `json:"skipped_files,omitempty"` // Binary or oversized files that were not searched
+	Results       []GrepFileResult `json:"results"`                 // Results for each file
+	Error         string           `json:"error,omitempty"`         // Error message, if any
+	Truncated     bool             `json:"truncated,omitempty"`     // Whether results were truncated
 }
 
+const (
+	grepProgressInterval = 50       // Report progress every N files searched
+	grepCancelCheckLines = 10000    // Check for cancellation every N lines within a file
+	maxGrepWorkers       = 8        // Files searched at once
+	maxGrepFileBytes     = 16 << 20 // Larger files are skipped
+)
+
 // GrepTool implements a tool for searching file contents
 type GrepTool struct {
 	core.BaseToolImpl
@@ -57,7 +81,7 @@ func NewGrepTool() *GrepTool {
 	tool := &GrepTool{}
 	tool.BaseToolImpl = *core.NewBaseTool(
 		"grep",
-		"Search file contents for specified patterns",
+		"Search file contents for a regular expression, skipping binary files and paths excluded by .gitignore and .ignore files, with optional context lines",
 		"filesystem",
 		map[string]interface{}{
 			"type": "object",
@@ -94,6 +118,31 @@ func NewGrepTool() *GrepTool {
 					"type":        "integer",
 					"description": "Maximum number of results to return",
 				},
+				"context": map[string]interface{}{
+					"type":        "integer",
+					"description": "Lines of context to show before and after each match",
+				},
+				"before_context": map[string]interface{}{
+					"type":        "integer",
+					"description": "Lines of context to show before each match (overrides context)",
+				},
+				"after_context": map[string]interface{}{
+					"type":        "integer",
+					"description": "Lines of context to show after each match (overrides context)",
+				},
+				"multiline": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Let matches span lines: \\n matches a line break, ^ and $ match at line boundaries, and (?s) makes . match line breaks",
+				},
+				"no_ignore": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also search files excluded by .gitignore and .ignore files and node_modules (default: false)",
+				},
+				"sort": map[string]interface{}{
+					"type":        "string",
+					"description": "Order of the files in the results: 'path' (default) or 'modified' for the most recently modified first",
+					"enum":        []string{"path", "modified"},
+				},
 			},
 			"required": []string{"pattern"},
 		},
@@ -102,7 +151,7 @@ func NewGrepTool() *GrepTool {
 }
 
 // Execute implements the Tool interface
-func (t *GrepTool) Execute(input json.RawMessage) (interface{}, error) {
+func (t *GrepTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
 	var params GrepInput
 	if err := json.Unmarshal(input, &params); err != nil {
 		return GrepResult{
@@ -146,15 +195,36 @@ func (t *GrepTool) Execute(input json.RawMessage) (interface{}, error) {
 		params.MaxResults = 1000
 	}
 
+	if params.BeforeContext <= 0 {
+		params.BeforeContext = params.Context
+	}
+	if params.AfterContext <= 0 {
+		params.AfterContext = params.Context
+	}
+
+	switch params.Sort {
+	case "", "path", "modified":
+	default:
+		return GrepResult{
+			Pattern: params.Pattern,
+			Error:   fmt.Sprintf("Invalid sort %q", params.Sort),
+		}, fmt.Errorf("invalid sort %q, must be 'path' or 'modified'", params.Sort)
+	}
+
 	// Compile the pattern
-	var pattern *regexp.Regexp
-	var err error
+	flags := ""
 	if params.IgnoreCase {
-		// Case insensitive
-		pattern, err = regexp.Compile("(?i)" + params.Pattern)
-	} else {
-		pattern, err = regexp.Compile(params.Pattern)
+		flags += "i"
 	}
+	if params.Multiline {
+		// ^ and $ match at line boundaries within the file
+		flags += "m"
+	}
+	expr := params.Pattern
+	if flags != "" {
+		expr = "(?" + flags + ")" + expr
+	}
+	pattern, err := regexp.Compile(expr)
 
 	if err != nil {
 		return GrepResult{
@@ -177,7 +247,7 @@ func (t *GrepTool) Execute(input json.RawMessage) (interface{}, error) {
 		return result, fmt.Errorf("search failed: path %s does not exist", searchPath)
 	}
 
-	err = t.searchFiles(searchPath, params, pattern, &result)
+	err = t.searchFiles(ctx, searchPath, params, pattern, &result)
 	if err != nil {
 		result.Error = fmt.Sprintf("Search failed: %v", err)
 		return result, fmt.Errorf("search failed: %w", err)
@@ -186,121 +256,289 @@ func (t *GrepTool) Execute(input json.RawMessage) (interface{}, error) {
 	return result, nil
 }
 
-// searchFiles searches files in the given path
-func (t *GrepTool) searchFiles(rootPath string, params GrepInput, pattern *regexp.Regexp, result *GrepResult) error {
-	fileCount := 0
-
-	walkFn := func(path string, info os.FileInfo, err error) error {
-		// Skip errors in accessing files
-		if err != nil {
-			return nil
-		}
-
-		// Skip directories
-		if info.IsDir() {
-			// Skip recursive search if not enabled
-			if !params.Recursive && path != rootPath {
-				return filepath.SkipDir
-			}
-			return nil
-		}
+// grepOutcome is the result of searching one file
+type grepOutcome struct {
+	path    string
+	modTime time.Time
+	matches []GrepMatch
+	skipped bool
+	err     error
+}
 
-		// Check file count limit
-		if fileCount >= params.MaxFiles {
-			result.Truncated = true
-			return filepath.SkipAll
-		}
+// searchFiles searches the files below rootPath with a pool of workers.
+// Files are found by a single walk that honours ignore files; the results
+// are sorted afterwards so they do not depend on which worker finished
+// first.
+func (t *GrepTool) searchFiles(ctx context.Context, rootPath string, params GrepInput, pattern *regexp.Regexp, result *GrepResult) error {
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	opts := walkOptions{NoIgnore: params.NoIgnore}
+	if !params.Recursive {
+		opts.MaxDepth = 1
+	}
 
-		// Check file name patterns
-		if params.Include != "" {
-			matched, err := filepath.Match(params.Include, filepath.Base(path))
-			if err != nil {
-				return nil // Skip on error
+	paths := make(chan string)
+	var walkErr error
+	var tooManyFiles bool
+	go func() {
+		defer close(paths)
+		fileCount := 0
+		walkErr = walkTree(searchCtx, rootPath, opts, func(path string, entry fs.DirEntry, depth int) error {
+			if entry.IsDir() || !entry.Type().IsRegular() {
+				return nil
 			}
-			if !matched {
+			if !grepNameMatches(params, filepath.Base(path)) {
 				return nil
 			}
-		}
 
-		if params.Exclude != "" {
-			matched, err := filepath.Match(params.Exclude, filepath.Base(path))
-			if err != nil {
-				return nil // Skip on error
+			// Check file count limit
+			if fileCount >= params.MaxFiles {
+				tooManyFiles = true
+				return filepath.SkipAll
 			}
-			if matched {
+			fileCount++
+
+			select {
+			case paths <- path:
 				return nil
+			case <-searchCtx.Done():
+				return searchCtx.Err()
+			}
+		})
+	}()
+
+	outcomes := make(chan grepOutcome)
+	var workers sync.WaitGroup
+	for i := 0; i < min(runtime.GOMAXPROCS(0), maxGrepWorkers); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for path := range paths {
+				outcomes <- t.searchInFile(searchCtx, path, pattern, params)
 			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(outcomes)
+	}()
+
+	enough := false
+	for outcome := range outcomes {
+		if outcome.err != nil {
+			continue // Skip files we cannot read
+		}
+		result.FilesSearched++
+		if result.FilesSearched%grepProgressInterval == 0 {
+			core.ReportProgress(ctx, fmt.Sprintf("searched %d files", result.FilesSearched), result.FilesSearched, params.MaxFiles)
+		}
+		if outcome.skipped {
+			result.SkippedFiles++
+			continue
+		}
+		if len(outcome.matches) == 0 {
+			continue
 		}
 
-		// Process the file
-		fileCount++
-		matches, err := t.searchInFile(path, pattern, params.MaxResults-result.TotalMatches)
-		if err != nil {
-			return nil // Skip on error
+		result.Results = append(result.Results, GrepFileResult{
+			FilePath: outcome.path,
+			ModTime:  outcome.modTime,
+			Matches:  outcome.matches,
+		})
+		result.TotalMatches += len(outcome.matches)
+
+		// Stop searching once there are enough matches
+		if result.TotalMatches >= params.MaxResults && !enough {
+			enough = true
+			cancel()
 		}
+	}
 
-		// Add results if we found matches
-		if len(matches) > 0 {
-			result.FilesMatched++
-			result.TotalMatches += len(matches)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if walkErr != nil && !enough {
+		return walkErr
+	}
 
-			fileResult := GrepFileResult{
-				FilePath: path,
-				Matches:  matches,
-			}
-			result.Results = append(result.Results, fileResult)
+	sortGrepResults(result.Results, params.Sort)
 
-			// Check if we've hit the max results
-			if result.TotalMatches >= params.MaxResults {
-				result.Truncated = true
-				return filepath.SkipAll
-			}
+	// Keep max_results matches, in result order
+	kept := 0
+	for i := range result.Results {
+		matches := result.Results[i].Matches
+		if kept+len(matches) > params.MaxResults {
+			result.Results[i].Matches = matches[:params.MaxResults-kept]
+			result.Results = result.Results[:i+1]
+			result.Truncated = true
+		}
+		kept += len(result.Results[i].Matches)
+		if kept >= params.MaxResults {
+			result.Results = result.Results[:i+1]
+			break
 		}
+	}
+	result.TotalMatches = kept
+	result.FilesMatched = len(result.Results)
+	result.Truncated = result.Truncated || enough || tooManyFiles
+	return nil
+}
 
-		return nil
+// grepNameMatches checks a file name against the include and exclude patterns
+func grepNameMatches(params GrepInput, name string) bool {
+	if params.Include != "" {
+		if matched, err := filepath.Match(params.Include, name); err != nil || !matched {
+			return false
+		}
 	}
+	if params.Exclude != "" {
+		if matched, err := filepath.Match(params.Exclude, name); err != nil || matched {
+			return false
+		}
+	}
+	return true
+}
 
-	return filepath.Walk(rootPath, walkFn)
+// sortGrepResults orders file results by path, or by modification time
+// with the newest first
+func sortGrepResults(results []GrepFileResult, order string) {
+	sort.Slice(results, func(i, j int) bool {
+		if order == "modified" && !results[i].ModTime.Equal(results[j].ModTime) {
+			return results[i].ModTime.After(results[j].ModTime)
+		}
+		return results[i].FilePath < results[j].FilePath
+	})
 }
 
-// searchInFile searches for the pattern in a single file
-func (t *GrepTool) searchInFile(filePath string, pattern *regexp.Regexp, maxMatches int) ([]GrepMatch, error) {
-	file, err := os.Open(filePath)
+// searchInFile searches for the pattern in a single file. Binary files,
+// which have a NUL byte near the start, and very large files are skipped.
+func (t *GrepTool) searchInFile(ctx context.Context, filePath string, pattern *regexp.Regexp, params GrepInput) grepOutcome {
+	outcome := grepOutcome{path: filePath}
+	info, err := os.Stat(filePath)
 	if err != nil {
-		return nil, err
+		outcome.err = err
+		return outcome
+	}
+	outcome.modTime = info.ModTime()
+	if info.Size() > maxGrepFileBytes {
+		outcome.skipped = true
+		return outcome
 	}
-	defer file.Close()
-
-	matches := []GrepMatch{}
-	lineNum := 0
-	scanner := bufio.NewScanner(file)
 
-	// Handle binary files gracefully
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Text()
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		outcome.err = err
+		return outcome
+	}
+	if isBinaryData(content) {
+		outcome.skipped = true
+		return outcome
+	}
 
-		// Check if we have a match
-		if pattern.MatchString(line) {
-			matches = append(matches, GrepMatch{
-				LineNumber: lineNum,
-				LineText:   line,
-			})
+	lines := strings.Split(string(content), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
 
-			// Check if we've hit the max matches for this file
-			if len(matches) >= maxMatches {
-				break
+	var spans []lineSpan
+	if params.Multiline {
+		spans = multilineSpans(content, pattern, len(lines), params.MaxResults)
+	} else {
+		for i, line := range lines {
+			// Check for cancellation periodically on large files
+			if i%grepCancelCheckLines == 0 {
+				if err := ctx.Err(); err != nil {
+					outcome.err = err
+					return outcome
+				}
+			}
+			if pattern.MatchString(line) {
+				spans = append(spans, lineSpan{i, i})
+				if len(spans) >= params.MaxResults {
+					break
+				}
 			}
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		// If it's likely a binary file, just skip it
-		if err == io.ErrUnexpectedEOF || strings.Contains(err.Error(), "invalid UTF-8") {
-			return nil, nil
+	outcome.matches = grepMatches(lines, spans, params.BeforeContext, params.AfterContext)
+	return outcome
+}
+
+// lineSpan is the range of 0-based lines a match covers
+type lineSpan struct {
+	start, end int
+}
+
+// multilineSpans finds the matches of a pattern in the whole content and
+// returns the lines they cover, of which there are lineCount. Matches that
+// share a line are merged.
+func multilineSpans(content []byte, pattern *regexp.Regexp, lineCount, maxMatches int) []lineSpan {
+	var lineStarts []int
+	lineStarts = append(lineStarts, 0)
+	for i, b := range content {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineAt := func(offset int) int {
+		return sort.SearchInts(lineStarts, offset+1) - 1
+	}
+
+	var spans []lineSpan
+	for _, loc := range pattern.FindAllIndex(content, -1) {
+		span := lineSpan{lineAt(loc[0]), lineAt(loc[0])}
+		// An empty match after the final newline, such as $, is on no line
+		if span.start >= lineCount {
+			break
+		}
+		if loc[1] > loc[0] {
+			span.end = lineAt(loc[1] - 1)
 		}
-		return nil, err
+		if n := len(spans); n > 0 && span.start <= spans[n-1].end {
+			spans[n-1].end = max(spans[n-1].end, span.end)
+			continue
+		}
+		if len(spans) >= maxMatches {
+			break
+		}
+		spans = append(spans, span)
 	}
+	return spans
+}
+
+// grepMatches turns matched line spans into matches with up to before and
+// after lines of context. Context lines are not repeated: a match's context
+// stops at the neighbouring matches and their context.
+func grepMatches(lines []string, spans []lineSpan, before, after int) []GrepMatch {
+	matches := make([]GrepMatch, 0, len(spans))
+	shown := -1 // Last line already part of a match or its context
+	for i, span := range spans {
+		match := GrepMatch{
+			LineNumber: span.start + 1,
+			LineText:   strings.Join(lines[span.start:span.end+1], "\n"),
+		}
+		if span.end > span.start {
+			match.EndLineNumber = span.end + 1
+		}
 
-	return matches, nil
+		if from := max(span.start-before, shown+1, 0); from < span.start {
+			match.ContextBefore = append([]string(nil), lines[from:span.start]...)
+		}
+		to := min(span.end+after, len(lines)-1)
+		if i+1 < len(spans) {
+			to = min(to, spans[i+1].start-1)
+		}
+		if to > span.end {
+			match.ContextAfter = append([]string(nil), lines[span.end+1:to+1]...)
+		}
+		shown = max(span.end, to)
+
+		matches = append(matches, match)
+	}
+	return matches
 }
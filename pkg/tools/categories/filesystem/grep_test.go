@@ -1,10 +1,13 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,7 +32,7 @@ func TestGrepTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -49,7 +52,7 @@ func TestGrepTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -70,7 +73,7 @@ func TestGrepTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -95,7 +98,7 @@ func TestGrepTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -117,7 +120,7 @@ func TestGrepTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -136,7 +139,7 @@ func TestGrepTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -154,7 +157,7 @@ func TestGrepTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		assert.Error(t, err) // Should return error for invalid pattern
 
 		// Check the result
@@ -172,7 +175,7 @@ func TestGrepTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		assert.Error(t, err) // Should return error for invalid path
 
 		// Check the result
@@ -227,3 +230,149 @@ TEST in uppercase should only be found with case-insensitive search.
 	err = os.WriteFile(filepath.Join(dir, "test.txt"), []byte(textContent), 0644)
 	require.NoError(t, err)
 }
+
+// runGrep executes the grep tool and returns its result
+func runGrep(t *testing.T, input GrepInput) GrepResult {
+	t.Helper()
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	result, err := NewGrepTool().Execute(context.Background(), data)
+	require.NoError(t, err)
+	return result.(GrepResult)
+}
+
+// writeFiles creates files below dir from a map of slash-separated paths to contents
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+// grepPaths returns the paths of the files in a result, relative to dir
+func grepPaths(t *testing.T, dir string, result GrepResult) []string {
+	t.Helper()
+	var paths []string
+	for _, file := range result.Results {
+		rel, err := filepath.Rel(dir, file.FilePath)
+		require.NoError(t, err)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+func TestGrepIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".gitignore":               "*.gen.go\n/build/\n",
+		"main.go":                  "needle\n",
+		"main.gen.go":              "needle\n",
+		"build/out.txt":            "needle\n",
+		"pkg/build/keep.txt":       "needle\n",
+		"pkg/.ignore":              "skip.txt\n",
+		"pkg/skip.txt":             "needle\n",
+		"node_modules/lib/x.js":    "needle\n",
+		".git/config":              "needle\n",
+		"image.bin":                "needle\x00\x01",
+		"vendor/.gitignore":        "!*.gen.go\n",
+		"vendor/generated.gen.go":  "needle\n",
+		"vendor/deep/other.gen.go": "needle\n",
+	})
+
+	result := runGrep(t, GrepInput{Pattern: "needle", Path: dir})
+	assert.Equal(t, []string{
+		"main.go",
+		"pkg/build/keep.txt",
+		"vendor/deep/other.gen.go",
+		"vendor/generated.gen.go",
+	}, grepPaths(t, dir, result))
+	assert.Equal(t, 1, result.SkippedFiles)
+
+	result = runGrep(t, GrepInput{Pattern: "needle", Path: dir, NoIgnore: true})
+	assert.Equal(t, []string{
+		"build/out.txt",
+		"main.gen.go",
+		"main.go",
+		"node_modules/lib/x.js",
+		"pkg/build/keep.txt",
+		"pkg/skip.txt",
+		"vendor/deep/other.gen.go",
+		"vendor/generated.gen.go",
+	}, grepPaths(t, dir, result))
+}
+
+func TestGrepContext(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.txt": "one\ntwo\nmatch three\nfour\nmatch five\nsix\nseven\neight\nmatch nine\r\nten\n",
+	})
+
+	result := runGrep(t, GrepInput{Pattern: "match", Path: dir, Context: 1, AfterContext: 2})
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, []GrepMatch{
+		{LineNumber: 3, LineText: "match three", ContextBefore: []string{"two"}, ContextAfter: []string{"four"}},
+		{LineNumber: 5, LineText: "match five", ContextAfter: []string{"six", "seven"}},
+		{LineNumber: 9, LineText: "match nine", ContextBefore: []string{"eight"}, ContextAfter: []string{"ten"}},
+	}, result.Results[0].Matches)
+}
+
+func TestGrepMultiline(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go": "package a\n\nfunc A() {\n\treturn\n}\n\nfunc B() {}\n",
+	})
+
+	result := runGrep(t, GrepInput{Pattern: `func A\(\) \{\n\treturn`, Path: dir, Multiline: true})
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, []GrepMatch{
+		{LineNumber: 3, EndLineNumber: 4, LineText: "func A() {\n\treturn"},
+	}, result.Results[0].Matches)
+
+	result = runGrep(t, GrepInput{Pattern: `^func \w+`, Path: dir, Multiline: true})
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, 2, result.TotalMatches)
+	assert.Equal(t, 7, result.Results[0].Matches[1].LineNumber)
+
+	// The end of a file that ends with a newline is not another line
+	writeFiles(t, dir, map[string]string{"b.txt": "foo\nbar\n"})
+	result = runGrep(t, GrepInput{Pattern: `$`, Path: filepath.Join(dir, "b.txt"), Multiline: true})
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, []GrepMatch{{LineNumber: 1, LineText: "foo"}, {LineNumber: 2, LineText: "bar"}}, result.Results[0].Matches)
+}
+
+func TestGrepSortAndLimits(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{}
+	for i := 0; i < 40; i++ {
+		files[fmt.Sprintf("f%02d.txt", i)] = "hit\nhit\n"
+	}
+	writeFiles(t, dir, files)
+	now := time.Now()
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "f07.txt"), now, now.Add(time.Hour)))
+
+	// Results do not depend on which worker finishes first
+	result := runGrep(t, GrepInput{Pattern: "hit", Path: dir})
+	assert.Equal(t, 40, result.FilesMatched)
+	assert.Equal(t, 40, result.FilesSearched)
+	assert.Equal(t, 80, result.TotalMatches)
+	assert.Equal(t, "f00.txt", filepath.Base(result.Results[0].FilePath))
+	assert.Equal(t, "f39.txt", filepath.Base(result.Results[39].FilePath))
+	assert.False(t, result.Truncated)
+
+	result = runGrep(t, GrepInput{Pattern: "hit", Path: dir, Sort: "modified"})
+	assert.Equal(t, "f07.txt", filepath.Base(result.Results[0].FilePath))
+
+	result = runGrep(t, GrepInput{Pattern: "hit", Path: dir, MaxResults: 5})
+	assert.Equal(t, 5, result.TotalMatches)
+	assert.True(t, result.Truncated)
+	assert.Len(t, result.Results[len(result.Results)-1].Matches, 1)
+
+	result = runGrep(t, GrepInput{Pattern: "hit", Path: dir, MaxFiles: 10})
+	assert.Equal(t, 10, result.FilesSearched)
+	assert.True(t, result.Truncated)
+
+	_, err := NewGrepTool().Execute(context.Background(), json.RawMessage(`{"pattern": "hit", "sort": "size"}`))
+	assert.Error(t, err)
+}
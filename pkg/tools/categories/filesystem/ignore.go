@@ -0,0 +1,220 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreFileNames are the files whose patterns exclude paths from searches,
+// read in every directory as git and ripgrep do
+var ignoreFileNames = []string{".gitignore", ".ignore"}
+
+// defaultIgnorePatterns exclude paths that are never worth searching even
+// without an ignore file. An ignore file can include them again with "!".
+var defaultIgnorePatterns = []string{"node_modules/"}
+
+// vcsDirs are version control directories, which are always skipped
+var vcsDirs = map[string]bool{".git": true, ".hg": true, ".svn": true}
+
+// ignoreRule is one pattern line of an ignore file
+type ignoreRule struct {
+	regex   *regexp.Regexp // Matches paths relative to the ignore file's directory
+	negate  bool           // "!" pattern: the path is included again
+	dirOnly bool           // Pattern ending in "/": only directories match
+}
+
+// ignoreRules holds the rules of the ignore files of one directory. Rules of
+// deeper directories come first and take precedence over their parents.
+type ignoreRules struct {
+	parent *ignoreRules
+	base   string // Slash-separated absolute directory the patterns are relative to
+	rules  []ignoreRule
+}
+
+// ignored reports whether a path is excluded by the rules. Within a
+// directory the last matching pattern wins.
+func (r *ignoreRules) ignored(path string, isDir bool) bool {
+	path = filepath.ToSlash(path)
+	for m := r; m != nil; m = m.parent {
+		rel := path
+		if m.base != "" {
+			if !strings.HasPrefix(path, m.base+"/") {
+				continue
+			}
+			rel = path[len(m.base)+1:]
+		}
+		for i := len(m.rules) - 1; i >= 0; i-- {
+			rule := m.rules[i]
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if rule.regex.MatchString(rel) {
+				return !rule.negate
+			}
+		}
+	}
+	return false
+}
+
+// withDir returns the rules that apply inside dir: those of its ignore
+// files on top of the parent's
+func (r *ignoreRules) withDir(dir string) *ignoreRules {
+	var rules []ignoreRule
+	for _, name := range ignoreFileNames {
+		rules = append(rules, readIgnoreFile(filepath.Join(dir, name))...)
+	}
+	if len(rules) == 0 {
+		return r
+	}
+	return &ignoreRules{parent: r, base: filepath.ToSlash(dir), rules: rules}
+}
+
+// rootIgnoreRules returns the rules that apply to a search starting at
+// root: the default patterns, and when root is inside a git repository the
+// ignore files of its parent directories up to the repository top and the
+// repository's info/exclude file. The ignore files of root itself are not
+// included.
+func rootIgnoreRules(root string) *ignoreRules {
+	rules := &ignoreRules{}
+	for _, pattern := range defaultIgnorePatterns {
+		if rule, ok := parseIgnorePattern(pattern); ok {
+			rules.rules = append(rules.rules, rule)
+		}
+	}
+
+	// Find the repository top, collecting the directories above root
+	var parents []string
+	top := ""
+	for dir := root; ; {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			top = dir
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+		parents = append(parents, dir)
+	}
+	if top == "" {
+		return rules
+	}
+
+	if exclude := readIgnoreFile(filepath.Join(top, ".git", "info", "exclude")); len(exclude) > 0 {
+		rules = &ignoreRules{parent: rules, base: filepath.ToSlash(top), rules: exclude}
+	}
+	for i := len(parents) - 1; i >= 0; i-- {
+		rules = rules.withDir(parents[i])
+	}
+	return rules
+}
+
+// readIgnoreFile parses an ignore file, which need not exist
+func readIgnoreFile(path string) []ignoreRule {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var rules []ignoreRule
+	for _, line := range strings.Split(string(content), "\n") {
+		if rule, ok := parseIgnorePattern(line); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnorePattern compiles one line of an ignore file with gitignore
+// semantics. Blank lines and comments give no rule.
+func parseIgnorePattern(line string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are dropped unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	regex, err := compileGlob(line)
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.regex = regex
+	return rule, true
+}
+
+// compileGlob compiles a glob matched against slash-separated relative
+// paths. As in ignore files, a glob with a slash other than at the end is
+// relative to the top directory, and one without matches names at any depth.
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	if strings.Contains(glob, "/") {
+		glob = strings.TrimPrefix(glob, "/")
+	} else {
+		glob = "**/" + glob
+	}
+	return regexp.Compile("^" + globRegexp(glob) + "$")
+}
+
+// globRegexp translates a slash-separated glob into a regular expression.
+// "*" and "?" do not match "/", "**" matches across directories, and
+// character classes such as "[a-z]" and "[!0-9]" are supported.
+func globRegexp(glob string) string {
+	var re strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			// Zero or more leading directories
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			// Everything inside a directory
+			re.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				re.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			re.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return re.String()
+}
@@ -0,0 +1,66 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnorePatterns(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "logs/debug.log", false, true},
+		{"*.log", "debug.txt", false, false},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"build/", "src/build", true, true},
+		{"build/", "src/build", false, false},
+		{"docs/*.md", "docs/a.md", false, true},
+		{"docs/*.md", "docs/sub/a.md", false, false},
+		{"docs/**/*.md", "docs/sub/deep/a.md", false, true},
+		{"docs/**/*.md", "docs/a.md", false, true},
+		{"**/cache", "a/b/cache", true, true},
+		{"out/**", "out/x/y", false, true},
+		{"file?.txt", "file1.txt", false, true},
+		{"file[0-9].txt", "filex.txt", false, false},
+		{"file[!0-9].txt", "filex.txt", false, true},
+		{`\#hash`, "#hash", false, true},
+		{"# comment", "# comment", false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.path, func(t *testing.T) {
+			rules := &ignoreRules{base: "/repo"}
+			if rule, ok := parseIgnorePattern(tc.pattern); ok {
+				rules.rules = append(rules.rules, rule)
+			}
+			assert.Equal(t, tc.ignored, rules.ignored("/repo/"+tc.path, tc.isDir))
+		})
+	}
+}
+
+func TestIgnoreRulesPrecedence(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git", "info"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub", "deeper"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitignore"), []byte("*.tmp\n!keep.tmp\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "info", "exclude"), []byte("secret\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "sub", ".ignore"), []byte("!local.tmp\n"), 0644))
+
+	// Starting below the repository top still applies the parent rules
+	rules := rootIgnoreRules(filepath.Join(root, "sub")).withDir(filepath.Join(root, "sub"))
+	assert.True(t, rules.ignored(filepath.Join(root, "sub", "a.tmp"), false))
+	assert.False(t, rules.ignored(filepath.Join(root, "sub", "keep.tmp"), false))
+	assert.False(t, rules.ignored(filepath.Join(root, "sub", "local.tmp"), false))
+	assert.True(t, rules.ignored(filepath.Join(root, "sub", "secret"), false))
+	assert.True(t, rules.ignored(filepath.Join(root, "sub", "node_modules"), true))
+	assert.False(t, rules.ignored(filepath.Join(root, "sub", "main.go"), false))
+}
@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -54,7 +55,14 @@ func NewMkdirTool() *MkdirTool {
 }
 
 // Execute implements the Tool interface
-func (t *MkdirTool) Execute(input json.RawMessage) (interface{}, error) {
+func (t *MkdirTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return MkdirOutput{
+			Created: false,
+			Error:   err.Error(),
+		}, err
+	}
+
 	var params MkdirInput
 	if err := json.Unmarshal(input, &params); err != nil {
 		return MkdirOutput{
@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -26,7 +27,7 @@ func TestMkdirTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -51,7 +52,7 @@ func TestMkdirTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err) // Should not return an error, just indicate it wasn't created
 
 		// Check the result
@@ -71,7 +72,7 @@ func TestMkdirTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		require.NoError(t, err)
 
 		// Check the result
@@ -94,7 +95,7 @@ func TestMkdirTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		assert.Error(t, err) // Should return error when parent doesn't exist
 
 		// Check the result indicates failure
@@ -112,7 +113,7 @@ func TestMkdirTool(t *testing.T) {
 		})
 		require.NoError(t, err)
 
-		result, err := tool.Execute(input)
+		result, err := tool.Execute(context.Background(), input)
 		assert.Error(t, err)
 
 		// Check the result
@@ -124,7 +125,7 @@ func TestMkdirTool(t *testing.T) {
 
 	t.Run("InvalidInput", func(t *testing.T) {
 		// Test error on invalid JSON input
-		result, err := tool.Execute([]byte(`{invalid json`))
+		result, err := tool.Execute(context.Background(), []byte(`{invalid json`))
 		assert.Error(t, err)
 
 		// Check the result
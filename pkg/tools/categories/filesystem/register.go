@@ -31,6 +31,11 @@ func Register(registry core.ToolRegistrar) error {
 		return err
 	}
 
+	// Register file_restore tool
+	if err := registry.RegisterTool("filesystem", NewFileRestoreTool()); err != nil {
+		return err
+	}
+
 	// Register file_rename tool
 	if err := registry.RegisterTool("filesystem", NewFileRenameTool()); err != nil {
 		return err
@@ -0,0 +1,95 @@
+package filesystem
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// walkOptions controls which entries walkTree visits
+type walkOptions struct {
+	NoIgnore bool // Visit entries that ignore files and the default patterns exclude
+	MaxDepth int  // Deepest level visited, the root's entries being level 1; 0 for no limit
+}
+
+// walkFunc is called for each entry walkTree visits, with its depth below
+// the root. Returning filepath.SkipDir skips a directory's contents, or the
+// rest of the directory for a file; filepath.SkipAll ends the walk.
+type walkFunc func(path string, entry fs.DirEntry, depth int) error
+
+// walkTree visits the entries below root in lexical order, skipping version
+// control directories and, unless opts.NoIgnore is set, paths excluded by
+// .gitignore and .ignore files. Directories that cannot be read are
+// skipped. A root that is a file is visited itself, at depth 0.
+func walkTree(ctx context.Context, root string, opts walkOptions, fn walkFunc) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(absRoot)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		err := fn(root, fs.FileInfoToDirEntry(info), 0)
+		if err == filepath.SkipDir || err == filepath.SkipAll {
+			return nil
+		}
+		return err
+	}
+
+	var rules *ignoreRules
+	if !opts.NoIgnore {
+		rules = rootIgnoreRules(absRoot)
+	}
+
+	// Paths passed to fn keep the form of root, while ignore rules match
+	// absolute paths
+	var walk func(dir, absDir string, depth int, rules *ignoreRules) error
+	walk = func(dir, absDir string, depth int, rules *ignoreRules) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !opts.NoIgnore {
+			rules = rules.withDir(absDir)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil // Skip directories we cannot read
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			isDir := entry.IsDir()
+			if isDir && vcsDirs[entry.Name()] {
+				continue
+			}
+			absPath := filepath.Join(absDir, entry.Name())
+			if rules != nil && rules.ignored(absPath, isDir) {
+				continue
+			}
+
+			if err := fn(path, entry, depth+1); err == filepath.SkipDir {
+				if isDir {
+					continue
+				}
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if isDir && (opts.MaxDepth <= 0 || depth+1 < opts.MaxDepth) {
+				if err := walk(path, absPath, depth+1, rules); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := walk(root, absRoot, 0, rules); err != filepath.SkipAll {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// defaultBlameLines is the number of lines git_blame covers when no end line is given
+const defaultBlameLines = 100
+
+// maxBlameLines is the largest number of lines git_blame covers
+const maxBlameLines = 500
+
+// uncommittedHash is the commit git blame reports for lines not yet committed
+const uncommittedHash = "0000000000000000000000000000000000000000"
+
+// BlameTool shows the commit that last changed each line of a file
+type BlameTool struct {
+	core.BaseToolImpl
+	gitRunner
+}
+
+// BlameInput represents parameters for the git_blame tool
+type BlameInput struct {
+	RepoPath  string `json:"repo_path,omitempty"`
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line,omitempty"` // First line, from 1 (default 1)
+	EndLine   int    `json:"end_line,omitempty"`   // Last line, inclusive
+	Revision  string `json:"revision,omitempty"`   // Blame the file as of this revision (default working tree)
+}
+
+// BlameLine describes the last change to a line
+type BlameLine struct {
+	Line        int    `json:"line"`
+	Commit      string `json:"commit,omitempty"` // Short hash; empty for uncommitted lines
+	Uncommitted bool   `json:"uncommitted,omitempty"`
+	Author      string `json:"author,omitempty"`
+	Date        string `json:"date,omitempty"` // Author date, RFC 3339
+	Summary     string `json:"summary,omitempty"`
+	Content     string `json:"content"`
+}
+
+// BlameResult lists the lines of a file range with their last change
+type BlameResult struct {
+	Path  string      `json:"path"`
+	Lines []BlameLine `json:"lines"`
+}
+
+// blameCommit is the commit information git blame --porcelain gives once per commit
+type blameCommit struct {
+	author  string
+	time    int64
+	tz      string
+	summary string
+}
+
+// NewBlameTool creates a new git_blame tool
+func NewBlameTool() *BlameTool {
+	tool := &BlameTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"git_blame",
+		"Show which commit, author and date last changed each line in a range of a file. "+
+			"Use git_show on a commit to see why it was changed.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"repo_path": repoPathSchema,
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "File to blame, relative to repo_path",
+				},
+				"start_line": map[string]interface{}{
+					"type":        "integer",
+					"description": "First line, counting from 1 (default 1)",
+					"minimum":     1,
+				},
+				"end_line": map[string]interface{}{
+					"type": "integer",
+					"description": fmt.Sprintf("Last line, inclusive (default start_line + %d, at most %d lines)",
+						defaultBlameLines-1, maxBlameLines),
+					"minimum": 1,
+				},
+				"revision": map[string]interface{}{
+					"type":        "string",
+					"description": "Blame the file as of this revision (default is the working tree)",
+				},
+			},
+			"required": []string{"path"},
+		},
+	)
+	return tool
+}
+
+// Execute blames the requested lines
+func (t *BlameTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params BlameInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for git_blame tool: %w", err)
+	}
+	if params.Path == "" {
+		return nil, fmt.Errorf("path parameter is required")
+	}
+
+	start := params.StartLine
+	if start <= 0 {
+		start = 1
+	}
+	end := params.EndLine
+	if end <= 0 {
+		end = start + defaultBlameLines - 1
+	}
+	if end < start {
+		return nil, fmt.Errorf("end_line %d is before start_line %d", end, start)
+	}
+	if end-start+1 > maxBlameLines {
+		end = start + maxBlameLines - 1
+	}
+
+	args := []string{"blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", start, end)}
+	if params.Revision != "" {
+		if err := checkRevision("revision", params.Revision); err != nil {
+			return nil, err
+		}
+		args = append(args, params.Revision)
+	}
+	args = append(args, "--", params.Path)
+
+	out, err := t.run(ctx, params.RepoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := parseBlame(out)
+	if err != nil {
+		return nil, err
+	}
+	return BlameResult{Path: params.Path, Lines: lines}, nil
+}
+
+// parseBlame parses the output of git blame --porcelain
+func parseBlame(out string) ([]BlameLine, error) {
+	lines := []BlameLine{}
+	commits := make(map[string]*blameCommit)
+
+	var hash string
+	var lineNumber int
+	for _, text := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			// The content of the line ends each entry
+			commit := commits[hash]
+			line := BlameLine{Line: lineNumber, Content: text[1:]}
+			if hash == uncommittedHash {
+				line.Uncommitted = true
+			} else if commit != nil {
+				line.Commit = hash[:12]
+				line.Author = commit.author
+				line.Date = formatBlameTime(commit.time, commit.tz)
+				line.Summary = commit.summary
+			}
+			lines = append(lines, line)
+			hash = ""
+
+		case hash == "":
+			// "<hash> <original line> <final line> [<lines in group>]"
+			fields := strings.Fields(text)
+			if len(fields) == 0 {
+				continue
+			}
+			if len(fields) < 3 || len(fields[0]) < 12 {
+				return nil, fmt.Errorf("unexpected git blame line %q", text)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected git blame line %q", text)
+			}
+			hash, lineNumber = fields[0], n
+			if commits[hash] == nil {
+				commits[hash] = &blameCommit{}
+			}
+
+		default:
+			key, value, _ := strings.Cut(text, " ")
+			commit := commits[hash]
+			switch key {
+			case "author":
+				commit.author = value
+			case "author-time":
+				commit.time, _ = strconv.ParseInt(value, 10, 64)
+			case "author-tz":
+				commit.tz = value
+			case "summary":
+				commit.summary = value
+			}
+		}
+	}
+	return lines, nil
+}
+
+// formatBlameTime formats a Unix time in a git time zone such as "+0530" as RFC 3339
+func formatBlameTime(unix int64, tz string) string {
+	location := time.UTC
+	if len(tz) == 5 {
+		hours, errH := strconv.Atoi(tz[1:3])
+		minutes, errM := strconv.Atoi(tz[3:5])
+		if errH == nil && errM == nil {
+			offset := hours*3600 + minutes*60
+			if tz[0] == '-' {
+				offset = -offset
+			}
+			location = time.FixedZone(tz, offset)
+		}
+	}
+	return time.Unix(unix, 0).In(location).Format(time.RFC3339)
+}
@@ -0,0 +1,47 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlameTool(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("Add lines", map[string]string{"f.txt": "one\ntwo\nthree\nfour\n"})
+	t.Setenv("GIT_AUTHOR_NAME", "Grace Hopper")
+	repo.commit("Change two", map[string]string{"f.txt": "one\nTWO\nthree\nfour\n"})
+	repo.write("f.txt", "one\nTWO\nthree\nFOUR\n")
+
+	tool := NewBlameTool()
+
+	blame := runTool(t, tool, BlameInput{RepoPath: repo.dir, Path: "f.txt", StartLine: 2, EndLine: 4}).(BlameResult)
+	require.Len(t, blame.Lines, 3)
+
+	assert.Equal(t, 2, blame.Lines[0].Line)
+	assert.Equal(t, "TWO", blame.Lines[0].Content)
+	assert.Equal(t, "Grace Hopper", blame.Lines[0].Author)
+	assert.Equal(t, "Change two", blame.Lines[0].Summary)
+	assert.Len(t, blame.Lines[0].Commit, 12)
+	assert.NotEmpty(t, blame.Lines[0].Date)
+
+	assert.Equal(t, "Ada Lovelace", blame.Lines[1].Author)
+	assert.Equal(t, "Add lines", blame.Lines[1].Summary)
+
+	assert.True(t, blame.Lines[2].Uncommitted)
+	assert.Empty(t, blame.Lines[2].Commit)
+	assert.Equal(t, "FOUR", blame.Lines[2].Content)
+
+	// At a revision, and with the default range running past the end of the file
+	blame = runTool(t, tool, BlameInput{RepoPath: repo.dir, Path: "f.txt", Revision: "HEAD~1"}).(BlameResult)
+	require.Len(t, blame.Lines, 4)
+	assert.Equal(t, "two", blame.Lines[1].Content)
+	assert.Equal(t, "Add lines", blame.Lines[3].Summary)
+}
+
+func TestFormatBlameTime(t *testing.T) {
+	assert.Equal(t, "1970-01-01T05:30:00+05:30", formatBlameTime(0, "+0530"))
+	assert.Equal(t, "1969-12-31T19:00:00-05:00", formatBlameTime(0, "-0500"))
+	assert.Equal(t, "1970-01-01T00:00:00Z", formatBlameTime(0, "bogus"))
+}
@@ -0,0 +1,141 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// branchFormat is a for-each-ref format with the fields of Branch separated by unit separators
+const branchFormat = "%(refname)%1f%(refname:short)%1f%(objectname:short)%1f%(upstream:short)%1f" +
+	"%(upstream:track,nobracket)%1f%(HEAD)%1f%(committerdate:iso-strict)%1f%(contents:subject)"
+
+// BranchesTool lists the branches of a repository
+type BranchesTool struct {
+	core.BaseToolImpl
+	gitRunner
+}
+
+// BranchesInput represents parameters for the git_branches tool
+type BranchesInput struct {
+	RepoPath      string `json:"repo_path,omitempty"`
+	IncludeRemote bool   `json:"include_remote,omitempty"`
+}
+
+// Branch describes a local or remote-tracking branch
+type Branch struct {
+	Name         string `json:"name"`
+	Remote       bool   `json:"remote,omitempty"`
+	Current      bool   `json:"current,omitempty"`
+	Commit       string `json:"commit"`
+	Upstream     string `json:"upstream,omitempty"`
+	Ahead        int    `json:"ahead,omitempty"`
+	Behind       int    `json:"behind,omitempty"`
+	UpstreamGone bool   `json:"upstream_gone,omitempty"` // The upstream branch was deleted
+	Date         string `json:"date"`                    // Committer date of the last commit, RFC 3339
+	Subject      string `json:"subject"`                 // Subject of the last commit
+}
+
+// BranchesResult lists branches, most recently committed to first
+type BranchesResult struct {
+	Current  string   `json:"current,omitempty"` // Empty when HEAD is detached
+	Branches []Branch `json:"branches"`
+}
+
+// NewBranchesTool creates a new git_branches tool
+func NewBranchesTool() *BranchesTool {
+	tool := &BranchesTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"git_branches",
+		"List the branches of a git repository, most recently committed to first, with their last commit "+
+			"and how far they are ahead of or behind their upstream.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"repo_path": repoPathSchema,
+				"include_remote": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also list remote-tracking branches, such as origin/main",
+				},
+			},
+		},
+	)
+	return tool
+}
+
+// Execute lists the branches
+func (t *BranchesTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params BranchesInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for git_branches tool: %w", err)
+	}
+
+	args := []string{"for-each-ref", "--sort=-committerdate", "--format=" + branchFormat, "refs/heads"}
+	if params.IncludeRemote {
+		args = append(args, "refs/remotes")
+	}
+
+	out, err := t.run(ctx, params.RepoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := BranchesResult{Branches: []Branch{}}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 8 {
+			continue
+		}
+		refname := fields[0]
+		// Skip symbolic refs such as origin/HEAD
+		if strings.HasPrefix(refname, "refs/remotes/") && strings.HasSuffix(refname, "/HEAD") {
+			continue
+		}
+
+		branch := Branch{
+			Name:     fields[1],
+			Remote:   strings.HasPrefix(refname, "refs/remotes/"),
+			Current:  fields[5] == "*",
+			Commit:   fields[2],
+			Upstream: fields[3],
+			Date:     fields[6],
+			Subject:  fields[7],
+		}
+		parseTrack(&branch, fields[4])
+		if branch.Current {
+			result.Current = branch.Name
+		}
+		result.Branches = append(result.Branches, branch)
+	}
+	return result, nil
+}
+
+// parseTrack reads an upstream tracking state such as "ahead 1, behind 2" or "gone"
+func parseTrack(branch *Branch, track string) {
+	if track == "gone" {
+		branch.UpstreamGone = true
+		return
+	}
+	for _, part := range strings.Split(track, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), " ")
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "ahead":
+			branch.Ahead = n
+		case "behind":
+			branch.Behind = n
+		}
+	}
+}
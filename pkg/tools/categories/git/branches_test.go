@@ -0,0 +1,56 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchesTool(t *testing.T) {
+	upstream := newTestRepo(t)
+	upstream.commit("first", map[string]string{"a.txt": "a\n"})
+
+	repo := &testRepo{t: t, dir: t.TempDir()}
+	repo.git("clone", "-q", upstream.dir, ".")
+	repo.commit("local work", map[string]string{"b.txt": "b\n"})
+	repo.git("branch", "feature", "HEAD~1")
+
+	tool := NewBranchesTool()
+
+	result := runTool(t, tool, BranchesInput{RepoPath: repo.dir}).(BranchesResult)
+	assert.Equal(t, "main", result.Current)
+	require.Len(t, result.Branches, 2)
+
+	byName := make(map[string]Branch)
+	for _, branch := range result.Branches {
+		byName[branch.Name] = branch
+	}
+	mainBranch := byName["main"]
+	assert.True(t, mainBranch.Current)
+	assert.Equal(t, "origin/main", mainBranch.Upstream)
+	assert.Equal(t, 1, mainBranch.Ahead)
+	assert.Equal(t, "local work", mainBranch.Subject)
+	assert.False(t, byName["feature"].Current)
+	assert.Equal(t, "first", byName["feature"].Subject)
+
+	result = runTool(t, tool, BranchesInput{RepoPath: repo.dir, IncludeRemote: true}).(BranchesResult)
+	var remotes []string
+	for _, branch := range result.Branches {
+		if branch.Remote {
+			remotes = append(remotes, branch.Name)
+		}
+	}
+	assert.Equal(t, []string{"origin/main"}, remotes)
+}
+
+func TestParseTrack(t *testing.T) {
+	var branch Branch
+	parseTrack(&branch, "ahead 2, behind 5")
+	assert.Equal(t, 2, branch.Ahead)
+	assert.Equal(t, 5, branch.Behind)
+
+	branch = Branch{}
+	parseTrack(&branch, "gone")
+	assert.True(t, branch.UpstreamGone)
+}
@@ -0,0 +1,187 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// StageTool adds changes to the index, or removes them from it. Unlike the
+// other git tools it modifies the repository, so it needs a higher
+// permission level than its category.
+type StageTool struct {
+	core.BaseToolImpl
+	gitRunner
+}
+
+// CommitTool records the staged changes in a new commit. It modifies the
+// repository, so it needs a higher permission level than its category.
+type CommitTool struct {
+	core.BaseToolImpl
+	gitRunner
+}
+
+// StageInput represents parameters for the git_stage tool
+type StageInput struct {
+	RepoPath string   `json:"repo_path,omitempty"`
+	Paths    []string `json:"paths,omitempty"`
+	All      bool     `json:"all,omitempty"`     // Every change, including untracked and deleted files
+	Unstage  bool     `json:"unstage,omitempty"` // Remove the changes from the index instead
+}
+
+// CommitInput represents parameters for the git_commit tool
+type CommitInput struct {
+	RepoPath string `json:"repo_path,omitempty"`
+	Message  string `json:"message"`
+	Amend    bool   `json:"amend,omitempty"`
+}
+
+// CommitResult describes the commit that was created
+type CommitResult struct {
+	Commit
+	Branch    string     `json:"branch,omitempty"`
+	Files     []DiffFile `json:"files"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+}
+
+// NewStageTool creates a new git_stage tool
+func NewStageTool() *StageTool {
+	tool := &StageTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"git_stage",
+		"Stage files for the next commit, or unstage them, and return the resulting repository status.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"repo_path": repoPathSchema,
+				"paths": map[string]interface{}{
+					"type":        "array",
+					"description": "Files or directories to stage or unstage",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"all": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Stage or unstage every change, including new and deleted files, instead of paths",
+				},
+				"unstage": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Remove the changes from the index, keeping them in the working tree",
+				},
+			},
+		},
+	)
+	return tool
+}
+
+// NewCommitTool creates a new git_commit tool
+func NewCommitTool() *CommitTool {
+	tool := &CommitTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"git_commit",
+		"Commit the staged changes with a message. Stage changes with git_stage first. Commit hooks run as usual.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"repo_path": repoPathSchema,
+				"message": map[string]interface{}{
+					"type":        "string",
+					"description": "The commit message: a short subject line, optionally followed by a blank line and a body",
+				},
+				"amend": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Replace the last commit instead of creating a new one. Do not amend commits that were pushed.",
+				},
+			},
+			"required": []string{"message"},
+		},
+	)
+	return tool
+}
+
+// Permission returns the permission level of the tool, which modifies the repository
+func (t *StageTool) Permission() core.PermissionLevel {
+	return core.PermissionReadWrite
+}
+
+// Permission returns the permission level of the tool, which modifies the repository
+func (t *CommitTool) Permission() core.PermissionLevel {
+	return core.PermissionReadWrite
+}
+
+// Execute stages or unstages the changes
+func (t *StageTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params StageInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for git_stage tool: %w", err)
+	}
+	if params.All == (len(params.Paths) > 0) {
+		return nil, fmt.Errorf("set either paths or all")
+	}
+
+	var args []string
+	switch {
+	case params.Unstage && params.All:
+		args = []string{"reset", "-q"}
+	case params.Unstage:
+		args = append([]string{"reset", "-q", "--"}, params.Paths...)
+	case params.All:
+		args = []string{"add", "--all"}
+	default:
+		args = append([]string{"add", "--"}, params.Paths...)
+	}
+
+	if _, err := t.run(ctx, params.RepoPath, args...); err != nil {
+		return nil, err
+	}
+	return t.status(ctx, params.RepoPath)
+}
+
+// Execute commits the staged changes
+func (t *CommitTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params CommitInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for git_commit tool: %w", err)
+	}
+	if strings.TrimSpace(params.Message) == "" {
+		return nil, fmt.Errorf("message parameter is required")
+	}
+
+	args := []string{"commit", "--quiet", "--file=-"}
+	if params.Amend {
+		args = append(args, "--amend")
+	}
+	if _, err := t.runInput(ctx, params.RepoPath, params.Message, args...); err != nil {
+		return nil, err
+	}
+
+	commit, err := t.showCommit(ctx, params.RepoPath, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	branch, err := t.run(ctx, params.RepoPath, "branch", "--show-current")
+	if err != nil {
+		return nil, err
+	}
+
+	return CommitResult{
+		Commit:    commit.Commit,
+		Branch:    strings.TrimSpace(branch),
+		Files:     commit.Files,
+		Additions: commit.Additions,
+		Deletions: commit.Deletions,
+	}, nil
+}
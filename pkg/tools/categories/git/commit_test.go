@@ -0,0 +1,62 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageAndCommit(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.write("a.txt", "a\n")
+	repo.write("b.txt", "b\n")
+
+	stage := NewStageTool()
+	commit := NewCommitTool()
+
+	// Stage and unstage work before the first commit
+	status := runTool(t, stage, StageInput{RepoPath: repo.dir, Paths: []string{"a.txt"}}).(*StatusResult)
+	assert.Equal(t, []FileChange{{Path: "a.txt", Status: "added"}}, status.Staged)
+	assert.Equal(t, []string{"b.txt"}, status.Untracked)
+
+	status = runTool(t, stage, StageInput{RepoPath: repo.dir, All: true, Unstage: true}).(*StatusResult)
+	assert.Empty(t, status.Staged)
+
+	status = runTool(t, stage, StageInput{RepoPath: repo.dir, All: true}).(*StatusResult)
+	assert.Len(t, status.Staged, 2)
+
+	result := runTool(t, commit, CommitInput{RepoPath: repo.dir, Message: "Add files\n\nTwo of them."}).(CommitResult)
+	assert.Equal(t, "Add files", result.Subject)
+	assert.Equal(t, "Two of them.", result.Body)
+	assert.Equal(t, "main", result.Branch)
+	assert.Equal(t, "Ada Lovelace", result.Author)
+	assert.Len(t, result.Files, 2)
+	assert.Equal(t, 2, result.Additions)
+
+	// Nothing staged: git's message is returned as the error
+	_, err := commit.Execute(context.Background(), json.RawMessage(`{"repo_path": "`+repo.dir+`", "message": "empty"}`))
+	assert.ErrorContains(t, err, "nothing to commit")
+
+	// Amend replaces the last commit
+	repo.write("a.txt", "changed\n")
+	runTool(t, stage, StageInput{RepoPath: repo.dir, Paths: []string{"a.txt"}})
+	amended := runTool(t, commit, CommitInput{RepoPath: repo.dir, Message: "Add files", Amend: true}).(CommitResult)
+	assert.Empty(t, amended.Parents)
+	assert.NotEqual(t, result.Hash, amended.Hash)
+}
+
+func TestStageValidatesInput(t *testing.T) {
+	stage := NewStageTool()
+
+	_, err := stage.Execute(context.Background(), json.RawMessage(`{}`))
+	assert.ErrorContains(t, err, "either paths or all")
+
+	_, err = stage.Execute(context.Background(), json.RawMessage(`{"all": true, "paths": ["x"]}`))
+	assert.ErrorContains(t, err, "either paths or all")
+
+	_, err = NewCommitTool().Execute(context.Background(), json.RawMessage(`{"message": "  "}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,231 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// DiffTool shows changes in a repository as per-file statistics and a patch
+type DiffTool struct {
+	core.BaseToolImpl
+	gitRunner
+}
+
+// DiffInput represents parameters for the git_diff tool
+type DiffInput struct {
+	RepoPath     string   `json:"repo_path,omitempty"`
+	Staged       bool     `json:"staged,omitempty"`        // Compare the index with HEAD, or with Base
+	Base         string   `json:"base,omitempty"`          // Revision to compare from
+	Target       string   `json:"target,omitempty"`        // Revision to compare to; requires Base
+	Paths        []string `json:"paths,omitempty"`         // Limit the diff to these paths
+	ContextLines *int     `json:"context_lines,omitempty"` // Lines of context around changes (default 3)
+	MaxBytes     int      `json:"max_bytes,omitempty"`
+}
+
+// DiffFile summarizes the changes to one file
+type DiffFile struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"`
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Binary    bool   `json:"binary,omitempty"`
+}
+
+// DiffResult represents the changes between two states of a repository
+type DiffResult struct {
+	Files     []DiffFile `json:"files"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+	Patch     string     `json:"patch"`
+	Truncated bool       `json:"truncated,omitempty"` // The patch was cut at max_bytes
+}
+
+// NewDiffTool creates a new git_diff tool
+func NewDiffTool() *DiffTool {
+	tool := &DiffTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"git_diff",
+		"Show changes in a git repository: per-file line counts and the patch. By default shows unstaged "+
+			"changes; set staged for changes staged for commit, or base and target to compare revisions. "+
+			"Untracked files are not included; git_status lists them.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"repo_path": repoPathSchema,
+				"staged": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Show staged changes: the index compared with HEAD, or with base if set",
+				},
+				"base": map[string]interface{}{
+					"type":        "string",
+					"description": "Revision to compare from, e.g. 'main' or 'HEAD~3'. Without target, compares it with the working tree. 'main...HEAD' compares HEAD with where it branched from main.",
+				},
+				"target": map[string]interface{}{
+					"type":        "string",
+					"description": "Revision to compare to; requires base",
+				},
+				"paths": map[string]interface{}{
+					"type":        "array",
+					"description": "Limit the diff to these files or directories",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"context_lines": map[string]interface{}{
+					"type":        "integer",
+					"description": "Lines of context around each change (default 3)",
+					"minimum":     0,
+				},
+				"max_bytes": maxPatchBytesSchema,
+			},
+		},
+	)
+	return tool
+}
+
+// Execute shows the requested changes
+func (t *DiffTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params DiffInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for git_diff tool: %w", err)
+	}
+
+	revisions, err := diffRevisions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	contextLines := 3
+	if params.ContextLines != nil && *params.ContextLines >= 0 {
+		contextLines = *params.ContextLines
+	}
+
+	result, err := t.diff(ctx, params.RepoPath, append([]string{"diff"}, revisions...), params.Paths, contextLines)
+	if err != nil {
+		return nil, err
+	}
+	result.Patch, result.Truncated = truncatePatch(result.Patch, patchLimit(params.MaxBytes))
+	return result, nil
+}
+
+// diffRevisions returns the git diff arguments selecting what to compare
+func diffRevisions(params DiffInput) ([]string, error) {
+	if params.Target != "" && params.Base == "" {
+		return nil, fmt.Errorf("target requires base")
+	}
+	if params.Staged && params.Target != "" {
+		return nil, fmt.Errorf("staged compares the index with base; it cannot be combined with target")
+	}
+
+	var args []string
+	if params.Staged {
+		args = append(args, "--cached")
+	}
+	for _, revision := range []struct{ name, value string }{{"base", params.Base}, {"target", params.Target}} {
+		if revision.value == "" {
+			continue
+		}
+		if err := checkRevision(revision.name, revision.value); err != nil {
+			return nil, err
+		}
+		args = append(args, revision.value)
+	}
+	return args, nil
+}
+
+// diff runs a git diff command, such as "diff --cached" or "diff-tree
+// --root <commit>", collecting per-file statistics and the patch. The
+// command's first word is the git subcommand; the rest select what to compare.
+func (g *gitRunner) diff(ctx context.Context, dir string, command, paths []string, contextLines int) (*DiffResult, error) {
+	diffArgs := func(format ...string) []string {
+		args := []string{command[0], "--no-color", "--no-ext-diff", "-M"}
+		args = append(args, format...)
+		args = append(args, command[1:]...)
+		return append(append(args, "--"), paths...)
+	}
+
+	nameStatus, err := g.run(ctx, dir, diffArgs("--name-status", "-z")...)
+	if err != nil {
+		return nil, err
+	}
+	numstat, err := g.run(ctx, dir, diffArgs("--numstat", "-z")...)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := g.run(ctx, dir, diffArgs("-U"+strconv.Itoa(contextLines))...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{Files: parseNameStatus(nameStatus), Patch: patch}
+	applyNumstat(result, numstat)
+	return result, nil
+}
+
+// parseNameStatus parses the output of git diff --name-status -z
+func parseNameStatus(out string) []DiffFile {
+	files := []DiffFile{}
+	fields := splitNUL(out)
+	for i := 0; i < len(fields); i++ {
+		code := fields[i]
+		if code == "" || i+1 >= len(fields) {
+			continue
+		}
+
+		file := DiffFile{Status: statusName(code[0])}
+		if (code[0] == 'R' || code[0] == 'C') && i+2 < len(fields) {
+			file.OldPath, file.Path = fields[i+1], fields[i+2]
+			i += 2
+		} else {
+			file.Path = fields[i+1]
+			i++
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// applyNumstat adds the line counts from git diff --numstat -z to the files
+func applyNumstat(result *DiffResult, out string) {
+	index := make(map[string]int, len(result.Files))
+	for i, file := range result.Files {
+		index[file.Path] = i
+	}
+
+	fields := splitNUL(out)
+	for i := 0; i < len(fields); i++ {
+		// "added\tdeleted\tpath", or "added\tdeleted\t" followed by the old and new paths of a rename
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		path := parts[2]
+		if path == "" && i+2 < len(fields) {
+			path = fields[i+2]
+			i += 2
+		}
+
+		j, ok := index[path]
+		if !ok {
+			continue
+		}
+		file := &result.Files[j]
+		if parts[0] == "-" {
+			file.Binary = true
+			continue
+		}
+		file.Additions, _ = strconv.Atoi(parts[0])
+		file.Deletions, _ = strconv.Atoi(parts[1])
+		result.Additions += file.Additions
+		result.Deletions += file.Deletions
+	}
+}
@@ -0,0 +1,64 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTool(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("first", map[string]string{"a.txt": "one\ntwo\n", "b.txt": "b\n"})
+	repo.commit("second", map[string]string{"a.txt": "one\n2\n", "c.bin": "\x00\x01"})
+
+	repo.write("a.txt", "one\n2\nthree\n")
+	repo.write("b.txt", "staged\n")
+	repo.git("add", "b.txt")
+
+	tool := NewDiffTool()
+
+	t.Run("Unstaged", func(t *testing.T) {
+		diff := runTool(t, tool, DiffInput{RepoPath: repo.dir}).(*DiffResult)
+		assert.Equal(t, []DiffFile{{Path: "a.txt", Status: "modified", Additions: 1}}, diff.Files)
+		assert.Contains(t, diff.Patch, "+three")
+		assert.NotContains(t, diff.Patch, "staged")
+	})
+
+	t.Run("Staged", func(t *testing.T) {
+		diff := runTool(t, tool, DiffInput{RepoPath: repo.dir, Staged: true}).(*DiffResult)
+		assert.Equal(t, []DiffFile{{Path: "b.txt", Status: "modified", Additions: 1, Deletions: 1}}, diff.Files)
+		assert.Equal(t, 1, diff.Additions)
+		assert.Contains(t, diff.Patch, "+staged")
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		diff := runTool(t, tool, DiffInput{RepoPath: repo.dir, Base: "HEAD~1", Target: "HEAD"}).(*DiffResult)
+		assert.Equal(t, []DiffFile{
+			{Path: "a.txt", Status: "modified", Additions: 1, Deletions: 1},
+			{Path: "c.bin", Status: "added", Binary: true},
+		}, diff.Files)
+	})
+
+	t.Run("PathsAndContext", func(t *testing.T) {
+		zero := 0
+		diff := runTool(t, tool, DiffInput{RepoPath: repo.dir, Base: "HEAD~1", Paths: []string{"b.txt"}, ContextLines: &zero}).(*DiffResult)
+		require.Len(t, diff.Files, 1)
+		assert.Equal(t, "b.txt", diff.Files[0].Path)
+		assert.Contains(t, diff.Patch, "@@ -1 +1 @@")
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		diff := runTool(t, tool, DiffInput{RepoPath: repo.dir, Base: "HEAD~1", MaxBytes: 40}).(*DiffResult)
+		assert.True(t, diff.Truncated)
+		assert.LessOrEqual(t, len(diff.Patch), 40)
+		assert.Len(t, diff.Files, 3)
+	})
+
+	t.Run("InvalidCombinations", func(t *testing.T) {
+		_, err := diffRevisions(DiffInput{Target: "HEAD"})
+		assert.Error(t, err)
+		_, err = diffRevisions(DiffInput{Staged: true, Base: "HEAD~1", Target: "HEAD"})
+		assert.Error(t, err)
+	})
+}
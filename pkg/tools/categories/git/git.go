@@ -0,0 +1,177 @@
+// Package git provides tools that report repository state as structured
+// results, and tools that stage and commit changes. Each tool runs the git
+// executable and parses its machine-readable output.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
+)
+
+// CategoryID is the category the git tools are registered in
+const CategoryID = "git"
+
+// defaultMaxPatchBytes is the amount of patch text returned by default
+const defaultMaxPatchBytes = 32 * 1024
+
+// maxPatchBytes is the largest amount of patch text a tool may return
+const maxPatchBytes = 256 * 1024
+
+// waitDelay is how long to wait for output after a cancelled git is killed
+const waitDelay = time.Second
+
+// repoPathSchema is the schema of the repo_path parameter shared by the git tools
+var repoPathSchema = map[string]interface{}{
+	"type":        "string",
+	"description": "A directory inside the repository (default current directory)",
+}
+
+// maxPatchBytesSchema is the schema of the max_bytes parameter of tools returning patches
+var maxPatchBytesSchema = map[string]interface{}{
+	"type":        "integer",
+	"description": fmt.Sprintf("Maximum bytes of patch text to return (default %d, at most %d)", defaultMaxPatchBytes, maxPatchBytes),
+	"minimum":     1,
+}
+
+// gitRunner is embedded in the git tools. It runs git in a repository,
+// restricted by the sandbox profile of the category.
+type gitRunner struct {
+	sandbox.Holder
+}
+
+// run runs git with args in dir and returns its stdout. A failure returns
+// an error with the message git printed.
+func (g *gitRunner) run(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := g.runBytes(ctx, dir, args...)
+	return string(out), err
+}
+
+// runInput runs git like run, writing input to its stdin
+func (g *gitRunner) runInput(ctx context.Context, dir, input string, args ...string) (string, error) {
+	out, err := g.exec(ctx, dir, strings.NewReader(input), args...)
+	return string(out), err
+}
+
+// runBytes runs git like run, returning its stdout unconverted
+func (g *gitRunner) runBytes(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	return g.exec(ctx, dir, nil, args...)
+}
+
+// exec runs git in dir with the given stdin, which may be nil
+func (g *gitRunner) exec(ctx context.Context, dir string, stdin io.Reader, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if dir == "" {
+		dir = "."
+	}
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.WaitDelay = waitDelay
+	cmd.Env = append(os.Environ(),
+		"LC_ALL=C",
+		"GIT_PAGER=cat",
+		"GIT_EDITOR=true",
+		"GIT_TERMINAL_PROMPT=0",
+		// Read-only commands must not take locks that could disturb the user's own git commands
+		"GIT_OPTIONAL_LOCKS=0",
+	)
+	if err := g.SandboxProfile().Prepare(cmd); err != nil {
+		return nil, fmt.Errorf("cannot apply the sandbox profile: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			message := strings.TrimSpace(stderr.String())
+			if message == "" {
+				message = strings.TrimSpace(stdout.String())
+			}
+			return nil, fmt.Errorf("git %s failed: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("failed to run git: %w", err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// checkRevision rejects revisions that git would read as an option
+func checkRevision(name, value string) error {
+	if strings.HasPrefix(value, "-") {
+		return fmt.Errorf("%s %q must not start with '-'", name, value)
+	}
+	return nil
+}
+
+// patchLimit returns the number of patch bytes to return for a requested maximum
+func patchLimit(requested int) int {
+	if requested <= 0 {
+		return defaultMaxPatchBytes
+	}
+	if requested > maxPatchBytes {
+		return maxPatchBytes
+	}
+	return requested
+}
+
+// truncatePatch cuts a patch to at most limit bytes, at a line boundary where possible
+func truncatePatch(patch string, limit int) (string, bool) {
+	if len(patch) <= limit {
+		return patch, false
+	}
+	cut := patch[:limit]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i+1]
+	}
+	return cut, true
+}
+
+// splitNUL splits NUL-terminated fields, as written by git's -z options
+func splitNUL(s string) []string {
+	s = strings.TrimSuffix(s, "\x00")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\x00")
+}
+
+// statusName describes a one-letter git change status
+func statusName(code byte) string {
+	switch code {
+	case 'A':
+		return "added"
+	case 'M':
+		return "modified"
+	case 'D':
+		return "deleted"
+	case 'R':
+		return "renamed"
+	case 'C':
+		return "copied"
+	case 'T':
+		return "type_changed"
+	case 'U':
+		return "unmerged"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,120 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepo is a temporary git repository
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+// newTestRepo creates an empty repository on branch main, isolated from the
+// user's git configuration
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Ada Lovelace")
+	t.Setenv("GIT_AUTHOR_EMAIL", "ada@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Ada Lovelace")
+	t.Setenv("GIT_COMMITTER_EMAIL", "ada@example.com")
+
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q", "-b", "main")
+	return r
+}
+
+// git runs a git command in the repository and returns its output
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, "git %v: %s", args, out)
+	return string(out)
+}
+
+// write creates or replaces a file in the repository
+func (r *testRepo) write(name, content string) {
+	r.t.Helper()
+
+	path := filepath.Join(r.dir, name)
+	require.NoError(r.t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(r.t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// commit writes the files and commits them with a message
+func (r *testRepo) commit(message string, files map[string]string) {
+	r.t.Helper()
+
+	for name, content := range files {
+		r.write(name, content)
+	}
+	r.git("add", "--all")
+	r.git("commit", "-q", "-m", message)
+}
+
+// runTool executes a tool with params and returns its result
+func runTool(t *testing.T, tool core.Tool, params interface{}) interface{} {
+	t.Helper()
+
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	result, err := tool.Execute(context.Background(), input)
+	require.NoError(t, err)
+	return result
+}
+
+func TestRejectsOptionsAsRevisions(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("first", map[string]string{"a.txt": "a\n"})
+
+	for _, call := range []struct {
+		tool  core.Tool
+		input string
+	}{
+		{NewLogTool(), `{"revision": "--output=/tmp/x"}`},
+		{NewDiffTool(), `{"base": "--output=/tmp/x"}`},
+		{NewShowTool(), `{"revision": "-p"}`},
+		{NewBlameTool(), `{"path": "a.txt", "revision": "--reverse"}`},
+	} {
+		input := json.RawMessage(call.input[:len(call.input)-1] + `, "repo_path": "` + repo.dir + `"}`)
+		_, err := call.tool.Execute(context.Background(), input)
+		assert.ErrorContains(t, err, "must not start with '-'", call.tool.Name())
+	}
+}
+
+func TestNotARepository(t *testing.T) {
+	newTestRepo(t)
+
+	_, err := NewStatusTool().Execute(context.Background(), json.RawMessage(`{"repo_path": "`+t.TempDir()+`"}`))
+	assert.ErrorContains(t, err, "not a git repository")
+}
+
+func TestMutatingToolsNeedReadWrite(t *testing.T) {
+	assert.Equal(t, core.PermissionReadWrite, NewStageTool().Permission())
+	assert.Equal(t, core.PermissionReadWrite, NewCommitTool().Permission())
+
+	readOnly := []core.Tool{NewStatusTool(), NewDiffTool(), NewLogTool(), NewShowTool(), NewBlameTool(), NewBranchesTool()}
+	for _, tool := range readOnly {
+		_, ok := tool.(core.PermissionedTool)
+		assert.False(t, ok, "%s should use the permission of its category", tool.Name())
+	}
+}
@@ -0,0 +1,201 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// defaultLogCount is the number of commits git_log returns by default
+const defaultLogCount = 20
+
+// maxLogCount is the largest number of commits git_log returns
+const maxLogCount = 200
+
+// commitFormat is a git pretty format with the fields of Commit separated by
+// unit separators, and commits separated by record separators
+const commitFormat = "%H%x1f%h%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%s%x1f%b%x1e"
+
+// Commit describes a commit
+type Commit struct {
+	Hash        string   `json:"hash"`
+	ShortHash   string   `json:"short_hash"`
+	Parents     []string `json:"parents,omitempty"`
+	Author      string   `json:"author"`
+	AuthorEmail string   `json:"author_email"`
+	Date        string   `json:"date"` // Author date, RFC 3339
+	Subject     string   `json:"subject"`
+	Body        string   `json:"body,omitempty"`
+}
+
+// LogTool lists commits, optionally filtered
+type LogTool struct {
+	core.BaseToolImpl
+	gitRunner
+}
+
+// LogInput represents parameters for the git_log tool
+type LogInput struct {
+	RepoPath    string   `json:"repo_path,omitempty"`
+	Revision    string   `json:"revision,omitempty"` // Revision or range, e.g. "main..HEAD" (default HEAD)
+	MaxCount    int      `json:"max_count,omitempty"`
+	Skip        int      `json:"skip,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Since       string   `json:"since,omitempty"`
+	Until       string   `json:"until,omitempty"`
+	Grep        string   `json:"grep,omitempty"` // Regular expression matched against commit messages
+	Paths       []string `json:"paths,omitempty"`
+	IncludeBody bool     `json:"include_body,omitempty"`
+}
+
+// LogResult lists commits, newest first
+type LogResult struct {
+	Commits []Commit `json:"commits"`
+	More    bool     `json:"more,omitempty"` // More commits match; use skip to page
+}
+
+// NewLogTool creates a new git_log tool
+func NewLogTool() *LogTool {
+	tool := &LogTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"git_log",
+		"List commits of a git repository, newest first, filtered by revision range, author, date, "+
+			"message or the paths they touch.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"repo_path": repoPathSchema,
+				"revision": map[string]interface{}{
+					"type":        "string",
+					"description": "Revision or range to list, e.g. 'main', 'v1.2..HEAD' or 'main..feature' (default HEAD)",
+				},
+				"max_count": map[string]interface{}{
+					"type":        "integer",
+					"description": fmt.Sprintf("Maximum number of commits (default %d, at most %d)", defaultLogCount, maxLogCount),
+					"minimum":     1,
+				},
+				"skip": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of matching commits to skip, for paging",
+					"minimum":     0,
+				},
+				"author": map[string]interface{}{
+					"type":        "string",
+					"description": "Only commits whose author name or email matches this regular expression",
+				},
+				"since": map[string]interface{}{
+					"type":        "string",
+					"description": "Only commits after this date, e.g. '2024-05-01' or '2 weeks ago'",
+				},
+				"until": map[string]interface{}{
+					"type":        "string",
+					"description": "Only commits before this date",
+				},
+				"grep": map[string]interface{}{
+					"type":        "string",
+					"description": "Only commits whose message matches this regular expression (case insensitive)",
+				},
+				"paths": map[string]interface{}{
+					"type":        "array",
+					"description": "Only commits that change these files or directories",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"include_body": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Include the full commit message, not just the subject",
+				},
+			},
+		},
+	)
+	return tool
+}
+
+// Execute lists the matching commits
+func (t *LogTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params LogInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for git_log tool: %w", err)
+	}
+
+	maxCount := params.MaxCount
+	if maxCount <= 0 {
+		maxCount = defaultLogCount
+	}
+	if maxCount > maxLogCount {
+		maxCount = maxLogCount
+	}
+
+	// Ask for one more commit than returned to learn whether there are more
+	args := []string{"log", "--no-color", "--format=" + commitFormat, "-n", strconv.Itoa(maxCount + 1)}
+	if params.Skip > 0 {
+		args = append(args, "--skip="+strconv.Itoa(params.Skip))
+	}
+	if params.Author != "" {
+		args = append(args, "--author="+params.Author)
+	}
+	if params.Since != "" {
+		args = append(args, "--since="+params.Since)
+	}
+	if params.Until != "" {
+		args = append(args, "--until="+params.Until)
+	}
+	if params.Grep != "" {
+		args = append(args, "--grep="+params.Grep, "--regexp-ignore-case")
+	}
+	if params.Revision != "" {
+		if err := checkRevision("revision", params.Revision); err != nil {
+			return nil, err
+		}
+		args = append(args, params.Revision)
+	}
+	args = append(append(args, "--"), params.Paths...)
+
+	out, err := t.run(ctx, params.RepoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := parseCommits(out, params.IncludeBody)
+	result := LogResult{Commits: commits}
+	if len(commits) > maxCount {
+		result.Commits = commits[:maxCount]
+		result.More = true
+	}
+	return result, nil
+}
+
+// parseCommits parses git output written with commitFormat
+func parseCommits(out string, includeBody bool) []Commit {
+	commits := []Commit{}
+	for _, record := range strings.Split(out, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != 8 {
+			continue
+		}
+
+		commit := Commit{
+			Hash:        fields[0],
+			ShortHash:   fields[1],
+			Parents:     strings.Fields(fields[2]),
+			Author:      fields[3],
+			AuthorEmail: fields[4],
+			Date:        fields[5],
+			Subject:     fields[6],
+		}
+		if includeBody {
+			commit.Body = strings.TrimSpace(fields[7])
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
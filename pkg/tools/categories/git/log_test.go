@@ -0,0 +1,95 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogTool(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("Add readme", map[string]string{"README.md": "hello\n"})
+	repo.commit("Fix parser bug\n\nThe parser dropped the last token.", map[string]string{"parser.go": "package p\n"})
+	t.Setenv("GIT_AUTHOR_NAME", "Grace Hopper")
+	t.Setenv("GIT_AUTHOR_EMAIL", "grace@example.com")
+	repo.commit("Update readme", map[string]string{"README.md": "hello world\n"})
+
+	tool := NewLogTool()
+
+	t.Run("All", func(t *testing.T) {
+		log := runTool(t, tool, LogInput{RepoPath: repo.dir}).(LogResult)
+		require.Len(t, log.Commits, 3)
+		assert.Equal(t, "Update readme", log.Commits[0].Subject)
+		assert.Equal(t, "Grace Hopper", log.Commits[0].Author)
+		assert.Equal(t, "grace@example.com", log.Commits[0].AuthorEmail)
+		assert.Equal(t, []string{log.Commits[1].Hash}, log.Commits[0].Parents)
+		assert.Empty(t, log.Commits[2].Parents)
+		assert.Empty(t, log.Commits[1].Body)
+		assert.False(t, log.More)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		log := runTool(t, tool, LogInput{RepoPath: repo.dir, Author: "ada"}).(LogResult)
+		assert.Len(t, log.Commits, 2)
+
+		log = runTool(t, tool, LogInput{RepoPath: repo.dir, Paths: []string{"README.md"}}).(LogResult)
+		assert.Len(t, log.Commits, 2)
+
+		log = runTool(t, tool, LogInput{RepoPath: repo.dir, Grep: "PARSER", IncludeBody: true}).(LogResult)
+		require.Len(t, log.Commits, 1)
+		assert.Equal(t, "The parser dropped the last token.", log.Commits[0].Body)
+
+		log = runTool(t, tool, LogInput{RepoPath: repo.dir, Revision: "HEAD~2..HEAD"}).(LogResult)
+		assert.Len(t, log.Commits, 2)
+	})
+
+	t.Run("Paging", func(t *testing.T) {
+		log := runTool(t, tool, LogInput{RepoPath: repo.dir, MaxCount: 2}).(LogResult)
+		assert.Len(t, log.Commits, 2)
+		assert.True(t, log.More)
+
+		log = runTool(t, tool, LogInput{RepoPath: repo.dir, MaxCount: 2, Skip: 2}).(LogResult)
+		require.Len(t, log.Commits, 1)
+		assert.Equal(t, "Add readme", log.Commits[0].Subject)
+		assert.False(t, log.More)
+	})
+}
+
+func TestShowTool(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("First", map[string]string{"a.txt": "one\n"})
+	repo.commit("Second\n\nWith a body.", map[string]string{"a.txt": "two\n", "b.txt": "b\n"})
+
+	tool := NewShowTool()
+
+	t.Run("Head", func(t *testing.T) {
+		show := runTool(t, tool, ShowInput{RepoPath: repo.dir}).(*ShowResult)
+		assert.Equal(t, "Second", show.Subject)
+		assert.Equal(t, "With a body.", show.Body)
+		assert.Equal(t, []DiffFile{
+			{Path: "a.txt", Status: "modified", Additions: 1, Deletions: 1},
+			{Path: "b.txt", Status: "added", Additions: 1},
+		}, show.Files)
+		assert.Contains(t, show.Patch, "-one\n+two")
+	})
+
+	t.Run("RootCommit", func(t *testing.T) {
+		show := runTool(t, tool, ShowInput{RepoPath: repo.dir, Revision: "HEAD~1"}).(*ShowResult)
+		assert.Equal(t, "First", show.Subject)
+		assert.Equal(t, []DiffFile{{Path: "a.txt", Status: "added", Additions: 1}}, show.Files)
+	})
+
+	t.Run("FileAtRevision", func(t *testing.T) {
+		file := runTool(t, tool, ShowInput{RepoPath: repo.dir, Revision: "HEAD~1", Path: "a.txt"}).(FileAtRevision)
+		assert.Equal(t, "one\n", file.Content)
+		assert.Equal(t, 4, file.Size)
+	})
+
+	t.Run("UnknownRevision", func(t *testing.T) {
+		_, err := tool.Execute(context.Background(), json.RawMessage(`{"repo_path": "`+repo.dir+`", "revision": "nope"}`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,26 @@
+package git
+
+import (
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// Register registers git tools with the registry
+func Register(registry core.ToolRegistrar) error {
+	tools := []core.Tool{
+		NewStatusTool(),
+		NewDiffTool(),
+		NewLogTool(),
+		NewShowTool(),
+		NewBlameTool(),
+		NewBranchesTool(),
+		NewStageTool(),
+		NewCommitTool(),
+	}
+
+	for _, tool := range tools {
+		if err := registry.RegisterTool(CategoryID, tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
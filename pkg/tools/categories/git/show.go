@@ -0,0 +1,155 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// ShowTool shows a commit, or a file as it was at a revision
+type ShowTool struct {
+	core.BaseToolImpl
+	gitRunner
+}
+
+// ShowInput represents parameters for the git_show tool
+type ShowInput struct {
+	RepoPath string `json:"repo_path,omitempty"`
+	Revision string `json:"revision,omitempty"` // Default HEAD
+	Path     string `json:"path,omitempty"`     // Show this file at the revision instead of the commit
+	MaxBytes int    `json:"max_bytes,omitempty"`
+}
+
+// ShowResult represents a commit and its changes. Merge commits are compared
+// with their first parent.
+type ShowResult struct {
+	Commit
+	Files     []DiffFile `json:"files"`
+	Additions int        `json:"additions"`
+	Deletions int        `json:"deletions"`
+	Patch     string     `json:"patch"`
+	Truncated bool       `json:"truncated,omitempty"`
+}
+
+// FileAtRevision represents the content of a file at a revision
+type FileAtRevision struct {
+	Revision  string `json:"revision"`
+	Path      string `json:"path"`
+	Content   string `json:"content,omitempty"`
+	Size      int    `json:"size"`
+	Binary    bool   `json:"binary,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// NewShowTool creates a new git_show tool
+func NewShowTool() *ShowTool {
+	tool := &ShowTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"git_show",
+		"Show a commit of a git repository with its full message, changed files and patch, "+
+			"or, when path is set, the content of a file as it was at that revision.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"repo_path": repoPathSchema,
+				"revision": map[string]interface{}{
+					"type":        "string",
+					"description": "Commit, branch or tag to show (default HEAD)",
+				},
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Show this file, relative to the repository root, as it was at the revision",
+				},
+				"max_bytes": maxPatchBytesSchema,
+			},
+		},
+	)
+	return tool
+}
+
+// Execute shows the commit or file
+func (t *ShowTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params ShowInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for git_show tool: %w", err)
+	}
+
+	revision := params.Revision
+	if revision == "" {
+		revision = "HEAD"
+	}
+	if err := checkRevision("revision", revision); err != nil {
+		return nil, err
+	}
+	limit := patchLimit(params.MaxBytes)
+
+	if params.Path != "" {
+		return t.showFile(ctx, params.RepoPath, revision, params.Path, limit)
+	}
+
+	result, err := t.showCommit(ctx, params.RepoPath, revision)
+	if err != nil {
+		return nil, err
+	}
+	result.Patch, result.Truncated = truncatePatch(result.Patch, limit)
+	return result, nil
+}
+
+// showCommit describes a commit and its changes
+func (g *gitRunner) showCommit(ctx context.Context, dir, revision string) (*ShowResult, error) {
+	out, err := g.run(ctx, dir, "show", "-s", "--no-color", "--format="+commitFormat, revision+"^{commit}", "--")
+	if err != nil {
+		return nil, err
+	}
+	commits := parseCommits(out, true)
+	if len(commits) != 1 {
+		return nil, fmt.Errorf("%s does not name a single commit", revision)
+	}
+	commit := commits[0]
+
+	// Compare with the first parent, or with nothing for the first commit
+	command := []string{"diff-tree", "-r", "--root", "--no-commit-id", commit.Hash}
+	if len(commit.Parents) > 0 {
+		command = []string{"diff", commit.Parents[0], commit.Hash}
+	}
+	diff, err := g.diff(ctx, dir, command, nil, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShowResult{
+		Commit:    commit,
+		Files:     diff.Files,
+		Additions: diff.Additions,
+		Deletions: diff.Deletions,
+		Patch:     diff.Patch,
+	}, nil
+}
+
+// showFile returns the content of a file at a revision
+func (t *ShowTool) showFile(ctx context.Context, dir, revision, path string, limit int) (interface{}, error) {
+	content, err := t.runBytes(ctx, dir, "show", "--no-color", revision+":"+path, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	result := FileAtRevision{Revision: revision, Path: path, Size: len(content)}
+	if bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 {
+		result.Binary = true
+		return result, nil
+	}
+	if len(content) > limit {
+		content = content[:limit]
+		result.Truncated = true
+	}
+	result.Content = string(content)
+	return result, nil
+}
@@ -0,0 +1,200 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// StatusTool reports the branch and the changed files of a repository
+type StatusTool struct {
+	core.BaseToolImpl
+	gitRunner
+}
+
+// StatusInput represents parameters for the git_status tool
+type StatusInput struct {
+	RepoPath string `json:"repo_path,omitempty"`
+}
+
+// FileChange is a changed file
+type FileChange struct {
+	Path    string `json:"path"`
+	OldPath string `json:"old_path,omitempty"` // Path before a rename or copy
+	Status  string `json:"status"`             // added, modified, deleted, renamed, copied or type_changed
+}
+
+// StatusResult represents the state of a repository. Paths are relative to Root.
+type StatusResult struct {
+	Root       string       `json:"root"`
+	Branch     string       `json:"branch,omitempty"` // Empty when HEAD is detached
+	Detached   bool         `json:"detached,omitempty"`
+	Commit     string       `json:"commit,omitempty"` // Empty before the first commit
+	Upstream   string       `json:"upstream,omitempty"`
+	Ahead      int          `json:"ahead,omitempty"`
+	Behind     int          `json:"behind,omitempty"`
+	Staged     []FileChange `json:"staged"`
+	Unstaged   []FileChange `json:"unstaged"`
+	Untracked  []string     `json:"untracked"`
+	Conflicted []string     `json:"conflicted,omitempty"`
+	Clean      bool         `json:"clean"`
+}
+
+// NewStatusTool creates a new git_status tool
+func NewStatusTool() *StatusTool {
+	tool := &StatusTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"git_status",
+		"Show the current branch, how far it is ahead of or behind its upstream, and the staged, "+
+			"unstaged, untracked and conflicted files of a git repository.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"repo_path": repoPathSchema,
+			},
+		},
+	)
+	return tool
+}
+
+// Execute reports the status of the repository
+func (t *StatusTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params StatusInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for git_status tool: %w", err)
+	}
+
+	return t.status(ctx, params.RepoPath)
+}
+
+// status reads the status of the repository containing dir
+func (g *gitRunner) status(ctx context.Context, dir string) (*StatusResult, error) {
+	root, err := g.run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := g.run(ctx, dir, "status", "--porcelain=v2", "--branch", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseStatus(out)
+	if err != nil {
+		return nil, err
+	}
+	result.Root = strings.TrimSpace(root)
+	return result, nil
+}
+
+// parseStatus parses the output of git status --porcelain=v2 --branch -z
+func parseStatus(out string) (*StatusResult, error) {
+	result := &StatusResult{
+		Staged:    []FileChange{},
+		Unstaged:  []FileChange{},
+		Untracked: []string{},
+	}
+
+	fields := splitNUL(out)
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if entry == "" {
+			continue
+		}
+
+		switch entry[0] {
+		case '#':
+			parseBranchHeader(result, entry)
+
+		case '1', '2':
+			// 1 XY sub mH mI mW hH hI path
+			// 2 XY sub mH mI mW hH hI Xscore path, followed by the original path
+			n := 9
+			if entry[0] == '2' {
+				n = 10
+			}
+			parts := strings.SplitN(entry, " ", n)
+			if len(parts) != n || len(parts[1]) != 2 {
+				return nil, fmt.Errorf("unexpected git status entry %q", entry)
+			}
+			change := FileChange{Path: parts[n-1]}
+			if entry[0] == '2' && i+1 < len(fields) {
+				i++
+				change.OldPath = fields[i]
+			}
+
+			index, worktree := parts[1][0], parts[1][1]
+			if index != '.' {
+				staged := change
+				staged.Status = statusName(index)
+				result.Staged = append(result.Staged, staged)
+			}
+			if worktree != '.' {
+				unstaged := FileChange{Path: change.Path, Status: statusName(worktree)}
+				result.Unstaged = append(result.Unstaged, unstaged)
+			}
+
+		case 'u':
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			parts := strings.SplitN(entry, " ", 11)
+			if len(parts) != 11 {
+				return nil, fmt.Errorf("unexpected git status entry %q", entry)
+			}
+			result.Conflicted = append(result.Conflicted, parts[10])
+
+		case '?':
+			result.Untracked = append(result.Untracked, strings.TrimPrefix(entry, "? "))
+
+		case '!':
+			// Ignored files are not requested
+
+		default:
+			return nil, fmt.Errorf("unexpected git status entry %q", entry)
+		}
+	}
+
+	result.Clean = len(result.Staged) == 0 && len(result.Unstaged) == 0 &&
+		len(result.Untracked) == 0 && len(result.Conflicted) == 0
+	return result, nil
+}
+
+// parseBranchHeader reads a "# branch.*" line of git status
+func parseBranchHeader(result *StatusResult, line string) {
+	key, value, _ := strings.Cut(strings.TrimPrefix(line, "# "), " ")
+	switch key {
+	case "branch.oid":
+		if value != "(initial)" {
+			result.Commit = value
+		}
+	case "branch.head":
+		if value == "(detached)" {
+			result.Detached = true
+		} else {
+			result.Branch = value
+		}
+	case "branch.upstream":
+		result.Upstream = value
+	case "branch.ab":
+		// +ahead -behind
+		for _, field := range strings.Fields(value) {
+			n, err := strconv.Atoi(field[1:])
+			if err != nil {
+				continue
+			}
+			if field[0] == '+' {
+				result.Ahead = n
+			} else {
+				result.Behind = n
+			}
+		}
+	}
+}
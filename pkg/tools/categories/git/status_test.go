@@ -0,0 +1,63 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusTool(t *testing.T) {
+	repo := newTestRepo(t)
+	tool := NewStatusTool()
+
+	t.Run("EmptyRepository", func(t *testing.T) {
+		status := runTool(t, tool, StatusInput{RepoPath: repo.dir}).(*StatusResult)
+		assert.Equal(t, "main", status.Branch)
+		assert.Empty(t, status.Commit)
+		assert.True(t, status.Clean)
+	})
+
+	repo.commit("first", map[string]string{"keep.txt": "keep\n", "old.txt": "old\n", "edit.txt": "one\n"})
+
+	t.Run("Changes", func(t *testing.T) {
+		repo.git("mv", "old.txt", "new.txt")
+		repo.write("edit.txt", "two\n")
+		repo.git("add", "edit.txt")
+		repo.write("edit.txt", "three\n")
+		repo.write("dir/untracked file.txt", "x\n")
+
+		status := runTool(t, tool, StatusInput{RepoPath: repo.dir}).(*StatusResult)
+		assert.Equal(t, "main", status.Branch)
+		assert.Len(t, status.Commit, 40)
+		assert.False(t, status.Clean)
+		assert.ElementsMatch(t, []FileChange{
+			{Path: "edit.txt", Status: "modified"},
+			{Path: "new.txt", OldPath: "old.txt", Status: "renamed"},
+		}, status.Staged)
+		assert.Equal(t, []FileChange{{Path: "edit.txt", Status: "modified"}}, status.Unstaged)
+		assert.Equal(t, []string{"dir/untracked file.txt"}, status.Untracked)
+	})
+
+	t.Run("Detached", func(t *testing.T) {
+		repo.git("stash", "-u", "-q")
+		repo.git("checkout", "-q", "--detach")
+
+		status := runTool(t, tool, StatusInput{RepoPath: repo.dir}).(*StatusResult)
+		assert.True(t, status.Detached)
+		assert.Empty(t, status.Branch)
+		assert.True(t, status.Clean)
+	})
+}
+
+func TestParseStatusUpstreamAndConflicts(t *testing.T) {
+	out := "# branch.oid 1234\x00# branch.head main\x00# branch.upstream origin/main\x00# branch.ab +2 -3\x00" +
+		"u UU N... 100644 100644 100644 100644 a b c both.txt\x00"
+
+	status, err := parseStatus(out)
+	assert.NoError(t, err)
+	assert.Equal(t, "origin/main", status.Upstream)
+	assert.Equal(t, 2, status.Ahead)
+	assert.Equal(t, 3, status.Behind)
+	assert.Equal(t, []string{"both.txt"}, status.Conflicted)
+	assert.False(t, status.Clean)
+}
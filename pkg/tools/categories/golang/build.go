@@ -0,0 +1,80 @@
+package golang
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// BuildTool compiles Go packages and reports compiler errors
+type BuildTool struct {
+	core.BaseToolImpl
+	toolchainRunner
+}
+
+// BuildInput represents parameters for the go_build tool
+type BuildInput struct {
+	Dir      string   `json:"dir,omitempty"`
+	Packages []string `json:"packages,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// BuildResult represents the outcome of a build
+type BuildResult struct {
+	Success bool         `json:"success"`
+	Errors  []Diagnostic `json:"errors"`
+	Output  string       `json:"output,omitempty"` // Output that is not a compiler error, such as module errors
+}
+
+// NewBuildTool creates a new go_build tool
+func NewBuildTool() *BuildTool {
+	tool := &BuildTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"go_build",
+		"Compile Go packages and return compiler errors with their file, line and column. "+
+			"Binaries are discarded; use this to check that code compiles.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"dir":      dirSchema,
+				"packages": packagesSchema,
+				"tags":     tagsSchema,
+			},
+		},
+	)
+	return tool
+}
+
+// Execute builds the packages
+func (t *BuildTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params BuildInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for go_build tool: %w", err)
+	}
+	if err := checkPackages(params.Packages); err != nil {
+		return nil, err
+	}
+
+	// Write binaries nowhere, so building a main package leaves no file behind
+	args := goArgs("build", params.Tags, []string{"-o", os.DevNull}, params.Packages)
+	out, err := t.run(ctx, params.Dir, "go", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostics, other := parseDiagnostics(out.stderr)
+	return BuildResult{
+		Success: out.exitCode == 0,
+		Errors:  diagnostics,
+		Output:  truncateOutput(strings.TrimSpace(other)),
+	}, nil
+}
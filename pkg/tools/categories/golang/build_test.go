@@ -0,0 +1,52 @@
+package golang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSucceeds(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+
+	result := runTool(t, NewBuildTool(), BuildInput{Dir: dir}).(BuildResult)
+	assert.True(t, result.Success)
+	assert.Empty(t, result.Errors)
+	assert.NoFileExists(t, dir+"/m", "binaries are discarded")
+}
+
+func TestBuildReportsCompilerErrors(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"ok/ok.go":   "package ok\n\nfunc Fine() int { return 1 }\n",
+		"bad/bad.go": "package bad\n\nfunc Broken() int {\n\treturn missing\n}\n",
+	})
+
+	result := runTool(t, NewBuildTool(), BuildInput{Dir: dir, Packages: []string{"./..."}}).(BuildResult)
+	assert.False(t, result.Success)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, Diagnostic{
+		Package: "example.com/m/bad",
+		File:    "bad/bad.go",
+		Line:    4,
+		Column:  9,
+		Message: "undefined: missing",
+	}, result.Errors[0])
+}
+
+func TestBuildTags(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"lib.go":   "package lib\n",
+		"extra.go": "//go:build extra\n\npackage lib\n\nvar X int = \"not an int\"\n",
+	})
+
+	result := runTool(t, NewBuildTool(), BuildInput{Dir: dir}).(BuildResult)
+	assert.True(t, result.Success)
+
+	result = runTool(t, NewBuildTool(), BuildInput{Dir: dir, Tags: []string{"extra"}}).(BuildResult)
+	assert.False(t, result.Success)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "extra.go", result.Errors[0].File)
+}
@@ -0,0 +1,190 @@
+package golang
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// maxDiffBytes is the amount of diff text returned by gofmt
+const maxDiffBytes = 64 * 1024
+
+// GofmtTool checks and fixes the formatting of Go source files
+type GofmtTool struct {
+	core.BaseToolImpl
+	toolchainRunner
+}
+
+// GofmtInput represents parameters for the gofmt tool
+type GofmtInput struct {
+	Dir   string   `json:"dir,omitempty"`
+	Paths []string `json:"paths,omitempty"`
+	Diff  bool     `json:"diff,omitempty"`  // Return the changes formatting would make
+	Write bool     `json:"write,omitempty"` // Reformat the files in place
+}
+
+// GofmtResult represents the formatting state of the files
+type GofmtResult struct {
+	Unformatted []string     `json:"unformatted"`       // Files whose formatting differs from gofmt's
+	Written     bool         `json:"written,omitempty"` // The unformatted files were reformatted
+	Diff        string       `json:"diff,omitempty"`
+	Truncated   bool         `json:"truncated,omitempty"` // The diff was cut short
+	Errors      []Diagnostic `json:"errors"`              // Syntax errors in files gofmt could not parse
+}
+
+// NewGofmtTool creates a new gofmt tool
+func NewGofmtTool() *GofmtTool {
+	tool := &GofmtTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"gofmt",
+		"List Go files that are not gofmt-formatted, optionally with the diff formatting would apply, "+
+			"or reformat them in place with write. Files with syntax errors are returned as errors.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"dir": dirSchema,
+				"paths": map[string]interface{}{
+					"type":        "array",
+					"description": "Files or directories to check, relative to dir (default '.', recursively)",
+					"items":       map[string]interface{}{"type": "string"},
+				},
+				"diff": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also return the changes gofmt would make as a unified diff",
+				},
+				"write": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Reformat unformatted files in place",
+				},
+			},
+		},
+	)
+	return tool
+}
+
+// Permission implements core.PermissionedTool: gofmt can rewrite files
+func (t *GofmtTool) Permission() core.PermissionLevel {
+	return core.PermissionReadWrite
+}
+
+// Execute checks or fixes the formatting
+func (t *GofmtTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params GofmtInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for gofmt tool: %w", err)
+	}
+	paths, err := gofmtPaths(params.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	unformatted, diagnostics, err := t.list(ctx, params.Dir, paths)
+	if err != nil {
+		return nil, err
+	}
+	result := GofmtResult{Unformatted: unformatted, Errors: diagnostics}
+	if len(unformatted) == 0 {
+		return result, nil
+	}
+
+	// Only the unformatted files need a diff or a rewrite
+	if params.Diff {
+		out, err := t.run(ctx, params.Dir, "gofmt", append([]string{"-d", "--"}, unformatted...)...)
+		if err != nil {
+			return nil, err
+		}
+		result.Diff, result.Truncated = clipDiff(out.stdout)
+	}
+	if params.Write {
+		out, err := t.run(ctx, params.Dir, "gofmt", append([]string{"-w", "--"}, unformatted...)...)
+		if err != nil {
+			return nil, err
+		}
+		if out.exitCode != 0 {
+			return nil, fmt.Errorf("gofmt -w failed: %s", strings.TrimSpace(out.stderr))
+		}
+		result.Written = true
+	}
+	return result, nil
+}
+
+// AffectedPaths implements core.FileMutator
+func (t *GofmtTool) AffectedPaths(input json.RawMessage) ([]string, error) {
+	var params GofmtInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for gofmt tool: %w", err)
+	}
+
+	// Only writing touches the disk, and only the unformatted files
+	if !params.Write {
+		return nil, nil
+	}
+	paths, err := gofmtPaths(params.Paths)
+	if err != nil {
+		return nil, err
+	}
+	unformatted, _, err := t.list(context.Background(), params.Dir, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	affected := make([]string, len(unformatted))
+	for i, path := range unformatted {
+		affected[i] = filepath.Join(params.Dir, path)
+	}
+	return affected, nil
+}
+
+// list runs gofmt -l and returns the unformatted files and the syntax errors
+func (t *GofmtTool) list(ctx context.Context, dir string, paths []string) ([]string, []Diagnostic, error) {
+	out, err := t.run(ctx, dir, "gofmt", append([]string{"-l", "--"}, paths...)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unformatted := []string{}
+	for _, line := range strings.Split(out.stdout, "\n") {
+		if line != "" {
+			unformatted = append(unformatted, line)
+		}
+	}
+	diagnostics, other := parseDiagnostics(out.stderr)
+	if other = strings.TrimSpace(other); other != "" && len(diagnostics) == 0 {
+		return nil, nil, fmt.Errorf("gofmt failed: %s", other)
+	}
+	return unformatted, diagnostics, nil
+}
+
+// gofmtPaths returns the paths to format, rejecting paths gofmt would read as flags
+func gofmtPaths(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return []string{"."}, nil
+	}
+	for _, path := range paths {
+		if strings.HasPrefix(path, "-") {
+			return nil, fmt.Errorf("path %q must not start with '-'", path)
+		}
+	}
+	return paths, nil
+}
+
+// clipDiff cuts a diff to at most maxDiffBytes, at a line boundary
+func clipDiff(diff string) (string, bool) {
+	if len(diff) <= maxDiffBytes {
+		return diff, false
+	}
+	cut := diff[:maxDiffBytes]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i+1]
+	}
+	return cut, true
+}
@@ -0,0 +1,65 @@
+package golang
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// formattingModule has one formatted, one unformatted and one unparsable file
+var formattingModule = map[string]string{
+	"good.go":       "package m\n\nfunc Good() {}\n",
+	"sub/ugly.go":   "package sub\n\nfunc  Ugly( ) {\n}\n",
+	"sub/broken.go": "package sub\n\nfunc Broken( {\n",
+}
+
+func TestGofmtLists(t *testing.T) {
+	dir := newTestModule(t, formattingModule)
+
+	result := runTool(t, NewGofmtTool(), GofmtInput{Dir: dir, Diff: true}).(GofmtResult)
+	assert.Equal(t, []string{"sub/ugly.go"}, result.Unformatted)
+	assert.Contains(t, result.Diff, "-func  Ugly( ) {")
+	assert.Contains(t, result.Diff, "+func Ugly() {")
+	assert.False(t, result.Written)
+
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "sub/broken.go", result.Errors[0].File)
+	assert.Equal(t, 3, result.Errors[0].Line)
+
+	content, err := os.ReadFile(filepath.Join(dir, "sub/ugly.go"))
+	require.NoError(t, err)
+	assert.Equal(t, formattingModule["sub/ugly.go"], string(content), "listing does not change files")
+}
+
+func TestGofmtWrites(t *testing.T) {
+	dir := newTestModule(t, formattingModule)
+	tool := NewGofmtTool()
+	input, err := json.Marshal(GofmtInput{Dir: dir, Paths: []string{"sub"}, Write: true})
+	require.NoError(t, err)
+
+	affected, err := tool.AffectedPaths(input)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "sub/ugly.go")}, affected)
+
+	result := runTool(t, tool, GofmtInput{Dir: dir, Paths: []string{"sub"}, Write: true}).(GofmtResult)
+	assert.True(t, result.Written)
+	assert.Equal(t, []string{"sub/ugly.go"}, result.Unformatted)
+
+	content, err := os.ReadFile(filepath.Join(dir, "sub/ugly.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package sub\n\nfunc Ugly() {\n}\n", string(content))
+
+	affected, err = tool.AffectedPaths(input)
+	require.NoError(t, err)
+	assert.Empty(t, affected, "nothing is left to format")
+}
+
+func TestGofmtOnlyWritingAffectsFiles(t *testing.T) {
+	affected, err := NewGofmtTool().AffectedPaths(json.RawMessage(`{"diff": true}`))
+	require.NoError(t, err)
+	assert.Empty(t, affected)
+}
@@ -0,0 +1,216 @@
+// Package golang provides tools that run the Go toolchain and parse its
+// output into structured results: compiler errors and vet findings with
+// their file and line, and failing tests with the location and output of
+// each failure.
+package golang
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
+)
+
+// CategoryID is the category the Go tools are registered in
+const CategoryID = "go"
+
+// waitDelay is how long to wait for output after a cancelled command is killed
+const waitDelay = time.Second
+
+// maxOutputBytes is the amount of unparsed output returned by a tool
+const maxOutputBytes = 16 * 1024
+
+// dirSchema is the schema of the dir parameter shared by the Go tools
+var dirSchema = map[string]interface{}{
+	"type":        "string",
+	"description": "Directory to run in, inside the module (default current directory)",
+}
+
+// packagesSchema is the schema of the packages parameter shared by the Go tools
+var packagesSchema = map[string]interface{}{
+	"type":        "array",
+	"description": "Package patterns, e.g. ['./...'] or ['./internal/store'] (default ./...)",
+	"items":       map[string]interface{}{"type": "string"},
+}
+
+// tagsSchema is the schema of the tags parameter shared by the Go tools
+var tagsSchema = map[string]interface{}{
+	"type":        "array",
+	"description": "Build tags to enable",
+	"items":       map[string]interface{}{"type": "string"},
+}
+
+// diagnosticLine matches a compiler or vet message such as "pkg/file.go:12:5: message"
+var diagnosticLine = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// Diagnostic is a message about a position in a Go source file
+type Diagnostic struct {
+	Package string `json:"package,omitempty"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// toolchainRunner is embedded in the Go tools. It runs the toolchain,
+// restricted by the sandbox profile of the category.
+type toolchainRunner struct {
+	sandbox.Holder
+}
+
+// commandResult is the output of a toolchain command
+type commandResult struct {
+	stdout   string
+	stderr   string
+	exitCode int
+}
+
+// run runs a toolchain command in dir. A non-zero exit status is not an
+// error: it is how the toolchain reports problems, which the caller parses.
+func (r *toolchainRunner) run(ctx context.Context, dir, name string, args ...string) (*commandResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.WaitDelay = waitDelay
+	if err := r.SandboxProfile().Prepare(cmd); err != nil {
+		return nil, fmt.Errorf("cannot apply the sandbox profile: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+
+	result := &commandResult{stdout: stdout.String(), stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		result.exitCode = exitErr.ExitCode()
+	case err != nil:
+		return nil, fmt.Errorf("failed to run %s: %w", name, err)
+	}
+	return result, nil
+}
+
+// goArgs builds the arguments of a go subcommand that takes build tags and packages
+func goArgs(subcommand string, tags []string, flags []string, packages []string) []string {
+	args := []string{subcommand}
+	if len(tags) > 0 {
+		args = append(args, "-tags="+strings.Join(tags, ","))
+	}
+	args = append(args, flags...)
+	if len(packages) == 0 {
+		packages = []string{"./..."}
+	}
+	return append(args, packages...)
+}
+
+// checkPackages rejects package patterns that go would read as flags
+func checkPackages(packages []string) error {
+	for _, pattern := range packages {
+		if strings.HasPrefix(pattern, "-") {
+			return fmt.Errorf("package pattern %q must not start with '-'", pattern)
+		}
+	}
+	return nil
+}
+
+// parseDiagnostics reads compiler and vet output: "# package" headers
+// followed by "file:line:col: message" lines, where indented lines continue
+// the previous message. Lines that are not diagnostics are returned as
+// other output.
+func parseDiagnostics(output string) ([]Diagnostic, string) {
+	diagnostics := []Diagnostic{}
+	var other []string
+	var pkg string
+	continuing := false // The previous line was part of a diagnostic
+
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# "):
+			pkg = strings.TrimPrefix(line, "# ")
+			// go vet names the test variant of a package "pkg [pkg.test]"
+			if i := strings.Index(pkg, " ["); i >= 0 {
+				pkg = pkg[:i]
+			}
+			continuing = false
+
+		case strings.HasPrefix(line, "\t") && continuing:
+			last := &diagnostics[len(diagnostics)-1]
+			last.Message += "\n" + strings.TrimSpace(line)
+
+		default:
+			// go vet prefixes type checking errors with "vet: "
+			if diagnostic, ok := parseDiagnostic(strings.TrimPrefix(line, "vet: ")); ok {
+				diagnostic.Package = pkg
+				diagnostics = append(diagnostics, diagnostic)
+				continuing = true
+				continue
+			}
+			if strings.TrimSpace(line) != "" {
+				other = append(other, line)
+			}
+			continuing = false
+		}
+	}
+	return diagnostics, strings.Join(other, "\n")
+}
+
+// parseDiagnostic parses a single "file:line:col: message" line
+func parseDiagnostic(line string) (Diagnostic, bool) {
+	match := diagnosticLine.FindStringSubmatch(line)
+	if match == nil {
+		return Diagnostic{}, false
+	}
+	lineNumber, _ := strconv.Atoi(match[2])
+	column, _ := strconv.Atoi(match[3])
+	return Diagnostic{File: filepath.Clean(match[1]), Line: lineNumber, Column: column, Message: match[4]}, true
+}
+
+// relativePath returns path relative to dir when it is inside dir, and path otherwise
+func relativePath(dir, path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	base, err := filepath.Abs(dirOrCurrent(dir))
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(base, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// dirOrCurrent returns dir, or the current directory if dir is empty
+func dirOrCurrent(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// truncateOutput cuts output that is too long to return, keeping its end
+// where the summary of a failure usually is
+func truncateOutput(output string) string {
+	if len(output) <= maxOutputBytes {
+		return output
+	}
+	return fmt.Sprintf("[... %d bytes omitted ...]\n", len(output)-maxOutputBytes) + output[len(output)-maxOutputBytes:]
+}
@@ -0,0 +1,87 @@
+package golang
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestModule writes a module named example.com/m with the given files
+// to a temporary directory and returns the directory
+func newTestModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/m\n\ngo 1.21\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+// runTool executes a tool with params and returns its result
+func runTool(t *testing.T, tool core.Tool, params interface{}) interface{} {
+	t.Helper()
+
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+
+	result, err := tool.Execute(context.Background(), input)
+	require.NoError(t, err)
+	return result
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	output := "# example.com/m/store\n" +
+		"store/store.go:12:5: undefined: missing\n" +
+		"store/store.go:20:2: cannot use x (variable of type int) as string value in return statement\n" +
+		"\thave (int)\n" +
+		"\twant (string)\n" +
+		"# example.com/m/api [example.com/m/api.test]\n" +
+		"vet: api/api_test.go:3:8: could not import fmt\n" +
+		"note: module requires Go 1.99\n"
+
+	diagnostics, other := parseDiagnostics(output)
+	assert.Equal(t, []Diagnostic{
+		{Package: "example.com/m/store", File: "store/store.go", Line: 12, Column: 5, Message: "undefined: missing"},
+		{Package: "example.com/m/store", File: "store/store.go", Line: 20, Column: 2,
+			Message: "cannot use x (variable of type int) as string value in return statement\nhave (int)\nwant (string)"},
+		{Package: "example.com/m/api", File: "api/api_test.go", Line: 3, Column: 8, Message: "could not import fmt"},
+	}, diagnostics)
+	assert.Equal(t, "note: module requires Go 1.99", other)
+}
+
+func TestParseDiagnosticsWithoutColumn(t *testing.T) {
+	diagnostics, other := parseDiagnostics("main.go:7: missing return\n")
+	assert.Equal(t, []Diagnostic{{File: "main.go", Line: 7, Message: "missing return"}}, diagnostics)
+	assert.Empty(t, other)
+}
+
+func TestRejectsOptionsAsPackages(t *testing.T) {
+	for _, tool := range []core.Tool{NewBuildTool(), NewTestTool(), NewVetTool()} {
+		_, err := tool.Execute(context.Background(), json.RawMessage(`{"packages": ["-toolexec=/tmp/x"]}`))
+		assert.ErrorContains(t, err, "must not start with '-'", tool.Name())
+	}
+
+	_, err := NewGofmtTool().Execute(context.Background(), json.RawMessage(`{"paths": ["-w"]}`))
+	assert.ErrorContains(t, err, "must not start with '-'")
+}
+
+func TestTruncateOutputKeepsTheEnd(t *testing.T) {
+	output := string(make([]byte, maxOutputBytes)) + "the end"
+	truncated := truncateOutput(output)
+	assert.Contains(t, truncated, "[... 7 bytes omitted ...]")
+	assert.Contains(t, truncated[len(truncated)-10:], "the end")
+}
@@ -0,0 +1,403 @@
+package golang
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// maxTestFailures is the number of failures go_test describes
+const maxTestFailures = 50
+
+// maxFailureOutput is the amount of output returned for each failure
+const maxFailureOutput = 4 * 1024
+
+// testLocation matches a t.Error or t.Fatal message such as "    store_test.go:42: got 1, want 2"
+var testLocation = regexp.MustCompile(`^\s*([\w.\-/]+\.go):(\d+): (.*)$`)
+
+// panicFrame matches a stack frame line such as "\t/src/app/store.go:42 +0x1d"
+var panicFrame = regexp.MustCompile(`^\s+(/\S+\.go):(\d+)`)
+
+// TestTool runs Go tests and reports the failures
+type TestTool struct {
+	core.BaseToolImpl
+	toolchainRunner
+}
+
+// TestInput represents parameters for the go_test tool
+type TestInput struct {
+	Dir         string   `json:"dir,omitempty"`
+	Packages    []string `json:"packages,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Run         string   `json:"run,omitempty"` // Regular expression selecting tests
+	Short       bool     `json:"short,omitempty"`
+	Race        bool     `json:"race,omitempty"`
+	NoCache     bool     `json:"no_cache,omitempty"`
+	TimeoutSecs int      `json:"timeout_secs,omitempty"`
+}
+
+// TestFailure describes a failed test, or a package that failed outside its tests
+type TestFailure struct {
+	Package string `json:"package"`
+	Test    string `json:"test,omitempty"` // Empty when the package failed outside a test
+	File    string `json:"file,omitempty"` // Where the first failure was reported, relative to dir
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message,omitempty"` // The first failure message
+	Output  string `json:"output"`            // Everything the test printed
+}
+
+// PackageResult summarizes the tests of one package
+type PackageResult struct {
+	Package     string  `json:"package"`
+	Status      string  `json:"status"` // pass, fail or skip (no test files)
+	Elapsed     float64 `json:"elapsed_secs"`
+	Passed      int     `json:"passed"`
+	Failed      int     `json:"failed"`
+	Skipped     int     `json:"skipped"`
+	BuildFailed bool    `json:"build_failed,omitempty"`
+}
+
+// TestResult represents the outcome of a test run
+type TestResult struct {
+	Success      bool            `json:"success"`
+	Passed       int             `json:"passed"`
+	Failed       int             `json:"failed"`
+	Skipped      int             `json:"skipped"`
+	Packages     []PackageResult `json:"packages"`
+	Failures     []TestFailure   `json:"failures"`
+	MoreFailures int             `json:"more_failures,omitempty"` // Failures not described
+	BuildErrors  []Diagnostic    `json:"build_errors"`
+	Output       string          `json:"output,omitempty"` // Output that could not be attributed to a test
+}
+
+// testEvent is an event written by go test -json, including the build
+// events written since Go 1.24
+type testEvent struct {
+	Action      string
+	Package     string
+	Test        string
+	Output      string
+	Elapsed     float64
+	ImportPath  string
+	FailedBuild string
+}
+
+// testState collects the events of one test
+type testState struct {
+	pkg    string
+	name   string
+	output []string
+	failed bool
+}
+
+// NewTestTool creates a new go_test tool
+func NewTestTool() *TestTool {
+	tool := &TestTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"go_test",
+		"Run Go tests and return pass/fail counts per package and, for each failing test, "+
+			"the file and line of the failure, its message and output. Compile errors are returned as build_errors.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"dir":      dirSchema,
+				"packages": packagesSchema,
+				"tags":     tagsSchema,
+				"run": map[string]interface{}{
+					"type":        "string",
+					"description": "Only run tests matching this regular expression, e.g. '^TestStore$' or 'TestParse/empty'",
+				},
+				"short": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Pass -short to skip long-running tests",
+				},
+				"race": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Enable the race detector",
+				},
+				"no_cache": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Run tests even if a cached result exists",
+				},
+				"timeout_secs": map[string]interface{}{
+					"type":        "integer",
+					"description": "Fail tests that run longer than this (go test's default is 10 minutes)",
+					"minimum":     1,
+				},
+			},
+		},
+	)
+	return tool
+}
+
+// Execute runs the tests
+func (t *TestTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params TestInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for go_test tool: %w", err)
+	}
+	if err := checkPackages(params.Packages); err != nil {
+		return nil, err
+	}
+
+	flags := []string{"-json"}
+	if params.Run != "" {
+		flags = append(flags, "-run="+params.Run)
+	}
+	if params.Short {
+		flags = append(flags, "-short")
+	}
+	if params.Race {
+		flags = append(flags, "-race")
+	}
+	if params.NoCache {
+		flags = append(flags, "-count=1")
+	}
+	if params.TimeoutSecs > 0 {
+		flags = append(flags, "-timeout="+strconv.Itoa(params.TimeoutSecs)+"s")
+	}
+
+	out, err := t.run(ctx, params.Dir, "go", goArgs("test", params.Tags, flags, params.Packages)...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := parseTestOutput(out.stdout, out.stderr)
+	result.Success = out.exitCode == 0 && result.Failed == 0 && len(result.BuildErrors) == 0
+
+	// Test output names files without their directory; find the package directories
+	if len(result.Failures) > 0 {
+		dirs := t.packageDirs(ctx, params.Dir, result.Failures)
+		for i := range result.Failures {
+			failure := &result.Failures[i]
+			if failure.File != "" && !filepath.IsAbs(failure.File) && dirs[failure.Package] != "" {
+				failure.File = filepath.Join(dirs[failure.Package], failure.File)
+			}
+			failure.File = relativePath(params.Dir, failure.File)
+		}
+	}
+	return result, nil
+}
+
+// packageDirs returns the directories of the packages with failures, by import path
+func (t *TestTool) packageDirs(ctx context.Context, dir string, failures []TestFailure) map[string]string {
+	seen := make(map[string]bool)
+	args := []string{"list", "-e", "-f", "{{.ImportPath}}\t{{.Dir}}"}
+	for _, failure := range failures {
+		if !seen[failure.Package] {
+			seen[failure.Package] = true
+			args = append(args, failure.Package)
+		}
+	}
+
+	dirs := make(map[string]string)
+	out, err := t.run(ctx, dir, "go", args...)
+	if err != nil {
+		return dirs
+	}
+	for _, line := range strings.Split(out.stdout, "\n") {
+		if importPath, pkgDir, ok := strings.Cut(line, "\t"); ok {
+			dirs[importPath] = pkgDir
+		}
+	}
+	return dirs
+}
+
+// parseTestOutput reads the events of go test -json and the diagnostics it writes to stderr
+func parseTestOutput(stdout, stderr string) TestResult {
+	result := TestResult{Packages: []PackageResult{}, Failures: []TestFailure{}}
+
+	tests := make(map[string]*testState)
+	var failed []*testState
+	packages := make(map[string]*PackageResult)
+	var packageOrder []string
+	var buildOutput, other []string
+
+	pkgResult := func(name string) *PackageResult {
+		if p, ok := packages[name]; ok {
+			return p
+		}
+		p := &PackageResult{Package: name}
+		packages[name] = p
+		packageOrder = append(packageOrder, name)
+		return p
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(stdout))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var event testEvent
+		if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &event) != nil {
+			if strings.TrimSpace(line) != "" {
+				other = append(other, line)
+			}
+			continue
+		}
+
+		switch event.Action {
+		case "build-output":
+			buildOutput = append(buildOutput, strings.TrimSuffix(event.Output, "\n"))
+			continue
+		case "build-fail":
+			continue
+		}
+
+		key := event.Package + "\x00" + event.Test
+		state, ok := tests[key]
+		if !ok {
+			state = &testState{pkg: event.Package, name: event.Test}
+			tests[key] = state
+		}
+
+		switch event.Action {
+		case "output":
+			state.output = append(state.output, strings.TrimSuffix(event.Output, "\n"))
+
+		case "pass", "fail", "skip":
+			p := pkgResult(event.Package)
+			if event.Test == "" {
+				p.Status = event.Action
+				p.Elapsed = event.Elapsed
+				p.BuildFailed = event.FailedBuild != ""
+				if event.Action == "fail" && !p.BuildFailed {
+					failed = append(failed, state)
+				}
+				continue
+			}
+
+			switch event.Action {
+			case "pass":
+				p.Passed++
+			case "fail":
+				p.Failed++
+				state.failed = true
+				failed = append(failed, state)
+			case "skip":
+				p.Skipped++
+			}
+		}
+	}
+
+	for _, name := range packageOrder {
+		p := packages[name]
+		result.Packages = append(result.Packages, *p)
+		result.Passed += p.Passed
+		result.Failed += p.Failed
+		result.Skipped += p.Skipped
+	}
+
+	for _, state := range failed {
+		failure, ok := describeFailure(state, tests, packages[state.pkg])
+		if !ok {
+			continue
+		}
+		if len(result.Failures) == maxTestFailures {
+			result.MoreFailures++
+			continue
+		}
+		result.Failures = append(result.Failures, failure)
+	}
+
+	// Go 1.24 reports compile errors as build events; older versions write them to stderr
+	buildErrors, buildOther := parseDiagnostics(strings.Join(buildOutput, "\n") + "\n" + stderr)
+	result.BuildErrors = buildErrors
+	if buildOther != "" {
+		other = append(other, buildOther)
+	}
+	result.Output = truncateOutput(strings.Join(other, "\n"))
+	return result
+}
+
+// describeFailure turns a failed test or package into a failure report. A
+// test that failed only because of a failed subtest is left out, as is a
+// package whose failure is explained by its failed tests.
+func describeFailure(state *testState, tests map[string]*testState, pkg *PackageResult) (TestFailure, bool) {
+	if state.name == "" && pkg != nil && pkg.Failed > 0 {
+		return TestFailure{}, false
+	}
+	output := testOutput(state.output)
+	if output == "" && state.name != "" && hasFailedSubtest(state, tests) {
+		return TestFailure{}, false
+	}
+
+	failure := TestFailure{Package: state.pkg, Test: state.name, Output: clipOutput(output)}
+	for _, line := range strings.Split(output, "\n") {
+		if match := testLocation.FindStringSubmatch(line); match != nil {
+			failure.File = match[1]
+			failure.Line, _ = strconv.Atoi(match[2])
+			failure.Message = match[3]
+			return failure, true
+		}
+	}
+
+	// A panic reports the first frame outside the runtime, the testing package and dependencies
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "panic: ") && failure.Message == "" {
+			failure.Message = line
+		}
+		match := panicFrame.FindStringSubmatch(line)
+		if match == nil || failure.File != "" {
+			continue
+		}
+		if strings.Contains(match[1], "/src/runtime/") || strings.Contains(match[1], "/src/testing/") || strings.Contains(match[1], "/pkg/mod/") {
+			continue
+		}
+		failure.File = match[1]
+		failure.Line, _ = strconv.Atoi(match[2])
+	}
+	return failure, true
+}
+
+// hasFailedSubtest reports whether a subtest of the test failed
+func hasFailedSubtest(state *testState, tests map[string]*testState) bool {
+	prefix := state.name + "/"
+	for _, other := range tests {
+		if other.pkg == state.pkg && other.failed && strings.HasPrefix(other.name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// testOutput removes the lines go test adds around a test's own output
+func testOutput(lines []string) string {
+	var kept []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "=== "),
+			strings.HasPrefix(trimmed, "--- FAIL"),
+			strings.HasPrefix(trimmed, "--- PASS"),
+			strings.HasPrefix(trimmed, "--- SKIP"),
+			trimmed == "FAIL", trimmed == "PASS",
+			strings.HasPrefix(trimmed, "FAIL\t"),
+			strings.HasPrefix(trimmed, "ok \t"),
+			strings.HasPrefix(trimmed, "ok  \t"):
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// clipOutput cuts the output of a failure, keeping its beginning where the
+// first failure is reported
+func clipOutput(output string) string {
+	if len(output) <= maxFailureOutput {
+		return output
+	}
+	return output[:maxFailureOutput] + fmt.Sprintf("\n[... %d bytes omitted ...]", len(output)-maxFailureOutput)
+}
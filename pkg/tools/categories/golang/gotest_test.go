@@ -0,0 +1,140 @@
+package golang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// calcModule has a package with passing, failing, skipped and panicking tests
+var calcModule = map[string]string{
+	"calc/calc.go": "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+	"calc/calc_test.go": `package calc
+
+import "testing"
+
+func TestAddPasses(t *testing.T) {}
+
+func TestAddFails(t *testing.T) {
+	if Add(1, 1) != 3 {
+		t.Errorf("Add(1, 1) = %d, want 3", Add(1, 1))
+	}
+}
+
+func TestSkipped(t *testing.T) { t.Skip("not today") }
+
+func TestTable(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {})
+	t.Run("broken", func(t *testing.T) { t.Fatal("boom") })
+}
+
+func TestPanics(t *testing.T) {
+	var m map[string]int
+	m["x"] = 1
+}
+`,
+	"other/other.go":      "package other\n",
+	"other/other_test.go": "package other\n\nimport \"testing\"\n\nfunc TestFine(t *testing.T) {}\n",
+}
+
+func TestGoTestReportsFailures(t *testing.T) {
+	dir := newTestModule(t, calcModule)
+
+	result := runTool(t, NewTestTool(), TestInput{Dir: dir, NoCache: true}).(TestResult)
+	assert.False(t, result.Success)
+	assert.Equal(t, 3, result.Passed)
+	assert.Equal(t, 4, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Empty(t, result.BuildErrors)
+
+	require.Len(t, result.Packages, 2)
+	assert.Equal(t, "example.com/m/calc", result.Packages[0].Package)
+	assert.Equal(t, "fail", result.Packages[0].Status)
+	assert.Equal(t, "pass", result.Packages[1].Status)
+
+	failures := make(map[string]TestFailure)
+	for _, failure := range result.Failures {
+		failures[failure.Test] = failure
+	}
+	assert.NotContains(t, failures, "TestTable", "a parent failing only through its subtest is not reported")
+	assert.NotContains(t, failures, "", "the package failure is explained by its tests")
+
+	failure := failures["TestAddFails"]
+	assert.Equal(t, "example.com/m/calc", failure.Package)
+	assert.Equal(t, "calc/calc_test.go", failure.File)
+	assert.Equal(t, 9, failure.Line)
+	assert.Equal(t, "Add(1, 1) = 2, want 3", failure.Message)
+	assert.NotContains(t, failure.Output, "--- FAIL")
+
+	failure = failures["TestTable/broken"]
+	assert.Equal(t, 17, failure.Line)
+	assert.Equal(t, "boom", failure.Message)
+
+	failure = failures["TestPanics"]
+	assert.Equal(t, "calc/calc_test.go", failure.File)
+	assert.Equal(t, 22, failure.Line)
+	assert.Contains(t, failure.Message, "panic: assignment to entry in nil map")
+}
+
+func TestGoTestRunSelectsTests(t *testing.T) {
+	dir := newTestModule(t, calcModule)
+
+	result := runTool(t, NewTestTool(), TestInput{Dir: dir, Packages: []string{"./calc"}, Run: "^TestAddPasses$"}).(TestResult)
+	assert.True(t, result.Success)
+	assert.Equal(t, 1, result.Passed)
+	assert.Empty(t, result.Failures)
+}
+
+func TestGoTestReportsBuildErrors(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"calc/calc.go":      "package calc\n",
+		"calc/calc_test.go": "package calc\n\nimport \"testing\"\n\nfunc TestX(t *testing.T) { undefinedCall() }\n",
+	})
+
+	result := runTool(t, NewTestTool(), TestInput{Dir: dir}).(TestResult)
+	assert.False(t, result.Success)
+	require.Len(t, result.BuildErrors, 1)
+	assert.Equal(t, "calc/calc_test.go", result.BuildErrors[0].File)
+	assert.Equal(t, 5, result.BuildErrors[0].Line)
+	assert.Contains(t, result.BuildErrors[0].Message, "undefined: undefinedCall")
+	require.Len(t, result.Packages, 1)
+	assert.True(t, result.Packages[0].BuildFailed)
+	assert.Empty(t, result.Failures, "a build failure is not reported as a test failure")
+}
+
+func TestGoTestReportsPackageFailures(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"calc/calc.go": "package calc\n",
+		"calc/main_test.go": `package calc
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	fmt.Println("setup failed")
+	os.Exit(3)
+}
+`,
+	})
+
+	result := runTool(t, NewTestTool(), TestInput{Dir: dir}).(TestResult)
+	assert.False(t, result.Success)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "example.com/m/calc", result.Failures[0].Package)
+	assert.Empty(t, result.Failures[0].Test)
+	assert.Contains(t, result.Failures[0].Output, "setup failed")
+}
+
+func TestTestOutputDropsFrameLines(t *testing.T) {
+	output := testOutput([]string{
+		"=== RUN   TestX",
+		"    x_test.go:3: bad",
+		"--- FAIL: TestX (0.00s)",
+		"=== CONT  TestX",
+	})
+	assert.Equal(t, "x_test.go:3: bad", output)
+}
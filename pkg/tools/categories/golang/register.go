@@ -0,0 +1,22 @@
+package golang
+
+import (
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// Register registers Go toolchain tools with the registry
+func Register(registry core.ToolRegistrar) error {
+	tools := []core.Tool{
+		NewBuildTool(),
+		NewTestTool(),
+		NewVetTool(),
+		NewGofmtTool(),
+	}
+
+	for _, tool := range tools {
+		if err := registry.RegisterTool(CategoryID, tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package golang
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// vetPosition matches the position of a vet finding, "file:line:col"
+var vetPosition = regexp.MustCompile(`^(.+):(\d+):(\d+)$`)
+
+// VetTool runs go vet and reports its findings
+type VetTool struct {
+	core.BaseToolImpl
+	toolchainRunner
+}
+
+// VetInput represents parameters for the go_vet tool
+type VetInput struct {
+	Dir      string   `json:"dir,omitempty"`
+	Packages []string `json:"packages,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// VetFinding is a suspicious construct reported by a vet analyzer
+type VetFinding struct {
+	Diagnostic
+	Analyzer string `json:"analyzer"`
+}
+
+// VetResult represents the findings of go vet
+type VetResult struct {
+	Success  bool         `json:"success"`
+	Findings []VetFinding `json:"findings"`
+	Errors   []Diagnostic `json:"errors"` // Code that does not compile, so was not analyzed
+	Output   string       `json:"output,omitempty"`
+}
+
+// vetDiagnostic is a finding in the output of go vet -json
+type vetDiagnostic struct {
+	Posn    string `json:"posn"`
+	Message string `json:"message"`
+}
+
+// NewVetTool creates a new go_vet tool
+func NewVetTool() *VetTool {
+	tool := &VetTool{}
+	tool.BaseToolImpl = *core.NewBaseTool(
+		"go_vet",
+		"Run go vet and return each finding with its analyzer, file, line and column. "+
+			"Packages that do not compile are reported as errors instead.",
+		CategoryID,
+		map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"dir":      dirSchema,
+				"packages": packagesSchema,
+				"tags":     tagsSchema,
+			},
+		},
+	)
+	return tool
+}
+
+// Execute vets the packages
+func (t *VetTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var params VetInput
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil, fmt.Errorf("invalid input for go_vet tool: %w", err)
+	}
+	if err := checkPackages(params.Packages); err != nil {
+		return nil, err
+	}
+
+	// With -json, vet exits successfully even when it has findings
+	out, err := t.run(ctx, params.Dir, "go", goArgs("vet", params.Tags, []string{"-json"}, params.Packages)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recent versions write the JSON to stdout, older ones to stderr
+	result := parseVetOutput(out.stdout + "\n" + out.stderr)
+	for i := range result.Findings {
+		result.Findings[i].File = relativePath(params.Dir, result.Findings[i].File)
+	}
+	result.Success = out.exitCode == 0 && len(result.Findings) == 0 && len(result.Errors) == 0
+	return result, nil
+}
+
+// parseVetOutput reads the output of go vet -json: a JSON object of
+// findings per package, mixed with text diagnostics for packages that
+// could not be type checked
+func parseVetOutput(output string) VetResult {
+	result := VetResult{Findings: []VetFinding{}}
+	var text, object []string
+
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case object != nil:
+			object = append(object, line)
+			if line == "}" {
+				result.Findings = append(result.Findings, parseVetObject(strings.Join(object, "\n"), &text)...)
+				object = nil
+			}
+		case line == "{}":
+		case line == "{":
+			object = []string{line}
+		default:
+			text = append(text, line)
+		}
+	}
+
+	diagnostics, other := parseDiagnostics(strings.Join(text, "\n"))
+	result.Errors = diagnostics
+	result.Output = truncateOutput(strings.TrimSpace(other))
+	return result
+}
+
+// parseVetObject reads the findings of one JSON object, which maps packages
+// to analyzers to findings. An analyzer that failed has an error instead of
+// findings; its message is added to text.
+func parseVetObject(object string, text *[]string) []VetFinding {
+	var packages map[string]map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(object), &packages); err != nil {
+		*text = append(*text, object)
+		return nil
+	}
+
+	var findings []VetFinding
+	for _, pkg := range sortedKeys(packages) {
+		analyzers := packages[pkg]
+		for _, analyzer := range sortedKeys(analyzers) {
+			var diagnostics []vetDiagnostic
+			if err := json.Unmarshal(analyzers[analyzer], &diagnostics); err != nil {
+				var failure struct {
+					Error string `json:"error"`
+				}
+				if json.Unmarshal(analyzers[analyzer], &failure) == nil && failure.Error != "" {
+					*text = append(*text, failure.Error)
+				}
+				continue
+			}
+
+			for _, d := range diagnostics {
+				finding := VetFinding{Diagnostic: Diagnostic{Package: pkg, File: d.Posn, Message: d.Message}, Analyzer: analyzer}
+				if match := vetPosition.FindStringSubmatch(d.Posn); match != nil {
+					finding.File = match[1]
+					finding.Line, _ = strconv.Atoi(match[2])
+					finding.Column, _ = strconv.Atoi(match[3])
+				}
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package golang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVetReportsFindings(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"clean/clean.go": "package clean\n\nfunc OK() {}\n",
+		"report/report.go": `package report
+
+import "fmt"
+
+func Print(name string) {
+	fmt.Printf("%d\n", name)
+}
+`,
+	})
+
+	result := runTool(t, NewVetTool(), VetInput{Dir: dir}).(VetResult)
+	assert.False(t, result.Success)
+	assert.Empty(t, result.Errors)
+	require.Len(t, result.Findings, 1)
+	finding := result.Findings[0]
+	assert.Equal(t, "example.com/m/report", finding.Package)
+	assert.Equal(t, "printf", finding.Analyzer)
+	assert.Equal(t, "report/report.go", finding.File)
+	assert.Equal(t, 6, finding.Line)
+	assert.Equal(t, 14, finding.Column)
+	assert.Contains(t, finding.Message, "format %d has arg name of wrong type string")
+}
+
+func TestVetSeparatesTypeErrors(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"bad/bad.go": "package bad\n\nfunc Broken() int {\n\treturn missing\n}\n",
+	})
+
+	result := runTool(t, NewVetTool(), VetInput{Dir: dir}).(VetResult)
+	assert.False(t, result.Success)
+	assert.Empty(t, result.Findings)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "bad/bad.go", result.Errors[0].File)
+	assert.Equal(t, 4, result.Errors[0].Line)
+	assert.Contains(t, result.Errors[0].Message, "undefined: missing")
+}
+
+func TestVetClean(t *testing.T) {
+	dir := newTestModule(t, map[string]string{
+		"clean/clean.go": "package clean\n\nfunc OK() {}\n",
+	})
+
+	result := runTool(t, NewVetTool(), VetInput{Dir: dir}).(VetResult)
+	assert.True(t, result.Success)
+	assert.Empty(t, result.Findings)
+	assert.Empty(t, result.Errors)
+	assert.Empty(t, result.Output)
+}
+
+func TestParseVetOutput(t *testing.T) {
+	output := "# example.com/m/bad\n" +
+		"vet: bad/bad.go:4:9: undefined: missing\n" +
+		"{}\n" +
+		"{\n" +
+		"\t\"example.com/m/a\": {\n" +
+		"\t\t\"unusedresult\": [\n" +
+		"\t\t\t{\"posn\": \"/src/a/a.go:3:2\", \"message\": \"result of fmt.Sprint call not used\"}\n" +
+		"\t\t],\n" +
+		"\t\t\"buildtag\": {\"error\": \"analysis failed\"}\n" +
+		"\t}\n" +
+		"}\n"
+
+	result := parseVetOutput(output)
+	assert.Equal(t, []VetFinding{{
+		Diagnostic: Diagnostic{Package: "example.com/m/a", File: "/src/a/a.go", Line: 3, Column: 2, Message: "result of fmt.Sprint call not used"},
+		Analyzer:   "unusedresult",
+	}}, result.Findings)
+	assert.Equal(t, []Diagnostic{{Package: "example.com/m/bad", File: "bad/bad.go", Line: 4, Column: 9, Message: "undefined: missing"}}, result.Errors)
+	assert.Equal(t, "analysis failed", result.Output)
+}
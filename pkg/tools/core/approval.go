@@ -0,0 +1,35 @@
+package core
+
+import (
+	"context"
+)
+
+// ApprovalRequest asks the user whether a tool may go ahead with an action
+type ApprovalRequest struct {
+	Tool   string // Name of the tool asking
+	Action string // What the tool wants to do, e.g. the command it would run
+	Reason string // Why approval is needed
+}
+
+// ApprovalFunc asks the user to approve a request and reports the answer.
+// It returns false if ctx is done before the user answers.
+type ApprovalFunc func(ctx context.Context, req ApprovalRequest) bool
+
+// approvalKey is the context key for the approval callback
+type approvalKey struct{}
+
+// WithApproval returns a context that sends approval requests to fn
+func WithApproval(ctx context.Context, fn ApprovalFunc) context.Context {
+	return context.WithValue(ctx, approvalKey{}, fn)
+}
+
+// RequestApproval asks the user registered on ctx to approve a request. The
+// second result is false if there is no one to ask, e.g. when tools are
+// served to another program.
+func RequestApproval(ctx context.Context, req ApprovalRequest) (approved bool, asked bool) {
+	fn, ok := ctx.Value(approvalKey{}).(ApprovalFunc)
+	if !ok || fn == nil {
+		return false, false
+	}
+	return fn(ctx, req), true
+}
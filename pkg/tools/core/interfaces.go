@@ -1,9 +1,12 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/navicore/mcpterm-go/pkg/backend"
+	"github.com/navicore/mcpterm-go/pkg/tools/policy"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
 )
 
 // PermissionLevel defines access rights for a tool category
@@ -29,8 +32,38 @@ type Tool interface {
 	// InputSchema returns the JSON schema for the tool's input
 	InputSchema() map[string]interface{}
 
-	// Execute performs the tool operation with given input
-	Execute(input json.RawMessage) (interface{}, error)
+	// Execute performs the tool operation with given input.
+	// Implementations must stop promptly and return ctx.Err() when ctx is done.
+	Execute(ctx context.Context, input json.RawMessage) (interface{}, error)
+}
+
+// FileMutator is implemented by tools that modify files on disk.
+// The tool manager uses it to checkpoint affected paths before execution.
+type FileMutator interface {
+	// AffectedPaths returns the paths the given input would modify
+	AffectedPaths(input json.RawMessage) ([]string, error)
+}
+
+// PermissionedTool is implemented by tools that declare their own permission
+// level instead of inheriting the level of their category
+type PermissionedTool interface {
+	// Permission returns the permission level of the tool
+	Permission() PermissionLevel
+}
+
+// CommandRunner is implemented by tools that run shell commands. They check
+// each command against the policy set by the tool manager before running it.
+type CommandRunner interface {
+	// SetCommandPolicy sets the policy commands are checked against; nil allows all commands
+	SetCommandPolicy(p *policy.Policy)
+}
+
+// Sandboxed is implemented by tools that start processes. The tool manager
+// sets the sandbox profile of the tool's category, which the tool applies to
+// every process it starts.
+type Sandboxed interface {
+	// SetSandboxProfile sets the profile applied to processes; nil restricts nothing
+	SetSandboxProfile(p *sandbox.Profile)
 }
 
 // BaseToolImpl provides common functionality for tool implementations
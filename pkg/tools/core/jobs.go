@@ -0,0 +1,22 @@
+package core
+
+import "time"
+
+// Job describes a process a tool started in the background
+type Job struct {
+	ID        int        `json:"id"`
+	Command   string     `json:"command"`
+	Dir       string     `json:"working_dir,omitempty"`
+	PID       int        `json:"pid"`
+	Running   bool       `json:"running"`
+	ExitCode  int        `json:"exit_code"` // Meaningful once the job has stopped
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+// JobLister is implemented by tools that run background jobs, so the
+// user interface can show what is running
+type JobLister interface {
+	// Jobs returns the jobs started in this session, oldest first
+	Jobs() []Job
+}
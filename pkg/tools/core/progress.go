@@ -0,0 +1,38 @@
+package core
+
+import (
+	"context"
+)
+
+// Progress describes an update reported by a running tool
+type Progress struct {
+	Tool    string // Name of the tool reporting progress
+	Message string // Human readable description of the current step
+	Current int    // Units of work completed so far
+	Total   int    // Total units of work, or 0 if unknown
+}
+
+// ProgressFunc receives progress updates from running tools
+type ProgressFunc func(Progress)
+
+// progressKey is the context key for the progress callback
+type progressKey struct{}
+
+// WithProgress returns a context that delivers tool progress updates to fn
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ReportProgress sends a progress update to the callback registered on ctx, if any
+func ReportProgress(ctx context.Context, message string, current, total int) {
+	fn, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok || fn == nil {
+		return
+	}
+
+	fn(Progress{
+		Message: message,
+		Current: current,
+		Total:   total,
+	})
+}
@@ -0,0 +1,166 @@
+// Package external runs tools implemented by executables declared in the
+// configuration file. A tool receives its input as JSON on stdin and writes
+// its result as JSON to stdout; a non-zero exit status reports a failure.
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
+)
+
+// DefaultCategory is the category of external tools that do not name one
+const DefaultCategory = "external"
+
+// waitDelay is how long to wait for output after the executable is killed
+const waitDelay = time.Second
+
+// maxErrorOutput is the amount of stderr or stdout included in error messages
+const maxErrorOutput = 2000
+
+// validName matches the tool names accepted by the model API
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Spec declares an external tool
+type Spec struct {
+	Name        string                 // Tool name as seen by the model
+	Description string                 // Description shown to the model
+	InputSchema map[string]interface{} // JSON schema of the input (default accepts any object)
+	Command     string                 // Executable to run
+	Args        []string               // Command line arguments
+	Env         map[string]string      // Extra environment variables
+	Dir         string                 // Working directory (default is the current directory)
+	Category    string                 // Category to register the tool in (default "external")
+	Permission  core.PermissionLevel   // Permission level of the tool (default execute)
+}
+
+// Validate checks the spec and fills in defaults
+func (s *Spec) Validate() error {
+	if !validName.MatchString(s.Name) {
+		return fmt.Errorf("external tool name %q must be 1-64 letters, digits, '_' or '-'", s.Name)
+	}
+	if s.Command == "" {
+		return fmt.Errorf("external tool %s has no command", s.Name)
+	}
+	if s.Description == "" {
+		return fmt.Errorf("external tool %s has no description", s.Name)
+	}
+
+	if s.InputSchema == nil {
+		s.InputSchema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	if schemaType, ok := s.InputSchema["type"]; ok && schemaType != "object" {
+		return fmt.Errorf("input schema of external tool %s must have type object, not %v", s.Name, schemaType)
+	}
+
+	if s.Category == "" {
+		s.Category = DefaultCategory
+	}
+
+	switch s.Permission {
+	case "":
+		s.Permission = core.PermissionExecute
+	case core.PermissionReadOnly, core.PermissionReadWrite, core.PermissionExecute:
+	default:
+		return fmt.Errorf("external tool %s has unknown permission %q (use read-only, read-write or execute)", s.Name, s.Permission)
+	}
+
+	return nil
+}
+
+// Tool runs an external executable
+type Tool struct {
+	*core.BaseToolImpl
+	sandbox.Holder
+	spec Spec
+}
+
+// NewTool creates a tool from a spec, which must have been validated
+func NewTool(spec Spec) *Tool {
+	return &Tool{
+		BaseToolImpl: core.NewBaseTool(spec.Name, spec.Description, spec.Category, spec.InputSchema),
+		spec:         spec,
+	}
+}
+
+// Permission returns the permission level declared for the tool
+func (t *Tool) Permission() core.PermissionLevel {
+	return t.spec.Permission
+}
+
+// Execute runs the executable with input on stdin and returns the JSON it writes to stdout
+func (t *Tool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(input) == 0 {
+		input = json.RawMessage("{}")
+	}
+
+	cmd := exec.CommandContext(ctx, t.spec.Command, t.spec.Args...)
+	// Children of a killed script may keep its output open; stop waiting for them
+	cmd.WaitDelay = waitDelay
+	cmd.Dir = t.spec.Dir
+	cmd.Env = append(os.Environ(), "MCPTERM_TOOL="+t.spec.Name)
+	for key, value := range t.spec.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	if err := t.SandboxProfile().Prepare(cmd); err != nil {
+		return nil, fmt.Errorf("cannot apply the sandbox profile to %s: %w", t.spec.Name, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("%s exited with status %d: %s", t.spec.Name, exitErr.ExitCode(), failureOutput(stderr.String(), stdout.String()))
+		}
+		return nil, fmt.Errorf("failed to run %s: %w", t.spec.Command, err)
+	}
+
+	output := bytes.TrimSpace(stdout.Bytes())
+	if !json.Valid(output) {
+		return nil, fmt.Errorf("%s did not write valid JSON to stdout: %s", t.spec.Name, truncate(string(output)))
+	}
+
+	return json.RawMessage(output), nil
+}
+
+// failureOutput picks the most useful output to explain a failure
+func failureOutput(stderr, stdout string) string {
+	if strings.TrimSpace(stderr) != "" {
+		return truncate(stderr)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		return truncate(stdout)
+	}
+	return "no output"
+}
+
+// truncate shortens output for error messages, keeping the end where errors usually are
+func truncate(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxErrorOutput {
+		return "..." + output[len(output)-maxErrorOutput:]
+	}
+	return output
+}
@@ -0,0 +1,94 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shellSpec returns a validated spec running script with sh
+func shellSpec(t *testing.T, script string) Spec {
+	t.Helper()
+
+	spec := Spec{Name: "script", Description: "Runs a test script", Command: "sh", Args: []string{"-c", script}}
+	require.NoError(t, spec.Validate())
+	return spec
+}
+
+func TestSpecValidate(t *testing.T) {
+	spec := Spec{Name: "lookup", Description: "Look up a ticket", Command: "lookup-ticket"}
+	require.NoError(t, spec.Validate())
+	assert.Equal(t, DefaultCategory, spec.Category)
+	assert.Equal(t, core.PermissionExecute, spec.Permission)
+	assert.Equal(t, "object", spec.InputSchema["type"])
+
+	tests := []struct {
+		name    string
+		spec    Spec
+		wantErr string
+	}{
+		{name: "InvalidName", spec: Spec{Name: "has space", Description: "d", Command: "c"}, wantErr: "must be 1-64"},
+		{name: "NoCommand", spec: Spec{Name: "t", Description: "d"}, wantErr: "has no command"},
+		{name: "NoDescription", spec: Spec{Name: "t", Command: "c"}, wantErr: "has no description"},
+		{name: "SchemaNotObject", spec: Spec{Name: "t", Description: "d", Command: "c", InputSchema: map[string]interface{}{"type": "string"}}, wantErr: "must have type object"},
+		{name: "UnknownPermission", spec: Spec{Name: "t", Description: "d", Command: "c", Permission: "admin"}, wantErr: "unknown permission"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.spec.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestToolExecute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("InputOnStdinResultOnStdout", func(t *testing.T) {
+		tool := NewTool(shellSpec(t, `read input; echo "{\"received\": $input, \"tool\": \"$MCPTERM_TOOL\"}"`))
+
+		result, err := tool.Execute(ctx, json.RawMessage(`{"id": 42}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"received": {"id": 42}, "tool": "script"}`, string(result.(json.RawMessage)))
+	})
+
+	t.Run("NonZeroExitReportsStderr", func(t *testing.T) {
+		tool := NewTool(shellSpec(t, `echo "ticket not found" >&2; exit 4`))
+
+		_, err := tool.Execute(ctx, json.RawMessage(`{}`))
+		assert.ErrorContains(t, err, "exited with status 4: ticket not found")
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		tool := NewTool(shellSpec(t, `echo "not json"`))
+
+		_, err := tool.Execute(ctx, json.RawMessage(`{}`))
+		assert.ErrorContains(t, err, "did not write valid JSON to stdout: not json")
+	})
+
+	t.Run("MissingExecutable", func(t *testing.T) {
+		spec := Spec{Name: "missing", Description: "d", Command: "/nonexistent/tool"}
+		require.NoError(t, spec.Validate())
+
+		_, err := NewTool(spec).Execute(ctx, json.RawMessage(`{}`))
+		assert.ErrorContains(t, err, "failed to run /nonexistent/tool")
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		tool := NewTool(shellSpec(t, `sleep 10`))
+
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := tool.Execute(ctx, json.RawMessage(`{}`))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected deadline error, got %v", err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
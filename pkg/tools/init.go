@@ -16,6 +16,21 @@ func InitializeTools(registry *Registry) error {
 		return fmt.Errorf("failed to register development tools: %w", err)
 	}
 
+	// Load git tools
+	if err := registerGitTools(registry); err != nil {
+		return fmt.Errorf("failed to register git tools: %w", err)
+	}
+
+	// Load Go tools
+	if err := registerGoTools(registry); err != nil {
+		return fmt.Errorf("failed to register go tools: %w", err)
+	}
+
+	// Load code intelligence tools
+	if err := registerCodeTools(registry); err != nil {
+		return fmt.Errorf("failed to register code tools: %w", err)
+	}
+
 	// Customer support tools would be added here
 	// if err := registerCustomerSupportTools(registry); err != nil {
 	//     return fmt.Errorf("failed to register customer_support tools: %w", err)
@@ -1,13 +1,21 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/navicore/mcpterm-go/pkg/backend"
+	"github.com/navicore/mcpterm-go/pkg/checkpoint"
 	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/external"
+	"github.com/navicore/mcpterm-go/pkg/tools/policy"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
 )
 
 // ToolManager handles tool execution and permissions
@@ -17,6 +25,24 @@ type ToolManager struct {
 	enabledCats    map[string]bool
 	toolsEnabled   bool
 	maxToolsPerMsg int
+	checkpoints    *checkpoint.Store
+	timeouts       TimeoutConfig
+	progress       core.ProgressFunc
+	approval       core.ApprovalFunc
+	validator      *SchemaValidator
+	closers        []io.Closer
+}
+
+// DefaultToolTimeout is the execution limit applied when no other timeout is configured
+const DefaultToolTimeout = 2 * time.Minute
+
+// TimeoutConfig defines how long tools may run before they are cancelled.
+// A tool timeout takes precedence over its category timeout, which takes
+// precedence over the default. Zero values are ignored.
+type TimeoutConfig struct {
+	Default    time.Duration
+	Categories map[string]time.Duration
+	Tools      map[string]time.Duration
 }
 
 // NewToolManager creates a new tool manager with default settings
@@ -26,6 +52,8 @@ func NewToolManager() *ToolManager {
 		enabledCats:    make(map[string]bool),
 		toolsEnabled:   true, // Enabled by default
 		maxToolsPerMsg: 10,   // Default limit
+		timeouts:       TimeoutConfig{Default: DefaultToolTimeout},
+		validator:      NewSchemaValidator(),
 	}
 }
 
@@ -109,8 +137,9 @@ func (tm *ToolManager) GetTools() []backend.ClaudeTool {
 	return tm.registry.GetEnabledTools()
 }
 
-// HandleToolUse processes a tool use request
-func (tm *ToolManager) HandleToolUse(toolUse *core.ToolUse) (*core.ToolResult, error) {
+// HandleToolUse processes a tool use request. The tool runs until it finishes,
+// ctx is cancelled, or its configured timeout expires.
+func (tm *ToolManager) HandleToolUse(ctx context.Context, toolUse *core.ToolUse) (*core.ToolResult, error) {
 	if !tm.IsToolsEnabled() {
 		return nil, fmt.Errorf("tool use is disabled")
 	}
@@ -125,9 +154,42 @@ func (tm *ToolManager) HandleToolUse(toolUse *core.ToolUse) (*core.ToolResult, e
 		return nil, fmt.Errorf("error finding tool %s: %w", toolUse.Name, err)
 	}
 
+	// Reject input that does not match the tool's schema before running anything
+	if err := tm.validator.Validate(tool, toolUse.Input); err != nil {
+		return nil, err
+	}
+
+	// Tools allowed more than their category need the user's approval
+	if err := tm.authorize(ctx, tool, toolUse.Input); err != nil {
+		return nil, err
+	}
+
+	// Snapshot any files the tool is about to modify
+	if err := tm.checkpoint(tool, toolUse.Input); err != nil {
+		return nil, fmt.Errorf("error checkpointing files for tool %s: %w", toolUse.Name, err)
+	}
+
+	// Bound the execution time and forward progress updates
+	timeout := tm.TimeoutFor(tool)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if progress := tm.getProgressHandler(); progress != nil {
+		ctx = core.WithProgress(ctx, func(p core.Progress) {
+			p.Tool = tool.Name()
+			progress(p)
+		})
+	}
+	if approval := tm.getApprovalHandler(); approval != nil {
+		ctx = core.WithApproval(ctx, approval)
+	}
+
 	// Execute the tool
-	result, err := tool.Execute(toolUse.Input)
+	result, err := tool.Execute(ctx, toolUse.Input)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("tool %s timed out after %s: %w", toolUse.Name, timeout, err)
+		}
 		return nil, fmt.Errorf("error executing tool %s: %w", toolUse.Name, err)
 	}
 
@@ -144,6 +206,133 @@ func (tm *ToolManager) HandleToolUse(toolUse *core.ToolUse) (*core.ToolResult, e
 	}, nil
 }
 
+// checkpoint snapshots the paths a file-modifying tool is about to touch
+func (tm *ToolManager) checkpoint(tool core.Tool, input json.RawMessage) error {
+	store := tm.GetCheckpointStore()
+	if store == nil {
+		return nil
+	}
+
+	mutator, ok := tool.(core.FileMutator)
+	if !ok {
+		return nil
+	}
+
+	paths, err := mutator.AffectedPaths(input)
+	if err != nil || len(paths) == 0 {
+		// Invalid input is reported by the tool itself
+		return nil
+	}
+
+	return store.Snapshot(tool.Name(), paths)
+}
+
+// SetCheckpointStore sets the store used to snapshot files before tools modify them
+func (tm *ToolManager) SetCheckpointStore(store *checkpoint.Store) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.checkpoints = store
+}
+
+// GetCheckpointStore returns the checkpoint store, or nil if checkpoints are disabled
+func (tm *ToolManager) GetCheckpointStore() *checkpoint.Store {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	return tm.checkpoints
+}
+
+// BeginTurn marks the start of a conversation turn for checkpointing
+func (tm *ToolManager) BeginTurn(prompt string) {
+	if store := tm.GetCheckpointStore(); store != nil {
+		store.BeginTurn(prompt)
+	}
+}
+
+// SetTimeouts sets the execution time limits for tools
+func (tm *ToolManager) SetTimeouts(timeouts TimeoutConfig) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if timeouts.Default <= 0 {
+		timeouts.Default = DefaultToolTimeout
+	}
+	tm.timeouts = timeouts
+}
+
+// TimeoutFor returns the execution time limit that applies to a tool
+func (tm *ToolManager) TimeoutFor(tool core.Tool) time.Duration {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if timeout := tm.timeouts.Tools[tool.Name()]; timeout > 0 {
+		return timeout
+	}
+	if timeout := tm.timeouts.Categories[tool.Category()]; timeout > 0 {
+		return timeout
+	}
+	return tm.timeouts.Default
+}
+
+// SetProgressHandler sets the callback that receives progress updates from running tools
+func (tm *ToolManager) SetProgressHandler(fn core.ProgressFunc) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.progress = fn
+}
+
+// getProgressHandler returns the progress callback, or nil if none is set
+func (tm *ToolManager) getProgressHandler() core.ProgressFunc {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	return tm.progress
+}
+
+// SetApprovalHandler sets the callback that asks the user to approve actions
+// tools are not allowed to take on their own, such as commands the command
+// policy marks "ask". Without one, such actions are refused.
+func (tm *ToolManager) SetApprovalHandler(fn core.ApprovalFunc) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.approval = fn
+}
+
+// getApprovalHandler returns the approval callback, or nil if none is set
+func (tm *ToolManager) getApprovalHandler() core.ApprovalFunc {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	return tm.approval
+}
+
+// SetCommandPolicy sets the policy that tools running shell commands check
+// commands against; nil allows all commands
+func (tm *ToolManager) SetCommandPolicy(p *policy.Policy) {
+	for _, runner := range tm.registry.commandRunners() {
+		runner.SetCommandPolicy(p)
+	}
+}
+
+// SetSandbox applies the sandbox profile of each category to the tools in it
+// that start processes. Tools in categories without a profile are not restricted.
+func (tm *ToolManager) SetSandbox(config sandbox.Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	for categoryID, tools := range tm.registry.sandboxed() {
+		profile := config.ForCategory(categoryID)
+		for _, tool := range tools {
+			tool.SetSandboxProfile(profile)
+		}
+	}
+	return nil
+}
+
 // SetMaxToolsPerMsg sets the maximum number of tool calls allowed per message
 func (tm *ToolManager) SetMaxToolsPerMsg(max int) {
 	tm.mu.Lock()
@@ -162,7 +351,162 @@ func (tm *ToolManager) GetMaxToolsPerMsg() int {
 	return tm.maxToolsPerMsg
 }
 
+// RegisterCategory adds a tool category, e.g. for tools discovered at runtime
+func (tm *ToolManager) RegisterCategory(cat *Category) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if err := tm.registry.RegisterCategory(cat); err != nil {
+		return err
+	}
+
+	tm.enabledCats[cat.ID] = cat.Enabled
+	return nil
+}
+
+// Jobs returns the background jobs started by tools, whether or not they are still running
+func (tm *ToolManager) Jobs() []core.Job {
+	return tm.registry.jobs()
+}
+
+// AddCloser registers a resource, such as a server process backing some
+// tools, to be released when the manager is closed
+func (tm *ToolManager) AddCloser(c io.Closer) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.closers = append(tm.closers, c)
+}
+
+// Close releases the resources registered with AddCloser, newest first,
+// and then those held by tools, such as a running shell session
+func (tm *ToolManager) Close() error {
+	tm.mu.Lock()
+	closers := append(tm.registry.closers(), tm.closers...)
+	tm.closers = nil
+	tm.mu.Unlock()
+
+	var firstErr error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// LoadExternalTools registers tools implemented by external executables.
+// A tool's category is created, enabled, if it does not exist yet; the IDs
+// of created categories are returned. All specs are checked before any tool
+// is registered.
+func (tm *ToolManager) LoadExternalTools(specs []external.Spec) ([]string, error) {
+	seen := make(map[string]bool)
+	loaded := make([]*external.Tool, 0, len(specs))
+	for _, spec := range specs {
+		if err := spec.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("external tool %s is declared more than once", spec.Name)
+		}
+		if existing, _ := tm.registry.lookup(spec.Name); existing != nil {
+			return nil, fmt.Errorf("external tool %s conflicts with an existing tool", spec.Name)
+		}
+		seen[spec.Name] = true
+
+		tool := external.NewTool(spec)
+		if err := tm.validator.Check(tool); err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, tool)
+	}
+
+	var created []string
+	for _, tool := range loaded {
+		if !tm.registry.hasCategory(tool.Category()) {
+			err := tm.RegisterCategory(&Category{
+				ID:          tool.Category(),
+				Name:        "External Tools: " + tool.Category(),
+				Description: "Tools provided by executables declared in the configuration",
+				Enabled:     true,
+				Permission:  tool.Permission(),
+				Tools:       []core.Tool{},
+			})
+			if err != nil {
+				return created, err
+			}
+			created = append(created, tool.Category())
+		}
+
+		if err := tm.registry.RegisterTool(tool.Category(), tool); err != nil {
+			return created, err
+		}
+	}
+
+	return created, nil
+}
+
+// PermissionFor returns the permission level of a tool: its own if it
+// declares one, otherwise that of its category
+func (tm *ToolManager) PermissionFor(tool core.Tool) core.PermissionLevel {
+	if permissioned, ok := tool.(core.PermissionedTool); ok {
+		return permissioned.Permission()
+	}
+
+	if _, cat := tm.registry.lookup(tool.Name()); cat != nil {
+		return cat.Permission
+	}
+	return core.PermissionExecute
+}
+
+// permissionRanks orders permission levels from least to most powerful
+var permissionRanks = map[core.PermissionLevel]int{
+	core.PermissionReadOnly:  0,
+	core.PermissionReadWrite: 1,
+	core.PermissionExecute:   2,
+}
+
+// maxApprovalInputBytes is the amount of tool input shown when asking for approval
+const maxApprovalInputBytes = 200
+
+// authorize returns nil if a tool may run. Enabling a category grants its
+// permission level to its tools; a tool with a higher level, such as
+// git_commit in the read-only git category or an external tool declared
+// "execute" in the filesystem category, runs only if the user approves
+// each call.
+func (tm *ToolManager) authorize(ctx context.Context, tool core.Tool, input json.RawMessage) error {
+	_, cat := tm.registry.lookup(tool.Name())
+	if cat == nil {
+		return nil
+	}
+	level := tm.PermissionFor(tool)
+	if permissionRanks[level] <= permissionRanks[cat.Permission] {
+		return nil
+	}
+
+	action := string(input)
+	if len(action) > maxApprovalInputBytes {
+		action = action[:maxApprovalInputBytes] + "..."
+	}
+	reason := fmt.Sprintf("%s has %s permission, more than the %s permission of the %s category", tool.Name(), level, cat.Permission, cat.ID)
+
+	approval := tm.getApprovalHandler()
+	if approval == nil {
+		return fmt.Errorf("%s requires the user's approval (%s), but there is no user to ask; "+
+			"tell the user what you wanted to do instead", tool.Name(), reason)
+	}
+	approved := approval(ctx, core.ApprovalRequest{Tool: tool.Name(), Action: action, Reason: reason})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !approved {
+		return fmt.Errorf("the user declined to run %s; do not retry it, ask the user how to proceed", tool.Name())
+	}
+	return nil
+}
+
 // RegisterTool registers a new tool with the manager
 func (tm *ToolManager) RegisterTool(categoryID string, tool core.Tool) error {
+	tm.validator.Forget(tool.Name())
 	return tm.registry.RegisterTool(categoryID, tool)
 }
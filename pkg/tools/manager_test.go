@@ -0,0 +1,282 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/navicore/mcpterm-go/pkg/tools/external"
+	"github.com/navicore/mcpterm-go/pkg/tools/policy"
+	"github.com/navicore/mcpterm-go/pkg/tools/sandbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadExternalTools(t *testing.T) {
+	manager, err := Initialize()
+	require.NoError(t, err)
+
+	created, err := manager.LoadExternalTools([]external.Spec{
+		{
+			Name:        "ticket_lookup",
+			Description: "Look up a ticket",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"id": map[string]interface{}{"type": "integer"}},
+				"required":   []string{"id"},
+			},
+			Command:    "sh",
+			Args:       []string{"-c", `cat`},
+			Category:   "team",
+			Permission: core.PermissionReadOnly,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team"}, created)
+
+	t.Run("Call", func(t *testing.T) {
+		result, err := manager.HandleToolUse(context.Background(), &core.ToolUse{
+			Name:  "ticket_lookup",
+			Input: json.RawMessage(`{"id": 7}`),
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id": 7}`, string(result.Result))
+	})
+
+	t.Run("InputValidated", func(t *testing.T) {
+		_, err := manager.HandleToolUse(context.Background(), &core.ToolUse{
+			Name:  "ticket_lookup",
+			Input: json.RawMessage(`{"id": "seven"}`),
+		})
+		var validationErr *InputValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("Permission", func(t *testing.T) {
+		tool, _ := manager.registry.lookup("ticket_lookup")
+		assert.Equal(t, core.PermissionReadOnly, manager.PermissionFor(tool))
+
+		shell, _ := manager.registry.lookup("shell")
+		assert.Equal(t, core.PermissionReadWrite, manager.PermissionFor(shell))
+	})
+
+	t.Run("PermissionAboveCategory", func(t *testing.T) {
+		_, err := manager.LoadExternalTools([]external.Spec{{
+			Name:        "ticket_close",
+			Description: "Close a ticket",
+			Command:     "sh",
+			Args:        []string{"-c", `cat`},
+			Category:    "team",
+			Permission:  core.PermissionExecute,
+		}})
+		require.NoError(t, err)
+		defer manager.SetApprovalHandler(nil)
+
+		call := func() error {
+			_, err := manager.HandleToolUse(context.Background(), &core.ToolUse{
+				Name:  "ticket_close",
+				Input: json.RawMessage(`{"id": 7}`),
+			})
+			return err
+		}
+
+		// The read-only category does not grant execute, so each call needs approval
+		assert.ErrorContains(t, call(), "no user to ask")
+
+		var asked []core.ApprovalRequest
+		manager.SetApprovalHandler(func(ctx context.Context, req core.ApprovalRequest) bool {
+			asked = append(asked, req)
+			return len(asked) == 1
+		})
+		require.NoError(t, call())
+		require.Len(t, asked, 1)
+		assert.Equal(t, "ticket_close", asked[0].Tool)
+		assert.Equal(t, `{"id": 7}`, asked[0].Action)
+		assert.Contains(t, asked[0].Reason, "more than the read-only permission of the team category")
+
+		assert.ErrorContains(t, call(), "declined")
+
+		// Tools within their category's level are not asked about
+		_, err = manager.HandleToolUse(context.Background(), &core.ToolUse{
+			Name:  "ticket_lookup",
+			Input: json.RawMessage(`{"id": 7}`),
+		})
+		require.NoError(t, err)
+		assert.Len(t, asked, 2)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			specs   []external.Spec
+			wantErr string
+		}{
+			{
+				name:    "ConflictsWithBuiltin",
+				specs:   []external.Spec{{Name: "grep", Description: "d", Command: "grep"}},
+				wantErr: "conflicts with an existing tool",
+			},
+			{
+				name: "Duplicate",
+				specs: []external.Spec{
+					{Name: "twice", Description: "d", Command: "c"},
+					{Name: "twice", Description: "d", Command: "c"},
+				},
+				wantErr: "declared more than once",
+			},
+			{
+				name: "InvalidSchema",
+				specs: []external.Spec{{Name: "bad_schema", Description: "d", Command: "c", InputSchema: map[string]interface{}{
+					"type":     "object",
+					"required": "id",
+				}}},
+				wantErr: "invalid input schema",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := manager.LoadExternalTools(tt.specs)
+				assert.ErrorContains(t, err, tt.wantErr)
+			})
+		}
+	})
+}
+
+func TestCommandPolicy(t *testing.T) {
+	manager, err := Initialize()
+	require.NoError(t, err)
+	defer manager.Close()
+	require.NoError(t, manager.EnableCategoriesByIDs([]string{"development"}))
+
+	commandPolicy, err := policy.New(policy.Config{
+		Rules: []policy.Rule{
+			{Action: policy.Deny, Command: "touch", Reason: "no new files"},
+			{Action: policy.Ask, Command: "echo"},
+		},
+	})
+	require.NoError(t, err)
+	manager.SetCommandPolicy(commandPolicy)
+
+	run := func(name, input string) (*core.ToolResult, error) {
+		return manager.HandleToolUse(context.Background(), &core.ToolUse{Name: name, Input: json.RawMessage(input)})
+	}
+
+	// Every tool that runs commands enforces the policy
+	for _, call := range [][2]string{
+		{"shell", `{"command": "touch", "args": ["x"]}`},
+		{"shell_session", `{"command": "true && touch x"}`},
+		{"job_start", `{"command": "touch x"}`},
+	} {
+		_, err := run(call[0], call[1])
+		require.Error(t, err, call[0])
+		assert.Contains(t, err.Error(), "no new files", call[0])
+	}
+
+	// Lines written to a job's stdin are checked too, as the job may be a
+	// shell, including a command split across calls
+	result, err := run("job_start", `{"command": "cat"}`)
+	require.NoError(t, err)
+	var job core.Job
+	require.NoError(t, json.Unmarshal(result.Result, &job))
+	input := func(text string) error {
+		_, err := run("job_input", fmt.Sprintf(`{"id": %d, "input": %q}`, job.ID, text))
+		return err
+	}
+	require.NoError(t, input("ls\n"))
+	assert.ErrorContains(t, input("touch x\n"), "no new files")
+	require.NoError(t, input("tou"))
+	assert.ErrorContains(t, input("ch x\n"), "no new files")
+
+	// Without an approval handler, commands that need approval are refused
+	_, err = run("shell", `{"command": "echo", "args": ["hi"]}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no user to ask")
+
+	var asked []core.ApprovalRequest
+	manager.SetApprovalHandler(func(ctx context.Context, req core.ApprovalRequest) bool {
+		asked = append(asked, req)
+		return len(asked) == 1
+	})
+
+	result, err = run("shell", `{"command": "echo", "args": ["hi"]}`)
+	require.NoError(t, err)
+	assert.Contains(t, string(result.Result), "hi")
+	require.Len(t, asked, 1)
+	assert.Equal(t, "shell", asked[0].Tool)
+	assert.Equal(t, "echo hi", asked[0].Action)
+
+	_, err = run("shell", `{"command": "echo", "args": ["again"]}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "declined")
+}
+
+func TestGitWritesNeedApproval(t *testing.T) {
+	manager, err := Initialize()
+	require.NoError(t, err)
+	defer manager.Close()
+	require.NoError(t, manager.EnableCategoriesByIDs([]string{"git"}))
+
+	input := json.RawMessage(`{"repo_path": "` + t.TempDir() + `", "all": true}`)
+	run := func(name string) error {
+		_, err := manager.HandleToolUse(context.Background(), &core.ToolUse{Name: name, Input: input})
+		return err
+	}
+
+	// git_stage and git_commit modify the repository, which the read-only category does not allow
+	assert.ErrorContains(t, run("git_stage"), "no user to ask")
+
+	var asked []core.ApprovalRequest
+	manager.SetApprovalHandler(func(ctx context.Context, req core.ApprovalRequest) bool {
+		asked = append(asked, req)
+		return false
+	})
+	assert.ErrorContains(t, run("git_stage"), "declined")
+	require.Len(t, asked, 1)
+	assert.Equal(t, "git_stage", asked[0].Tool)
+	assert.Contains(t, asked[0].Reason, "read-write permission, more than the read-only permission of the git category")
+
+	// Read-only git tools run without asking
+	assert.ErrorContains(t, run("git_status"), "not a git repository")
+	assert.Len(t, asked, 1)
+}
+
+func TestSandboxProfilesByCategory(t *testing.T) {
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	manager, err := Initialize()
+	require.NoError(t, err)
+	defer manager.Close()
+	require.NoError(t, manager.EnableCategoriesByIDs([]string{"development"}))
+
+	_, err = manager.LoadExternalTools([]external.Spec{{
+		Name:        "show_env",
+		Description: "Show the environment",
+		Command:     "sh",
+		Args:        []string{"-c", `printf '{"key": "%s"}' "$AWS_SECRET_ACCESS_KEY"`},
+	}})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.SetSandbox(sandbox.Config{
+		Profiles:   map[string]sandbox.Profile{"no-cloud": {ScrubEnv: []string{"AWS_*"}}},
+		Categories: map[string]string{"development": "no-cloud"},
+	}))
+
+	run := func(name, input string) string {
+		result, err := manager.HandleToolUse(context.Background(), &core.ToolUse{Name: name, Input: json.RawMessage(input)})
+		require.NoError(t, err, name)
+		return string(result.Result)
+	}
+
+	// Every tool in the development category runs without the scrubbed variables
+	assert.NotContains(t, run("shell", `{"command": "env"}`), "AWS_SECRET_ACCESS_KEY")
+	assert.NotContains(t, run("shell_session", `{"command": "env"}`), "AWS_SECRET_ACCESS_KEY")
+
+	// Tools in other categories are not restricted
+	assert.Contains(t, run("show_env", `{}`), "secret")
+
+	err = manager.SetSandbox(sandbox.Config{Categories: map[string]string{"development": "missing"}})
+	assert.ErrorContains(t, err, "unknown sandbox profile")
+}
@@ -0,0 +1,270 @@
+// Package policy decides whether tools may run a shell command. A policy is
+// an ordered list of rules matching the program, its leading words, its
+// arguments or a regular expression; the first rule that matches a command
+// decides whether it is allowed, denied or needs the user's approval.
+//
+// A command line is split into the simple commands it runs, including those
+// in pipelines, lists, subshells and command substitutions, and each is
+// checked on its own. The most restrictive decision wins. The policy is a
+// guard against mistakes, not a sandbox: a determined script can still hide
+// what it runs.
+package policy
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Action is the outcome of a rule
+type Action string
+
+const (
+	// Allow runs the command
+	Allow Action = "allow"
+
+	// Ask runs the command only if the user approves it
+	Ask Action = "ask"
+
+	// Deny refuses to run the command
+	Deny Action = "deny"
+)
+
+// severity orders actions from least to most restrictive
+var severity = map[Action]int{Allow: 0, Ask: 1, Deny: 2}
+
+// Rule matches commands and decides what happens to them. All criteria that
+// are set must match.
+type Rule struct {
+	Action  Action   // What to do with a matching command
+	Command string   // Program name, compared without its directory, e.g. "rm"
+	Prefix  string   // Leading words of the command, e.g. "git push"
+	Args    []string // Arguments that must all be present, see below
+	Pattern string   // Regular expression matched against the command text
+	Reason  string   // Explanation given to the model when the rule denies or asks
+
+	pattern *regexp.Regexp
+}
+
+// Config holds the rules of a policy and the action for commands no rule matches
+type Config struct {
+	Default Action // Action when no rule matches (default allow)
+	Rules   []Rule
+}
+
+// Policy evaluates commands against a list of rules. A nil policy allows everything.
+type Policy struct {
+	defaultAction Action
+	rules         []Rule
+}
+
+// Decision is the outcome of evaluating a command
+type Decision struct {
+	Action  Action
+	Command string // The command the decision is about
+	Rule    *Rule  // The rule that matched, or nil if the default applied
+}
+
+// Reason explains the decision, for the model or the user
+func (d Decision) Reason() string {
+	if d.Rule != nil && d.Rule.Reason != "" {
+		return d.Rule.Reason
+	}
+	if d.Rule != nil {
+		return fmt.Sprintf("it matches a rule that says %s", d.Action)
+	}
+	return fmt.Sprintf("commands that match no rule are set to %s", d.Action)
+}
+
+// New checks the rules and creates a policy
+func New(config Config) (*Policy, error) {
+	p := &Policy{defaultAction: config.Default}
+	if p.defaultAction == "" {
+		p.defaultAction = Allow
+	}
+	if _, ok := severity[p.defaultAction]; !ok {
+		return nil, fmt.Errorf("unknown default action %q (use allow, ask or deny)", config.Default)
+	}
+
+	for i, rule := range config.Rules {
+		if _, ok := severity[rule.Action]; !ok {
+			return nil, fmt.Errorf("rule %d has unknown action %q (use allow, ask or deny)", i+1, rule.Action)
+		}
+		if rule.Command == "" && rule.Prefix == "" && len(rule.Args) == 0 && rule.Pattern == "" {
+			return nil, fmt.Errorf("rule %d matches nothing: set command, prefix, args or pattern", i+1)
+		}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d has an invalid pattern: %w", i+1, err)
+			}
+			rule.pattern = pattern
+		}
+		p.rules = append(p.rules, rule)
+	}
+
+	return p, nil
+}
+
+// Evaluate decides whether a program may run with the given arguments.
+// Programs that run a script, such as "bash -c script", have the script checked too.
+func (p *Policy) Evaluate(argv []string) Decision {
+	if p == nil || len(argv) == 0 {
+		return Decision{Action: Allow, Command: strings.Join(argv, " ")}
+	}
+
+	decision := p.evaluateCommand(argv)
+	if script, ok := shellScript(argv); ok {
+		decision = stricter(decision, p.EvaluateLine(script))
+	}
+	return decision
+}
+
+// EvaluateLine decides whether a shell command line may run. Each command
+// it contains is evaluated, and the most restrictive decision is returned.
+func (p *Policy) EvaluateLine(line string) Decision {
+	if p == nil {
+		return Decision{Action: Allow, Command: line}
+	}
+
+	decision := Decision{Action: Allow, Command: line}
+
+	// Rules with only a pattern also see the whole line, e.g. to catch "curl ... | sh"
+	for i := range p.rules {
+		rule := &p.rules[i]
+		if rule.patternOnly() && rule.pattern.MatchString(line) {
+			decision = stricter(decision, Decision{Action: rule.Action, Command: line, Rule: rule})
+			break
+		}
+	}
+
+	for _, argv := range SplitCommands(line) {
+		decision = stricter(decision, p.Evaluate(argv))
+	}
+	return decision
+}
+
+// evaluateCommand applies the first matching rule to a single command
+func (p *Policy) evaluateCommand(argv []string) Decision {
+	text := strings.Join(argv, " ")
+	for i := range p.rules {
+		if p.rules[i].matches(argv, text) {
+			return Decision{Action: p.rules[i].Action, Command: text, Rule: &p.rules[i]}
+		}
+	}
+	return Decision{Action: p.defaultAction, Command: text}
+}
+
+// stricter returns the more restrictive decision, preferring a when they are equal
+func stricter(a, b Decision) Decision {
+	if severity[b.Action] > severity[a.Action] {
+		return b
+	}
+	return a
+}
+
+// patternOnly reports whether the rule matches on its pattern alone
+func (r *Rule) patternOnly() bool {
+	return r.pattern != nil && r.Command == "" && r.Prefix == "" && len(r.Args) == 0
+}
+
+// matches reports whether a command satisfies every criterion of the rule
+func (r *Rule) matches(argv []string, text string) bool {
+	if r.Command != "" && programName(argv[0]) != r.Command {
+		return false
+	}
+
+	if r.Prefix != "" {
+		words := strings.Fields(r.Prefix)
+		if len(argv) < len(words) || programName(argv[0]) != programName(words[0]) {
+			return false
+		}
+		for i := 1; i < len(words); i++ {
+			if argv[i] != words[i] {
+				return false
+			}
+		}
+	}
+
+	for _, spec := range r.Args {
+		if !hasArg(argv[1:], spec) {
+			return false
+		}
+	}
+
+	if r.pattern != nil && !r.pattern.MatchString(text) {
+		return false
+	}
+	return true
+}
+
+// hasArg reports whether args contain one of the alternatives in spec,
+// separated by "|". Short flags may be combined or given separately, so
+// "-rf" matches "-rf", "-fr" and "-r -f"; long flags also match with a
+// value, so "--force" matches "--force=true".
+func hasArg(args []string, spec string) bool {
+	for _, want := range strings.Split(spec, "|") {
+		switch {
+		case strings.HasPrefix(want, "--"):
+			for _, arg := range args {
+				if arg == want || strings.HasPrefix(arg, want+"=") {
+					return true
+				}
+			}
+		case strings.HasPrefix(want, "-") && len(want) > 1:
+			found := true
+			for _, flag := range want[1:] {
+				if !hasShortFlag(args, flag) {
+					found = false
+					break
+				}
+			}
+			if found {
+				return true
+			}
+		default:
+			for _, arg := range args {
+				if arg == want {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hasShortFlag reports whether a single letter flag appears in args, alone or combined with others
+func hasShortFlag(args []string, flag rune) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if len(arg) > 1 && arg[0] == '-' && arg[1] != '-' && strings.ContainsRune(arg[1:], flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// programName returns the name of a program without its directory
+func programName(path string) string {
+	return filepath.Base(path)
+}
+
+// shells are programs whose -c argument is a command line to check
+var shells = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true}
+
+// shellScript returns the script a shell is asked to run with -c
+func shellScript(argv []string) (string, bool) {
+	if !shells[programName(argv[0])] {
+		return "", false
+	}
+	for i := 1; i < len(argv)-1; i++ {
+		arg := argv[i]
+		if len(arg) > 1 && arg[0] == '-' && arg[1] != '-' && strings.ContainsRune(arg[1:], 'c') {
+			return argv[i+1], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,237 @@
+package policy
+
+import (
+	"regexp"
+	"strings"
+)
+
+// reservedWords are shell keywords that may precede a command, as in "if rm x; then"
+var reservedWords = map[string]bool{
+	"!": true, "{": true, "}": true, "if": true, "then": true, "else": true, "elif": true,
+	"fi": true, "do": true, "done": true, "while": true, "until": true, "time": true,
+}
+
+// wrappers are programs that run the command given in their arguments
+var wrappers = map[string]bool{
+	"sudo": true, "env": true, "nohup": true, "exec": true, "command": true,
+	"builtin": true, "nice": true, "xargs": true, "timeout": true,
+}
+
+// wrapperValueFlags are wrapper options followed by a value, e.g. "sudo -u root"
+var wrapperValueFlags = map[string]bool{"-u": true, "-g": true, "-n": true, "-C": true, "-s": true}
+
+// assignment matches a variable assignment such as FOO=bar
+var assignment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// SplitCommands splits a shell command line into the simple commands it
+// runs. Quotes and escapes are removed from words; pipelines, lists,
+// subshells and command substitutions become separate commands; variable
+// assignments, redirections and wrappers such as sudo or env are dropped so
+// each command starts with the program it runs.
+func SplitCommands(line string) [][]string {
+	l := &lexer{}
+	l.run([]rune(line))
+
+	var commands [][]string
+	for _, words := range l.commands {
+		if argv := normalize(words); len(argv) > 0 {
+			commands = append(commands, argv)
+		}
+	}
+	return commands
+}
+
+// lexerFrame is the state of a command interrupted by a command substitution
+type lexerFrame struct {
+	words    []string
+	word     strings.Builder
+	inWord   bool
+	inDouble bool
+	closer   rune // Character that ends the substitution: ')' or '`'
+}
+
+// lexer splits a command line into words grouped by command
+type lexer struct {
+	commands [][]string
+	words    []string
+	word     strings.Builder
+	inWord   bool
+	skipWord bool // The next word is the target of a redirection
+	inDouble bool
+	stack    []*lexerFrame
+}
+
+func (l *lexer) run(runes []rune) {
+	inSingle := false
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case inSingle:
+			if r == '\'' {
+				inSingle = false
+			} else {
+				l.word.WriteRune(r)
+			}
+
+		case r == '\\':
+			if next != 0 {
+				if next != '\n' {
+					l.word.WriteRune(next)
+					l.inWord = true
+				}
+				i++
+			}
+
+		case r == '$' && next == '(':
+			l.open(')')
+			i++
+
+		case r == '`':
+			if l.closing('`') {
+				l.close()
+			} else {
+				l.open('`')
+			}
+
+		case l.inDouble:
+			if r == '"' {
+				l.inDouble = false
+			} else {
+				l.word.WriteRune(r)
+			}
+
+		case r == '\'':
+			inSingle = true
+			l.inWord = true
+
+		case r == '"':
+			l.inDouble = true
+			l.inWord = true
+
+		case r == ')':
+			if l.closing(')') {
+				l.close()
+			} else {
+				l.endCommand()
+			}
+
+		case r == '<' || r == '>' || (r == '&' && next == '>'):
+			l.redirect()
+			// Skip the rest of the operator, e.g. ">>", ">&", "&>" or "<<"
+			for i+1 < len(runes) && strings.ContainsRune("<>&|", runes[i+1]) {
+				i++
+			}
+
+		case r == ';' || r == '&' || r == '|' || r == '(' || r == '\n':
+			l.endCommand()
+
+		case r == ' ' || r == '\t':
+			l.endWord()
+
+		default:
+			l.word.WriteRune(r)
+			l.inWord = true
+		}
+	}
+
+	for len(l.stack) > 0 {
+		l.close()
+	}
+	l.endCommand()
+}
+
+// closing reports whether c ends the innermost command substitution
+func (l *lexer) closing(c rune) bool {
+	return len(l.stack) > 0 && l.stack[len(l.stack)-1].closer == c
+}
+
+// open starts a command substitution, setting the current command aside
+func (l *lexer) open(closer rune) {
+	frame := &lexerFrame{words: l.words, inWord: l.inWord, inDouble: l.inDouble, closer: closer}
+	frame.word.WriteString(l.word.String())
+	l.stack = append(l.stack, frame)
+
+	l.words = nil
+	l.word.Reset()
+	l.inWord = false
+	l.inDouble = false
+}
+
+// close ends a command substitution and resumes the command around it
+func (l *lexer) close() {
+	l.endCommand()
+
+	frame := l.stack[len(l.stack)-1]
+	l.stack = l.stack[:len(l.stack)-1]
+
+	l.words = frame.words
+	l.word.Reset()
+	l.word.WriteString(frame.word.String())
+	l.inWord = frame.inWord
+	l.inDouble = frame.inDouble
+}
+
+// redirect ends the current word, dropping a file descriptor number such as the 2 in "2>"
+func (l *lexer) redirect() {
+	if l.inWord && strings.Trim(l.word.String(), "0123456789") == "" {
+		l.word.Reset()
+		l.inWord = false
+	}
+	l.endWord()
+	l.skipWord = true
+}
+
+func (l *lexer) endWord() {
+	if !l.inWord {
+		return
+	}
+	if l.skipWord {
+		l.skipWord = false
+	} else {
+		l.words = append(l.words, l.word.String())
+	}
+	l.word.Reset()
+	l.inWord = false
+}
+
+func (l *lexer) endCommand() {
+	l.endWord()
+	l.skipWord = false
+	if len(l.words) > 0 {
+		l.commands = append(l.commands, l.words)
+		l.words = nil
+	}
+}
+
+// normalize drops the words before the program a command runs
+func normalize(words []string) []string {
+	for len(words) > 0 {
+		word := words[0]
+		switch {
+		case reservedWords[word] || assignment.MatchString(word):
+			words = words[1:]
+		case wrappers[programName(word)]:
+			words = words[1:]
+			// Skip the wrapper's options and, for env, its assignments
+			for len(words) > 0 && (strings.HasPrefix(words[0], "-") || assignment.MatchString(words[0])) {
+				if wrapperValueFlags[words[0]] && len(words) > 1 {
+					words = words[1:]
+				}
+				words = words[1:]
+			}
+			// timeout takes a duration before the command
+			if programName(word) == "timeout" && len(words) > 0 {
+				words = words[1:]
+			}
+		default:
+			return words
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"fmt"
+	"io"
 	"sync"
 
 	"github.com/navicore/mcpterm-go/pkg/backend"
@@ -50,6 +51,33 @@ func NewRegistry() *Registry {
 		Tools:       []core.Tool{},
 	})
 
+	r.RegisterCategory(&Category{
+		ID:          "git",
+		Name:        "Git Tools",
+		Description: "Tools for inspecting git repositories and committing changes",
+		Enabled:     false,                   // Disabled by default
+		Permission:  core.PermissionReadOnly, // git_stage and git_commit ask for approval
+		Tools:       []core.Tool{},
+	})
+
+	r.RegisterCategory(&Category{
+		ID:          "go",
+		Name:        "Go Tools",
+		Description: "Tools for building, testing, vetting and formatting Go code",
+		Enabled:     false, // Disabled by default
+		Permission:  core.PermissionExecute,
+		Tools:       []core.Tool{},
+	})
+
+	r.RegisterCategory(&Category{
+		ID:          "code",
+		Name:        "Code Intelligence Tools",
+		Description: "Tools for navigating Go code by its symbols, definitions and references",
+		Enabled:     false, // Disabled by default
+		Permission:  core.PermissionReadOnly,
+		Tools:       []core.Tool{},
+	})
+
 	r.RegisterCategory(&Category{
 		ID:          "customer_support",
 		Name:        "Customer Support Tools",
@@ -140,6 +168,94 @@ func (r *Registry) GetTool(name string) (core.Tool, error) {
 	return nil, fmt.Errorf("tool %s not found or not enabled", name)
 }
 
+// lookup finds a tool and its category by name, whether or not the category is enabled
+func (r *Registry) lookup(name string) (core.Tool, *Category) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, cat := range r.Categories {
+		for _, tool := range cat.Tools {
+			if tool.Name() == name {
+				return tool, cat
+			}
+		}
+	}
+	return nil, nil
+}
+
+// closers returns the tools that hold resources, such as processes, to be released
+func (r *Registry) closers() []io.Closer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var closers []io.Closer
+	for _, cat := range r.Categories {
+		for _, tool := range cat.Tools {
+			if closer, ok := tool.(io.Closer); ok {
+				closers = append(closers, closer)
+			}
+		}
+	}
+	return closers
+}
+
+// commandRunners returns the tools that run shell commands
+func (r *Registry) commandRunners() []core.CommandRunner {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var runners []core.CommandRunner
+	for _, cat := range r.Categories {
+		for _, tool := range cat.Tools {
+			if runner, ok := tool.(core.CommandRunner); ok {
+				runners = append(runners, runner)
+			}
+		}
+	}
+	return runners
+}
+
+// sandboxed returns the tools that start processes, by category ID
+func (r *Registry) sandboxed() map[string][]core.Sandboxed {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make(map[string][]core.Sandboxed)
+	for _, cat := range r.Categories {
+		for _, tool := range cat.Tools {
+			if sandboxed, ok := tool.(core.Sandboxed); ok {
+				tools[cat.ID] = append(tools[cat.ID], sandboxed)
+			}
+		}
+	}
+	return tools
+}
+
+// jobs returns the background jobs of tools that run them
+func (r *Registry) jobs() []core.Job {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var jobs []core.Job
+	for _, cat := range r.Categories {
+		for _, tool := range cat.Tools {
+			if lister, ok := tool.(core.JobLister); ok {
+				jobs = append(jobs, lister.Jobs()...)
+			}
+		}
+	}
+	return jobs
+}
+
+// hasCategory reports whether a category is registered
+func (r *Registry) hasCategory(categoryID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, exists := r.Categories[categoryID]
+	return exists
+}
+
 // SetCategoryEnabled enables or disables an entire category
 func (r *Registry) SetCategoryEnabled(categoryID string, enabled bool) error {
 	r.mu.Lock()
@@ -0,0 +1,194 @@
+// Package sandbox restricts the processes that tools start. A profile sets
+// resource limits, removes environment variables such as credentials, and
+// can cut the process off from the network. Profiles are assigned to tool
+// categories, so for example the development tools can run without cloud
+// credentials while external tools keep them.
+//
+// Resource limits and network isolation are supported on Linux. The
+// SysProcAttr of os/exec has no field for resource limits, and Go runs no
+// caller code between fork and exec where setrlimit could be called. Setting
+// them with prlimit after Start would leave the command, and any process it
+// starts in the meantime, unlimited for a moment. So limits are set by
+// starting the command through /bin/sh, which runs ulimit and then execs the
+// command; the limits are in place before the command starts, which then
+// sees its full path as argv[0]. Network isolation starts the process in new
+// user and network namespaces, where only a loopback interface exists.
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Profile describes the restrictions applied to a process. The zero value
+// restricts nothing.
+type Profile struct {
+	CPUSecs   int      // CPU time per process, in seconds
+	MemoryMB  int      // Virtual memory per process, in MiB
+	OpenFiles int      // Open files per process
+	Processes int      // Processes, counted across all processes of the user
+	ScrubEnv  []string // Patterns of environment variable names to remove, e.g. "AWS_*"
+	NoNetwork bool     // Run without network access
+}
+
+// Config holds named profiles and the categories they apply to
+type Config struct {
+	Profiles   map[string]Profile // Profiles by name
+	Categories map[string]string  // Profile name by category ID
+}
+
+// Validate checks that the profile's limits and patterns are usable
+func (p *Profile) Validate() error {
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"cpu_secs", p.CPUSecs},
+		{"memory_mb", p.MemoryMB},
+		{"open_files", p.OpenFiles},
+		{"processes", p.Processes},
+	}
+	for _, l := range limits {
+		if l.value < 0 {
+			return fmt.Errorf("%s must not be negative", l.name)
+		}
+	}
+	for _, pattern := range p.ScrubEnv {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid scrub_env pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks every profile and that categories name existing profiles
+func (c Config) Validate() error {
+	for name, profile := range c.Profiles {
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("sandbox profile %s: %w", name, err)
+		}
+	}
+	for category, name := range c.Categories {
+		if _, ok := c.Profiles[name]; !ok {
+			return fmt.Errorf("category %s uses unknown sandbox profile %q", category, name)
+		}
+	}
+	return nil
+}
+
+// ForCategory returns the profile of a category, or nil if it has none
+func (c Config) ForCategory(categoryID string) *Profile {
+	name, ok := c.Categories[categoryID]
+	if !ok {
+		return nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil
+	}
+	return &profile
+}
+
+// Prepare applies the profile to a command that has not been started yet.
+// It changes the command's environment, and when limits are set, the program
+// it runs. A nil profile leaves the command unchanged.
+func (p *Profile) Prepare(cmd *exec.Cmd) error {
+	if p == nil {
+		return nil
+	}
+
+	if len(p.ScrubEnv) > 0 {
+		env := cmd.Env
+		if env == nil {
+			env = os.Environ()
+		}
+		cmd.Env = scrubEnv(env, p.ScrubEnv)
+	}
+
+	if p.hasLimits() {
+		if err := limit(cmd, p.ulimitScript()); err != nil {
+			return err
+		}
+	}
+
+	if p.NoNetwork {
+		if err := isolateNetwork(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Profile) hasLimits() bool {
+	return p.CPUSecs > 0 || p.MemoryMB > 0 || p.OpenFiles > 0 || p.Processes > 0
+}
+
+// ulimitScript returns a script that sets the limits and runs its arguments.
+// ulimit sets both the soft and hard limits, so the command cannot raise them.
+func (p *Profile) ulimitScript() string {
+	var limits []string
+	add := func(flag string, value int) {
+		if value > 0 {
+			limits = append(limits, "ulimit "+flag+" "+strconv.Itoa(value))
+		}
+	}
+	add("-t", p.CPUSecs)
+	add("-v", p.MemoryMB*1024)
+	add("-n", p.OpenFiles)
+	if p.Processes > 0 {
+		// bash names the process limit -u, dash -p
+		n := strconv.Itoa(p.Processes)
+		limits = append(limits, "{ ulimit -u "+n+" 2>/dev/null || ulimit -p "+n+"; }")
+	}
+
+	return strings.Join(append(limits, `exec "$@"`), " && ")
+}
+
+// scrubEnv returns env without the variables whose names match a pattern
+func scrubEnv(env []string, patterns []string) []string {
+	kept := make([]string, 0, len(env))
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if !matchesAny(name, patterns) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Holder is embedded in tools that start processes. It holds the profile
+// set by the tool manager.
+type Holder struct {
+	mu      sync.RWMutex
+	profile *Profile
+}
+
+// SetSandboxProfile sets the profile applied to processes; nil restricts nothing
+func (h *Holder) SetSandboxProfile(p *Profile) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.profile = p
+}
+
+// SandboxProfile returns the current profile, which may be nil
+func (h *Holder) SandboxProfile() *Profile {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.profile
+}
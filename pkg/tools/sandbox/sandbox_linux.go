@@ -0,0 +1,70 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// shellPath is the shell that sets limits before running a command
+const shellPath = "/bin/sh"
+
+// limit makes cmd run through a shell that applies the limits in script
+// first. A command that cannot be run is left alone, so that Start reports
+// why as it would without limits.
+func limit(cmd *exec.Cmd, script string) error {
+	if cmd.Err != nil {
+		return nil
+	}
+	if info, err := os.Stat(cmd.Path); err != nil || info.IsDir() || info.Mode().Perm()&0111 == 0 {
+		return nil
+	}
+
+	args := append([]string{shellPath, "-c", script, "mcpterm-sandbox", cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = shellPath
+	cmd.Args = args
+	return nil
+}
+
+// isolateNetwork starts cmd in new user and network namespaces. The user
+// namespace maps the current user to itself, so files keep their owners.
+func isolateNetwork(cmd *exec.Cmd) error {
+	if err := userNamespacesAvailable(); err != nil {
+		return err
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	attr := cmd.SysProcAttr
+	attr.Cloneflags |= syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	attr.GidMappingsEnableSetgroups = false
+	return nil
+}
+
+var (
+	userNamespacesOnce sync.Once
+	userNamespacesErr  error
+)
+
+// userNamespacesAvailable reports why unprivileged user namespaces cannot be
+// created, or nil if they can
+func userNamespacesAvailable() error {
+	userNamespacesOnce.Do(func() {
+		if data, err := os.ReadFile("/proc/sys/user/max_user_namespaces"); err == nil && strings.TrimSpace(string(data)) == "0" {
+			userNamespacesErr = fmt.Errorf("running without network access needs user namespaces, which are disabled (user.max_user_namespaces is 0)")
+			return
+		}
+		if data, err := os.ReadFile("/proc/sys/kernel/unprivileged_userns_clone"); err == nil && strings.TrimSpace(string(data)) == "0" {
+			userNamespacesErr = fmt.Errorf("running without network access needs unprivileged user namespaces, which are disabled (kernel.unprivileged_userns_clone is 0)")
+		}
+	})
+	return userNamespacesErr
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func limit(cmd *exec.Cmd, script string) error {
+	return fmt.Errorf("resource limits for tool commands are only supported on Linux")
+}
+
+func isolateNetwork(cmd *exec.Cmd) error {
+	return fmt.Errorf("running tool commands without network access is only supported on Linux")
+}
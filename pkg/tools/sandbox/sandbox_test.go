@@ -0,0 +1,160 @@
+package sandbox
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run prepares and runs a command with the profile, returning its output
+func run(t *testing.T, p *Profile, name string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command(name, args...)
+	require.NoError(t, p.Prepare(cmd))
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func TestScrubEnv(t *testing.T) {
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("GITHUB_TOKEN", "token")
+	t.Setenv("SANDBOX_KEPT", "kept")
+
+	p := &Profile{ScrubEnv: []string{"AWS_*", "*_TOKEN"}}
+	output := run(t, p, "env")
+
+	assert.NotContains(t, output, "AWS_SECRET_ACCESS_KEY")
+	assert.NotContains(t, output, "GITHUB_TOKEN")
+	assert.Contains(t, output, "SANDBOX_KEPT=kept")
+}
+
+func TestLimits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resource limits are only supported on Linux")
+	}
+
+	p := &Profile{CPUSecs: 7, MemoryMB: 2048, OpenFiles: 64}
+	output := run(t, p, "sh", "-c", `ulimit -t; ulimit -v; ulimit -n; echo "$0 $1"`, "zero", "one")
+
+	assert.Equal(t, "7\n2097152\n64\nzero one", output)
+}
+
+func TestLimitsTakeEffect(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resource limits are only supported on Linux")
+	}
+
+	// Only stdin, stdout and stderr may be open, so cat cannot open a file
+	// (or, when it is linked dynamically, even its libraries)
+	require.NoError(t, exec.Command("cat", "/dev/null").Run())
+	cmd := exec.Command("cat", "/dev/null")
+	require.NoError(t, (&Profile{OpenFiles: 3}).Prepare(cmd))
+	output, err := cmd.CombinedOutput()
+	require.Error(t, err)
+	assert.Regexp(t, "Too many open files|Error 24", string(output))
+
+	// A busy loop is killed once it has used its CPU time
+	cmd = exec.Command("sh", "-c", "while :; do :; done")
+	require.NoError(t, (&Profile{CPUSecs: 1}).Prepare(cmd))
+	start := time.Now()
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	require.True(t, ok)
+	assert.True(t, status.Signaled(), "the loop is killed by a signal, not %v", err)
+	assert.Less(t, time.Since(start), 30*time.Second)
+}
+
+func TestLimitsKeepStartErrors(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resource limits are only supported on Linux")
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing")
+	for _, name := range []string{"mcpterm-no-such-command", missing} {
+		plain := exec.Command(name).Start()
+		require.Error(t, plain)
+
+		cmd := exec.Command(name)
+		require.NoError(t, (&Profile{OpenFiles: 64}).Prepare(cmd))
+		assert.Equal(t, plain.Error(), cmd.Start().Error(), name)
+	}
+}
+
+func TestProcessLimit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resource limits are only supported on Linux")
+	}
+
+	output := run(t, &Profile{Processes: 1000}, "cat", "/proc/self/limits")
+	assert.Regexp(t, `Max processes\s+1000\s+1000\s`, output)
+}
+
+func TestNoNetwork(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("network isolation is only supported on Linux")
+	}
+
+	p := &Profile{NoNetwork: true}
+	cmd := exec.Command("cat", "/proc/net/dev")
+	if err := p.Prepare(cmd); err != nil {
+		t.Skipf("user namespaces are not available: %v", err)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Skipf("user namespaces are not available: %v", err)
+	}
+
+	// Only the loopback interface exists in the new network namespace
+	var interfaces []string
+	for _, line := range strings.Split(string(output), "\n")[2:] {
+		if name, _, ok := strings.Cut(line, ":"); ok {
+			interfaces = append(interfaces, strings.TrimSpace(name))
+		}
+	}
+	assert.Equal(t, []string{"lo"}, interfaces)
+}
+
+func TestNilProfileChangesNothing(t *testing.T) {
+	var p *Profile
+	cmd := exec.Command("true")
+	path, args := cmd.Path, cmd.Args
+
+	require.NoError(t, p.Prepare(cmd))
+	assert.Equal(t, path, cmd.Path)
+	assert.Equal(t, args, cmd.Args)
+	assert.Nil(t, cmd.Env)
+	assert.Nil(t, cmd.SysProcAttr)
+}
+
+func TestConfig(t *testing.T) {
+	config := Config{
+		Profiles:   map[string]Profile{"strict": {CPUSecs: 60, NoNetwork: true}},
+		Categories: map[string]string{"development": "strict"},
+	}
+	require.NoError(t, config.Validate())
+
+	profile := config.ForCategory("development")
+	require.NotNil(t, profile)
+	assert.Equal(t, 60, profile.CPUSecs)
+	assert.Nil(t, config.ForCategory("filesystem"))
+
+	invalid := []Config{
+		{Categories: map[string]string{"development": "missing"}},
+		{Profiles: map[string]Profile{"bad": {MemoryMB: -1}}},
+		{Profiles: map[string]Profile{"bad": {ScrubEnv: []string{"AWS_["}}}},
+	}
+	for _, c := range invalid {
+		assert.Error(t, c.Validate(), "config %+v", c)
+	}
+}
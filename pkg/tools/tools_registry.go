@@ -1,8 +1,11 @@
 package tools
 
 import (
+	"github.com/navicore/mcpterm-go/pkg/tools/categories/code"
 	"github.com/navicore/mcpterm-go/pkg/tools/categories/development"
 	"github.com/navicore/mcpterm-go/pkg/tools/categories/filesystem"
+	"github.com/navicore/mcpterm-go/pkg/tools/categories/git"
+	"github.com/navicore/mcpterm-go/pkg/tools/categories/golang"
 )
 
 // registerFilesystemTools registers filesystem tools
@@ -15,6 +18,21 @@ func registerDevelopmentTools(registry *Registry) error {
 	return development.Register(registry)
 }
 
+// registerGitTools registers git tools
+func registerGitTools(registry *Registry) error {
+	return git.Register(registry)
+}
+
+// registerGoTools registers Go toolchain tools
+func registerGoTools(registry *Registry) error {
+	return golang.Register(registry)
+}
+
+// registerCodeTools registers code intelligence tools
+func registerCodeTools(registry *Registry) error {
+	return code.Register(registry)
+}
+
 // registerCustomerSupportTools would register customer support tools
 // func registerCustomerSupportTools(registry *Registry) error {
 //     return customersupport.Register(registry)
@@ -0,0 +1,278 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// FieldError describes a problem with a single field of a tool's input
+type FieldError struct {
+	Field   string `json:"field"`   // Dotted path of the field, or "(input)" for the whole input
+	Message string `json:"message"` // What is wrong with the field
+}
+
+// InputValidationError is returned when a tool's input does not match its input schema
+type InputValidationError struct {
+	Tool   string                 // Name of the tool
+	Fields []FieldError           // Offending fields
+	Schema map[string]interface{} // Schema the input was validated against
+}
+
+// Error renders the failures in a format the model can act on
+func (e *InputValidationError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "invalid input for tool %s:\n", e.Tool)
+	for _, field := range e.Fields {
+		fmt.Fprintf(&sb, "  - %s: %s\n", field.Field, field.Message)
+	}
+
+	if params := describeParameters(e.Schema); params != "" {
+		fmt.Fprintf(&sb, "Expected parameters: %s\n", params)
+	}
+	sb.WriteString("Correct these fields and call the tool again.")
+
+	return sb.String()
+}
+
+// inputField is used when a failure applies to the input as a whole
+const inputField = "(input)"
+
+// quotedName matches the quoted property names in validator messages
+var quotedName = regexp.MustCompile(`'((?:[^'\\]|\\.)*)'`)
+
+// SchemaValidator validates tool inputs against their JSON schemas.
+// Compiled schemas are cached by tool name.
+type SchemaValidator struct {
+	mu      sync.Mutex
+	schemas map[string]*jsonschema.Schema
+}
+
+// NewSchemaValidator creates a validator with an empty schema cache
+func NewSchemaValidator() *SchemaValidator {
+	return &SchemaValidator{
+		schemas: make(map[string]*jsonschema.Schema),
+	}
+}
+
+// Validate checks input against the tool's input schema. It returns an
+// *InputValidationError if the input does not match the schema.
+func (v *SchemaValidator) Validate(tool core.Tool, input json.RawMessage) error {
+	schema, err := v.compile(tool)
+	if err != nil {
+		return err
+	}
+
+	// Tools without parameters may be called with no input at all
+	if len(bytes.TrimSpace(input)) == 0 {
+		input = json.RawMessage("{}")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+	var instance interface{}
+	if err := decoder.Decode(&instance); err != nil {
+		return &InputValidationError{
+			Tool:   tool.Name(),
+			Fields: []FieldError{{Field: inputField, Message: fmt.Sprintf("is not valid JSON: %v", err)}},
+			Schema: tool.InputSchema(),
+		}
+	}
+
+	if err := schema.Validate(instance); err != nil {
+		validationErr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return fmt.Errorf("error validating input for tool %s: %w", tool.Name(), err)
+		}
+
+		return &InputValidationError{
+			Tool:   tool.Name(),
+			Fields: fieldErrors(validationErr),
+			Schema: tool.InputSchema(),
+		}
+	}
+
+	return nil
+}
+
+// Forget drops the cached schema for a tool, e.g. when the tool is replaced
+func (v *SchemaValidator) Forget(name string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	delete(v.schemas, name)
+}
+
+// Check compiles the tool's input schema so an invalid schema is reported
+// when the tool is loaded rather than when it is first called
+func (v *SchemaValidator) Check(tool core.Tool) error {
+	_, err := v.compile(tool)
+	return err
+}
+
+// compile returns the compiled input schema for a tool, compiling it on first use
+func (v *SchemaValidator) compile(tool core.Tool) (*jsonschema.Schema, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if schema, ok := v.schemas[tool.Name()]; ok {
+		return schema, nil
+	}
+
+	schemaJSON, err := json.Marshal(tool.InputSchema())
+	if err != nil {
+		return nil, fmt.Errorf("error encoding input schema for tool %s: %w", tool.Name(), err)
+	}
+
+	url := "mem:///tools/" + tool.Name() + ".json"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, bytes.NewReader(schemaJSON)); err != nil {
+		return nil, fmt.Errorf("invalid input schema for tool %s: %w", tool.Name(), err)
+	}
+
+	schema, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input schema for tool %s: %w", tool.Name(), err)
+	}
+
+	v.schemas[tool.Name()] = schema
+	return schema, nil
+}
+
+// fieldErrors flattens a validation error tree into one entry per offending field
+func fieldErrors(err *jsonschema.ValidationError) []FieldError {
+	var fields []FieldError
+	seen := make(map[FieldError]bool)
+
+	add := func(field FieldError) {
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+
+		location := fieldName(e.InstanceLocation)
+		switch {
+		case strings.HasSuffix(e.KeywordLocation, "/required"):
+			for _, name := range quotedNames(e.Message) {
+				add(FieldError{Field: joinField(location, name), Message: "is required but missing"})
+			}
+		case strings.HasSuffix(e.KeywordLocation, "/additionalProperties"):
+			for _, name := range quotedNames(e.Message) {
+				add(FieldError{Field: joinField(location, name), Message: "is not a recognized parameter"})
+			}
+		default:
+			add(FieldError{Field: location, Message: e.Message})
+		}
+	}
+	walk(err)
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+
+	return fields
+}
+
+// fieldName converts a JSON pointer such as "/args/0" to a dotted field name
+func fieldName(pointer string) string {
+	if pointer == "" || pointer == "/" {
+		return inputField
+	}
+
+	parts := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, "~1", "/")
+		parts[i] = strings.ReplaceAll(part, "~0", "~")
+	}
+	return strings.Join(parts, ".")
+}
+
+// joinField appends a property name to a parent field
+func joinField(parent, name string) string {
+	if parent == inputField {
+		return name
+	}
+	return parent + "." + name
+}
+
+// quotedNames extracts the property names quoted in a validator message
+func quotedNames(message string) []string {
+	var names []string
+	for _, match := range quotedName.FindAllStringSubmatch(message, -1) {
+		names = append(names, strings.ReplaceAll(match[1], `\'`, `'`))
+	}
+	return names
+}
+
+// describeParameters summarizes the top-level parameters of a schema,
+// e.g. "path (string, required), limit (integer)"
+func describeParameters(schema map[string]interface{}) string {
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok || len(props) == 0 {
+		return ""
+	}
+
+	required := make(map[string]bool)
+	switch names := schema["required"].(type) {
+	case []string:
+		for _, name := range names {
+			required[name] = true
+		}
+	case []interface{}:
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		// List required parameters first
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
+		}
+		return names[i] < names[j]
+	})
+
+	descriptions := make([]string, 0, len(names))
+	for _, name := range names {
+		attrs := []string{}
+		if prop, ok := props[name].(map[string]interface{}); ok {
+			if typ, ok := prop["type"].(string); ok {
+				attrs = append(attrs, typ)
+			}
+		}
+		if required[name] {
+			attrs = append(attrs, "required")
+		}
+
+		if len(attrs) == 0 {
+			descriptions = append(descriptions, name)
+		} else {
+			descriptions = append(descriptions, fmt.Sprintf("%s (%s)", name, strings.Join(attrs, ", ")))
+		}
+	}
+
+	return strings.Join(descriptions, ", ")
+}
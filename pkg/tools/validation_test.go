@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleTool is a tool with a schema covering the common validation failures
+type sampleTool struct {
+	*core.BaseToolImpl
+}
+
+func (t *sampleTool) Execute(ctx context.Context, input json.RawMessage) (interface{}, error) {
+	return "ok", nil
+}
+
+func TestBuiltinSchemasCompile(t *testing.T) {
+	manager, err := Initialize()
+	require.NoError(t, err)
+
+	validator := NewSchemaValidator()
+	for _, category := range manager.registry.Categories {
+		for _, tool := range category.Tools {
+			_, err := validator.compile(tool)
+			assert.NoError(t, err, "schema for tool %s should compile", tool.Name())
+		}
+	}
+}
+
+func TestSchemaValidator(t *testing.T) {
+	tool := &sampleTool{core.NewBaseTool("sample", "Sample tool", "filesystem", map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path":  map[string]interface{}{"type": "string"},
+			"limit": map[string]interface{}{"type": "integer"},
+			"mode":  map[string]interface{}{"type": "string", "enum": []string{"create", "apply"}},
+		},
+		"required": []string{"path", "mode"},
+	})}
+	validator := NewSchemaValidator()
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected []FieldError
+	}{
+		{
+			name:  "Valid input",
+			input: `{"path": "a.txt", "mode": "create", "limit": 3}`,
+		},
+		{
+			name:  "Missing required fields",
+			input: `{}`,
+			expected: []FieldError{
+				{Field: "mode", Message: "is required but missing"},
+				{Field: "path", Message: "is required but missing"},
+			},
+		},
+		{
+			name:  "Wrong type and enum value",
+			input: `{"path": "a.txt", "mode": "delete", "limit": "3"}`,
+			expected: []FieldError{
+				{Field: "limit", Message: "expected integer, but got string"},
+				{Field: "mode", Message: `value must be one of "create", "apply"`},
+			},
+		},
+		{
+			name:  "Empty input",
+			input: ``,
+			expected: []FieldError{
+				{Field: "mode", Message: "is required but missing"},
+				{Field: "path", Message: "is required but missing"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validator.Validate(tool, json.RawMessage(tc.input))
+			if tc.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *InputValidationError
+			require.True(t, errors.As(err, &validationErr), "expected InputValidationError, got %v", err)
+			assert.Equal(t, "sample", validationErr.Tool)
+			assert.Equal(t, tc.expected, validationErr.Fields)
+			assert.Contains(t, err.Error(), "Expected parameters: mode (string, required), path (string, required), limit (integer)")
+		})
+	}
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		err := validator.Validate(tool, json.RawMessage(`{"path": `))
+
+		var validationErr *InputValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Len(t, validationErr.Fields, 1)
+		assert.Equal(t, "(input)", validationErr.Fields[0].Field)
+	})
+}
+
+func TestHandleToolUseValidatesInput(t *testing.T) {
+	manager, err := Initialize()
+	require.NoError(t, err)
+
+	_, err = manager.HandleToolUse(context.Background(), &core.ToolUse{
+		Name:  "file_read",
+		Input: json.RawMessage(`{"path": 42}`),
+	})
+
+	var validationErr *InputValidationError
+	require.True(t, errors.As(err, &validationErr), "expected InputValidationError, got %v", err)
+	assert.Equal(t, []FieldError{{Field: "path", Message: "expected string, but got number"}}, validationErr.Fields)
+}
+
+func TestHandleToolUseRejectsEmptyDeletePath(t *testing.T) {
+	manager, err := Initialize()
+	require.NoError(t, err)
+
+	// file_delete relies on its schema to reject an empty path
+	_, err = manager.HandleToolUse(context.Background(), &core.ToolUse{
+		Name:  "file_delete",
+		Input: json.RawMessage(`{"path": ""}`),
+	})
+
+	var validationErr *InputValidationError
+	require.True(t, errors.As(err, &validationErr), "expected InputValidationError, got %v", err)
+	require.Len(t, validationErr.Fields, 1)
+	assert.Equal(t, "path", validationErr.Fields[0].Field)
+}
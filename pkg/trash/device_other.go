@@ -0,0 +1,13 @@
+//go:build !unix
+
+package trash
+
+import "errors"
+
+// devicesSupported reports whether deviceOf can tell file systems apart
+const devicesSupported = false
+
+// deviceOf is not supported where file systems cannot be told apart
+func deviceOf(path string) (uint64, error) {
+	return 0, errors.New("file system devices are not supported on this platform")
+}
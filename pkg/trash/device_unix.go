@@ -0,0 +1,21 @@
+//go:build unix
+
+package trash
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// devicesSupported reports whether deviceOf can tell file systems apart
+const devicesSupported = true
+
+// deviceOf returns the device of the file system holding path, without
+// following a final symbolic link
+func deviceOf(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Lstat(path, &st); err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return uint64(st.Dev), nil
+}
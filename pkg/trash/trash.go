@@ -0,0 +1,426 @@
+// Package trash implements the freedesktop.org trash specification: deleted
+// files are moved to a trash directory with a .trashinfo file recording
+// where they came from and when, so they can be listed and restored.
+package trash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// infoSuffix is the extension of the files describing trashed items
+const infoSuffix = ".trashinfo"
+
+// dateFormat is the layout of DeletionDate in a .trashinfo file, in local time
+const dateFormat = "2006-01-02T15:04:05"
+
+// Item is a file or directory in the trash
+type Item struct {
+	Name         string    `json:"name"`          // Name of the item in the trash directory
+	Path         string    `json:"path"`          // Absolute path the item was deleted from
+	DeletionDate time.Time `json:"deletion_date"` // When the item was moved to the trash
+	IsDir        bool      `json:"is_dir"`        // Whether the item is a directory
+	TrashDir     string    `json:"trash_dir"`     // Trash directory holding the item
+
+	infoTime time.Time // When the .trashinfo file was written, ordering deletions within a second
+}
+
+// FilesPath returns where the item is kept in the trash
+func (i Item) FilesPath() string {
+	return filepath.Join(i.TrashDir, "files", i.Name)
+}
+
+// infoPath returns the item's .trashinfo file
+func (i Item) infoPath() string {
+	return filepath.Join(i.TrashDir, "info", i.Name+infoSuffix)
+}
+
+// trashDir is a trash directory. Items in the trash of a top directory
+// record their paths relative to that directory.
+type trashDir struct {
+	path string
+	top  string // Top directory of the mount, or "" for the home trash
+}
+
+// Supported reports whether the freedesktop trash is used on this platform.
+// macOS has a trash of its own and Windows a recycle bin.
+func Supported() bool {
+	return devicesSupported && runtime.GOOS != "darwin"
+}
+
+// HomeDir returns the home trash directory, $XDG_DATA_HOME/Trash or
+// ~/.local/share/Trash
+func HomeDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dataHome) {
+		return filepath.Join(dataHome, "Trash"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "share", "Trash"), nil
+}
+
+// Move moves a file or directory to the trash. Paths on the same file
+// system as the home trash go there; others go to the trash of the top
+// directory of their mount, so nothing is copied between file systems.
+func Move(path string) (Item, error) {
+	if !Supported() {
+		return Item{}, fmt.Errorf("the freedesktop trash is not supported on %s", runtime.GOOS)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return Item{}, err
+	}
+
+	dir, err := trashDirFor(absPath)
+	if err != nil {
+		return Item{}, err
+	}
+	for _, sub := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(dir.path, sub), 0700); err != nil {
+			return Item{}, fmt.Errorf("failed to create trash directory: %w", err)
+		}
+	}
+
+	recorded := absPath
+	if dir.top != "" {
+		if recorded, err = filepath.Rel(dir.top, absPath); err != nil {
+			return Item{}, err
+		}
+	}
+	now := time.Now()
+	item, err := reserveName(dir.path, filepath.Base(absPath), recorded, now)
+	if err != nil {
+		return Item{}, err
+	}
+	if err := os.Rename(absPath, item.FilesPath()); err != nil {
+		_ = os.Remove(item.infoPath())
+		return Item{}, fmt.Errorf("failed to move %s to the trash: %w", absPath, err)
+	}
+
+	item.Path = absPath
+	item.DeletionDate = now.Truncate(time.Second)
+	item.IsDir = info.IsDir()
+	return item, nil
+}
+
+// reserveName claims a name in a trash directory by creating its .trashinfo
+// file, which fails if another deletion got there first. Taken names get a
+// numeric suffix.
+func reserveName(dir, base, recorded string, now time.Time) (Item, error) {
+	content := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", escapePath(recorded), now.Format(dateFormat))
+	for n := 1; ; n++ {
+		item := Item{Name: base, TrashDir: dir}
+		if n > 1 {
+			item.Name = fmt.Sprintf("%s.%d", base, n)
+		}
+
+		f, err := os.OpenFile(item.infoPath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		} else if err != nil {
+			return Item{}, fmt.Errorf("failed to write trash info: %w", err)
+		}
+		// An item left without its info file also takes the name
+		if _, err := os.Lstat(item.FilesPath()); err == nil {
+			f.Close()
+			_ = os.Remove(item.infoPath())
+			continue
+		}
+
+		_, err = f.WriteString(content)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(item.infoPath())
+			return Item{}, fmt.Errorf("failed to write trash info: %w", err)
+		}
+		return item, nil
+	}
+}
+
+// trashDirFor chooses the trash directory for an absolute path
+func trashDirFor(absPath string) (trashDir, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return trashDir{}, err
+	}
+	dev, err := deviceOf(absPath)
+	if err != nil {
+		return trashDir{}, err
+	}
+	// The home trash may not exist yet, so check where it would be created
+	homeDev, err := deviceOf(existingAncestor(home))
+	if err == nil && homeDev == dev {
+		return trashDir{path: home}, nil
+	}
+
+	top := filepath.Dir(absPath)
+	for top != filepath.Dir(top) {
+		if parentDev, err := deviceOf(filepath.Dir(top)); err != nil || parentDev != dev {
+			break
+		}
+		top = filepath.Dir(top)
+	}
+	return topTrashDir(top)
+}
+
+// topTrashDir returns the trash directory of a mount's top directory:
+// $top/.Trash/$uid when the administrator created $top/.Trash with the
+// sticky bit set, and $top/.Trash-$uid otherwise
+func topTrashDir(top string) (trashDir, error) {
+	uid := strconv.Itoa(os.Getuid())
+
+	shared := filepath.Join(top, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		dir := filepath.Join(shared, uid)
+		if err := os.Mkdir(dir, 0700); err == nil || os.IsExist(err) {
+			if isRealDir(dir) {
+				return trashDir{path: dir, top: top}, nil
+			}
+		}
+	}
+
+	dir := filepath.Join(top, ".Trash-"+uid)
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return trashDir{}, fmt.Errorf("no trash directory available on the file system of %s: %w", top, err)
+	}
+	if !isRealDir(dir) {
+		return trashDir{}, fmt.Errorf("no trash directory available on the file system of %s: %s is not a directory", top, dir)
+	}
+	return trashDir{path: dir, top: top}, nil
+}
+
+// List returns the items in the home trash and in the trash directories of
+// mounted file systems, most recently deleted first
+func List() ([]Item, error) {
+	if !Supported() {
+		return nil, fmt.Errorf("the freedesktop trash is not supported on %s", runtime.GOOS)
+	}
+	home, err := HomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := []trashDir{{path: home}}
+	uid := strconv.Itoa(os.Getuid())
+	for _, top := range mountPoints() {
+		for _, path := range []string{filepath.Join(top, ".Trash", uid), filepath.Join(top, ".Trash-"+uid)} {
+			if isRealDir(path) {
+				dirs = append(dirs, trashDir{path: path, top: top})
+			}
+		}
+	}
+
+	var items []Item
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if seen[dir.path] {
+			continue
+		}
+		seen[dir.path] = true
+		items = append(items, readTrashDir(dir)...)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].DeletionDate.Equal(items[j].DeletionDate) {
+			return items[i].DeletionDate.After(items[j].DeletionDate)
+		}
+		return items[i].infoTime.After(items[j].infoTime)
+	})
+	return items, nil
+}
+
+// readTrashDir reads the items of a trash directory, skipping info files
+// that cannot be parsed or whose item is gone
+func readTrashDir(dir trashDir) []Item {
+	entries, err := os.ReadDir(filepath.Join(dir.path, "info"))
+	if err != nil {
+		return nil
+	}
+	var items []Item
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), infoSuffix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		item := Item{Name: name, TrashDir: dir.path}
+		if info, err := entry.Info(); err == nil {
+			item.infoTime = info.ModTime()
+		}
+		if err := parseInfo(item.infoPath(), dir.top, &item); err != nil {
+			continue
+		}
+		info, err := os.Lstat(item.FilesPath())
+		if err != nil {
+			continue
+		}
+		item.IsDir = info.IsDir()
+		items = append(items, item)
+	}
+	return items
+}
+
+// parseInfo reads the original path and deletion date from a .trashinfo file
+func parseInfo(path, top string, item *Item) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	inGroup := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == "[Trash Info]"
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !inGroup || !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			original, err := url.PathUnescape(value)
+			if err != nil {
+				return fmt.Errorf("invalid path in %s: %w", path, err)
+			}
+			if !filepath.IsAbs(original) {
+				if top == "" {
+					return fmt.Errorf("relative path in %s", path)
+				}
+				original = filepath.Join(top, original)
+			}
+			item.Path = filepath.Clean(original)
+		case "DeletionDate":
+			// A missing or malformed date is not fatal
+			if date, err := time.ParseInLocation(dateFormat, value, time.Local); err == nil {
+				item.DeletionDate = date
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if item.Path == "" {
+		return fmt.Errorf("no path in %s", path)
+	}
+	return nil
+}
+
+// Find returns the most recently deleted item that was at path, or whose
+// name in the trash is path
+func Find(path string) (Item, error) {
+	items, err := List()
+	if err != nil {
+		return Item{}, err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Item{}, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	for _, item := range items {
+		if item.Path == absPath || item.Name == path {
+			return item, nil
+		}
+	}
+	return Item{}, fmt.Errorf("%s is not in the trash", path)
+}
+
+// Restore moves an item out of the trash to dest, or to where it was
+// deleted from when dest is empty, and returns where it went. Missing parent
+// directories are created; an existing path is never overwritten.
+func Restore(item Item, dest string) (string, error) {
+	if dest == "" {
+		dest = item.Path
+	}
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if _, err := os.Lstat(dest); err == nil {
+		return "", fmt.Errorf("%s already exists", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create parent directory: %w", err)
+	}
+	if err := os.Rename(item.FilesPath(), dest); err != nil {
+		return "", fmt.Errorf("failed to restore %s: %w", item.Name, err)
+	}
+	if err := os.Remove(item.infoPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return dest, fmt.Errorf("restored %s but failed to remove its trash info: %w", dest, err)
+	}
+	return dest, nil
+}
+
+// escapePath escapes a path for a .trashinfo file as a URL path would be
+func escapePath(path string) string {
+	return (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath()
+}
+
+// existingAncestor returns path or its nearest ancestor that exists
+func existingAncestor(path string) string {
+	for {
+		if _, err := os.Lstat(path); err == nil || filepath.Dir(path) == path {
+			return path
+		}
+		path = filepath.Dir(path)
+	}
+}
+
+// isRealDir reports whether path is a directory and not a symbolic link
+func isRealDir(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.IsDir()
+}
+
+// mountPoints returns the mount points listed in /proc/self/mounts, where
+// the system provides it
+func mountPoints() []string {
+	content, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil
+	}
+	var mounts []string
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		mounts = append(mounts, unescapeMount(fields[1]))
+	}
+	return mounts
+}
+
+// unescapeMount decodes the octal escapes, such as "\040" for a space, of a
+// path in the mount table
+func unescapeMount(path string) string {
+	var out strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+4 <= len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				out.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		out.WriteByte(path[i])
+	}
+	return out.String()
+}
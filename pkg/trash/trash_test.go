@@ -0,0 +1,161 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTrash points the home trash into a temporary directory and returns
+// a directory for files to delete, on the same file system
+func setupTrash(t *testing.T) (home, work string) {
+	t.Helper()
+	if !Supported() {
+		t.Skip("the freedesktop trash is not used on this platform")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	work = filepath.Join(dir, "work")
+	require.NoError(t, os.Mkdir(work, 0755))
+	return filepath.Join(dir, "data", "Trash"), work
+}
+
+func TestMoveAndRestore(t *testing.T) {
+	home, work := setupTrash(t)
+	path := filepath.Join(work, "notes 100%.txt")
+	require.NoError(t, os.WriteFile(path, []byte("keep me"), 0644))
+
+	item, err := Move(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, item.Path)
+	assert.Equal(t, home, item.TrashDir)
+	assert.Equal(t, "notes 100%.txt", item.Name)
+	assert.NoFileExists(t, path)
+
+	info, err := os.ReadFile(filepath.Join(home, "info", "notes 100%.txt.trashinfo"))
+	require.NoError(t, err)
+	assert.Contains(t, string(info), "[Trash Info]\nPath="+escapePath(path)+"\nDeletionDate=")
+	assert.Contains(t, string(info), "notes%20100%25.txt")
+
+	items, err := List()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, path, items[0].Path)
+	assert.Equal(t, item.DeletionDate.Unix(), items[0].DeletionDate.Unix())
+	assert.False(t, items[0].IsDir)
+
+	found, err := Find(path)
+	require.NoError(t, err)
+	dest, err := Restore(found, "")
+	require.NoError(t, err)
+	assert.Equal(t, path, dest)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "keep me", string(content))
+	assert.NoFileExists(t, filepath.Join(home, "info", "notes 100%.txt.trashinfo"))
+
+	items, err = List()
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestMoveNameClash(t *testing.T) {
+	home, work := setupTrash(t)
+	path := filepath.Join(work, "dir")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.MkdirAll(filepath.Join(path, "sub"), 0755))
+		_, err := Move(path)
+		require.NoError(t, err)
+	}
+	// A leftover item without an info file keeps its name too
+	require.NoError(t, os.WriteFile(filepath.Join(home, "files", "dir.4"), nil, 0644))
+	require.NoError(t, os.MkdirAll(path, 0755))
+	item, err := Move(path)
+	require.NoError(t, err)
+	assert.Equal(t, "dir.5", item.Name)
+	assert.True(t, item.IsDir)
+
+	items, err := List()
+	require.NoError(t, err)
+	assert.Len(t, items, 4)
+	assert.Equal(t, "dir.5", items[0].Name, "most recent deletion first")
+	for _, item := range items {
+		assert.Equal(t, path, item.Path)
+	}
+
+	// Restoring never overwrites, but can go elsewhere
+	require.NoError(t, os.Mkdir(path, 0755))
+	_, err = Restore(items[0], "")
+	assert.ErrorContains(t, err, "already exists")
+	dest, err := Restore(items[0], filepath.Join(work, "new", "dir"))
+	require.NoError(t, err)
+	assert.DirExists(t, dest)
+}
+
+func TestFindByName(t *testing.T) {
+	_, work := setupTrash(t)
+	path := filepath.Join(work, "a.txt")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	_, err := Move(path)
+	require.NoError(t, err)
+
+	item, err := Find("a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, path, item.Path)
+
+	_, err = Find(filepath.Join(work, "b.txt"))
+	assert.ErrorContains(t, err, "not in the trash")
+}
+
+func TestTopTrashDir(t *testing.T) {
+	if !Supported() {
+		t.Skip("the freedesktop trash is not used on this platform")
+	}
+	uid := strconv.Itoa(os.Getuid())
+
+	t.Run("Per-user directory", func(t *testing.T) {
+		top := t.TempDir()
+		// .Trash without the sticky bit is not trusted
+		require.NoError(t, os.Mkdir(filepath.Join(top, ".Trash"), 0777))
+		dir, err := topTrashDir(top)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(top, ".Trash-"+uid), dir.path)
+		assert.Equal(t, top, dir.top)
+	})
+
+	t.Run("Shared directory", func(t *testing.T) {
+		top := t.TempDir()
+		shared := filepath.Join(top, ".Trash")
+		require.NoError(t, os.Mkdir(shared, 0777))
+		require.NoError(t, os.Chmod(shared, 0777|os.ModeSticky))
+		dir, err := topTrashDir(top)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(shared, uid), dir.path)
+	})
+
+	t.Run("Relative paths", func(t *testing.T) {
+		top := t.TempDir()
+		dir, err := topTrashDir(top)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Join(dir.path, "files", "x"), 0700))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir.path, "info"), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir.path, "info", "x.trashinfo"),
+			[]byte("[Trash Info]\nPath=src/my%20x\nDeletionDate=2024-05-01T10:20:30\n"), 0600))
+
+		items := readTrashDir(dir)
+		require.Len(t, items, 1)
+		assert.Equal(t, filepath.Join(top, "src", "my x"), items[0].Path)
+		assert.True(t, items[0].IsDir)
+		assert.Equal(t, 2024, items[0].DeletionDate.Year())
+	})
+}
+
+func TestUnescapeMount(t *testing.T) {
+	assert.Equal(t, "/media/usb stick", unescapeMount(`/media/usb\040stick`))
+	assert.Equal(t, `/a\b`, unescapeMount(`/a\134b`))
+	assert.Equal(t, `/odd\`, unescapeMount(`/odd\`))
+}
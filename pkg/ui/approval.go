@@ -0,0 +1,37 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// ToolApprovalMsg asks the user to approve an action of a running tool.
+// The answer is sent on Reply, which must be buffered.
+type ToolApprovalMsg struct {
+	Request core.ApprovalRequest
+	Reply   chan<- bool
+}
+
+var approvalStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#FF5F87")).
+	PaddingLeft(2).
+	Bold(true)
+
+// answerApproval sends the user's answer to the pending approval request
+func (m *Model) answerApproval(approved bool) {
+	if m.pendingApproval == nil {
+		return
+	}
+	m.pendingApproval.Reply <- approved
+	m.pendingApproval = nil
+	m.updateViewportContent()
+}
+
+// renderApprovalPrompt shows the pending approval request
+func (m Model) renderApprovalPrompt() string {
+	req := m.pendingApproval.Request
+	return approvalStyle.Render(fmt.Sprintf("%s wants to run: %s", req.Tool, req.Action)) + "\n" +
+		helpStyle.Render(fmt.Sprintf("Approval needed because %s. Allow? (y/n)", req.Reason))
+}
@@ -0,0 +1,290 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/navicore/mcpterm-go/pkg/chat"
+	"github.com/navicore/mcpterm-go/pkg/mcp"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// mcpCommandTimeout bounds requests made to MCP servers by slash commands
+const mcpCommandTimeout = 30 * time.Second
+
+// commandResultMsg carries the output of a slash command run in the background
+type commandResultMsg struct {
+	content string
+	err     error
+}
+
+// isCommand reports whether input is a slash command rather than a chat message
+func isCommand(input string) bool {
+	return strings.HasPrefix(input, "/") && !strings.HasPrefix(input, "//")
+}
+
+// runCommand executes a slash command and returns a tea.Cmd producing its result
+func (m *Model) runCommand(input string) tea.Cmd {
+	fields := strings.Fields(input)
+	name := strings.TrimPrefix(fields[0], "/")
+
+	switch name {
+	case "undo":
+		return m.undoCommand()
+	case "continue":
+		return m.continueCommand(fields[1:])
+	case "jobs":
+		return m.jobsCommand()
+	case "mcp":
+		return m.mcpCommand()
+	case "resources":
+		return m.resourcesCommand()
+	case "resource":
+		return m.resourceCommand(fields[1:])
+	case "prompts":
+		return m.promptsCommand()
+	default:
+		// MCP prompt templates are invoked as /server:prompt
+		if server, prompt, ok := strings.Cut(name, ":"); ok && server != "" && prompt != "" {
+			return m.promptCommand(server, prompt, fields[1:])
+		}
+		return func() tea.Msg {
+			return commandResultMsg{err: fmt.Errorf("unknown command /%s", name)}
+		}
+	}
+}
+
+// undoCommand reverts the file changes made during the last turn
+func (m *Model) undoCommand() tea.Cmd {
+	service := m.chatService
+	return func() tea.Msg {
+		checkpointer, ok := service.(chat.Checkpointer)
+		if !ok {
+			return commandResultMsg{err: fmt.Errorf("this chat service does not support undo")}
+		}
+
+		turn, restored, err := checkpointer.UndoLastTurn()
+		if err != nil {
+			return commandResultMsg{err: err}
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "**Reverted turn `%s`**\n\n", turn.ID)
+		if len(restored) == 0 {
+			sb.WriteString("No files needed to be restored.")
+		}
+		for _, path := range restored {
+			fmt.Fprintf(&sb, "- `%s`\n", path)
+		}
+		if len(turn.Skipped) > 0 {
+			sb.WriteString("\nNot restored, as they were not snapshotted:\n\n")
+			for _, skip := range turn.Skipped {
+				fmt.Fprintf(&sb, "- `%s`: %s\n", skip.Path, skip.Reason)
+			}
+		}
+
+		return commandResultMsg{content: sb.String()}
+	}
+}
+
+// formatToolProgress renders a tool progress update for the processing indicator
+func formatToolProgress(p core.Progress) string {
+	status := fmt.Sprintf("(%s: %s", p.Tool, p.Message)
+	if p.Total > 0 {
+		status += fmt.Sprintf(" %d/%d", p.Current, p.Total)
+	}
+	return status + ")"
+}
+
+// continueCommand resumes a turn that paused on a loop limit for more steps
+func (m *Model) continueCommand(args []string) tea.Cmd {
+	steps := chat.DefaultContinueSteps
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return func() tea.Msg {
+				return commandResultMsg{err: fmt.Errorf("usage: /continue [steps], where steps is a positive number")}
+			}
+		}
+		steps = n
+	}
+
+	service := m.chatService
+	ctx := m.startTurn()
+	return func() tea.Msg {
+		continuer, ok := service.(chat.Continuer)
+		if !ok {
+			return commandResultMsg{err: fmt.Errorf("this chat service does not support continuing turns")}
+		}
+
+		response, err := continuer.ContinueTurn(ctx, steps)
+		if err != nil {
+			return commandResultMsg{err: err}
+		}
+		return llmResponseMsg{response: response}
+	}
+}
+
+// mcpCommand lists the configured MCP servers and their status
+func (m *Model) mcpCommand() tea.Cmd {
+	service := m.chatService
+	return func() tea.Msg {
+		provider, ok := service.(chat.MCPProvider)
+		if !ok || provider.MCPServers() == nil {
+			return commandResultMsg{content: "No MCP servers are configured."}
+		}
+		servers := provider.MCPServers()
+
+		var sb strings.Builder
+		sb.WriteString("**MCP servers**\n\n")
+		for _, client := range servers.Clients() {
+			info := client.ServerInfo().ServerInfo
+			fmt.Fprintf(&sb, "- `%s`: connected to %s %s\n", client.Name(), info.Name, info.Version)
+		}
+
+		errs := servers.Errors()
+		names := make([]string, 0, len(errs))
+		for name := range errs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&sb, "- `%s`: failed\n\n```\n%v\n```\n", name, errs[name])
+		}
+
+		return commandResultMsg{content: sb.String()}
+	}
+}
+
+// mcpServers returns the MCP servers of a chat service, or an error if there are none
+func mcpServers(service chat.ChatServiceInterface) (*mcp.Manager, error) {
+	provider, ok := service.(chat.MCPProvider)
+	if !ok || provider.MCPServers() == nil {
+		return nil, fmt.Errorf("no MCP servers are configured")
+	}
+	return provider.MCPServers(), nil
+}
+
+// resourcesCommand lists the resources offered by the MCP servers
+func (m *Model) resourcesCommand() tea.Cmd {
+	service := m.chatService
+	return func() tea.Msg {
+		servers, err := mcpServers(service)
+		if err != nil {
+			return commandResultMsg{err: err}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), mcpCommandTimeout)
+		defer cancel()
+
+		resources, err := servers.ListResources(ctx)
+		if len(resources) == 0 {
+			if err != nil {
+				return commandResultMsg{err: err}
+			}
+			return commandResultMsg{content: "The MCP servers offer no resources."}
+		}
+
+		var sb strings.Builder
+		sb.WriteString("**MCP resources**\n\n")
+		for _, resource := range resources {
+			fmt.Fprintf(&sb, "- `%s` (%s): %s", resource.URI, resource.Server, resource.Name)
+			if resource.Description != "" {
+				fmt.Fprintf(&sb, " - %s", resource.Description)
+			}
+			sb.WriteString("\n")
+		}
+		if err != nil {
+			fmt.Fprintf(&sb, "\n**Error:** %v\n", err)
+		}
+		sb.WriteString("\nAttach one to your next message with `/resource <uri>`.")
+
+		return commandResultMsg{content: sb.String()}
+	}
+}
+
+// resourceCommand attaches an MCP resource to the next message
+func (m *Model) resourceCommand(args []string) tea.Cmd {
+	if len(args) != 1 {
+		return func() tea.Msg {
+			return commandResultMsg{err: fmt.Errorf("usage: /resource <uri>")}
+		}
+	}
+
+	service := m.chatService
+	return func() tea.Msg {
+		host, ok := service.(chat.MCPHost)
+		if !ok {
+			return commandResultMsg{err: fmt.Errorf("this chat service does not support MCP resources")}
+		}
+
+		attachment, err := host.AttachResource(args[0])
+		if err != nil {
+			return commandResultMsg{err: err}
+		}
+
+		return commandResultMsg{content: fmt.Sprintf("Attached `%s` from %s (%d characters) to your next message.",
+			attachment.URI, attachment.Server, len(attachment.Text))}
+	}
+}
+
+// promptsCommand lists the prompt templates offered by the MCP servers
+func (m *Model) promptsCommand() tea.Cmd {
+	service := m.chatService
+	return func() tea.Msg {
+		servers, err := mcpServers(service)
+		if err != nil {
+			return commandResultMsg{err: err}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), mcpCommandTimeout)
+		defer cancel()
+
+		prompts, err := servers.ListPrompts(ctx)
+		if len(prompts) == 0 {
+			if err != nil {
+				return commandResultMsg{err: err}
+			}
+			return commandResultMsg{content: "The MCP servers offer no prompts."}
+		}
+
+		var sb strings.Builder
+		sb.WriteString("**MCP prompts**\n\n")
+		for _, prompt := range prompts {
+			usage := strings.TrimSpace(fmt.Sprintf("/%s:%s %s", prompt.Server, prompt.Name, prompt.Usage()))
+			fmt.Fprintf(&sb, "- `%s`", usage)
+			if prompt.Description != "" {
+				fmt.Fprintf(&sb, ": %s", prompt.Description)
+			}
+			sb.WriteString("\n")
+		}
+		if err != nil {
+			fmt.Fprintf(&sb, "\n**Error:** %v\n", err)
+		}
+
+		return commandResultMsg{content: sb.String()}
+	}
+}
+
+// promptCommand renders an MCP prompt template and sends it to the model
+func (m *Model) promptCommand(server, prompt string, args []string) tea.Cmd {
+	service := m.chatService
+	ctx := m.startTurn()
+	return func() tea.Msg {
+		host, ok := service.(chat.MCPHost)
+		if !ok {
+			return commandResultMsg{err: fmt.Errorf("this chat service does not support MCP prompts")}
+		}
+
+		response, err := host.SendPrompt(ctx, server, prompt, args)
+		if err != nil {
+			return commandResultMsg{err: err}
+		}
+		return llmResponseMsg{response: response}
+	}
+}
@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/navicore/mcpterm-go/pkg/diff"
+)
+
+// diffIndent lines diff blocks up with the margin glamour gives other content
+const diffIndent = "  "
+
+var (
+	diffHeaderStyle  = lipgloss.NewStyle().Bold(true)
+	diffHunkStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#56B6C2"))
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#E06C75"))
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#98C379"))
+	diffContextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#AAAAAA"))
+
+	// Changed words within changed lines stand out with a background
+	diffRemovedWordStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Background(lipgloss.Color("#8B2F3A"))
+	diffAddedWordStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#FFFFFF")).
+				Background(lipgloss.Color("#2F6B3A"))
+)
+
+// wordMarkerPattern matches the [-removed-] and {+added+} markers of word diffs
+var wordMarkerPattern = regexp.MustCompile(`\[-.*?-\]|\{\+.*?\+\}`)
+
+// sideBySideMarkerPattern matches the marker column of side-by-side diff
+// rows, followed by the right-aligned line number of the new side
+var sideBySideMarkerPattern = regexp.MustCompile(` ([|<>]) ( {0,3}\d{1,4}| {4})( |$)`)
+
+// renderMarkdown renders message content as markdown, except for fenced
+// diff blocks, which are coloured line by line
+func renderMarkdown(renderer *glamour.TermRenderer, content string) (string, error) {
+	if !strings.Contains(content, "```diff") {
+		return renderer.Render(content)
+	}
+
+	var sb, markdown strings.Builder
+	flush := func() error {
+		if strings.TrimSpace(markdown.String()) == "" {
+			markdown.Reset()
+			return nil
+		}
+		rendered, err := renderer.Render(markdown.String())
+		if err != nil {
+			return err
+		}
+		sb.WriteString(rendered)
+		markdown.Reset()
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "```diff" {
+			markdown.WriteString(lines[i] + "\n")
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "```" {
+			end++
+		}
+		if err := flush(); err != nil {
+			return "", err
+		}
+		sb.WriteString(renderDiff(lines[i+1:end]) + "\n")
+		i = end
+	}
+	if err := flush(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// renderDiff colours the lines of a unified, word-level or side-by-side diff
+func renderDiff(lines []string) string {
+	var sb strings.Builder
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") ||
+			strings.HasPrefix(line, "diff ") || strings.HasPrefix(line, "Binary files "):
+			sb.WriteString(diffIndent + diffHeaderStyle.Render(line) + "\n")
+			i++
+		case strings.HasPrefix(line, "@@"):
+			sb.WriteString(diffIndent + diffHunkStyle.Render(line) + "\n")
+			i++
+		case strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+"):
+			n := renderChangeRun(&sb, lines[i:])
+			i += n
+		default:
+			sb.WriteString(diffIndent + renderDiffLine(line) + "\n")
+			i++
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// renderChangeRun renders a run of removed lines followed by added lines,
+// returning the number of lines it used. When both sides have the same
+// number of lines, each removed line is paired with an added line and the
+// words that differ between them are highlighted.
+func renderChangeRun(sb *strings.Builder, lines []string) int {
+	removed := 0
+	for removed < len(lines) && isChangeLine(lines[removed], '-') {
+		removed++
+	}
+	added := 0
+	for removed+added < len(lines) && isChangeLine(lines[removed+added], '+') {
+		added++
+	}
+	if removed != added {
+		for _, line := range lines[:removed] {
+			sb.WriteString(diffIndent + diffRemovedStyle.Render(line) + "\n")
+		}
+		for _, line := range lines[removed : removed+added] {
+			sb.WriteString(diffIndent + diffAddedStyle.Render(line) + "\n")
+		}
+		return removed + added
+	}
+
+	var oldLines, newLines []string
+	for i := 0; i < removed; i++ {
+		segments := diff.Words(lines[i][1:], lines[removed+i][1:])
+		var oldLine, newLine strings.Builder
+		oldLine.WriteString(diffRemovedStyle.Render("-"))
+		newLine.WriteString(diffAddedStyle.Render("+"))
+		for _, segment := range segments {
+			switch segment.Kind {
+			case '-':
+				oldLine.WriteString(diffRemovedWordStyle.Render(segment.Text))
+			case '+':
+				newLine.WriteString(diffAddedWordStyle.Render(segment.Text))
+			default:
+				oldLine.WriteString(diffRemovedStyle.Render(segment.Text))
+				newLine.WriteString(diffAddedStyle.Render(segment.Text))
+			}
+		}
+		oldLines = append(oldLines, oldLine.String())
+		newLines = append(newLines, newLine.String())
+	}
+	for _, line := range append(oldLines, newLines...) {
+		sb.WriteString(diffIndent + line + "\n")
+	}
+	return removed + added
+}
+
+// isChangeLine reports whether a line is a removed ('-') or added ('+') line
+// of a unified diff, as opposed to a file header
+func isChangeLine(line string, kind byte) bool {
+	if line == "" || line[0] != kind {
+		return false
+	}
+	return !strings.HasPrefix(line, "--- ") && !strings.HasPrefix(line, "+++ ")
+}
+
+// renderDiffLine colours a context line, which may carry word diff markers
+// or be a side-by-side row
+func renderDiffLine(line string) string {
+	if wordMarkerPattern.MatchString(line) {
+		return renderWordMarkers(line)
+	}
+
+	if match := sideBySideMarkerPattern.FindStringSubmatchIndex(line); match != nil && match[0] >= 5 {
+		left, marker, right := line[:match[2]], line[match[2]:match[3]], line[match[3]:]
+		switch marker {
+		case "|":
+			return diffRemovedStyle.Render(left) + diffHunkStyle.Render(marker) + diffAddedStyle.Render(right)
+		case "<":
+			return diffRemovedStyle.Render(left) + diffHunkStyle.Render(marker) + right
+		case ">":
+			return left + diffHunkStyle.Render(marker) + diffAddedStyle.Render(right)
+		}
+	}
+	return diffContextStyle.Render(line)
+}
+
+// renderWordMarkers colours the changed words of a word diff line and drops
+// their markers
+func renderWordMarkers(line string) string {
+	var sb strings.Builder
+	last := 0
+	for _, match := range wordMarkerPattern.FindAllStringIndex(line, -1) {
+		sb.WriteString(diffContextStyle.Render(line[last:match[0]]))
+		word := line[match[0]+2 : match[1]-2]
+		if line[match[0]] == '[' {
+			sb.WriteString(diffRemovedWordStyle.Render(word))
+		} else {
+			sb.WriteString(diffAddedWordStyle.Render(word))
+		}
+		last = match[1]
+	}
+	sb.WriteString(diffContextStyle.Render(line[last:]))
+	return sb.String()
+}
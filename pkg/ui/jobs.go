@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/navicore/mcpterm-go/pkg/chat"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
+)
+
+// jobsPollInterval is how often the jobs panel is refreshed
+const jobsPollInterval = time.Second
+
+// maxJobsPanelRows is the number of jobs listed in the panel before the rest are summarized
+const maxJobsPanelRows = 4
+
+// jobsMsg carries the current background jobs of the chat service
+type jobsMsg struct {
+	jobs []core.Job
+}
+
+var (
+	jobsTitleStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFA500")).
+			PaddingLeft(2).
+			Bold(true)
+
+	jobsRowStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#AAAAAA")).
+			PaddingLeft(2)
+)
+
+// pollJobs schedules the next refresh of the jobs panel, if the chat service runs jobs
+func (m Model) pollJobs() tea.Cmd {
+	provider, ok := m.chatService.(chat.JobProvider)
+	if !ok {
+		return nil
+	}
+
+	return tea.Tick(jobsPollInterval, func(time.Time) tea.Msg {
+		return jobsMsg{jobs: provider.Jobs()}
+	})
+}
+
+// runningJobs returns the jobs that have not exited
+func runningJobs(jobs []core.Job) []core.Job {
+	var running []core.Job
+	for _, job := range jobs {
+		if job.Running {
+			running = append(running, job)
+		}
+	}
+	return running
+}
+
+// renderJobsPanel lists the running background jobs, or returns "" if there are none
+func (m Model) renderJobsPanel() string {
+	running := runningJobs(m.jobs)
+	if len(running) == 0 {
+		return ""
+	}
+
+	lines := []string{jobsTitleStyle.Render(fmt.Sprintf("Jobs (%d running)", len(running)))}
+	for i, job := range running {
+		if i == maxJobsPanelRows {
+			lines = append(lines, jobsRowStyle.Render(fmt.Sprintf("  … %d more, see /jobs", len(running)-i)))
+			break
+		}
+
+		row := fmt.Sprintf("● #%d %s  pid %d  %s", job.ID, job.Command, job.PID, formatJobAge(time.Since(job.StartedAt)))
+		lines = append(lines, jobsRowStyle.Render(truncateLine(row, m.windowWidth-4)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatJobAge renders how long a job has been running, e.g. "2m05s"
+func formatJobAge(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return d.String()
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// truncateLine shortens a line to width characters, marking the cut with an ellipsis
+func truncateLine(line string, width int) string {
+	runes := []rune(line)
+	if width <= 1 || len(runes) <= width {
+		return line
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// jobsCommand lists all background jobs of the conversation
+func (m *Model) jobsCommand() tea.Cmd {
+	service := m.chatService
+	return func() tea.Msg {
+		provider, ok := service.(chat.JobProvider)
+		if !ok {
+			return commandResultMsg{err: fmt.Errorf("this chat service does not run background jobs")}
+		}
+
+		jobs := provider.Jobs()
+		if len(jobs) == 0 {
+			return commandResultMsg{content: "No background jobs have been started."}
+		}
+
+		var sb strings.Builder
+		sb.WriteString("**Background jobs**\n\n")
+		for _, job := range jobs {
+			status := fmt.Sprintf("running for %s", formatJobAge(time.Since(job.StartedAt)))
+			if !job.Running {
+				status = fmt.Sprintf("exited with status %d", job.ExitCode)
+			}
+			fmt.Fprintf(&sb, "- #%d `%s` (pid %d): %s\n", job.ID, job.Command, job.PID, status)
+		}
+
+		return commandResultMsg{content: sb.String()}
+	}
+}
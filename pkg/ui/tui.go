@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/navicore/mcpterm-go/pkg/chat"
+	"github.com/navicore/mcpterm-go/pkg/tools/core"
 )
 
 // Message represents a chat message
@@ -24,15 +27,21 @@ type llmResponseMsg struct {
 	err      error
 }
 
+// ToolProgressMsg reports progress from a tool running in the background
+type ToolProgressMsg struct {
+	Progress core.Progress
+}
+
 // Model represents the TUI state
 type Model struct {
-	viewport    viewport.Model
-	editor      *ViEditor
-	messages    []Message
-	chatService chat.ChatServiceInterface
-	err         error
-	showHelp    bool
-	windowWidth int
+	viewport     viewport.Model
+	editor       *ViEditor
+	messages     []Message
+	chatService  chat.ChatServiceInterface
+	err          error
+	showHelp     bool
+	windowWidth  int
+	windowHeight int
 
 	// Focus state
 	viewportFocused bool
@@ -44,7 +53,15 @@ type Model struct {
 	viewportVisual    bool     // Whether visual mode is active in viewport
 
 	// Processing state
-	isProcessing bool // Whether the LLM is currently processing a response
+	isProcessing bool               // Whether the LLM is currently processing a response
+	toolProgress string             // Latest progress reported by a running tool
+	cancelTurn   context.CancelFunc // Stops the turn sent to the model, nil when none is running
+
+	// Background jobs started by tools, shown in the jobs panel
+	jobs []core.Job
+
+	// Tool action waiting for the user's approval
+	pendingApproval *ToolApprovalMsg
 }
 
 // Style definitions
@@ -144,8 +161,8 @@ func (m *Model) updateViewportContent() {
 			sb.WriteString(botMessageStyle.Render(msg.Username+":") + "\n")
 		}
 
-		// Render the message content as markdown
-		mdContent, err := renderer.Render(msg.Content)
+		// Render the message content as markdown, with diffs coloured
+		mdContent, err := renderMarkdown(renderer, msg.Content)
 		if err != nil {
 			// Fallback to plain text if markdown rendering fails
 			if msg.IsUser {
@@ -165,7 +182,15 @@ func (m *Model) updateViewportContent() {
 	// Add processing indicator if LLM is generating a response
 	if m.isProcessing {
 		sb.WriteString(botMessageStyle.Render("Assistant:") + "\n")
-		sb.WriteString(processingStyle.Render("⏳ Processing...") + "\n\n")
+		status := "⏳ Processing... (Esc to cancel)"
+		if m.toolProgress != "" {
+			status += " " + m.toolProgress
+		}
+		sb.WriteString(processingStyle.Render(status) + "\n\n")
+
+		if m.pendingApproval != nil {
+			sb.WriteString(m.renderApprovalPrompt() + "\n\n")
+		}
 	}
 
 	content := sb.String()
@@ -238,9 +263,35 @@ func (m Model) renderHelp() string {
 	}
 }
 
+// startTurn returns the context for a turn sent to the model, which Esc
+// and Ctrl+C cancel until the turn ends
+func (m *Model) startTurn() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelTurn = cancel
+	return ctx
+}
+
+// endTurn releases the context of a finished turn
+func (m *Model) endTurn() {
+	if m.cancelTurn != nil {
+		m.cancelTurn()
+		m.cancelTurn = nil
+	}
+}
+
+// cancelRunningTurn stops the turn the model is working on; its response
+// arrives once the backend request or tool in progress returns
+func (m *Model) cancelRunningTurn() {
+	if m.cancelTurn != nil {
+		m.cancelTurn()
+		m.toolProgress = "(cancelling)"
+		m.updateViewportContent()
+	}
+}
+
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	return m.editor.Init()
+	return tea.Batch(m.editor.Init(), m.pollJobs())
 }
 
 // Update implements tea.Model
@@ -255,22 +306,101 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case llmResponseMsg:
 		// Handle LLM response
 		m.isProcessing = false
+		m.toolProgress = ""
+		m.pendingApproval = nil
+		m.endTurn()
 
+		if errors.Is(msg.err, context.Canceled) {
+			m.AddMessage(Message{Username: "System", Content: "Cancelled.", IsUser: false})
+			return m, nil
+		}
 		if msg.err != nil {
 			m.err = msg.err
 			return m, nil
 		}
 
-		// Add bot response
+		// Add bot response, or the notice explaining why the turn stopped
+		username := "Assistant"
+		if msg.response.Sender == "system" {
+			username = "System"
+		}
 		m.AddMessage(Message{
-			Username: "Assistant",
+			Username: username,
 			Content:  msg.response.Content,
 			IsUser:   false,
 		})
 
 		return m, nil
 
+	case ToolProgressMsg:
+		// Show what the running tool is doing
+		if m.isProcessing {
+			m.toolProgress = formatToolProgress(msg.Progress)
+			m.updateViewportContent()
+		}
+		return m, nil
+
+	case ToolApprovalMsg:
+		// A tool waits for the user to allow or refuse an action
+		m.answerApproval(false) // Refuse a stale request, if any
+		m.pendingApproval = &msg
+		m.updateViewportContent()
+		return m, nil
+
+	case jobsMsg:
+		// Resize the viewport when the jobs panel appears, disappears or changes height
+		before := len(runningJobs(m.jobs))
+		m.jobs = msg.jobs
+		if len(runningJobs(m.jobs)) != before {
+			m.layout()
+		}
+		return m, m.pollJobs()
+
+	case commandResultMsg:
+		// Handle slash command output
+		m.isProcessing = false
+		m.endTurn()
+
+		content := msg.content
+		if errors.Is(msg.err, context.Canceled) {
+			content = "Cancelled."
+		} else if msg.err != nil {
+			content = fmt.Sprintf("**Error:** %v", msg.err)
+		}
+
+		m.AddMessage(Message{
+			Username: "System",
+			Content:  content,
+			IsUser:   false,
+		})
+
+		return m, nil
+
 	case tea.KeyMsg:
+		// While a tool waits for approval, keys answer it
+		if m.pendingApproval != nil {
+			switch msg.String() {
+			case "y", "Y":
+				m.answerApproval(true)
+			case "n", "N":
+				m.answerApproval(false)
+			case "esc", "ctrl+c":
+				// Refuse and give up on the turn
+				m.answerApproval(false)
+				m.cancelRunningTurn()
+			}
+			return m, nil
+		}
+
+		// While the model works, Esc and Ctrl+C cancel the turn
+		if m.cancelTurn != nil {
+			switch msg.String() {
+			case "esc", "ctrl+c":
+				m.cancelRunningTurn()
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
@@ -318,6 +448,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// Add to input history
 					m.editor.AddToHistory(userMsg)
 
+					// Slash commands are handled locally instead of being sent to the model
+					if isCommand(userMsg) {
+						m.editor.Reset()
+						m.isProcessing = true
+						m.updateViewportContent()
+						cmd := m.runCommand(userMsg)
+						return m, cmd
+					}
+
 					// Add user message immediately
 					m.AddMessage(Message{
 						Content: userMsg,
@@ -332,8 +471,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.updateViewportContent()
 
 					// Process message in the background
+					ctx := m.startTurn()
 					return m, func() tea.Msg {
-						response, err := m.chatService.SendMessage(userMsg)
+						response, err := m.chatService.SendMessage(ctx, userMsg)
 						return llmResponseMsg{
 							response: response,
 							err:      err,
@@ -817,22 +957,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.WindowSizeMsg:
 		m.windowWidth = msg.Width
-
-		// Adjust viewport height to leave room for input and help text
-		headerHeight := 2
-		footerHeight := 5
-
-		if m.showHelp {
-			footerHeight += 2 // Extra line for detailed vi help
-		}
-
-		m.viewport.Width = msg.Width
-		m.viewport.Height = msg.Height - headerHeight - footerHeight
-
-		m.editor.SetWidth(msg.Width - 4)
-
-		// Update viewport content after resize
-		m.updateViewportContent()
+		m.windowHeight = msg.Height
+		m.layout()
 
 		return m, nil
 	}
@@ -866,6 +992,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// layout sizes the viewport to the window, leaving room for the jobs panel,
+// input and help text
+func (m *Model) layout() {
+	headerHeight := 2
+	footerHeight := 5
+
+	if m.showHelp {
+		footerHeight += 2 // Extra line for detailed vi help
+	}
+
+	if panel := m.renderJobsPanel(); panel != "" {
+		footerHeight += lipgloss.Height(panel)
+	}
+
+	m.viewport.Width = m.windowWidth
+	m.viewport.Height = m.windowHeight - headerHeight - footerHeight
+
+	m.editor.SetWidth(m.windowWidth - 4)
+
+	// Update viewport content after resize
+	m.updateViewportContent()
+}
+
 // renderModeIndicator renders the current mode indicator
 func (m Model) renderModeIndicator() string {
 	focusText := "INPUT"
@@ -919,7 +1068,12 @@ func (m Model) View() string {
 		viewportView = m.viewport.View()
 	}
 
-	// Combine the viewport, status line, input field, and help text
+	// Running background jobs are listed between the viewport and the status line
+	if panel := m.renderJobsPanel(); panel != "" {
+		viewportView += "\n" + panel
+	}
+
+	// Combine the viewport, jobs panel, status line, input field, and help text
 	helpText := m.renderHelp()
 
 	return fmt.Sprintf(